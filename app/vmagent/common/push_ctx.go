@@ -17,6 +17,12 @@ type PushCtx struct {
 
 	// Samples contains flat list of all the samples used in WriteRequest.
 	Samples []prompbmarshal.Sample
+
+	// Exemplars contains flat list of all the exemplars used in WriteRequest.
+	Exemplars []prompbmarshal.Exemplar
+
+	// ExemplarLabels contains flat list of all the exemplar labels used in WriteRequest.
+	ExemplarLabels []prompbmarshal.Label
 }
 
 // Reset resets ctx.
@@ -26,6 +32,7 @@ func (ctx *PushCtx) Reset() {
 		ts := &tss[i]
 		ts.Labels = nil
 		ts.Samples = nil
+		ts.Exemplars = nil
 	}
 	ctx.WriteRequest.Timeseries = ctx.WriteRequest.Timeseries[:0]
 
@@ -33,6 +40,15 @@ func (ctx *PushCtx) Reset() {
 	ctx.Labels = ctx.Labels[:0]
 
 	ctx.Samples = ctx.Samples[:0]
+
+	exemplars := ctx.Exemplars
+	for i := range exemplars {
+		exemplars[i].Labels = nil
+	}
+	ctx.Exemplars = exemplars[:0]
+
+	promrelabel.CleanLabels(ctx.ExemplarLabels)
+	ctx.ExemplarLabels = ctx.ExemplarLabels[:0]
 }
 
 // GetPushCtx returns PushCtx from pool.
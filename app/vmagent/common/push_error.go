@@ -0,0 +1,34 @@
+package common
+
+import (
+	"fmt"
+)
+
+// PushError is returned by insert handlers if the parsed data cannot be pushed to remote storage.
+//
+// It allows distinguishing push errors, which may disappear on the next attempt, from data parsing errors.
+type PushError struct {
+	Err error
+}
+
+// Error implements error interface.
+func (pe *PushError) Error() string {
+	return fmt.Sprintf("cannot push data to remote storage: %s", pe.Err)
+}
+
+// Unwrap returns the underlying error.
+func (pe *PushError) Unwrap() error {
+	return pe.Err
+}
+
+// NewPushError returns PushError for the given err.
+//
+// nil is returned if err is nil.
+func NewPushError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PushError{
+		Err: err,
+	}
+}
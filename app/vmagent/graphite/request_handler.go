@@ -20,10 +20,21 @@ var (
 //
 // See https://graphite.readthedocs.io/en/latest/feeding-carbon.html#the-plaintext-protocol
 func InsertHandler(r io.Reader) error {
-	return stream.Parse(r, insertRows)
+	return stream.Parse(r, func(rows []parser.Row) error {
+		return insertRows(rows, false)
+	})
 }
 
-func insertRows(rows []parser.Row) error {
+// TryInsertHandlerForReader processes data in graphite plaintext protocol from r.
+//
+// It returns common.PushError if the data cannot be pushed because of full `-remoteWrite.url` queues. See remotewrite.TryPush.
+func TryInsertHandlerForReader(r io.Reader) error {
+	return stream.Parse(r, func(rows []parser.Row) error {
+		return insertRows(rows, true)
+	})
+}
+
+func insertRows(rows []parser.Row, tryPush bool) error {
 	ctx := common.GetPushCtx()
 	defer common.PutPushCtx(ctx)
 
@@ -56,7 +67,13 @@ func insertRows(rows []parser.Row) error {
 	ctx.WriteRequest.Timeseries = tssDst
 	ctx.Labels = labels
 	ctx.Samples = samples
-	remotewrite.Push(nil, &ctx.WriteRequest)
+	if tryPush {
+		if err := remotewrite.TryPush(nil, &ctx.WriteRequest); err != nil {
+			return common.NewPushError(err)
+		}
+	} else {
+		remotewrite.Push(nil, &ctx.WriteRequest)
+	}
 	rowsInserted.Add(len(rows))
 	rowsPerInsert.Update(float64(len(rows)))
 	return nil
@@ -38,7 +38,7 @@ var (
 // See https://github.com/influxdata/telegraf/tree/master/plugins/inputs/socket_listener/
 func InsertHandlerForReader(r io.Reader, isGzipped bool) error {
 	return stream.Parse(r, isGzipped, "", "", func(db string, rows []parser.Row) error {
-		return insertRows(nil, db, rows, nil)
+		return insertRows(nil, db, rows, nil, false)
 	})
 }
 
@@ -56,11 +56,20 @@ func InsertHandlerForHTTP(at *auth.Token, req *http.Request) error {
 	// Read db tag from https://docs.influxdata.com/influxdb/v1.7/tools/api/#write-http-endpoint
 	db := q.Get("db")
 	return stream.Parse(req.Body, isGzipped, precision, db, func(db string, rows []parser.Row) error {
-		return insertRows(at, db, rows, extraLabels)
+		return insertRows(at, db, rows, extraLabels, false)
 	})
 }
 
-func insertRows(at *auth.Token, db string, rows []parser.Row, extraLabels []prompbmarshal.Label) error {
+// TryInsertHandlerForReader processes data in influx line protocol from r.
+//
+// It returns common.PushError if the data cannot be pushed because of full `-remoteWrite.url` queues. See remotewrite.TryPush.
+func TryInsertHandlerForReader(r io.Reader, isGzipped bool) error {
+	return stream.Parse(r, isGzipped, "", "", func(db string, rows []parser.Row) error {
+		return insertRows(nil, db, rows, nil, true)
+	})
+}
+
+func insertRows(at *auth.Token, db string, rows []parser.Row, extraLabels []prompbmarshal.Label, tryPush bool) error {
 	ctx := getPushCtx()
 	defer putPushCtx(ctx)
 
@@ -130,7 +139,13 @@ func insertRows(at *auth.Token, db string, rows []parser.Row, extraLabels []prom
 	ctx.ctx.Labels = labels
 	ctx.ctx.Samples = samples
 	ctx.commonLabels = commonLabels
-	remotewrite.Push(at, &ctx.ctx.WriteRequest)
+	if tryPush {
+		if err := remotewrite.TryPush(at, &ctx.ctx.WriteRequest); err != nil {
+			return common.NewPushError(err)
+		}
+	} else {
+		remotewrite.Push(at, &ctx.ctx.WriteRequest)
+	}
 	rowsInserted.Add(rowsTotal)
 	if at != nil {
 		rowsTenantInserted.Get(at).Add(rowsTotal)
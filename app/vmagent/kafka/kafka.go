@@ -0,0 +1,334 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/common"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/graphite"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/influx"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/prometheusimport"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/promremotewrite"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/vmimport"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/flagutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/kafkautil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+var (
+	topics = flagutil.NewArrayString("kafka.consumer.topic", "Kafka topic names for data consumption. "+
+		"See https://docs.victoriametrics.com/vmagent.html#reading-metrics-from-kafka")
+	brokers = flagutil.NewArrayString("kafka.consumer.topic.brokers", "List of brokers to connect for the corresponding -kafka.consumer.topic, "+
+		"e.g. -kafka.consumer.topic.brokers='host-1:9092;host-2:9092'")
+	defaultFormat = flag.String("kafka.consumer.topic.defaultFormat", "promremotewrite", "Expected data format in the topic if -kafka.consumer.topic.format is skipped")
+	formats       = flagutil.NewArrayString("kafka.consumer.topic.format", "Data format for the corresponding -kafka.consumer.topic. "+
+		"Valid formats: influx, prometheus, promremotewrite, graphite, jsonline")
+	groupIDs = flagutil.NewArrayString("kafka.consumer.topic.groupID", "Consumer group id for the corresponding -kafka.consumer.topic. "+
+		"Consumed offsets are committed for this group after the data is passed to -remoteWrite.url queues. Defaults to vmagent")
+	isGzipped = flagutil.NewArrayBool("kafka.consumer.topic.isGzipped", "Whether messages at the corresponding -kafka.consumer.topic are gzip-compressed. "+
+		"Only prometheus, jsonline and influx formats accept gzipped messages")
+	options = flagutil.NewArrayString("kafka.consumer.topic.options", "Optional key=value;key1=value2 settings for the corresponding -kafka.consumer.topic. "+
+		"Supported options: client.id, security.protocol, sasl.mechanisms, auto.offset.reset. "+
+		"See https://docs.victoriametrics.com/vmagent.html#reading-metrics-from-kafka")
+	basicAuthUsername = flagutil.NewArrayString("kafka.consumer.topic.basicAuth.username", "Optional SASL username for the corresponding -kafka.consumer.topic. "+
+		"Must be used in conjunction with -kafka.consumer.topic.options='security.protocol=SASL_SSL;sasl.mechanisms=PLAIN'")
+	basicAuthPassword = flagutil.NewArrayString("kafka.consumer.topic.basicAuth.password", "Optional SASL password for the corresponding -kafka.consumer.topic. "+
+		"Must be used in conjunction with -kafka.consumer.topic.options='security.protocol=SASL_SSL;sasl.mechanisms=PLAIN'")
+)
+
+var consumers []*consumer
+
+// Init starts consuming data from Kafka topics specified via -kafka.consumer.topic.
+//
+// The consumed data is passed to remotewrite.Push, so remotewrite.Init must be called before Init.
+func Init() {
+	for i, topic := range *topics {
+		format := formats.GetOptionalArg(i)
+		if format == "" {
+			format = *defaultFormat
+		}
+		handler, err := getHandler(format, isGzipped.GetOptionalArg(i))
+		if err != nil {
+			logger.Fatalf("invalid -kafka.consumer.topic.format for -kafka.consumer.topic=%q: %s", topic, err)
+		}
+		bs, err := kafkautil.ParseBrokers(brokers.GetOptionalArg(i))
+		if err != nil {
+			logger.Fatalf("invalid -kafka.consumer.topic.brokers for -kafka.consumer.topic=%q: %s", topic, err)
+		}
+		opts, err := kafkautil.ParseOptions(options.GetOptionalArg(i))
+		if err != nil {
+			logger.Fatalf("invalid -kafka.consumer.topic.options for -kafka.consumer.topic=%q: %s", topic, err)
+		}
+		groupID := groupIDs.GetOptionalArg(i)
+		if groupID == "" {
+			groupID = "vmagent"
+		}
+		cfg := &kafkautil.Config{
+			Brokers:  bs,
+			Options:  opts,
+			Username: basicAuthUsername.GetOptionalArg(i),
+			Password: basicAuthPassword.GetOptionalArg(i),
+		}
+		c, err := newConsumer(topic, groupID, cfg, handler)
+		if err != nil {
+			logger.Fatalf("cannot start consuming -kafka.consumer.topic=%q: %s", topic, err)
+		}
+		consumers = append(consumers, c)
+	}
+}
+
+// Stop stops consuming data from Kafka topics.
+//
+// It must be called before remotewrite.Stop, so the consumed data is flushed to -remoteWrite.url queues.
+func Stop() {
+	for _, c := range consumers {
+		c.MustStop()
+	}
+	consumers = nil
+}
+
+func getHandler(format string, isGzipped bool) (func(r io.Reader) error, error) {
+	switch format {
+	case "promremotewrite":
+		return func(r io.Reader) error {
+			return promremotewrite.TryInsertHandlerForReader(r, false)
+		}, nil
+	case "influx":
+		return func(r io.Reader) error {
+			return influx.TryInsertHandlerForReader(r, isGzipped)
+		}, nil
+	case "prometheus":
+		return func(r io.Reader) error {
+			return prometheusimport.TryInsertHandlerForReader(r, isGzipped)
+		}, nil
+	case "graphite":
+		return graphite.TryInsertHandlerForReader, nil
+	case "jsonline":
+		return func(r io.Reader) error {
+			return vmimport.TryInsertHandlerForReader(r, isGzipped)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q; supported formats: influx, prometheus, promremotewrite, graphite, jsonline", format)
+	}
+}
+
+// consumer reads messages from Kafka topic and passes them to handler.
+//
+// Offsets for the consumed messages are committed only after handler successfully pushes them,
+// so the messages are processed with at-least-once semantics.
+//
+// The handler must return common.PushError if the parsed data cannot be pushed to remote storage.
+// Such messages are retried, while messages with other errors are skipped, since they cannot be parsed on the next attempt too.
+type consumer struct {
+	topic   string
+	kc      *kgo.Client
+	handler func(r io.Reader) error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	messagesRead   *metrics.Counter
+	bytesRead      *metrics.Counter
+	parseErrors    *metrics.Counter
+	pushErrors     *metrics.Counter
+	commitErrors   *metrics.Counter
+	fetchErrors    *metrics.Counter
+	commitDuration *metrics.Histogram
+}
+
+func newConsumer(topic, groupID string, cfg *kafkautil.Config, handler func(r io.Reader) error) (*consumer, error) {
+	resetOffset := kgo.NewOffset().AtStart()
+	options := make(map[string]string, len(cfg.Options))
+	for k, v := range cfg.Options {
+		if k != "auto.offset.reset" {
+			options[k] = v
+			continue
+		}
+		switch v {
+		case "earliest", "smallest", "beginning":
+			resetOffset = kgo.NewOffset().AtStart()
+		case "latest", "largest", "end":
+			resetOffset = kgo.NewOffset().AtEnd()
+		default:
+			return nil, fmt.Errorf("unsupported auto.offset.reset=%q; supported values: earliest, latest", v)
+		}
+	}
+	cfgCopy := *cfg
+	cfgCopy.Options = options
+	opts, err := cfgCopy.NewClientOpts()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts,
+		kgo.ConsumeTopics(topic),
+		kgo.ConsumerGroup(groupID),
+		kgo.ConsumeResetOffset(resetOffset),
+		kgo.DisableAutoCommit(),
+		kgo.BlockRebalanceOnPoll(),
+	)
+	kc, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create Kafka client: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &consumer{
+		topic:   topic,
+		kc:      kc,
+		handler: handler,
+		ctx:     ctx,
+		cancel:  cancel,
+
+		messagesRead:   metrics.GetOrCreateCounter(fmt.Sprintf(`vmagent_kafka_consumer_messages_read_total{topic=%q}`, topic)),
+		bytesRead:      metrics.GetOrCreateCounter(fmt.Sprintf(`vmagent_kafka_consumer_bytes_read_total{topic=%q}`, topic)),
+		parseErrors:    metrics.GetOrCreateCounter(fmt.Sprintf(`vmagent_kafka_consumer_parse_errors_total{topic=%q}`, topic)),
+		pushErrors:     metrics.GetOrCreateCounter(fmt.Sprintf(`vmagent_kafka_consumer_push_errors_total{topic=%q}`, topic)),
+		commitErrors:   metrics.GetOrCreateCounter(fmt.Sprintf(`vmagent_kafka_consumer_commit_errors_total{topic=%q}`, topic)),
+		fetchErrors:    metrics.GetOrCreateCounter(fmt.Sprintf(`vmagent_kafka_consumer_fetch_errors_total{topic=%q}`, topic)),
+		commitDuration: metrics.GetOrCreateHistogram(fmt.Sprintf(`vmagent_kafka_consumer_commit_duration_seconds{topic=%q}`, topic)),
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.run()
+	}()
+	logger.Infof("started consuming Kafka topic %q with consumer group %q", topic, groupID)
+	return c, nil
+}
+
+// MustStop stops c.
+//
+// Messages, which were successfully processed by handler, are committed before the return.
+func (c *consumer) MustStop() {
+	c.cancel()
+	c.wg.Wait()
+	c.kc.Close()
+	logger.Infof("stopped consuming Kafka topic %q", c.topic)
+}
+
+func (c *consumer) run() {
+	for {
+		fetches := c.kc.PollFetches(c.ctx)
+		if fetches.IsClientClosed() || c.ctx.Err() != nil {
+			return
+		}
+		fetches.EachError(func(topic string, partition int32, err error) {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			c.fetchErrors.Inc()
+			fetchErrorLogger.Warnf("error when fetching messages from Kafka topic %q, partition %d: %s", topic, partition, err)
+		})
+		if err := c.processFetches(fetches); err != nil {
+			// Do not commit offsets for the polled messages, so they are consumed again after the restart.
+			logger.Errorf("stopping consuming Kafka topic %q without committing offsets for the last polled messages; "+
+				"they will be consumed again after the restart: %s", c.topic, err)
+			return
+		}
+		c.commit()
+		c.kc.AllowRebalance()
+	}
+}
+
+// processFetches processes all the records from fetches.
+//
+// It returns non-nil error if some records couldn't be pushed to remote storage before c is stopped.
+func (c *consumer) processFetches(fetches kgo.Fetches) error {
+	iter := fetches.RecordIter()
+	for !iter.Done() {
+		if err := c.processRecord(iter.Next()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processRecord passes r to c.handler.
+//
+// Messages, which cannot be parsed, are skipped. Messages, which cannot be pushed to remote storage, are retried until c is stopped.
+// Non-nil error is returned if r couldn't be pushed to remote storage before c is stopped.
+func (c *consumer) processRecord(r *kgo.Record) error {
+	c.messagesRead.Inc()
+	c.bytesRead.Add(len(r.Value))
+	retryDuration := pushRetryMinInterval
+	for {
+		err := c.handler(bytes.NewReader(r.Value))
+		if err == nil {
+			return nil
+		}
+		var pe *common.PushError
+		if !errors.As(err, &pe) {
+			// Skip the message, since it cannot be processed on the next attempt too.
+			c.parseErrors.Inc()
+			parseErrorLogger.Errorf("cannot process message from Kafka topic %q, partition %d, offset %d: %s", r.Topic, r.Partition, r.Offset, err)
+			return nil
+		}
+		c.pushErrors.Inc()
+		if c.ctx.Err() != nil {
+			return fmt.Errorf("cannot push message from partition %d, offset %d: %w", r.Partition, r.Offset, err)
+		}
+		// Retry the message, since push errors may be temporary.
+		// The data from the message may be duplicated at remote storage if it was partially pushed.
+		pushErrorLogger.Warnf("cannot push message from Kafka topic %q, partition %d, offset %d: %s; retrying in %.3f seconds",
+			r.Topic, r.Partition, r.Offset, err, retryDuration.Seconds())
+		select {
+		case <-c.ctx.Done():
+		case <-time.After(retryDuration):
+		}
+		retryDuration *= 2
+		if retryDuration > time.Minute {
+			retryDuration = time.Minute
+		}
+	}
+}
+
+// pushRetryMinInterval is the initial interval between attempts to push messages, which couldn't be pushed to remote storage.
+var pushRetryMinInterval = time.Second
+
+// commit commits offsets for the processed messages.
+//
+// It retries on errors, since uncommitted messages are consumed again after the restart or rebalance,
+// which results in duplicate data.
+func (c *consumer) commit() {
+	retryDuration := time.Second
+	for {
+		startTime := time.Now()
+		// Use background context, so the processed messages are committed on graceful shutdown.
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := c.kc.CommitUncommittedOffsets(ctx)
+		cancel()
+		c.commitDuration.UpdateDuration(startTime)
+		if err == nil {
+			return
+		}
+		c.commitErrors.Inc()
+		if c.ctx.Err() != nil {
+			logger.Errorf("cannot commit offsets for Kafka topic %q on shutdown; the last consumed messages will be consumed again: %s", c.topic, err)
+			return
+		}
+		logger.Warnf("cannot commit offsets for Kafka topic %q: %s; retrying in %.3f seconds", c.topic, err, retryDuration.Seconds())
+		select {
+		case <-c.ctx.Done():
+		case <-time.After(retryDuration):
+		}
+		retryDuration *= 2
+		if retryDuration > time.Minute {
+			retryDuration = time.Minute
+		}
+	}
+}
+
+var (
+	fetchErrorLogger = logger.WithThrottler("kafkaFetchError", 5*time.Second)
+	parseErrorLogger = logger.WithThrottler("kafkaParseError", 5*time.Second)
+	pushErrorLogger  = logger.WithThrottler("kafkaPushError", 5*time.Second)
+)
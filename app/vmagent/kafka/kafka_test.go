@@ -0,0 +1,295 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/common"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/kafkautil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompb"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/promremotewrite/stream"
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/golang/snappy"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestGetHandler(t *testing.T) {
+	f := func(format string, resultExpected bool) {
+		t.Helper()
+		_, err := getHandler(format, false)
+		if resultExpected && err != nil {
+			t.Fatalf("unexpected error for format %q: %s", format, err)
+		}
+		if !resultExpected && err == nil {
+			t.Fatalf("expecting non-nil error for format %q", format)
+		}
+	}
+	f("promremotewrite", true)
+	f("influx", true)
+	f("prometheus", true)
+	f("graphite", true)
+	f("jsonline", true)
+	f("", false)
+	f("foobar", false)
+}
+
+func TestNewConsumerFailure(t *testing.T) {
+	f := func(options map[string]string) {
+		t.Helper()
+		cfg := &kafkautil.Config{
+			Brokers: []string{"localhost:9092"},
+			Options: options,
+		}
+		handler := func(r io.Reader) error {
+			return nil
+		}
+		if _, err := newConsumer("foo", "bar", cfg, handler); err == nil {
+			t.Fatalf("expecting non-nil error for options %v", options)
+		}
+	}
+	f(map[string]string{
+		"auto.offset.reset": "foo",
+	})
+	f(map[string]string{
+		"unsupported.option": "foo",
+	})
+}
+
+func TestConsumerProcessRecord(t *testing.T) {
+	defer func(d time.Duration) {
+		pushRetryMinInterval = d
+	}(pushRetryMinInterval)
+	pushRetryMinInterval = time.Millisecond
+
+	newTestConsumer := func(handler func(r io.Reader) error) *consumer {
+		ctx, cancel := context.WithCancel(context.Background())
+		s := metrics.NewSet()
+		return &consumer{
+			topic:        "foo",
+			handler:      handler,
+			ctx:          ctx,
+			cancel:       cancel,
+			messagesRead: s.NewCounter(`vmagent_kafka_consumer_messages_read_total`),
+			bytesRead:    s.NewCounter(`vmagent_kafka_consumer_bytes_read_total`),
+			parseErrors:  s.NewCounter(`vmagent_kafka_consumer_parse_errors_total`),
+			pushErrors:   s.NewCounter(`vmagent_kafka_consumer_push_errors_total`),
+		}
+	}
+	r := &kgo.Record{
+		Topic: "foo",
+		Value: []byte("foo 123"),
+	}
+
+	// parse errors are skipped
+	calls := 0
+	c := newTestConsumer(func(r io.Reader) error {
+		calls++
+		return errors.New("cannot parse data")
+	})
+	if err := c.processRecord(r); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls != 1 {
+		t.Fatalf("unexpected number of handler calls for parse error; got %d; want 1", calls)
+	}
+	if n := c.parseErrors.Get(); n != 1 {
+		t.Fatalf("unexpected number of parse errors; got %d; want 1", n)
+	}
+
+	// push errors are retried
+	calls = 0
+	c = newTestConsumer(func(r io.Reader) error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("error when processing imported data: %w", common.NewPushError(errors.New("queue is full")))
+		}
+		return nil
+	})
+	if err := c.processRecord(r); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls != 3 {
+		t.Fatalf("unexpected number of handler calls for push errors; got %d; want 3", calls)
+	}
+	if n := c.pushErrors.Get(); n != 2 {
+		t.Fatalf("unexpected number of push errors; got %d; want 2", n)
+	}
+	if n := c.parseErrors.Get(); n != 0 {
+		t.Fatalf("unexpected number of parse errors; got %d; want 0", n)
+	}
+
+	// push errors are returned after the consumer is stopped, so the offset isn't committed
+	c = newTestConsumer(func(r io.Reader) error {
+		return common.NewPushError(errors.New("queue is full"))
+	})
+	c.cancel()
+	err := c.processRecord(r)
+	var pe *common.PushError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expecting push error; got %v", err)
+	}
+}
+
+func TestConsumerKafka(t *testing.T) {
+	defer func(d time.Duration) {
+		pushRetryMinInterval = d
+	}(pushRetryMinInterval)
+	pushRetryMinInterval = time.Millisecond
+
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, "foo"))
+	if err != nil {
+		t.Fatalf("cannot start Kafka cluster: %s", err)
+	}
+	defer cluster.Close()
+	brokers := cluster.ListenAddrs()
+
+	var pushAllowed atomic.Bool
+	var pushAttempts atomic.Int64
+	var rowsLock sync.Mutex
+	var rows []string
+	handler := func(r io.Reader) error {
+		return stream.Parse(r, false, func(tss []prompb.TimeSeries) error {
+			pushAttempts.Add(1)
+			if !pushAllowed.Load() {
+				return common.NewPushError(errors.New("queue is full"))
+			}
+			rowsLock.Lock()
+			for _, ts := range tss {
+				for _, s := range ts.Samples {
+					rows = append(rows, fmt.Sprintf("%s %v", ts.Labels[0].Value, s.Value))
+				}
+			}
+			rowsLock.Unlock()
+			return nil
+		})
+	}
+	f := func(rowsExpected []string, offsetExpected int64) {
+		t.Helper()
+		rowsLock.Lock()
+		defer rowsLock.Unlock()
+		if !reflect.DeepEqual(rows, rowsExpected) {
+			t.Fatalf("unexpected rows delivered;\ngot\n%q\nwant\n%q", rows, rowsExpected)
+		}
+		if offset := getCommittedOffset(t, brokers, "bar", "foo"); offset != offsetExpected {
+			t.Fatalf("unexpected committed offset; got %d; want %d", offset, offsetExpected)
+		}
+	}
+
+	produceRemoteWriteMessages(t, brokers, "foo", "metric1", "metric2")
+	c, err := newConsumer("foo", "bar", &kafkautil.Config{Brokers: brokers}, handler)
+	if err != nil {
+		t.Fatalf("cannot create consumer: %s", err)
+	}
+
+	// The message must be retried while it cannot be pushed, and its offset mustn't be committed
+	waitFor(t, func() bool {
+		return pushAttempts.Load() >= 3
+	})
+	f(nil, -1)
+
+	// The messages must be delivered and committed after they are pushed
+	pushAllowed.Store(true)
+	waitFor(t, func() bool {
+		return getCommittedOffset(t, brokers, "bar", "foo") == 2
+	})
+	c.MustStop()
+	f([]string{"metric1 1", "metric2 2"}, 2)
+
+	// The committed messages mustn't be consumed again by the same consumer group
+	produceRemoteWriteMessages(t, brokers, "foo", "metric3")
+	c, err = newConsumer("foo", "bar", &kafkautil.Config{Brokers: brokers}, handler)
+	if err != nil {
+		t.Fatalf("cannot create consumer: %s", err)
+	}
+	waitFor(t, func() bool {
+		return getCommittedOffset(t, brokers, "bar", "foo") == 3
+	})
+	c.MustStop()
+	f([]string{"metric1 1", "metric2 2", "metric3 1"}, 3)
+}
+
+// produceRemoteWriteMessages produces a message in Prometheus remote write format per each metricName to the given Kafka topic.
+//
+// The i-th message contains a single sample with value i+1.
+func produceRemoteWriteMessages(t *testing.T, brokers []string, topic string, metricNames ...string) {
+	t.Helper()
+	kc, err := kgo.NewClient(kgo.SeedBrokers(brokers...), kgo.DefaultProduceTopic(topic))
+	if err != nil {
+		t.Fatalf("cannot create Kafka client: %s", err)
+	}
+	defer kc.Close()
+	for i, metricName := range metricNames {
+		wr := &prompbmarshal.WriteRequest{
+			Timeseries: []prompbmarshal.TimeSeries{{
+				Labels: []prompbmarshal.Label{{
+					Name:  "__name__",
+					Value: metricName,
+				}},
+				Samples: []prompbmarshal.Sample{{
+					Value:     float64(i + 1),
+					Timestamp: 1000,
+				}},
+			}},
+		}
+		data := snappy.Encode(nil, prompbmarshal.MarshalWriteRequest(nil, wr))
+		if err := kc.ProduceSync(context.Background(), &kgo.Record{Value: data}).FirstErr(); err != nil {
+			t.Fatalf("cannot produce message: %s", err)
+		}
+	}
+}
+
+// getCommittedOffset returns the committed offset for the first partition of the given topic by the given consumer group.
+//
+// -1 is returned if there is no committed offset.
+func getCommittedOffset(t *testing.T, brokers []string, groupID, topic string) int64 {
+	t.Helper()
+	kc, err := kgo.NewClient(kgo.SeedBrokers(brokers...))
+	if err != nil {
+		t.Fatalf("cannot create Kafka client: %s", err)
+	}
+	defer kc.Close()
+	req := kmsg.NewPtrOffsetFetchRequest()
+	req.Group = groupID
+	rt := kmsg.NewOffsetFetchRequestTopic()
+	rt.Topic = topic
+	rt.Partitions = []int32{0}
+	req.Topics = append(req.Topics, rt)
+	resp, err := req.RequestWith(context.Background(), kc)
+	if err != nil {
+		t.Fatalf("cannot fetch committed offsets: %s", err)
+	}
+	if err := kerr.ErrorForCode(resp.ErrorCode); err != nil {
+		t.Fatalf("cannot fetch committed offsets: %s", err)
+	}
+	for _, rt := range resp.Topics {
+		for _, p := range rt.Partitions {
+			if err := kerr.ErrorForCode(p.ErrorCode); err != nil {
+				t.Fatalf("cannot fetch committed offset for partition %d: %s", p.Partition, err)
+			}
+			return p.Offset
+		}
+	}
+	return -1
+}
+
+func waitFor(t *testing.T, f func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !f() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout when waiting for the condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
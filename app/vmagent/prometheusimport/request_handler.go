@@ -1,6 +1,7 @@
 package prometheusimport
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/common"
@@ -33,13 +34,22 @@ func InsertHandler(at *auth.Token, req *http.Request) error {
 	}
 	isGzipped := req.Header.Get("Content-Encoding") == "gzip"
 	return stream.Parse(req.Body, defaultTimestamp, isGzipped, true, func(rows []parser.Row) error {
-		return insertRows(at, rows, extraLabels)
+		return insertRows(at, rows, extraLabels, false)
 	}, func(s string) {
 		httpserver.LogError(req, s)
 	})
 }
 
-func insertRows(at *auth.Token, rows []parser.Row, extraLabels []prompbmarshal.Label) error {
+// TryInsertHandlerForReader processes data in Prometheus text exposition format from r.
+//
+// It returns common.PushError if the data cannot be pushed because of full `-remoteWrite.url` queues. See remotewrite.TryPush.
+func TryInsertHandlerForReader(r io.Reader, isGzipped bool) error {
+	return stream.Parse(r, 0, isGzipped, true, func(rows []parser.Row) error {
+		return insertRows(nil, rows, nil, true)
+	}, nil)
+}
+
+func insertRows(at *auth.Token, rows []parser.Row, extraLabels []prompbmarshal.Label, tryPush bool) error {
 	ctx := common.GetPushCtx()
 	defer common.PutPushCtx(ctx)
 
@@ -73,7 +83,13 @@ func insertRows(at *auth.Token, rows []parser.Row, extraLabels []prompbmarshal.L
 	ctx.WriteRequest.Timeseries = tssDst
 	ctx.Labels = labels
 	ctx.Samples = samples
-	remotewrite.Push(at, &ctx.WriteRequest)
+	if tryPush {
+		if err := remotewrite.TryPush(at, &ctx.WriteRequest); err != nil {
+			return common.NewPushError(err)
+		}
+	} else {
+		remotewrite.Push(at, &ctx.WriteRequest)
+	}
 	rowsInserted.Add(len(rows))
 	if at != nil {
 		rowsTenantInserted.Get(at).Add(len(rows))
@@ -1,6 +1,7 @@
 package promremotewrite
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/common"
@@ -29,11 +30,20 @@ func InsertHandler(at *auth.Token, req *http.Request) error {
 	}
 	isVMRemoteWrite := req.Header.Get("Content-Encoding") == "zstd"
 	return stream.Parse(req.Body, isVMRemoteWrite, func(tss []prompb.TimeSeries) error {
-		return insertRows(at, tss, extraLabels)
+		return insertRows(at, tss, extraLabels, false)
 	})
 }
 
-func insertRows(at *auth.Token, timeseries []prompb.TimeSeries, extraLabels []prompbmarshal.Label) error {
+// TryInsertHandlerForReader processes Prometheus remote write data from r.
+//
+// It returns common.PushError if the data cannot be pushed because of full `-remoteWrite.url` queues. See remotewrite.TryPush.
+func TryInsertHandlerForReader(r io.Reader, isVMRemoteWrite bool) error {
+	return stream.Parse(r, isVMRemoteWrite, func(tss []prompb.TimeSeries) error {
+		return insertRows(nil, tss, nil, true)
+	})
+}
+
+func insertRows(at *auth.Token, timeseries []prompb.TimeSeries, extraLabels []prompbmarshal.Label, tryPush bool) error {
 	ctx := common.GetPushCtx()
 	defer common.PutPushCtx(ctx)
 
@@ -41,6 +51,8 @@ func insertRows(at *auth.Token, timeseries []prompb.TimeSeries, extraLabels []pr
 	tssDst := ctx.WriteRequest.Timeseries[:0]
 	labels := ctx.Labels[:0]
 	samples := ctx.Samples[:0]
+	exemplars := ctx.Exemplars[:0]
+	exemplarLabels := ctx.ExemplarLabels[:0]
 	for i := range timeseries {
 		ts := &timeseries[i]
 		rowsTotal += len(ts.Samples)
@@ -61,15 +73,45 @@ func insertRows(at *auth.Token, timeseries []prompb.TimeSeries, extraLabels []pr
 				Timestamp: sample.Timestamp,
 			})
 		}
+		exemplarsLen := len(exemplars)
+		for i := range ts.Exemplars {
+			e := &ts.Exemplars[i]
+			exemplarLabelsLen := len(exemplarLabels)
+			for j := range e.Labels {
+				label := &e.Labels[j]
+				exemplarLabels = append(exemplarLabels, prompbmarshal.Label{
+					Name:  bytesutil.ToUnsafeString(label.Name),
+					Value: bytesutil.ToUnsafeString(label.Value),
+				})
+			}
+			exemplars = append(exemplars, prompbmarshal.Exemplar{
+				Labels:    exemplarLabels[exemplarLabelsLen:],
+				Value:     e.Value,
+				Timestamp: e.Timestamp,
+			})
+		}
+		var tsExemplars []prompbmarshal.Exemplar
+		if len(exemplars) > exemplarsLen {
+			tsExemplars = exemplars[exemplarsLen:]
+		}
 		tssDst = append(tssDst, prompbmarshal.TimeSeries{
-			Labels:  labels[labelsLen:],
-			Samples: samples[samplesLen:],
+			Labels:    labels[labelsLen:],
+			Samples:   samples[samplesLen:],
+			Exemplars: tsExemplars,
 		})
 	}
 	ctx.WriteRequest.Timeseries = tssDst
 	ctx.Labels = labels
 	ctx.Samples = samples
-	remotewrite.Push(at, &ctx.WriteRequest)
+	ctx.Exemplars = exemplars
+	ctx.ExemplarLabels = exemplarLabels
+	if tryPush {
+		if err := remotewrite.TryPush(at, &ctx.WriteRequest); err != nil {
+			return common.NewPushError(err)
+		}
+	} else {
+		remotewrite.Push(at, &ctx.WriteRequest)
+	}
 	rowsInserted.Add(rowsTotal)
 	if at != nil {
 		rowsTenantInserted.Get(at).Add(rowsTotal)
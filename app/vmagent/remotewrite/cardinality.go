@@ -0,0 +1,428 @@
+package remotewrite
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/bufferedwriter"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/cgroup"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/hyperloglog"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/cespare/xxhash/v2"
+)
+
+var (
+	cardinalityExplorerWindow = flag.Duration("cardinalityExplorer.window", 0, "The sliding window for tracking series cardinality, which is exposed at /api/v1/status/tsdb page. "+
+		"The cardinality explorer is disabled by default. See https://docs.victoriametrics.com/vmagent.html#cardinality-explorer")
+	cardinalityExplorerMaxEntries = flag.Int("cardinalityExplorer.maxEntries", 10000, "The maximum number of metric names, label names, scrape jobs and remote storage urls "+
+		"to track by the cardinality explorer. New entries are ignored when the limit is reached. "+
+		"See https://docs.victoriametrics.com/vmagent.html#cardinality-explorer")
+)
+
+// cardinalityTrackerGlobal tracks the cardinality of series passed to remote storage systems.
+//
+// It is nil if -cardinalityExplorer.window isn't set.
+var cardinalityTrackerGlobal *cardinalityTracker
+
+func initCardinalityTracker() {
+	if *cardinalityExplorerWindow <= 0 {
+		return
+	}
+	cardinalityTrackerGlobal = newCardinalityTracker(*cardinalityExplorerWindow, *cardinalityExplorerMaxEntries)
+	_ = metrics.NewGauge(`vmagent_cardinality_explorer_entries`, func() float64 {
+		return float64(cardinalityTrackerGlobal.EntriesCount())
+	})
+	_ = metrics.NewGauge(`vmagent_cardinality_explorer_size_bytes`, func() float64 {
+		return float64(cardinalityTrackerGlobal.SizeBytes())
+	})
+}
+
+var cardinalityExplorerEntriesSkipped = metrics.NewCounter(`vmagent_cardinality_explorer_entries_skipped_total`)
+
+// cardinalityTracker tracks the number of unique series over the sliding window.
+//
+// The window is split into two halves - the previous and the current one.
+// The current half becomes the previous one every window/2, so the tracked stats
+// cover the time range from window/2 to window.
+type cardinalityTracker struct {
+	rotationInterval uint64
+	maxEntries       int64
+	shardsCount      int
+
+	// lastRotationTime is the unix timestamp in seconds for the last rotation of prev and curr.
+	lastRotationTime atomic.Uint64
+
+	// rotationLock serializes prev and curr rotations.
+	rotationLock sync.Mutex
+	prev         atomic.Pointer[cardinalityStats]
+	curr         atomic.Pointer[cardinalityStats]
+}
+
+func newCardinalityTracker(window time.Duration, maxEntries int) *cardinalityTracker {
+	rotationInterval := uint64(window.Seconds() / 2)
+	if rotationInterval == 0 {
+		rotationInterval = 1
+	}
+	ct := &cardinalityTracker{
+		rotationInterval: rotationInterval,
+		maxEntries:       int64(maxEntries),
+		shardsCount:      cgroup.AvailableCPUs(),
+	}
+	ct.lastRotationTime.Store(fasttime.UnixTimestamp())
+	ct.prev.Store(newCardinalityStats(ct.shardsCount))
+	ct.curr.Store(newCardinalityStats(ct.shardsCount))
+	return ct
+}
+
+// The list of dimensions tracked by cardinalityTracker.
+const (
+	dimensionSeriesByMetricName = iota
+	dimensionLabelValuesByLabelName
+	dimensionSeriesByJob
+	dimensionSeriesByURL
+	dimensionsCount
+
+	// dimensionTotalSeries is used for the total number of series, which has no keys.
+	dimensionTotalSeries = -1
+)
+
+// cardinalityStats contains the stats for a half of the sliding window.
+//
+// Every tracked key is stored in a single shard selected by the key hash, so concurrent goroutines
+// registering series with distinct keys do not contend on a single lock, while the memory usage
+// is limited by maxEntries per dimension.
+type cardinalityStats struct {
+	// entries contains the number of tracked keys per dimension across all the shards.
+	entries [dimensionsCount]atomic.Int64
+
+	shards []cardinalityStatsShard
+}
+
+func newCardinalityStats(shardsCount int) *cardinalityStats {
+	cs := &cardinalityStats{
+		shards: make([]cardinalityStatsShard, shardsCount),
+	}
+	for i := range cs.shards {
+		m := &cs.shards[i].m
+		for j := range m {
+			m[j] = make(map[string]*hyperloglog.Sketch)
+		}
+	}
+	return cs
+}
+
+type cardinalityStatsShardNopad struct {
+	mu          sync.Mutex
+	totalSeries hyperloglog.Sketch
+	m           [dimensionsCount]map[string]*hyperloglog.Sketch
+}
+
+type cardinalityStatsShard struct {
+	cardinalityStatsShardNopad
+
+	// The padding prevents false sharing on widespread platforms with
+	// 128 mod (cache line size) = 0 .
+	_ [128 - unsafe.Sizeof(cardinalityStatsShardNopad{})%128]byte
+}
+
+// addLocked adds h to the sketch for the given key at the given dimension of shard.
+//
+// New keys are ignored if the number of keys for the dimension across all the shards of cs reaches maxEntries.
+func (shard *cardinalityStatsShard) addLocked(cs *cardinalityStats, dimension int, key string, h uint64, maxEntries int64) {
+	m := shard.m[dimension]
+	s := m[key]
+	if s == nil {
+		if cs.entries[dimension].Add(1) > maxEntries {
+			cs.entries[dimension].Add(-1)
+			cardinalityExplorerEntriesSkipped.Inc()
+			return
+		}
+		s = &hyperloglog.Sketch{}
+		// Clone the key, since it may refer to a byte slice, which is re-used by the caller.
+		m[strings.Clone(key)] = s
+	}
+	s.Add(h)
+}
+
+// rotateIfNeeded rotates ct.prev and ct.curr if currentTime exceeds the rotation interval.
+func (ct *cardinalityTracker) rotateIfNeeded(currentTime uint64) {
+	if currentTime < ct.lastRotationTime.Load()+ct.rotationInterval {
+		return
+	}
+
+	ct.rotationLock.Lock()
+	defer ct.rotationLock.Unlock()
+
+	lastRotationTime := ct.lastRotationTime.Load()
+	if currentTime < lastRotationTime+ct.rotationInterval {
+		// The stats have been already rotated by a concurrent goroutine.
+		return
+	}
+	if currentTime < lastRotationTime+2*ct.rotationInterval {
+		ct.prev.Store(ct.curr.Load())
+	} else {
+		// There were no rotations during the whole window, so the current stats are outdated too.
+		ct.prev.Store(newCardinalityStats(ct.shardsCount))
+	}
+	ct.curr.Store(newCardinalityStats(ct.shardsCount))
+	ct.lastRotationTime.Store(currentTime)
+}
+
+// cardinalityItem is an item to be added to cardinalityStats.
+type cardinalityItem struct {
+	dimension int
+	key       string
+	h         uint64
+}
+
+// cardinalityItems holds cardinalityItem entries per each shard of cardinalityStats.
+//
+// It is used for adding all the items for the registered series to the shard under a single lock.
+type cardinalityItems struct {
+	shards [][]cardinalityItem
+}
+
+var cardinalityItemsPool sync.Pool
+
+func getCardinalityItems(shardsCount int) *cardinalityItems {
+	v := cardinalityItemsPool.Get()
+	if v == nil {
+		v = &cardinalityItems{}
+	}
+	cis := v.(*cardinalityItems)
+	if len(cis.shards) != shardsCount {
+		cis.shards = make([][]cardinalityItem, shardsCount)
+	}
+	return cis
+}
+
+func putCardinalityItems(cis *cardinalityItems) {
+	for i, items := range cis.shards {
+		// Reset items in order to free up memory occupied by keys.
+		for j := range items {
+			items[j] = cardinalityItem{}
+		}
+		cis.shards[i] = items[:0]
+	}
+	cardinalityItemsPool.Put(cis)
+}
+
+func (cis *cardinalityItems) add(dimension int, key string, h uint64) {
+	idx := xxhash.Sum64String(key) % uint64(len(cis.shards))
+	cis.shards[idx] = append(cis.shards[idx], cardinalityItem{
+		dimension: dimension,
+		key:       key,
+		h:         h,
+	})
+}
+
+func (cis *cardinalityItems) addTotalSeries(h uint64) {
+	idx := h % uint64(len(cis.shards))
+	cis.shards[idx] = append(cis.shards[idx], cardinalityItem{
+		dimension: dimensionTotalSeries,
+		h:         h,
+	})
+}
+
+// RegisterSeries registers tss passed to remote storage systems after global relabeling.
+func (ct *cardinalityTracker) RegisterSeries(tss []prompbmarshal.TimeSeries) {
+	ct.rotateIfNeeded(fasttime.UnixTimestamp())
+	cs := ct.curr.Load()
+
+	cis := getCardinalityItems(len(cs.shards))
+	for i := range tss {
+		labels := tss[i].Labels
+		h := getLabelsHash(labels)
+		cis.addTotalSeries(h)
+		for _, label := range labels {
+			switch label.Name {
+			case "__name__":
+				cis.add(dimensionSeriesByMetricName, label.Value, h)
+				continue
+			case "job":
+				cis.add(dimensionSeriesByJob, label.Value, h)
+			}
+			cis.add(dimensionLabelValuesByLabelName, label.Name, xxhash.Sum64String(label.Value))
+		}
+	}
+	for i, items := range cis.shards {
+		if len(items) == 0 {
+			continue
+		}
+		shard := &cs.shards[i]
+		shard.mu.Lock()
+		for _, item := range items {
+			if item.dimension == dimensionTotalSeries {
+				shard.totalSeries.Add(item.h)
+			} else {
+				shard.addLocked(cs, item.dimension, item.key, item.h, ct.maxEntries)
+			}
+		}
+		shard.mu.Unlock()
+	}
+	putCardinalityItems(cis)
+}
+
+// RegisterURLSeries registers tss passed to the remote storage with the given sanitizedURL after per-url relabeling.
+func (ct *cardinalityTracker) RegisterURLSeries(sanitizedURL string, tss []prompbmarshal.TimeSeries) {
+	ct.rotateIfNeeded(fasttime.UnixTimestamp())
+	cs := ct.curr.Load()
+
+	shard := &cs.shards[xxhash.Sum64String(sanitizedURL)%uint64(len(cs.shards))]
+	shard.mu.Lock()
+	for i := range tss {
+		h := getLabelsHash(tss[i].Labels)
+		shard.addLocked(cs, dimensionSeriesByURL, sanitizedURL, h, ct.maxEntries)
+	}
+	shard.mu.Unlock()
+}
+
+// EntriesCount returns the number of entries tracked by ct.
+func (ct *cardinalityTracker) EntriesCount() int {
+	n := 0
+	for _, cs := range []*cardinalityStats{ct.prev.Load(), ct.curr.Load()} {
+		for i := range cs.entries {
+			n += int(cs.entries[i].Load())
+		}
+	}
+	return n
+}
+
+// SizeBytes returns the approximate size of ct in bytes.
+func (ct *cardinalityTracker) SizeBytes() int {
+	n := 0
+	for _, cs := range []*cardinalityStats{ct.prev.Load(), ct.curr.Load()} {
+		for i := range cs.shards {
+			shard := &cs.shards[i]
+			shard.mu.Lock()
+			n += shard.totalSeries.SizeBytes()
+			for _, m := range shard.m {
+				for k, s := range m {
+					n += len(k) + s.SizeBytes()
+				}
+			}
+			shard.mu.Unlock()
+		}
+	}
+	return n
+}
+
+// cardinalityStatus contains top entries tracked by cardinalityTracker.
+type cardinalityStatus struct {
+	TotalSeries                 uint64
+	SeriesCountByMetricName     []cardinalityEntry
+	LabelValueCountByLabelName  []cardinalityEntry
+	SeriesCountByJob            []cardinalityEntry
+	SeriesCountByRemoteWriteURL []cardinalityEntry
+}
+
+type cardinalityEntry struct {
+	Name  string
+	Count uint64
+}
+
+// GetStatus returns up to topN entries with the biggest counts per every tracked dimension.
+//
+// The stats are merged across the previous and the current halves of the window and across all their shards.
+func (ct *cardinalityTracker) GetStatus(topN int) *cardinalityStatus {
+	ct.rotateIfNeeded(fasttime.UnixTimestamp())
+
+	var total hyperloglog.Sketch
+	var ms [dimensionsCount]map[string]*hyperloglog.Sketch
+	for i := range ms {
+		ms[i] = make(map[string]*hyperloglog.Sketch)
+	}
+	for _, cs := range []*cardinalityStats{ct.prev.Load(), ct.curr.Load()} {
+		for i := range cs.shards {
+			shard := &cs.shards[i]
+			shard.mu.Lock()
+			total.Merge(&shard.totalSeries)
+			for j, m := range shard.m {
+				mergeCardinalitySketches(ms[j], m)
+			}
+			shard.mu.Unlock()
+		}
+	}
+	return &cardinalityStatus{
+		TotalSeries:                 total.Estimate(),
+		SeriesCountByMetricName:     getTopCardinalityEntries(ms[dimensionSeriesByMetricName], topN),
+		LabelValueCountByLabelName:  getTopCardinalityEntries(ms[dimensionLabelValuesByLabelName], topN),
+		SeriesCountByJob:            getTopCardinalityEntries(ms[dimensionSeriesByJob], topN),
+		SeriesCountByRemoteWriteURL: getTopCardinalityEntries(ms[dimensionSeriesByURL], topN),
+	}
+}
+
+func mergeCardinalitySketches(dst, src map[string]*hyperloglog.Sketch) {
+	for k, s := range src {
+		d := dst[k]
+		if d == nil {
+			d = &hyperloglog.Sketch{}
+			dst[k] = d
+		}
+		d.Merge(s)
+	}
+}
+
+func getTopCardinalityEntries(m map[string]*hyperloglog.Sketch, topN int) []cardinalityEntry {
+	entries := make([]cardinalityEntry, 0, len(m))
+	for k, s := range m {
+		entries = append(entries, cardinalityEntry{
+			Name:  k,
+			Count: s.Estimate(),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := &entries[i], &entries[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Name < b.Name
+	})
+	if len(entries) > topN {
+		entries = entries[:topN]
+	}
+	return entries
+}
+
+// WriteCardinalityStatus writes the cardinality stats for series passed to remote storage systems to w.
+//
+// The response has the format similar to /api/v1/status/tsdb at VictoriaMetrics.
+// See https://docs.victoriametrics.com/vmagent.html#cardinality-explorer
+func WriteCardinalityStatus(w http.ResponseWriter, r *http.Request) error {
+	ct := cardinalityTrackerGlobal
+	if ct == nil {
+		return fmt.Errorf("cardinality explorer is disabled; enable it via -cardinalityExplorer.window command-line flag")
+	}
+	topN := 10
+	topNStr := r.FormValue("topN")
+	if len(topNStr) > 0 {
+		n, err := strconv.Atoi(topNStr)
+		if err != nil {
+			return fmt.Errorf("cannot parse `topN` arg %q: %w", topNStr, err)
+		}
+		if n <= 0 {
+			n = 1
+		}
+		if n > 1000 {
+			n = 1000
+		}
+		topN = n
+	}
+	status := ct.GetStatus(topN)
+
+	w.Header().Set("Content-Type", "application/json")
+	bw := bufferedwriter.Get(w)
+	defer bufferedwriter.Put(bw)
+	WriteCardinalityStatusResponse(bw, status)
+	return bw.Flush()
+}
@@ -0,0 +1,128 @@
+// Code generated by qtc from "cardinality_response.qtpl". DO NOT EDIT.
+// See https://github.com/valyala/quicktemplate for details.
+
+// CardinalityStatusResponse generates response for /api/v1/status/tsdb at vmagent.
+
+//line app/vmagent/remotewrite/cardinality_response.qtpl:4
+package remotewrite
+
+//line app/vmagent/remotewrite/cardinality_response.qtpl:4
+import (
+	qtio422016 "io"
+
+	qt422016 "github.com/valyala/quicktemplate"
+)
+
+//line app/vmagent/remotewrite/cardinality_response.qtpl:4
+var (
+	_ = qtio422016.Copy
+	_ = qt422016.AcquireByteBuffer
+)
+
+//line app/vmagent/remotewrite/cardinality_response.qtpl:4
+func StreamCardinalityStatusResponse(qw422016 *qt422016.Writer, status *cardinalityStatus) {
+//line app/vmagent/remotewrite/cardinality_response.qtpl:4
+	qw422016.N().S(`{"status":"success","data":{"totalSeries":`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:8
+	qw422016.N().DUL(status.TotalSeries)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:8
+	qw422016.N().S(`,"seriesCountByMetricName":`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:9
+	streamcardinalityEntries(qw422016, status.SeriesCountByMetricName)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:9
+	qw422016.N().S(`,"labelValueCountByLabelName":`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:10
+	streamcardinalityEntries(qw422016, status.LabelValueCountByLabelName)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:10
+	qw422016.N().S(`,"seriesCountByJob":`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:11
+	streamcardinalityEntries(qw422016, status.SeriesCountByJob)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:11
+	qw422016.N().S(`,"seriesCountByRemoteWriteURL":`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:12
+	streamcardinalityEntries(qw422016, status.SeriesCountByRemoteWriteURL)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:12
+	qw422016.N().S(`}}`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+}
+
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+func WriteCardinalityStatusResponse(qq422016 qtio422016.Writer, status *cardinalityStatus) {
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+	StreamCardinalityStatusResponse(qw422016, status)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+}
+
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+func CardinalityStatusResponse(status *cardinalityStatus) string {
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+	WriteCardinalityStatusResponse(qb422016, status)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+	qs422016 := string(qb422016.B)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+	return qs422016
+//line app/vmagent/remotewrite/cardinality_response.qtpl:15
+}
+
+//line app/vmagent/remotewrite/cardinality_response.qtpl:17
+func streamcardinalityEntries(qw422016 *qt422016.Writer, a []cardinalityEntry) {
+//line app/vmagent/remotewrite/cardinality_response.qtpl:17
+	qw422016.N().S(`[`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:19
+	for i, e := range a {
+//line app/vmagent/remotewrite/cardinality_response.qtpl:19
+		qw422016.N().S(`{"name":`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:21
+		qw422016.N().Q(e.Name)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:21
+		qw422016.N().S(`,"value":`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:22
+		qw422016.N().DUL(e.Count)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:22
+		qw422016.N().S(`}`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:24
+		if i+1 < len(a) {
+//line app/vmagent/remotewrite/cardinality_response.qtpl:24
+			qw422016.N().S(`,`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:24
+		}
+//line app/vmagent/remotewrite/cardinality_response.qtpl:25
+	}
+//line app/vmagent/remotewrite/cardinality_response.qtpl:25
+	qw422016.N().S(`]`)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+}
+
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+func writecardinalityEntries(qq422016 qtio422016.Writer, a []cardinalityEntry) {
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+	streamcardinalityEntries(qw422016, a)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+}
+
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+func cardinalityEntries(a []cardinalityEntry) string {
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+	writecardinalityEntries(qb422016, a)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+	qs422016 := string(qb422016.B)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+	return qs422016
+//line app/vmagent/remotewrite/cardinality_response.qtpl:27
+}
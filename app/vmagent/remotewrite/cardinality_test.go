@@ -0,0 +1,204 @@
+package remotewrite
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+)
+
+func TestCardinalityTracker(t *testing.T) {
+	newSeries := func(metricName, job, instance string) prompbmarshal.TimeSeries {
+		return prompbmarshal.TimeSeries{
+			Labels: []prompbmarshal.Label{
+				{
+					Name:  "__name__",
+					Value: metricName,
+				},
+				{
+					Name:  "instance",
+					Value: instance,
+				},
+				{
+					Name:  "job",
+					Value: job,
+				},
+			},
+		}
+	}
+	var tss []prompbmarshal.TimeSeries
+	for i := 0; i < 10; i++ {
+		instance := fmt.Sprintf("host-%d", i)
+		tss = append(tss, newSeries("up", "node", instance))
+		tss = append(tss, newSeries("node_load1", "node", instance))
+		if i < 3 {
+			tss = append(tss, newSeries("up", "vmagent", instance))
+		}
+	}
+
+	ct := newCardinalityTracker(time.Hour, 1000)
+	ct.RegisterSeries(tss)
+	// Duplicate series must be ignored.
+	ct.RegisterSeries(tss)
+	ct.RegisterURLSeries("1:secret-url", tss)
+	ct.RegisterURLSeries("2:secret-url", tss[:5])
+
+	var bb bytes.Buffer
+	WriteCardinalityStatusResponse(&bb, ct.GetStatus(2))
+	result := bb.String()
+	resultExpected := `{"status":"success","data":{"totalSeries":23,` +
+		`"seriesCountByMetricName":[{"name":"up","value":13},{"name":"node_load1","value":10}],` +
+		`"labelValueCountByLabelName":[{"name":"instance","value":10},{"name":"job","value":2}],` +
+		`"seriesCountByJob":[{"name":"node","value":20},{"name":"vmagent","value":3}],` +
+		`"seriesCountByRemoteWriteURL":[{"name":"1:secret-url","value":23},{"name":"2:secret-url","value":5}]}}`
+	if result != resultExpected {
+		t.Fatalf("unexpected result;\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+}
+
+func TestCardinalityTrackerRotation(t *testing.T) {
+	tss := []prompbmarshal.TimeSeries{
+		{
+			Labels: []prompbmarshal.Label{
+				{
+					Name:  "__name__",
+					Value: "foo",
+				},
+			},
+		},
+	}
+	ct := newCardinalityTracker(time.Hour, 1000)
+	ct.RegisterSeries(tss)
+
+	rotate := func() {
+		ct.rotateIfNeeded(ct.lastRotationTime.Load() + ct.rotationInterval)
+	}
+
+	// The series must remain visible during the first rotation.
+	rotate()
+	if n := ct.GetStatus(10).TotalSeries; n != 1 {
+		t.Fatalf("unexpected number of series after the first rotation; got %d; want 1", n)
+	}
+
+	// The series must disappear after the second rotation.
+	rotate()
+	if n := ct.GetStatus(10).TotalSeries; n != 0 {
+		t.Fatalf("unexpected number of series after the second rotation; got %d; want 0", n)
+	}
+}
+
+func TestCardinalityTrackerMaxEntries(t *testing.T) {
+	newSeries := func(metricName string) prompbmarshal.TimeSeries {
+		return prompbmarshal.TimeSeries{
+			Labels: []prompbmarshal.Label{
+				{
+					Name:  "__name__",
+					Value: metricName,
+				},
+			},
+		}
+	}
+	ct := newCardinalityTracker(time.Hour, 3)
+
+	// The limit must be applied to all the keys across all the shards.
+	const workers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				tss := []prompbmarshal.TimeSeries{
+					newSeries(fmt.Sprintf("metric_%d", (workerID+j)%10)),
+				}
+				ct.RegisterSeries(tss)
+				ct.RegisterURLSeries(fmt.Sprintf("%d:secret-url", j), tss)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	status := ct.GetStatus(100)
+	if n := status.TotalSeries; n != 10 {
+		t.Fatalf("unexpected number of series; got %d; want 10", n)
+	}
+	if n := len(status.SeriesCountByMetricName); n != 3 {
+		t.Fatalf("unexpected number of metric names; got %d; want 3", n)
+	}
+	if n := len(status.SeriesCountByRemoteWriteURL); n != 3 {
+		t.Fatalf("unexpected number of remote storage urls; got %d; want 3", n)
+	}
+	if n := ct.EntriesCount(); n != 6 {
+		t.Fatalf("unexpected number of entries; got %d; want 6", n)
+	}
+}
+
+func TestCardinalityTrackerConcurrent(t *testing.T) {
+	ct := newCardinalityTracker(time.Hour, 1000)
+
+	// Register 10K unique series for the same metric name and job from concurrent goroutines.
+	const workers = 8
+	const seriesPerWorker = 1250
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			var tss []prompbmarshal.TimeSeries
+			for j := 0; j < seriesPerWorker; j++ {
+				tss = append(tss, prompbmarshal.TimeSeries{
+					Labels: []prompbmarshal.Label{
+						{
+							Name:  "__name__",
+							Value: "foo",
+						},
+						{
+							Name:  "job",
+							Value: "bar",
+						},
+						{
+							Name:  "instance",
+							Value: fmt.Sprintf("host-%d-%d", workerID, j),
+						},
+					},
+				})
+				if len(tss) == 100 {
+					ct.RegisterSeries(tss)
+					ct.RegisterURLSeries("1:secret-url", tss)
+					tss = tss[:0]
+				}
+			}
+			ct.RegisterSeries(tss)
+			ct.RegisterURLSeries("1:secret-url", tss)
+		}(i)
+	}
+	wg.Wait()
+
+	// Every key must be tracked only once, e.g. it mustn't be duplicated among shards.
+	// The keys are: metric name `foo`, label names `job` and `instance`, job `bar` and the remote storage url.
+	if n := ct.EntriesCount(); n != 5 {
+		t.Fatalf("unexpected number of entries; got %d; want 5", n)
+	}
+
+	// The estimations must be close to the real number of series.
+	const seriesExpected = workers * seriesPerWorker
+	checkEstimate := func(name string, n uint64) {
+		t.Helper()
+		if d := math.Abs(float64(n)-seriesExpected) / seriesExpected; d > 0.1 {
+			t.Fatalf("too big estimation error for %s; got %d; want %d", name, n, seriesExpected)
+		}
+	}
+	status := ct.GetStatus(10)
+	checkEstimate("totalSeries", status.TotalSeries)
+	checkEstimate("seriesCountByMetricName", status.SeriesCountByMetricName[0].Count)
+	checkEstimate("labelValueCountByLabelName", status.LabelValueCountByLabelName[0].Count)
+	checkEstimate("seriesCountByJob", status.SeriesCountByJob[0].Count)
+	checkEstimate("seriesCountByRemoteWriteURL", status.SeriesCountByRemoteWriteURL[0].Count)
+	if n := status.LabelValueCountByLabelName[1].Count; n != 1 {
+		t.Fatalf("unexpected number of values for label %q; got %d; want 1", status.LabelValueCountByLabelName[1].Name, n)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/common"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/timerpool"
 	"github.com/VictoriaMetrics/metrics"
+	"github.com/twmb/franz-go/pkg/kgo"
 )
 
 var (
@@ -26,6 +27,9 @@ var (
 		"to the corresponding -remoteWrite.url . See https://docs.victoriametrics.com/vmagent.html#victoriametrics-remote-write-protocol")
 	forceVMProto = flagutil.NewArrayBool("remoteWrite.forceVMProto", "Whether to force VictoriaMetrics remote write protocol for sending data "+
 		"to the corresponding -remoteWrite.url . See https://docs.victoriametrics.com/vmagent.html#victoriametrics-remote-write-protocol")
+	useOpenTelemetry = flagutil.NewArrayBool("remoteWrite.useOpenTelemetry", "Whether to send data to the corresponding -remoteWrite.url via OpenTelemetry protocol "+
+		"instead of Prometheus remote write protocol. In this case -remoteWrite.url must point to OTLP/HTTP metrics endpoint such as http://otel-collector:4318/v1/metrics . "+
+		"See https://docs.victoriametrics.com/vmagent.html#sending-data-via-opentelemetry-protocol")
 
 	rateLimit = flagutil.NewArrayInt("remoteWrite.rateLimit", 0, "Optional rate limit in bytes per second for data sent to the corresponding -remoteWrite.url. "+
 		"By default, the rate limit is disabled. It can be useful for limiting load on remote storage when big amounts of buffered data "+
@@ -80,9 +84,15 @@ type client struct {
 	// Whether to use VictoriaMetrics remote write protocol for sending the data to remoteWriteURL
 	useVMProto bool
 
+	// Whether to use OpenTelemetry protocol for sending the data to remoteWriteURL
+	useOpenTelemetry bool
+
 	fq *persistentqueue.FastQueue
 	hc *http.Client
 
+	// kc is used for sending the data to Kafka if remoteWriteURL starts with kafka://
+	kc *kgo.Client
+
 	sendBlock func(block []byte) bool
 	authCfg   *promauth.Config
 	awsCfg    *awsapi.Config
@@ -150,10 +160,14 @@ func newHTTPClient(argIdx int, remoteWriteURL, sanitizedURL string, fq *persiste
 
 	useVMProto := forceVMProto.GetOptionalArg(argIdx)
 	usePromProto := forcePromProto.GetOptionalArg(argIdx)
+	c.useOpenTelemetry = useOpenTelemetry.GetOptionalArg(argIdx)
 	if useVMProto && usePromProto {
 		logger.Fatalf("-remoteWrite.useVMProto and -remoteWrite.usePromProto cannot be set simultaneously for -remoteWrite.url=%s", sanitizedURL)
 	}
-	if !useVMProto && !usePromProto {
+	if c.useOpenTelemetry && (useVMProto || usePromProto) {
+		logger.Fatalf("-remoteWrite.useOpenTelemetry cannot be set simultaneously with -remoteWrite.forceVMProto or -remoteWrite.forcePromProto for -remoteWrite.url=%s", sanitizedURL)
+	}
+	if !useVMProto && !usePromProto && !c.useOpenTelemetry {
 		// Auto-detect whether the remote storage supports VictoriaMetrics remote write protocol.
 		doRequest := func(url string) (*http.Response, error) {
 			return c.doRequest(url, nil)
@@ -203,6 +217,9 @@ func (c *client) init(argIdx, concurrency int, sanitizedURL string) {
 func (c *client) MustStop() {
 	close(c.stopCh)
 	c.wg.Wait()
+	if c.kc != nil {
+		c.kc.Close()
+	}
 	logger.Infof("stopped client for -remoteWrite.url=%q", c.sanitizedURL)
 }
 
@@ -346,7 +363,9 @@ func (c *client) newRequest(url string, body []byte) *http.Request {
 	h := req.Header
 	h.Set("User-Agent", "vmagent")
 	h.Set("Content-Type", "application/x-protobuf")
-	if c.useVMProto {
+	if c.useOpenTelemetry {
+		h.Set("Content-Encoding", "gzip")
+	} else if c.useVMProto {
 		h.Set("Content-Encoding", "zstd")
 		h.Set("X-VictoriaMetrics-Remote-Write-Version", "1")
 	} else {
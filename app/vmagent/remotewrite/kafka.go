@@ -0,0 +1,185 @@
+package remotewrite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/kafkautil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/persistentqueue"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/timerpool"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// newKafkaClient returns a client, which sends blocks from fq to Kafka topic specified at remoteWriteURL.
+//
+// remoteWriteURL must have the form kafka://broker1:9092;broker2:9092/?topic=name&option1=value1 .
+// Every block is sent as a single Kafka message in Prometheus remote write format.
+func newKafkaClient(argIdx int, remoteWriteURL *url.URL, sanitizedURL string, fq *persistentqueue.FastQueue) *client {
+	if forceVMProto.GetOptionalArg(argIdx) || useOpenTelemetry.GetOptionalArg(argIdx) {
+		logger.Fatalf("-remoteWrite.forceVMProto and -remoteWrite.useOpenTelemetry cannot be used with Kafka -remoteWrite.url=%s", sanitizedURL)
+	}
+	authCfg, err := getAuthConfig(argIdx)
+	if err != nil {
+		logger.Fatalf("FATAL: cannot initialize auth config for remoteWrite.url=%q: %s", sanitizedURL, err)
+	}
+	opts, err := getKafkaProducerOpts(remoteWriteURL)
+	if err != nil {
+		logger.Fatalf("FATAL: invalid Kafka -remoteWrite.url=%q: %s", sanitizedURL, err)
+	}
+	cfg := &kafkautil.Config{
+		Brokers:   opts.brokers,
+		Options:   opts.options,
+		Username:  basicAuthUsername.GetOptionalArg(argIdx),
+		Password:  basicAuthPassword.GetOptionalArg(argIdx),
+		TLSConfig: authCfg.NewTLSConfig(),
+	}
+	kcOpts, err := cfg.NewClientOpts()
+	if err != nil {
+		logger.Fatalf("FATAL: cannot initialize Kafka client for -remoteWrite.url=%q: %s", sanitizedURL, err)
+	}
+	kcOpts = append(kcOpts,
+		kgo.DefaultProduceTopic(opts.topic),
+		kgo.ProducerBatchCompression(opts.compression),
+		kgo.ProducerBatchMaxBytes(persistentqueue.MaxBlockSize),
+		kgo.RecordDeliveryTimeout(sendTimeout.GetOptionalArg(argIdx)),
+	)
+	kc, err := kgo.NewClient(kcOpts...)
+	if err != nil {
+		logger.Fatalf("FATAL: cannot initialize Kafka client for -remoteWrite.url=%q: %s", sanitizedURL, err)
+	}
+	c := &client{
+		sanitizedURL:   sanitizedURL,
+		remoteWriteURL: remoteWriteURL.String(),
+		authCfg:        authCfg,
+		fq:             fq,
+		kc:             kc,
+		stopCh:         make(chan struct{}),
+	}
+	c.sendBlock = c.sendBlockKafka
+	return c
+}
+
+type kafkaProducerOpts struct {
+	brokers     []string
+	topic       string
+	compression kgo.CompressionCodec
+	options     map[string]string
+}
+
+func getKafkaProducerOpts(u *url.URL) (*kafkaProducerOpts, error) {
+	brokers, err := kafkautil.ParseBrokers(u.Host)
+	if err != nil {
+		return nil, err
+	}
+	opts := &kafkaProducerOpts{
+		brokers:     brokers,
+		compression: kgo.NoCompression(),
+		options:     make(map[string]string),
+	}
+	for k, vs := range u.Query() {
+		v := vs[len(vs)-1]
+		switch k {
+		case "topic":
+			opts.topic = v
+		case "compression.type":
+			cc, err := getKafkaCompressionCodec(v)
+			if err != nil {
+				return nil, err
+			}
+			opts.compression = cc
+		default:
+			opts.options[k] = v
+		}
+	}
+	if opts.topic == "" {
+		return nil, fmt.Errorf("missing `topic` query arg")
+	}
+	return opts, nil
+}
+
+func getKafkaCompressionCodec(s string) (kgo.CompressionCodec, error) {
+	switch strings.ToLower(s) {
+	case "none":
+		return kgo.NoCompression(), nil
+	case "gzip":
+		return kgo.GzipCompression(), nil
+	case "snappy":
+		return kgo.SnappyCompression(), nil
+	case "lz4":
+		return kgo.Lz4Compression(), nil
+	case "zstd":
+		return kgo.ZstdCompression(), nil
+	default:
+		return kgo.NoCompression(), fmt.Errorf("unsupported compression.type=%q; supported values: none, gzip, snappy, lz4, zstd", s)
+	}
+}
+
+// sendBlockKafka sends the given block to Kafka topic configured at c.kc.
+//
+// The block is removed from the persistent queue only after it is acknowledged by Kafka brokers,
+// so the data is delivered with at-least-once semantics.
+//
+// The function returns false only if c.stopCh is closed.
+// Otherwise it tries sending the block to Kafka indefinitely.
+func (c *client) sendBlockKafka(block []byte) bool {
+	c.rl.register(len(block), c.stopCh)
+	retryDuration := time.Second
+	retriesCount := 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+again:
+	startTime := time.Now()
+	r := &kgo.Record{
+		Value: block,
+	}
+	err := c.kc.ProduceSync(ctx, r).FirstErr()
+	c.requestDuration.UpdateDuration(startTime)
+	if err == nil {
+		c.requestsOKCount.Inc()
+		c.bytesSent.Add(len(block))
+		c.blocksSent.Inc()
+		return true
+	}
+	if errors.Is(err, kerr.MessageTooLarge) || errors.Is(err, kerr.RecordListTooLarge) {
+		// There is no sense in re-sending the block, since it will be rejected again.
+		remoteWriteRejectedLogger.Errorf("sending a block with size %d bytes to %q was rejected (skipping the block): %s", len(block), c.sanitizedURL, err)
+		c.packetsDropped.Inc()
+		return true
+	}
+	c.errorsCount.Inc()
+	if ctx.Err() != nil {
+		return false
+	}
+	retriesCount++
+	retryDuration *= 2
+	if retryDuration > time.Minute {
+		retryDuration = time.Minute
+	}
+	logger.Warnf("couldn't send a block with size %d bytes to %q during retry #%d: %s; re-sending the block in %.3f seconds",
+		len(block), c.sanitizedURL, retriesCount, err, retryDuration.Seconds())
+	t := timerpool.Get(retryDuration)
+	select {
+	case <-c.stopCh:
+		timerpool.Put(t)
+		return false
+	case <-t.C:
+		timerpool.Put(t)
+	}
+	c.retriesCount.Inc()
+	goto again
+}
@@ -0,0 +1,204 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/persistentqueue"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompb"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/promremotewrite/stream"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestGetKafkaProducerOpts(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatalf("cannot parse %q: %s", s, err)
+		}
+		opts, err := getKafkaProducerOpts(u)
+		if err != nil {
+			if resultExpected != "" {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			return
+		}
+		result := fmt.Sprintf("brokers=%s, topic=%s, options=%v", strings.Join(opts.brokers, ";"), opts.topic, opts.options)
+		if result != resultExpected {
+			t.Fatalf("unexpected result;\ngot\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+
+	f("kafka://localhost:9092/?topic=foo", "brokers=localhost:9092, topic=foo, options=map[]")
+	f("kafka://host1:9092;host2:9092/?topic=foo&client.id=bar&compression.type=zstd",
+		"brokers=host1:9092;host2:9092, topic=foo, options=map[client.id:bar]")
+
+	// missing topic
+	f("kafka://localhost:9092/", "")
+
+	// missing brokers
+	f("kafka:///?topic=foo", "")
+
+	// unsupported compression
+	f("kafka://localhost:9092/?topic=foo&compression.type=bar", "")
+}
+
+func TestKafkaClientSendBlockUnavailable(t *testing.T) {
+	// Obtain an address without listening broker
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %s", err)
+	}
+	brokers := []string{ln.Addr().String()}
+	_ = ln.Close()
+
+	fq := persistentqueue.MustOpenFastQueue(filepath.Join(t.TempDir(), "queue"), "kafka-test", 10, 0)
+	defer fq.MustClose()
+
+	u, err := url.Parse(fmt.Sprintf("kafka://%s/?topic=foo", strings.Join(brokers, ";")))
+	if err != nil {
+		t.Fatalf("cannot parse url: %s", err)
+	}
+	c := newKafkaClient(0, u, "kafka-test", fq)
+	c.init(0, 1, "kafka-test")
+	fq.MustWriteBlock([]byte("foo"))
+
+	// Wait until the worker starts sending the block
+	deadline := time.Now().Add(5 * time.Second)
+	for fq.GetPendingBytes() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The unsent block must be returned to the queue on stop
+	fq.UnblockAllReaders()
+	c.MustStop()
+	if n := fq.GetPendingBytes(); n == 0 {
+		t.Fatalf("the unsent block must be returned to the queue")
+	}
+}
+
+func TestKafkaClientSendBlock(t *testing.T) {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, "foo"))
+	if err != nil {
+		t.Fatalf("cannot start Kafka cluster: %s", err)
+	}
+	defer cluster.Close()
+	brokers := cluster.ListenAddrs()
+
+	fq := persistentqueue.MustOpenFastQueue(filepath.Join(t.TempDir(), "queue"), "kafka-test", 10, 0)
+	defer fq.MustClose()
+
+	u, err := url.Parse(fmt.Sprintf("kafka://%s/?topic=foo", strings.Join(brokers, ";")))
+	if err != nil {
+		t.Fatalf("cannot parse url: %s", err)
+	}
+	c := newKafkaClient(0, u, "kafka-test", fq)
+	c.init(0, 1, "kafka-test")
+	for i, metricName := range []string{"metric1", "metric2"} {
+		wr := &prompbmarshal.WriteRequest{
+			Timeseries: []prompbmarshal.TimeSeries{{
+				Labels: []prompbmarshal.Label{{
+					Name:  "__name__",
+					Value: metricName,
+				}},
+				Samples: []prompbmarshal.Sample{{
+					Value:     float64(i + 1),
+					Timestamp: 1000,
+				}},
+			}},
+		}
+		pushWriteRequest(wr, fq.MustWriteBlock, false)
+	}
+
+	// Consume the sent blocks via consumer group
+	kc, err := kgo.NewClient(
+		kgo.SeedBrokers(brokers...),
+		kgo.ConsumeTopics("foo"),
+		kgo.ConsumerGroup("bar"),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+		kgo.DisableAutoCommit(),
+	)
+	if err != nil {
+		t.Fatalf("cannot create Kafka client: %s", err)
+	}
+	defer kc.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var rows []string
+	for len(rows) < 2 {
+		fetches := kc.PollFetches(ctx)
+		if err := ctx.Err(); err != nil {
+			t.Fatalf("cannot consume the sent blocks; consumed rows: %q; error: %s", rows, err)
+		}
+		fetches.EachRecord(func(r *kgo.Record) {
+			err := stream.Parse(bytes.NewReader(r.Value), false, func(tss []prompb.TimeSeries) error {
+				for _, ts := range tss {
+					for _, s := range ts.Samples {
+						rows = append(rows, fmt.Sprintf("%s %v", ts.Labels[0].Value, s.Value))
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("cannot parse the sent block: %s", err)
+			}
+		})
+	}
+	rowsExpected := []string{"metric1 1", "metric2 2"}
+	if !reflect.DeepEqual(rows, rowsExpected) {
+		t.Fatalf("unexpected rows sent;\ngot\n%q\nwant\n%q", rows, rowsExpected)
+	}
+	if err := kc.CommitUncommittedOffsets(ctx); err != nil {
+		t.Fatalf("cannot commit offsets: %s", err)
+	}
+	if offset := getKafkaCommittedOffset(t, kc, "bar", "foo"); offset != 2 {
+		t.Fatalf("unexpected committed offset; got %d; want 2", offset)
+	}
+
+	// The sent blocks must be removed from the queue
+	fq.UnblockAllReaders()
+	c.MustStop()
+	if n := fq.GetPendingBytes(); n != 0 {
+		t.Fatalf("unexpected pending bytes in the queue after sending the blocks; got %d; want 0", n)
+	}
+}
+
+// getKafkaCommittedOffset returns the committed offset for the first partition of the given topic by the given consumer group.
+func getKafkaCommittedOffset(t *testing.T, kc *kgo.Client, groupID, topic string) int64 {
+	t.Helper()
+	req := kmsg.NewPtrOffsetFetchRequest()
+	req.Group = groupID
+	rt := kmsg.NewOffsetFetchRequestTopic()
+	rt.Topic = topic
+	rt.Partitions = []int32{0}
+	req.Topics = append(req.Topics, rt)
+	resp, err := req.RequestWith(context.Background(), kc)
+	if err != nil {
+		t.Fatalf("cannot fetch committed offsets: %s", err)
+	}
+	if err := kerr.ErrorForCode(resp.ErrorCode); err != nil {
+		t.Fatalf("cannot fetch committed offsets: %s", err)
+	}
+	for _, rt := range resp.Topics {
+		for _, p := range rt.Partitions {
+			if err := kerr.ErrorForCode(p.ErrorCode); err != nil {
+				t.Fatalf("cannot fetch committed offset for partition %d: %s", p.Partition, err)
+			}
+			return p.Offset
+		}
+	}
+	return -1
+}
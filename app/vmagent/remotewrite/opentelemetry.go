@@ -0,0 +1,543 @@
+package remotewrite
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/bytesutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/decimal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/persistentqueue"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/opentelemetry/pb"
+	"github.com/klauspost/compress/gzip"
+)
+
+// pushOpenTelemetryRequest converts wr to OpenTelemetry ExportMetricsServiceRequest and passes it to pushBlock.
+//
+// The request is gzip-compressed. It is split into smaller requests if it exceeds -remoteWrite.maxBlockSize.
+func pushOpenTelemetryRequest(wr *prompbmarshal.WriteRequest, pushBlock func(block []byte)) {
+	if len(wr.Timeseries) == 0 {
+		// Nothing to push
+		return
+	}
+	ms := timeSeriesToOpenTelemetryMetrics(wr.Timeseries)
+	pushOpenTelemetryMetrics(ms, pushBlock)
+}
+
+func pushOpenTelemetryMetrics(ms []*pb.Metric, pushBlock func(block []byte)) {
+	if len(ms) == 0 {
+		return
+	}
+	req := &pb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*pb.ResourceMetrics{
+			{
+				// Some receivers skip resource metrics without resource, so always send it.
+				Resource: &pb.Resource{},
+				ScopeMetrics: []*pb.ScopeMetrics{
+					{
+						Metrics: ms,
+					},
+				},
+			},
+		},
+	}
+	if req.SizeVT() <= maxUnpackedBlockSize.IntN() {
+		bb := writeRequestBufPool.Get()
+		bb.B = bytesutil.ResizeNoCopyNoOverallocate(bb.B, req.SizeVT())
+		n, err := req.MarshalToVT(bb.B)
+		if err != nil {
+			logger.Panicf("BUG: cannot marshal OpenTelemetry request: %s", err)
+		}
+		bb.B = bb.B[:n]
+		zb := snappyBufPool.Get()
+		zb.B = compressGzip(zb.B[:0], bb.B)
+		writeRequestBufPool.Put(bb)
+		if len(zb.B) <= persistentqueue.MaxBlockSize {
+			pushBlock(zb.B)
+			blockSizeRows.Update(float64(getOpenTelemetryDataPointsCount(ms)))
+			blockSizeBytes.Update(float64(len(zb.B)))
+			snappyBufPool.Put(zb)
+			return
+		}
+		snappyBufPool.Put(zb)
+	}
+
+	// Too big block. Recursively split it into smaller parts if possible.
+	if len(ms) == 1 {
+		m1, m2 := splitOpenTelemetryMetric(ms[0])
+		if m1 == nil {
+			logger.Warnf("dropping OpenTelemetry data point for metric %q exceeding -remoteWrite.maxBlockSize=%d bytes", ms[0].Name, maxUnpackedBlockSize.N)
+			return
+		}
+		pushOpenTelemetryMetrics([]*pb.Metric{m1}, pushBlock)
+		pushOpenTelemetryMetrics([]*pb.Metric{m2}, pushBlock)
+		return
+	}
+	n := len(ms) / 2
+	pushOpenTelemetryMetrics(ms[:n], pushBlock)
+	pushOpenTelemetryMetrics(ms[n:], pushBlock)
+}
+
+// splitOpenTelemetryMetric splits data points for m into two metrics.
+//
+// nil metrics are returned if m contains less than two data points.
+func splitOpenTelemetryMetric(m *pb.Metric) (*pb.Metric, *pb.Metric) {
+	m1 := &pb.Metric{
+		Name: m.Name,
+	}
+	m2 := &pb.Metric{
+		Name: m.Name,
+	}
+	switch t := m.Data.(type) {
+	case *pb.Metric_Gauge:
+		dps := t.Gauge.DataPoints
+		if len(dps) < 2 {
+			return nil, nil
+		}
+		n := len(dps) / 2
+		m1.Data = &pb.Metric_Gauge{Gauge: &pb.Gauge{DataPoints: dps[:n]}}
+		m2.Data = &pb.Metric_Gauge{Gauge: &pb.Gauge{DataPoints: dps[n:]}}
+	case *pb.Metric_Sum:
+		dps := t.Sum.DataPoints
+		if len(dps) < 2 {
+			return nil, nil
+		}
+		n := len(dps) / 2
+		m1.Data = &pb.Metric_Sum{Sum: newOpenTelemetrySum(dps[:n])}
+		m2.Data = &pb.Metric_Sum{Sum: newOpenTelemetrySum(dps[n:])}
+	case *pb.Metric_Histogram:
+		dps := t.Histogram.DataPoints
+		if len(dps) < 2 {
+			return nil, nil
+		}
+		n := len(dps) / 2
+		m1.Data = &pb.Metric_Histogram{Histogram: newOpenTelemetryHistogram(dps[:n])}
+		m2.Data = &pb.Metric_Histogram{Histogram: newOpenTelemetryHistogram(dps[n:])}
+	default:
+		return nil, nil
+	}
+	return m1, m2
+}
+
+func getOpenTelemetryDataPointsCount(ms []*pb.Metric) int {
+	n := 0
+	for _, m := range ms {
+		switch t := m.Data.(type) {
+		case *pb.Metric_Gauge:
+			n += len(t.Gauge.DataPoints)
+		case *pb.Metric_Sum:
+			n += len(t.Sum.DataPoints)
+		case *pb.Metric_Histogram:
+			n += len(t.Histogram.DataPoints)
+		}
+	}
+	return n
+}
+
+func newOpenTelemetrySum(dps []*pb.NumberDataPoint) *pb.Sum {
+	return &pb.Sum{
+		DataPoints:             dps,
+		AggregationTemporality: pb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		IsMonotonic:            true,
+	}
+}
+
+func newOpenTelemetryHistogram(dps []*pb.HistogramDataPoint) *pb.Histogram {
+	return &pb.Histogram{
+		DataPoints:             dps,
+		AggregationTemporality: pb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+	}
+}
+
+// timeSeriesToOpenTelemetryMetrics converts tss to OpenTelemetry metrics.
+//
+// The following conversion rules are applied:
+//
+//   - series with names ending with `_total` are converted to cumulative monotonic sums.
+//   - series with names ending with `_bucket` and containing `le` label are converted to cumulative histograms
+//     together with the corresponding `_sum` and `_count` series.
+//   - the rest of series are converted to gauges.
+func timeSeriesToOpenTelemetryMetrics(tss []prompbmarshal.TimeSeries) []*pb.Metric {
+	// Collect histogram names, so the corresponding _sum and _count series could be merged into histograms.
+	histogramNames := make(map[string]struct{})
+	for i := range tss {
+		ts := &tss[i]
+		name := getMetricName(ts.Labels)
+		if strings.HasSuffix(name, "_bucket") && hasValidLe(ts.Labels) {
+			histogramNames[strings.TrimSuffix(name, "_bucket")] = struct{}{}
+		}
+	}
+
+	var ms []*pb.Metric
+	metricsIdx := make(map[string]*pb.Metric)
+	histograms := make(map[string]*histogramPoint)
+	var histogramPoints []*histogramPoint
+	var keyBuf []byte
+	for i := range tss {
+		ts := &tss[i]
+		name := getMetricName(ts.Labels)
+		if hName, suffix := getHistogramNameAndSuffix(name, histogramNames); hName != "" {
+			le := math.NaN()
+			if suffix == "_bucket" {
+				le = getLe(ts.Labels)
+				if math.IsNaN(le) {
+					// Invalid bucket. Send it as gauge.
+					m := getOrCreateOpenTelemetryMetric(&ms, metricsIdx, name, "gauge")
+					g := m.Data.(*pb.Metric_Gauge).Gauge
+					g.DataPoints = appendNumberDataPoints(g.DataPoints, ts)
+					continue
+				}
+			}
+			for _, s := range ts.Samples {
+				keyBuf = appendHistogramPointKey(keyBuf[:0], hName, ts.Labels, s.Timestamp)
+				hp := histograms[string(keyBuf)]
+				if hp == nil {
+					hp = &histogramPoint{
+						attributes: labelsToOpenTelemetryAttributes(ts.Labels, true),
+						timestamp:  s.Timestamp,
+					}
+					histograms[string(keyBuf)] = hp
+					histogramPoints = append(histogramPoints, hp)
+					m := getOrCreateOpenTelemetryMetric(&ms, metricsIdx, hName, "histogram")
+					h := m.Data.(*pb.Metric_Histogram).Histogram
+					h.DataPoints = append(h.DataPoints, &pb.HistogramDataPoint{})
+					hp.dp = h.DataPoints[len(h.DataPoints)-1]
+				}
+				hp.add(suffix, le, s.Value)
+			}
+			if suffix == "_bucket" && len(ts.Exemplars) > 0 && len(ts.Samples) > 0 {
+				keyBuf = appendHistogramPointKey(keyBuf[:0], hName, ts.Labels, ts.Samples[len(ts.Samples)-1].Timestamp)
+				hp := histograms[string(keyBuf)]
+				hp.dp.Exemplars = appendOpenTelemetryExemplars(hp.dp.Exemplars, ts.Exemplars)
+			}
+			continue
+		}
+		if strings.HasSuffix(name, "_total") {
+			m := getOrCreateOpenTelemetryMetric(&ms, metricsIdx, name, "sum")
+			sum := m.Data.(*pb.Metric_Sum).Sum
+			sum.DataPoints = appendNumberDataPoints(sum.DataPoints, ts)
+			continue
+		}
+		m := getOrCreateOpenTelemetryMetric(&ms, metricsIdx, name, "gauge")
+		g := m.Data.(*pb.Metric_Gauge).Gauge
+		g.DataPoints = appendNumberDataPoints(g.DataPoints, ts)
+	}
+	for _, hp := range histogramPoints {
+		hp.finalize()
+	}
+	return ms
+}
+
+func getOrCreateOpenTelemetryMetric(ms *[]*pb.Metric, metricsIdx map[string]*pb.Metric, name, kind string) *pb.Metric {
+	key := kind + ":" + name
+	if m := metricsIdx[key]; m != nil {
+		return m
+	}
+	m := &pb.Metric{
+		Name: name,
+	}
+	switch kind {
+	case "gauge":
+		m.Data = &pb.Metric_Gauge{Gauge: &pb.Gauge{}}
+	case "sum":
+		m.Data = &pb.Metric_Sum{Sum: newOpenTelemetrySum(nil)}
+	case "histogram":
+		m.Data = &pb.Metric_Histogram{Histogram: newOpenTelemetryHistogram(nil)}
+	default:
+		logger.Panicf("BUG: unexpected metric kind: %q", kind)
+	}
+	metricsIdx[key] = m
+	*ms = append(*ms, m)
+	return m
+}
+
+func appendNumberDataPoints(dst []*pb.NumberDataPoint, ts *prompbmarshal.TimeSeries) []*pb.NumberDataPoint {
+	attributes := labelsToOpenTelemetryAttributes(ts.Labels, false)
+	for i, s := range ts.Samples {
+		dp := &pb.NumberDataPoint{
+			Attributes:   attributes,
+			TimeUnixNano: uint64(s.Timestamp) * 1e6,
+			Value: &pb.NumberDataPoint_AsDouble{
+				AsDouble: s.Value,
+			},
+		}
+		if decimal.IsStaleNaN(s.Value) {
+			dp.Value = &pb.NumberDataPoint_AsDouble{}
+			dp.Flags = uint32(pb.DataPointFlags_FLAG_NO_RECORDED_VALUE)
+		}
+		if i == len(ts.Samples)-1 {
+			// Attach exemplars to the last data point only in order to avoid their duplication.
+			dp.Exemplars = appendOpenTelemetryExemplars(nil, ts.Exemplars)
+		}
+		dst = append(dst, dp)
+	}
+	return dst
+}
+
+func appendOpenTelemetryExemplars(dst []*pb.Exemplar, exemplars []prompbmarshal.Exemplar) []*pb.Exemplar {
+	for i := range exemplars {
+		e := &exemplars[i]
+		pe := &pb.Exemplar{
+			TimeUnixNano: uint64(e.Timestamp) * 1e6,
+			Value: &pb.Exemplar_AsDouble{
+				AsDouble: e.Value,
+			},
+		}
+		for _, label := range e.Labels {
+			switch label.Name {
+			case "trace_id":
+				if traceID, err := hex.DecodeString(label.Value); err == nil {
+					pe.TraceId = traceID
+					continue
+				}
+			case "span_id":
+				if spanID, err := hex.DecodeString(label.Value); err == nil {
+					pe.SpanId = spanID
+					continue
+				}
+			}
+			pe.FilteredAttributes = append(pe.FilteredAttributes, newOpenTelemetryKeyValue(label.Name, label.Value))
+		}
+		dst = append(dst, pe)
+	}
+	return dst
+}
+
+// labelsToOpenTelemetryAttributes converts labels to OpenTelemetry attributes.
+//
+// __name__ label is skipped. `le` label is skipped if skipLe is set.
+func labelsToOpenTelemetryAttributes(labels []prompbmarshal.Label, skipLe bool) []*pb.KeyValue {
+	attributes := make([]*pb.KeyValue, 0, len(labels))
+	for _, label := range labels {
+		if label.Name == "__name__" || (skipLe && label.Name == "le") {
+			continue
+		}
+		attributes = append(attributes, newOpenTelemetryKeyValue(label.Name, label.Value))
+	}
+	return attributes
+}
+
+func newOpenTelemetryKeyValue(key, value string) *pb.KeyValue {
+	return &pb.KeyValue{
+		Key: key,
+		Value: &pb.AnyValue{
+			Value: &pb.AnyValue_StringValue{
+				StringValue: value,
+			},
+		},
+	}
+}
+
+func getMetricName(labels []prompbmarshal.Label) string {
+	for _, label := range labels {
+		if label.Name == "__name__" {
+			return label.Value
+		}
+	}
+	return ""
+}
+
+func hasValidLe(labels []prompbmarshal.Label) bool {
+	return !math.IsNaN(getLe(labels))
+}
+
+// getLe returns `le` label value from labels.
+//
+// NaN is returned if labels have no valid `le` label.
+func getLe(labels []prompbmarshal.Label) float64 {
+	for _, label := range labels {
+		if label.Name == "le" {
+			le, err := strconv.ParseFloat(label.Value, 64)
+			if err != nil {
+				return math.NaN()
+			}
+			return le
+		}
+	}
+	return math.NaN()
+}
+
+// getHistogramNameAndSuffix returns histogram name and series suffix (_bucket, _sum or _count) for the given series name.
+//
+// Empty histogram name is returned if the series doesn't belong to histograms.
+func getHistogramNameAndSuffix(name string, histogramNames map[string]struct{}) (string, string) {
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		hName := strings.TrimSuffix(name, suffix)
+		if _, ok := histogramNames[hName]; ok {
+			return hName, suffix
+		}
+	}
+	return "", ""
+}
+
+// getHistogramFamilyName returns histogram name for the series name with _bucket, _sum or _count suffix.
+//
+// Empty string is returned if the name has no such suffix.
+func getHistogramFamilyName(name string) string {
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		if strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(name, suffix)
+		}
+	}
+	return ""
+}
+
+// isSameHistogramPoint returns true if a and b may belong to the same histogram data point,
+// e.g. they have the same histogram name and the same labels except of `le`.
+func isSameHistogramPoint(a, b *prompbmarshal.TimeSeries) bool {
+	hName := getHistogramFamilyName(getMetricName(a.Labels))
+	if hName == "" || hName != getHistogramFamilyName(getMetricName(b.Labels)) {
+		return false
+	}
+	keyA := appendHistogramPointKey(nil, hName, a.Labels, 0)
+	keyB := appendHistogramPointKey(nil, hName, b.Labels, 0)
+	return string(keyA) == string(keyB)
+}
+
+// appendHistogramPointKey appends a key for histogram data point to dst and returns the result.
+//
+// The key contains histogram name, labels except of __name__ and le, and timestamp.
+func appendHistogramPointKey(dst []byte, name string, labels []prompbmarshal.Label, timestamp int64) []byte {
+	dst = append(dst, name...)
+	dst = append(dst, 0)
+	dst = strconv.AppendInt(dst, timestamp, 10)
+	sortedLabels := make([]prompbmarshal.Label, 0, len(labels))
+	for _, label := range labels {
+		if label.Name == "__name__" || label.Name == "le" {
+			continue
+		}
+		sortedLabels = append(sortedLabels, label)
+	}
+	sort.Slice(sortedLabels, func(i, j int) bool {
+		return sortedLabels[i].Name < sortedLabels[j].Name
+	})
+	for _, label := range sortedLabels {
+		dst = append(dst, 0)
+		dst = append(dst, label.Name...)
+		dst = append(dst, '=')
+		dst = append(dst, label.Value...)
+	}
+	return dst
+}
+
+// histogramPoint is used for collecting Prometheus histogram series into OpenTelemetry histogram data point.
+type histogramPoint struct {
+	attributes []*pb.KeyValue
+	timestamp  int64
+
+	dp *pb.HistogramDataPoint
+
+	buckets  []histogramBucket
+	sum      float64
+	hasSum   bool
+	count    float64
+	hasCount bool
+	isStale  bool
+}
+
+type histogramBucket struct {
+	le    float64
+	count float64
+}
+
+func (hp *histogramPoint) add(suffix string, le, value float64) {
+	if decimal.IsStaleNaN(value) {
+		hp.isStale = true
+		return
+	}
+	switch suffix {
+	case "_bucket":
+		hp.buckets = append(hp.buckets, histogramBucket{
+			le:    le,
+			count: value,
+		})
+	case "_sum":
+		hp.sum = value
+		hp.hasSum = true
+	case "_count":
+		hp.count = value
+		hp.hasCount = true
+	}
+}
+
+// finalize fills hp.dp with the collected data.
+func (hp *histogramPoint) finalize() {
+	dp := hp.dp
+	dp.Attributes = hp.attributes
+	dp.TimeUnixNano = uint64(hp.timestamp) * 1e6
+	if hp.isStale {
+		dp.Flags = uint32(pb.DataPointFlags_FLAG_NO_RECORDED_VALUE)
+		return
+	}
+
+	buckets := hp.buckets
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].le < buckets[j].le
+	})
+	if len(buckets) == 0 || !math.IsInf(buckets[len(buckets)-1].le, 1) {
+		// Add missing +Inf bucket.
+		infCount := hp.count
+		if !hp.hasCount && len(buckets) > 0 {
+			infCount = buckets[len(buckets)-1].count
+		}
+		buckets = append(buckets, histogramBucket{
+			le:    math.Inf(1),
+			count: infCount,
+		})
+	}
+
+	// Convert cumulative Prometheus buckets to non-cumulative OpenTelemetry buckets.
+	dp.ExplicitBounds = make([]float64, 0, len(buckets)-1)
+	dp.BucketCounts = make([]uint64, 0, len(buckets))
+	prevCount := 0.0
+	for _, b := range buckets {
+		if !math.IsInf(b.le, 1) {
+			dp.ExplicitBounds = append(dp.ExplicitBounds, b.le)
+		}
+		d := b.count - prevCount
+		if d < 0 {
+			d = 0
+		}
+		dp.BucketCounts = append(dp.BucketCounts, uint64(math.Round(d)))
+		if b.count > prevCount {
+			prevCount = b.count
+		}
+	}
+	dp.Count = uint64(math.Round(buckets[len(buckets)-1].count))
+	if hp.hasCount {
+		dp.Count = uint64(math.Round(hp.count))
+	}
+	if hp.hasSum {
+		sum := hp.sum
+		dp.Sum = &sum
+	}
+}
+
+func compressGzip(dst, src []byte) []byte {
+	bb := bytes.NewBuffer(dst)
+	zw := gzipWriterPool.Get().(*gzip.Writer)
+	zw.Reset(bb)
+	if _, err := zw.Write(src); err != nil {
+		logger.Panicf("BUG: unexpected error when writing gzip-compressed data to memory: %s", err)
+	}
+	if err := zw.Close(); err != nil {
+		logger.Panicf("BUG: unexpected error when closing gzip writer: %s", err)
+	}
+	gzipWriterPool.Put(zw)
+	return bb.Bytes()
+}
+
+var gzipWriterPool = &sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
@@ -0,0 +1,245 @@
+package remotewrite
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/decimal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/opentelemetry/pb"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/opentelemetry/stream"
+	"github.com/klauspost/compress/gzip"
+)
+
+func TestPushOpenTelemetryRequest(t *testing.T) {
+	f := func(tss []prompbmarshal.TimeSeries, metricTypesExpected, resultExpected string) {
+		t.Helper()
+
+		var blocks [][]byte
+		pushBlock := func(block []byte) {
+			blocks = append(blocks, append([]byte{}, block...))
+		}
+		wr := &prompbmarshal.WriteRequest{
+			Timeseries: tss,
+		}
+		pushOpenTelemetryRequest(wr, pushBlock)
+		if len(blocks) != 1 {
+			t.Fatalf("unexpected number of blocks; got %d; want 1", len(blocks))
+		}
+
+		// Verify metric types
+		zr, err := gzip.NewReader(bytes.NewReader(blocks[0]))
+		if err != nil {
+			t.Fatalf("cannot open gzip reader: %s", err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("cannot decompress block: %s", err)
+		}
+		var req pb.ExportMetricsServiceRequest
+		if err := req.UnmarshalVT(data); err != nil {
+			t.Fatalf("cannot unmarshal OpenTelemetry request: %s", err)
+		}
+		var metricTypes []string
+		for _, rm := range req.ResourceMetrics {
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					metricTypes = append(metricTypes, fmt.Sprintf("%s:%T", m.Name, m.Data))
+				}
+			}
+		}
+		if s := strings.Join(metricTypes, ","); s != metricTypesExpected {
+			t.Fatalf("unexpected metric types;\ngot\n%s\nwant\n%s", s, metricTypesExpected)
+		}
+
+		// Verify the block can be parsed back
+		var result []string
+		err = stream.ParseStream(bytes.NewReader(blocks[0]), true, func(tss []prompbmarshal.TimeSeries) error {
+			for _, ts := range tss {
+				result = append(result, timeSeriesToString(ts))
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("cannot parse OpenTelemetry request: %s", err)
+		}
+		sort.Strings(result)
+		if s := strings.Join(result, ""); s != resultExpected {
+			t.Fatalf("unexpected result;\ngot\n%s\nwant\n%s", s, resultExpected)
+		}
+	}
+
+	// gauge
+	f([]prompbmarshal.TimeSeries{
+		newTestTimeSeries(`foo{job="a"}`, 1.5, 1000),
+	}, "foo:*pb.Metric_Gauge", `foo{job="a"} 1.5 1000
+`)
+
+	// monotonic sum
+	f([]prompbmarshal.TimeSeries{
+		newTestTimeSeries(`http_requests_total{path="/foo"}`, 10, 1000),
+		newTestTimeSeries(`http_requests_total{path="/bar"}`, 2, 1000),
+	}, "http_requests_total:*pb.Metric_Sum", `http_requests_total{path="/bar"} 2 1000
+http_requests_total{path="/foo"} 10 1000
+`)
+
+	// histogram
+	f([]prompbmarshal.TimeSeries{
+		newTestTimeSeries(`req_duration_bucket{job="a",le="0.5"}`, 1, 2000),
+		newTestTimeSeries(`req_duration_bucket{job="a",le="1"}`, 3, 2000),
+		newTestTimeSeries(`req_duration_bucket{job="a",le="+Inf"}`, 4, 2000),
+		newTestTimeSeries(`req_duration_sum{job="a"}`, 2.5, 2000),
+		newTestTimeSeries(`req_duration_count{job="a"}`, 4, 2000),
+		newTestTimeSeries(`req_size_count{job="a"}`, 5, 2000),
+	}, "req_duration:*pb.Metric_Histogram,req_size_count:*pb.Metric_Gauge", `req_duration_bucket{job="a",le="+Inf"} 4 2000
+req_duration_bucket{job="a",le="0.5"} 1 2000
+req_duration_bucket{job="a",le="1"} 3 2000
+req_duration_count{job="a"} 4 2000
+req_duration_sum{job="a"} 2.5 2000
+req_size_count{job="a"} 5 2000
+`)
+
+	// stale marker
+	f([]prompbmarshal.TimeSeries{
+		newTestTimeSeries(`foo`, decimal.StaleNaN, 1000),
+	}, "foo:*pb.Metric_Gauge", `foo NaN 1000
+`)
+}
+
+func TestPushOpenTelemetryRequestSplit(t *testing.T) {
+	defer func(n int64) {
+		maxUnpackedBlockSize.N = n
+	}(maxUnpackedBlockSize.N)
+	maxUnpackedBlockSize.N = 200
+
+	var tss []prompbmarshal.TimeSeries
+	for i := 0; i < 20; i++ {
+		tss = append(tss, newTestTimeSeries(fmt.Sprintf(`foo{instance="host-%d"}`, i), float64(i), 1000))
+	}
+	var blocksCount int
+	rowsCount := 0
+	pushBlock := func(block []byte) {
+		blocksCount++
+		err := stream.ParseStream(bytes.NewReader(block), true, func(tss []prompbmarshal.TimeSeries) error {
+			rowsCount += len(tss)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("cannot parse OpenTelemetry request: %s", err)
+		}
+	}
+	pushOpenTelemetryRequest(&prompbmarshal.WriteRequest{Timeseries: tss}, pushBlock)
+	if blocksCount < 2 {
+		t.Fatalf("expecting the request to be split into multiple blocks; got %d blocks", blocksCount)
+	}
+	if rowsCount != len(tss) {
+		t.Fatalf("unexpected number of rows; got %d; want %d", rowsCount, len(tss))
+	}
+}
+
+func TestPendingSeriesOpenTelemetryHistogram(t *testing.T) {
+	defer func(n int) {
+		*maxRowsPerBlock = n
+	}(*maxRowsPerBlock)
+	*maxRowsPerBlock = 2
+
+	var blocks []string
+	pushBlock := func(block []byte) {
+		var result []string
+		err := stream.ParseStream(bytes.NewReader(block), true, func(tss []prompbmarshal.TimeSeries) error {
+			for _, ts := range tss {
+				result = append(result, timeSeriesToString(ts))
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("cannot parse OpenTelemetry request: %s", err)
+		}
+		sort.Strings(result)
+		blocks = append(blocks, strings.Join(result, ""))
+	}
+	var wr writeRequest
+	wr.pushBlock = pushBlock
+	wr.isOpenTelemetry = true
+	wr.roundDigits = 100
+	wr.push([]prompbmarshal.TimeSeries{
+		newTestTimeSeries(`foo{job="a"}`, 1, 2000),
+		newTestTimeSeries(`req_duration_bucket{job="a",le="0.5"}`, 1, 2000),
+		newTestTimeSeries(`req_duration_bucket{job="a",le="1"}`, 3, 2000),
+		newTestTimeSeries(`req_duration_bucket{job="a",le="+Inf"}`, 4, 2000),
+		newTestTimeSeries(`req_duration_sum{job="a"}`, 2.5, 2000),
+		newTestTimeSeries(`req_duration_count{job="a"}`, 4, 2000),
+		newTestTimeSeries(`req_duration_bucket{job="b",le="1"}`, 1, 2000),
+		newTestTimeSeries(`req_duration_bucket{job="b",le="+Inf"}`, 1, 2000),
+		newTestTimeSeries(`req_duration_sum{job="b"}`, 0.5, 2000),
+		newTestTimeSeries(`req_duration_count{job="b"}`, 1, 2000),
+	})
+	wr.flush()
+
+	// Series for the same histogram data point must be sent in a single block,
+	// while series for distinct data points may be sent in distinct blocks.
+	blocksExpected := []string{
+		`foo{job="a"} 1 2000
+req_duration_bucket{job="a",le="+Inf"} 4 2000
+req_duration_bucket{job="a",le="0.5"} 1 2000
+req_duration_bucket{job="a",le="1"} 3 2000
+req_duration_count{job="a"} 4 2000
+req_duration_sum{job="a"} 2.5 2000
+`,
+		`req_duration_bucket{job="b",le="+Inf"} 1 2000
+req_duration_bucket{job="b",le="1"} 1 2000
+req_duration_count{job="b"} 1 2000
+req_duration_sum{job="b"} 0.5 2000
+`,
+	}
+	if s, sExpected := strings.Join(blocks, "---\n"), strings.Join(blocksExpected, "---\n"); s != sExpected {
+		t.Fatalf("unexpected blocks;\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+}
+
+func newTestTimeSeries(s string, value float64, timestamp int64) prompbmarshal.TimeSeries {
+	name := s
+	var labels []prompbmarshal.Label
+	if n := strings.IndexByte(s, '{'); n >= 0 {
+		name = s[:n]
+		for _, kv := range strings.Split(strings.TrimSuffix(s[n+1:], "}"), ",") {
+			k, v, _ := strings.Cut(kv, "=")
+			labels = append(labels, prompbmarshal.Label{
+				Name:  k,
+				Value: strings.Trim(v, `"`),
+			})
+		}
+	}
+	labels = append([]prompbmarshal.Label{{Name: "__name__", Value: name}}, labels...)
+	return prompbmarshal.TimeSeries{
+		Labels: labels,
+		Samples: []prompbmarshal.Sample{{
+			Value:     value,
+			Timestamp: timestamp,
+		}},
+	}
+}
+
+func timeSeriesToString(ts prompbmarshal.TimeSeries) string {
+	var sb strings.Builder
+	var labels []string
+	for _, label := range ts.Labels {
+		if label.Name == "__name__" {
+			sb.WriteString(label.Value)
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("%s=%q", label.Name, label.Value))
+	}
+	sort.Strings(labels)
+	if len(labels) > 0 {
+		sb.WriteString("{" + strings.Join(labels, ",") + "}")
+	}
+	for _, s := range ts.Samples {
+		fmt.Fprintf(&sb, " %v %d\n", s.Value, s.Timestamp)
+	}
+	return sb.String()
+}
@@ -37,10 +37,11 @@ type pendingSeries struct {
 	periodicFlusherWG sync.WaitGroup
 }
 
-func newPendingSeries(pushBlock func(block []byte), isVMRemoteWrite bool, significantFigures, roundDigits int) *pendingSeries {
+func newPendingSeries(pushBlock func(block []byte), isVMRemoteWrite, isOpenTelemetry bool, significantFigures, roundDigits int) *pendingSeries {
 	var ps pendingSeries
 	ps.wr.pushBlock = pushBlock
 	ps.wr.isVMRemoteWrite = isVMRemoteWrite
+	ps.wr.isOpenTelemetry = isOpenTelemetry
 	ps.wr.significantFigures = significantFigures
 	ps.wr.roundDigits = roundDigits
 	ps.stopCh = make(chan struct{})
@@ -96,6 +97,9 @@ type writeRequest struct {
 	// Whether to encode the write request with VictoriaMetrics remote write protocol.
 	isVMRemoteWrite bool
 
+	// Whether to encode the write request with OpenTelemetry protocol.
+	isOpenTelemetry bool
+
 	// How many significant figures must be left before sending the writeRequest to pushBlock.
 	significantFigures int
 
@@ -106,13 +110,15 @@ type writeRequest struct {
 
 	tss []prompbmarshal.TimeSeries
 
-	labels  []prompbmarshal.Label
-	samples []prompbmarshal.Sample
-	buf     []byte
+	labels         []prompbmarshal.Label
+	samples        []prompbmarshal.Sample
+	exemplars      []prompbmarshal.Exemplar
+	exemplarLabels []prompbmarshal.Label
+	buf            []byte
 }
 
 func (wr *writeRequest) reset() {
-	// Do not reset lastFlushTime, pushBlock, isVMRemoteWrite, significantFigures and roundDigits, since they are re-used.
+	// Do not reset lastFlushTime, pushBlock, isVMRemoteWrite, isOpenTelemetry, significantFigures and roundDigits, since they are re-used.
 
 	wr.wr.Timeseries = nil
 
@@ -120,6 +126,7 @@ func (wr *writeRequest) reset() {
 		ts := &wr.tss[i]
 		ts.Labels = nil
 		ts.Samples = nil
+		ts.Exemplars = nil
 	}
 	wr.tss = wr.tss[:0]
 
@@ -127,6 +134,15 @@ func (wr *writeRequest) reset() {
 	wr.labels = wr.labels[:0]
 
 	wr.samples = wr.samples[:0]
+
+	for i := range wr.exemplars {
+		wr.exemplars[i].Labels = nil
+	}
+	wr.exemplars = wr.exemplars[:0]
+
+	promrelabel.CleanLabels(wr.exemplarLabels)
+	wr.exemplarLabels = wr.exemplarLabels[:0]
+
 	wr.buf = wr.buf[:0]
 }
 
@@ -134,7 +150,11 @@ func (wr *writeRequest) flush() {
 	wr.wr.Timeseries = wr.tss
 	wr.adjustSampleValues()
 	atomic.StoreUint64(&wr.lastFlushTime, fasttime.UnixTimestamp())
-	pushWriteRequest(&wr.wr, wr.pushBlock, wr.isVMRemoteWrite)
+	if wr.isOpenTelemetry {
+		pushOpenTelemetryRequest(&wr.wr, wr.pushBlock)
+	} else {
+		pushWriteRequest(&wr.wr, wr.pushBlock, wr.isVMRemoteWrite)
+	}
 	wr.reset()
 }
 
@@ -163,6 +183,11 @@ func (wr *writeRequest) push(src []prompbmarshal.TimeSeries) {
 		tssDst = append(tssDst, prompbmarshal.TimeSeries{})
 		wr.copyTimeSeries(&tssDst[len(tssDst)-1], &src[i])
 		if len(wr.samples) >= maxSamplesPerBlock || len(wr.labels) >= maxLabelsPerBlock {
+			if wr.isOpenTelemetry && i+1 < len(src) && isSameHistogramPoint(&src[i], &src[i+1]) {
+				// Do not split series of the same histogram between blocks,
+				// since otherwise they cannot be converted into a single OpenTelemetry histogram data point.
+				continue
+			}
 			wr.tss = tssDst
 			wr.flush()
 			tssDst = wr.tss
@@ -191,6 +216,34 @@ func (wr *writeRequest) copyTimeSeries(dst, src *prompbmarshal.TimeSeries) {
 	samplesDst = append(samplesDst, src.Samples...)
 	dst.Samples = samplesDst[len(samplesDst)-len(src.Samples):]
 
+	if len(src.Exemplars) > 0 {
+		exemplarsDst := wr.exemplars
+		exemplarLabelsDst := wr.exemplarLabels
+		exemplarsLen := len(exemplarsDst)
+		for i := range src.Exemplars {
+			srcExemplar := &src.Exemplars[i]
+			exemplarLabelsLen := len(exemplarLabelsDst)
+			for j := range srcExemplar.Labels {
+				exemplarLabelsDst = append(exemplarLabelsDst, prompbmarshal.Label{})
+				dstLabel := &exemplarLabelsDst[len(exemplarLabelsDst)-1]
+				srcLabel := &srcExemplar.Labels[j]
+
+				buf = append(buf, srcLabel.Name...)
+				dstLabel.Name = bytesutil.ToUnsafeString(buf[len(buf)-len(srcLabel.Name):])
+				buf = append(buf, srcLabel.Value...)
+				dstLabel.Value = bytesutil.ToUnsafeString(buf[len(buf)-len(srcLabel.Value):])
+			}
+			exemplarsDst = append(exemplarsDst, prompbmarshal.Exemplar{
+				Labels:    exemplarLabelsDst[exemplarLabelsLen:],
+				Value:     srcExemplar.Value,
+				Timestamp: srcExemplar.Timestamp,
+			})
+		}
+		dst.Exemplars = exemplarsDst[exemplarsLen:]
+		wr.exemplars = exemplarsDst
+		wr.exemplarLabels = exemplarLabelsDst
+	}
+
 	wr.samples = samplesDst
 	wr.labels = labelsDst
 	wr.buf = buf
@@ -226,17 +279,29 @@ func pushWriteRequest(wr *prompbmarshal.WriteRequest, pushBlock func(block []byt
 	// Too big block. Recursively split it into smaller parts if possible.
 	if len(wr.Timeseries) == 1 {
 		// A single time series left. Recursively split its samples into smaller parts if possible.
-		samples := wr.Timeseries[0].Samples
+		ts := &wr.Timeseries[0]
+		samples := ts.Samples
+		exemplars := ts.Exemplars
 		if len(samples) == 1 {
+			if len(exemplars) > 0 {
+				// Try sending the sample without exemplars, since they may be too big.
+				ts.Exemplars = nil
+				pushWriteRequest(wr, pushBlock, isVMRemoteWrite)
+				ts.Exemplars = exemplars
+				return
+			}
 			logger.Warnf("dropping a sample for metric with too long labels exceeding -remoteWrite.maxBlockSize=%d bytes", maxUnpackedBlockSize.N)
 			return
 		}
+		// Send exemplars only with the first half of samples in order to avoid their duplication.
 		n := len(samples) / 2
-		wr.Timeseries[0].Samples = samples[:n]
+		ts.Samples = samples[:n]
 		pushWriteRequest(wr, pushBlock, isVMRemoteWrite)
-		wr.Timeseries[0].Samples = samples[n:]
+		ts.Samples = samples[n:]
+		ts.Exemplars = nil
 		pushWriteRequest(wr, pushBlock, isVMRemoteWrite)
-		wr.Timeseries[0].Samples = samples
+		ts.Samples = samples
+		ts.Exemplars = exemplars
 		return
 	}
 	timeseries := wr.Timeseries
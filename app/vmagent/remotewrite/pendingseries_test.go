@@ -68,3 +68,55 @@ func newTestWriteRequest(seriesCount, labelsCount int) *prompbmarshal.WriteReque
 	}
 	return &wr
 }
+
+func TestWriteRequestCopyTimeSeriesWithExemplars(t *testing.T) {
+	src := prompbmarshal.TimeSeries{
+		Labels: []prompbmarshal.Label{
+			{
+				Name:  "__name__",
+				Value: "foo",
+			},
+		},
+		Samples: []prompbmarshal.Sample{
+			{
+				Value:     1,
+				Timestamp: 1000,
+			},
+		},
+		Exemplars: []prompbmarshal.Exemplar{
+			{
+				Labels: []prompbmarshal.Label{
+					{
+						Name:  "trace_id",
+						Value: "abc",
+					},
+				},
+				Value:     1,
+				Timestamp: 900,
+			},
+		},
+	}
+	var wr writeRequest
+	var dst prompbmarshal.TimeSeries
+	wr.copyTimeSeries(&dst, &src)
+
+	// Modify the source in order to make sure dst doesn't refer to it
+	src.Exemplars[0].Labels[0].Value = "def"
+	src.Exemplars[0].Value = 2
+
+	if len(dst.Exemplars) != 1 {
+		t.Fatalf("unexpected number of exemplars; got %d; want 1", len(dst.Exemplars))
+	}
+	e := &dst.Exemplars[0]
+	if len(e.Labels) != 1 || e.Labels[0].Name != "trace_id" || e.Labels[0].Value != "abc" {
+		t.Fatalf("unexpected exemplar labels: %v", e.Labels)
+	}
+	if e.Value != 1 || e.Timestamp != 900 {
+		t.Fatalf("unexpected exemplar value=%v, timestamp=%d; want value=1, timestamp=900", e.Value, e.Timestamp)
+	}
+
+	wr.reset()
+	if len(wr.exemplars) != 0 || len(wr.exemplarLabels) != 0 {
+		t.Fatalf("exemplars must be reset; got %d exemplars and %d exemplar labels", len(wr.exemplars), len(wr.exemplarLabels))
+	}
+}
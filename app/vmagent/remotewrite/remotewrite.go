@@ -34,6 +34,7 @@ var (
 		"or Prometheus remote_write protocol. Example url: http://<victoriametrics-host>:8428/api/v1/write . "+
 		"Pass multiple -remoteWrite.url options in order to replicate the collected data to multiple remote storage systems. "+
 		"The data can be sharded among the configured remote storage systems if -remoteWrite.shardByURL flag is set. "+
+		"The data can be written to Kafka topic via kafka://<broker-host>:9092/?topic=<topic-name> url. See https://docs.victoriametrics.com/vmagent.html#writing-metrics-to-kafka . "+
 		"See also -remoteWrite.multitenantURL")
 	remoteWriteMultitenantURLs = flagutil.NewArrayString("remoteWrite.multitenantURL", "Base path for multitenant remote storage URL to write data to. "+
 		"See https://docs.victoriametrics.com/vmagent.html#multitenancy for details. Example url: http://<vminsert>:8480 . "+
@@ -80,6 +81,10 @@ var (
 		"are written to the corresponding -remoteWrite.url . See also -remoteWrite.streamAggr.keepInput and https://docs.victoriametrics.com/stream-aggregation.html")
 	streamAggrDedupInterval = flagutil.NewArrayDuration("remoteWrite.streamAggr.dedupInterval", 0, "Input samples are de-duplicated with this interval before being aggregated. "+
 		"Only the last sample per each time series per each interval is aggregated if the interval is greater than zero")
+	streamAggrPersistState = flagutil.NewArrayBool("remoteWrite.streamAggr.persistState", "Whether to save the state of stream aggregation "+
+		"for the corresponding -remoteWrite.streamAggr.config at -remoteWrite.tmpDataPath on graceful shutdown and to restore it on startup. "+
+		"This prevents from gaps and resets in the aggregated series for outputs such as total, increase and rate_sum after vmagent restart. "+
+		"The saved state is discarded if the stream aggregation config has been changed. See https://docs.victoriametrics.com/stream-aggregation.html#persisting-state")
 )
 
 var (
@@ -108,6 +113,8 @@ var maxQueues = cgroup.AvailableCPUs() * 16
 
 const persistentQueueDirname = "persistent-queue"
 
+const streamAggrStateDirname = "streamaggr-state"
+
 // InitSecretFlags must be called after flag.Parse and before any logging.
 func InitSecretFlags() {
 	if !*showRemoteWriteURL {
@@ -146,6 +153,7 @@ func Init() {
 			return float64(dailySeriesLimiter.CurrentItems())
 		})
 	}
+	initCardinalityTracker()
 	if *queues > maxQueues {
 		*queues = maxQueues
 	}
@@ -328,30 +336,56 @@ func Stop() {
 //
 // Note that wr may be modified by Push because of relabeling and rounding.
 func Push(at *auth.Token, wr *prompbmarshal.WriteRequest) {
+	rwctxs := getRemoteWriteCtxs(at)
+	pushToRemoteWriteCtxs(rwctxs, wr)
+}
+
+// TryPush sends wr to remote storage systems set via `-remoteWrite.url` like Push does.
+//
+// Unlike Push, it returns an error without sending wr if the queue for at least a single `-remoteWrite.url` is full,
+// since sending wr to the full queue may result in dropping the oldest data from the queue.
+// The queue size is limited by `-remoteWrite.maxDiskUsagePerURL` command-line flag.
+// The caller may retry sending wr later.
+//
+// Note that wr may be modified by TryPush because of relabeling and rounding.
+func TryPush(at *auth.Token, wr *prompbmarshal.WriteRequest) error {
+	rwctxs := getRemoteWriteCtxs(at)
+	for _, rwctx := range rwctxs {
+		if rwctx.fq.IsFull() {
+			return fmt.Errorf("the queue for -remoteWrite.url=%q is full; see -remoteWrite.maxDiskUsagePerURL command-line flag", rwctx.sanitizedURL)
+		}
+	}
+	pushToRemoteWriteCtxs(rwctxs, wr)
+	return nil
+}
+
+func getRemoteWriteCtxs(at *auth.Token) []*remoteWriteCtx {
 	if at == nil && len(*remoteWriteMultitenantURLs) > 0 {
 		// Write data to default tenant if at isn't set while -remoteWrite.multitenantURL is set.
 		at = defaultAuthToken
 	}
-	var rwctxs []*remoteWriteCtx
 	if at == nil {
-		rwctxs = rwctxsDefault
-	} else {
-		if len(*remoteWriteMultitenantURLs) == 0 {
-			logger.Panicf("BUG: -remoteWrite.multitenantURL command-line flag must be set when __tenant_id__=%q label is set", at)
-		}
-		rwctxsMapLock.Lock()
-		tenantID := tenantmetrics.TenantID{
-			AccountID: at.AccountID,
-			ProjectID: at.ProjectID,
-		}
-		rwctxs = rwctxsMap[tenantID]
-		if rwctxs == nil {
-			rwctxs = newRemoteWriteCtxs(at, *remoteWriteMultitenantURLs)
-			rwctxsMap[tenantID] = rwctxs
-		}
-		rwctxsMapLock.Unlock()
+		return rwctxsDefault
+	}
+	if len(*remoteWriteMultitenantURLs) == 0 {
+		logger.Panicf("BUG: -remoteWrite.multitenantURL command-line flag must be set when __tenant_id__=%q label is set", at)
+	}
+	rwctxsMapLock.Lock()
+	defer rwctxsMapLock.Unlock()
+
+	tenantID := tenantmetrics.TenantID{
+		AccountID: at.AccountID,
+		ProjectID: at.ProjectID,
+	}
+	rwctxs := rwctxsMap[tenantID]
+	if rwctxs == nil {
+		rwctxs = newRemoteWriteCtxs(at, *remoteWriteMultitenantURLs)
+		rwctxsMap[tenantID] = rwctxs
 	}
+	return rwctxs
+}
 
+func pushToRemoteWriteCtxs(rwctxs []*remoteWriteCtx, wr *prompbmarshal.WriteRequest) {
 	var rctx *relabelCtx
 	rcs := allRelabelConfigs.Load()
 	pcsGlobal := rcs.global
@@ -392,6 +426,9 @@ func Push(at *auth.Token, wr *prompbmarshal.WriteRequest) {
 		}
 		sortLabelsIfNeeded(tssBlock)
 		tssBlock = limitSeriesCardinality(tssBlock)
+		if ct := cardinalityTrackerGlobal; ct != nil {
+			ct.RegisterSeries(tssBlock)
+		}
 		pushBlockToRemoteStorages(rwctxs, tssBlock)
 		if rctx != nil {
 			rctx.reset()
@@ -544,14 +581,19 @@ var (
 )
 
 type remoteWriteCtx struct {
-	idx int
-	fq  *persistentqueue.FastQueue
-	c   *client
+	idx          int
+	sanitizedURL string
+	fq           *persistentqueue.FastQueue
+	c            *client
 
 	sas                 atomic.Pointer[streamaggr.Aggregators]
 	streamAggrKeepInput bool
 	streamAggrDropInput bool
 
+	// streamAggrStatePath is the path to the file with stream aggregation state.
+	// It is empty if -remoteWrite.streamAggr.persistState isn't set for the given -remoteWrite.url.
+	streamAggrStatePath string
+
 	pss        []*pendingSeries
 	pssNextIdx uint64
 
@@ -584,8 +626,10 @@ func newRemoteWriteCtx(argIdx int, remoteWriteURL *url.URL, maxInmemoryBlocks in
 	switch remoteWriteURL.Scheme {
 	case "http", "https":
 		c = newHTTPClient(argIdx, remoteWriteURL.String(), sanitizedURL, fq, *queues)
+	case "kafka":
+		c = newKafkaClient(argIdx, remoteWriteURL, sanitizedURL, fq)
 	default:
-		logger.Fatalf("unsupported scheme: %s for remoteWriteURL: %s, want `http`, `https`", remoteWriteURL.Scheme, sanitizedURL)
+		logger.Fatalf("unsupported scheme: %s for remoteWriteURL: %s, want `http`, `https` or `kafka`", remoteWriteURL.Scheme, sanitizedURL)
 	}
 	c.init(argIdx, *queues, sanitizedURL)
 
@@ -600,14 +644,15 @@ func newRemoteWriteCtx(argIdx int, remoteWriteURL *url.URL, maxInmemoryBlocks in
 	}
 	pss := make([]*pendingSeries, pssLen)
 	for i := range pss {
-		pss[i] = newPendingSeries(fq.MustWriteBlock, c.useVMProto, sf, rd)
+		pss[i] = newPendingSeries(fq.MustWriteBlock, c.useVMProto, c.useOpenTelemetry, sf, rd)
 	}
 
 	rwctx := &remoteWriteCtx{
-		idx: argIdx,
-		fq:  fq,
-		c:   c,
-		pss: pss,
+		idx:          argIdx,
+		sanitizedURL: sanitizedURL,
+		fq:           fq,
+		c:            c,
+		pss:          pss,
 
 		rowsPushedAfterRelabel: metrics.GetOrCreateCounter(fmt.Sprintf(`vmagent_remotewrite_rows_pushed_after_relabel_total{path=%q, url=%q}`, queuePath, sanitizedURL)),
 		rowsDroppedByRelabel:   metrics.GetOrCreateCounter(fmt.Sprintf(`vmagent_remotewrite_relabel_metrics_dropped_total{path=%q, url=%q}`, queuePath, sanitizedURL)),
@@ -621,6 +666,13 @@ func newRemoteWriteCtx(argIdx int, remoteWriteURL *url.URL, maxInmemoryBlocks in
 		if err != nil {
 			logger.Fatalf("cannot initialize stream aggregators from -remoteWrite.streamAggr.config=%q: %s", sasFile, err)
 		}
+		if streamAggrPersistState.GetOptionalArg(argIdx) {
+			statePath := filepath.Join(*tmpDataPath, streamAggrStateDirname, fmt.Sprintf("%d_%016X", argIdx+1, h))
+			if err := sas.LoadState(statePath); err != nil {
+				logger.Errorf("discarding stream aggregation state at %q for -remoteWrite.streamAggr.config=%q: %s", statePath, sasFile, err)
+			}
+			rwctx.streamAggrStatePath = statePath
+		}
 		rwctx.sas.Store(sas)
 		rwctx.streamAggrKeepInput = streamAggrKeepInput.GetOptionalArg(argIdx)
 		rwctx.streamAggrDropInput = streamAggrDropInput.GetOptionalArg(argIdx)
@@ -636,6 +688,9 @@ func (rwctx *remoteWriteCtx) MustStop() {
 	// because sas can write pending series to rwctx.pss if there are any
 	sas := rwctx.sas.Swap(nil)
 	sas.MustStop()
+	if rwctx.streamAggrStatePath != "" {
+		sas.MustSaveState(rwctx.streamAggrStatePath)
+	}
 
 	for _, ps := range rwctx.pss {
 		ps.MustStop()
@@ -730,6 +785,10 @@ func (rwctx *remoteWriteCtx) pushInternal(tss []prompbmarshal.TimeSeries) {
 		rctx.appendExtraLabels(tss, labelsGlobal)
 	}
 
+	if ct := cardinalityTrackerGlobal; ct != nil {
+		ct.RegisterURLSeries(rwctx.sanitizedURL, tss)
+	}
+
 	pss := rwctx.pss
 	idx := atomic.AddUint64(&rwctx.pssNextIdx, 1) % uint64(len(pss))
 	pss[idx].Push(tss)
@@ -0,0 +1,78 @@
+package remotewrite
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/persistentqueue"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promrelabel"
+	"github.com/VictoriaMetrics/metrics"
+)
+
+func TestTryPush(t *testing.T) {
+	rcsOrig := allRelabelConfigs.Load()
+	rwctxsOrig := rwctxsDefault
+	defer func() {
+		allRelabelConfigs.Store(rcsOrig)
+		rwctxsDefault = rwctxsOrig
+	}()
+	allRelabelConfigs.Store(&relabelConfigs{
+		perURL: []*promrelabel.ParsedConfigs{nil},
+	})
+
+	maxPendingBytes := int64(persistentqueue.MaxBlockSize + 8 + 100)
+	fq := persistentqueue.MustOpenFastQueue(filepath.Join(t.TempDir(), "queue"), "try-push-test", 1, maxPendingBytes)
+	defer fq.MustClose()
+	rwctx := &remoteWriteCtx{
+		sanitizedURL: "try-push-test",
+		fq:           fq,
+		pss: []*pendingSeries{
+			newPendingSeries(fq.MustWriteBlock, false, false, 0, 0),
+		},
+		rowsPushedAfterRelabel: metrics.GetOrCreateCounter(`vmagent_remotewrite_try_push_test_rows_pushed_total`),
+		rowsDroppedByRelabel:   metrics.GetOrCreateCounter(`vmagent_remotewrite_try_push_test_rows_dropped_total`),
+	}
+	rwctxsDefault = []*remoteWriteCtx{rwctx}
+
+	wr := &prompbmarshal.WriteRequest{
+		Timeseries: []prompbmarshal.TimeSeries{{
+			Labels: []prompbmarshal.Label{{
+				Name:  "__name__",
+				Value: "foo",
+			}},
+			Samples: []prompbmarshal.Sample{{
+				Value:     1,
+				Timestamp: 1000,
+			}},
+		}},
+	}
+
+	// Fill the queue
+	for !fq.IsFull() {
+		fq.MustWriteBlock([]byte("foobar"))
+	}
+	err := TryPush(nil, wr)
+	if err == nil {
+		t.Fatalf("expecting non-nil error when the queue is full")
+	}
+	if !strings.Contains(err.Error(), "try-push-test") {
+		t.Fatalf("the error must contain the url of the full queue; got %q", err)
+	}
+
+	// Free up the space in the queue
+	if _, ok := fq.MustReadBlock(nil); !ok {
+		t.Fatalf("unexpected ok=false")
+	}
+	pendingBytes := fq.GetPendingBytes()
+	if err := TryPush(nil, wr); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Flush the pushed data to the queue
+	rwctx.pss[0].MustStop()
+	if n := fq.GetPendingBytes(); n <= pendingBytes {
+		t.Fatalf("the pushed data must be written to the queue; got %d pending bytes; want more than %d", n, pendingBytes)
+	}
+}
@@ -1,6 +1,7 @@
 package vmimport
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmagent/common"
@@ -32,11 +33,22 @@ func InsertHandler(at *auth.Token, req *http.Request) error {
 	}
 	isGzipped := req.Header.Get("Content-Encoding") == "gzip"
 	return stream.Parse(req.Body, isGzipped, func(rows []parser.Row) error {
-		return insertRows(at, rows, extraLabels)
+		return insertRows(at, rows, extraLabels, false)
 	})
 }
 
-func insertRows(at *auth.Token, rows []parser.Row, extraLabels []prompbmarshal.Label) error {
+// TryInsertHandlerForReader processes data in JSON line format from r.
+//
+// It returns common.PushError if the data cannot be pushed because of full `-remoteWrite.url` queues. See remotewrite.TryPush.
+//
+// See https://docs.victoriametrics.com/#how-to-import-data-in-json-line-format
+func TryInsertHandlerForReader(r io.Reader, isGzipped bool) error {
+	return stream.Parse(r, isGzipped, func(rows []parser.Row) error {
+		return insertRows(nil, rows, nil, true)
+	})
+}
+
+func insertRows(at *auth.Token, rows []parser.Row, extraLabels []prompbmarshal.Label, tryPush bool) error {
 	ctx := common.GetPushCtx()
 	defer common.PutPushCtx(ctx)
 
@@ -76,7 +88,13 @@ func insertRows(at *auth.Token, rows []parser.Row, extraLabels []prompbmarshal.L
 	ctx.WriteRequest.Timeseries = tssDst
 	ctx.Labels = labels
 	ctx.Samples = samples
-	remotewrite.Push(at, &ctx.WriteRequest)
+	if tryPush {
+		if err := remotewrite.TryPush(at, &ctx.WriteRequest); err != nil {
+			return common.NewPushError(err)
+		}
+	} else {
+		remotewrite.Push(at, &ctx.WriteRequest)
+	}
 	rowsInserted.Add(rowsTotal)
 	if at != nil {
 		rowsTenantInserted.Get(at).Add(rowsTotal)
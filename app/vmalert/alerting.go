@@ -33,8 +33,13 @@ type AlertingRule struct {
 	GroupName     string
 	EvalInterval  time.Duration
 	Debug         bool
+	// Baseline is the name of the group's baseline
+	// exposed to templates via $baseline, $deviation and $zscore
+	Baseline string
 
 	q datasource.Querier
+	// baselines holds values computed by baseline rules of the group
+	baselines *baselineStore
 
 	alertsMu sync.RWMutex
 	// stores list of active alerts
@@ -69,6 +74,8 @@ func newAlertingRule(qb datasource.QuerierBuilder, group *Group, cfg config.Rule
 		GroupName:     group.Name,
 		EvalInterval:  group.Interval,
 		Debug:         cfg.Debug,
+		Baseline:      cfg.Baseline,
+		baselines:     group.baselines,
 		q: qb.BuildWithParams(datasource.QuerierParams{
 			DataSourceType:     group.Type.String(),
 			EvaluationInterval: group.Interval,
@@ -199,6 +206,8 @@ type labelSet struct {
 	// in case of conflicts, extra labels are preferred.
 	// used as labels attached to notifier.Alert and ALERTS series written to remote storage.
 	processed map[string]string
+	// baseline contains values of the rule's baseline for the series
+	baseline baselineValues
 }
 
 // toLabels converts labels from given Metric
@@ -207,6 +216,7 @@ func (ar *AlertingRule) toLabels(m datasource.Metric, qFn templates.QueryFn) (*l
 	ls := &labelSet{
 		origin:    make(map[string]string),
 		processed: make(map[string]string),
+		baseline:  ar.baselines.get(ar.Baseline, m.Labels),
 	}
 	for _, l := range m.Labels {
 		ls.origin[l.Name] = l.Value
@@ -218,9 +228,12 @@ func (ar *AlertingRule) toLabels(m datasource.Metric, qFn templates.QueryFn) (*l
 	}
 
 	extraLabels, err := notifier.ExecTemplate(qFn, ar.Labels, notifier.AlertTplData{
-		Labels: ls.origin,
-		Value:  m.Values[0],
-		Expr:   ar.Expr,
+		Labels:    ls.origin,
+		Value:     m.Values[0],
+		Expr:      ar.Expr,
+		Baseline:  ls.baseline.Baseline,
+		Deviation: ls.baseline.Deviation,
+		ZScore:    ls.baseline.ZScore,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to expand labels: %s", err)
@@ -363,6 +376,7 @@ func (ar *AlertingRule) Exec(ctx context.Context, ts time.Time, limit int) ([]pr
 				ar.logDebugf(ts, a, "INACTIVE => PENDING")
 			}
 			a.Value = m.Values[0]
+			a.Baseline, a.Deviation, a.ZScore = ls.baseline.Baseline, ls.baseline.Deviation, ls.baseline.ZScore
 			// re-exec template since Value or query can be used in annotations
 			a.Annotations, err = a.ExecTemplate(qFn, ls.origin, ar.Annotations)
 			if err != nil {
@@ -429,6 +443,40 @@ func (ar *AlertingRule) Exec(ctx context.Context, ts time.Time, limit int) ([]pr
 	return ar.toTimeSeries(ts.Unix()), nil
 }
 
+// Eval executes AlertingRule expression via the given Querier similarly to Exec.
+// Unlike Exec, it doesn't update the list of active alerts or the state of the Rule.
+// It returns received time series with labels and annotations templates
+// expanded for each of them.
+func (ar *AlertingRule) Eval(ctx context.Context, q datasource.Querier, ts time.Time) APIRuleEval {
+	start := time.Now()
+	res, req, err := q.Query(ctx, ar.Expr, ts)
+	if err != nil {
+		err = fmt.Errorf("failed to execute query %q: %w", ar.Expr, err)
+	}
+	re := newRuleEval(ar.Name, ts, start, res, req, err)
+	if err != nil {
+		return re
+	}
+
+	qFn := func(query string) ([]datasource.Metric, error) {
+		res, _, err := q.Query(ctx, query, ts)
+		return res.Data, err
+	}
+	for _, m := range res.Data {
+		s := newRuleEvalSeries(m)
+		a, err := ar.newAlert(m, nil, ts, qFn)
+		if a != nil {
+			s.Labels = a.Labels
+			s.Annotations = a.Annotations
+		}
+		if err != nil {
+			s.Error = err.Error()
+		}
+		re.Series = append(re.Series, s)
+	}
+	return re
+}
+
 func (ar *AlertingRule) toTimeSeries(timestamp int64) []prompbmarshal.TimeSeries {
 	var tss []prompbmarshal.TimeSeries
 	for _, a := range ar.alerts {
@@ -456,6 +504,8 @@ func (ar *AlertingRule) UpdateWith(r Rule) error {
 	ar.Annotations = nr.Annotations
 	ar.EvalInterval = nr.EvalInterval
 	ar.Debug = nr.Debug
+	ar.Baseline = nr.Baseline
+	ar.baselines = nr.baselines
 	ar.q = nr.q
 	ar.state = nr.state
 	return nil
@@ -498,6 +548,10 @@ func (ar *AlertingRule) newAlert(m datasource.Metric, ls *labelSet, start time.T
 		ActiveAt: start,
 		Expr:     ar.Expr,
 		For:      ar.For,
+
+		Baseline:  ls.baseline.Baseline,
+		Deviation: ls.baseline.Deviation,
+		ZScore:    ls.baseline.ZScore,
 	}
 	a.Annotations, err = a.ExecTemplate(qFn, ls.origin, ar.Annotations)
 	return a, err
@@ -596,6 +650,8 @@ func (ar *AlertingRule) newAlertAPI(a notifier.Alert) *APIAlert {
 	if a.State == notifier.StateFiring && !a.KeepFiringSince.IsZero() {
 		aa.Stabilizing = true
 	}
+	aa.SilencedBy = a.SilencedBy
+	aa.Inhibited = a.Inhibited
 	return aa
 }
 
@@ -705,14 +761,38 @@ func (ar *AlertingRule) Restore(ctx context.Context, q datasource.Querier, ts ti
 	return nil
 }
 
+// updateMuted updates silenced and inhibited status of the current alerts
+// of AlertingRule and registers its firing alerts as inhibition sources in the given inhibitor.
+func (ar *AlertingRule) updateMuted(ts time.Time, in *inhibitor) {
+	ar.alertsMu.Lock()
+	defer ar.alertsMu.Unlock()
+
+	var firing []map[string]string
+	for _, a := range ar.alerts {
+		if a.State == notifier.StateFiring {
+			firing = append(firing, a.Labels)
+		}
+	}
+	in.setFiring(ar.ID(), firing)
+
+	for _, a := range ar.alerts {
+		a.SilencedBy = silences.silencedBy(a.Labels, ts)
+		a.Inhibited = in.isInhibited(a.Labels)
+	}
+}
+
 // alertsToSend walks through the current alerts of AlertingRule
 // and returns only those which should be sent to notifier.
+// Muted alerts are skipped.
 // Isn't concurrent safe.
 func (ar *AlertingRule) alertsToSend(ts time.Time, resolveDuration, resendDelay time.Duration) []notifier.Alert {
 	needsSending := func(a *notifier.Alert) bool {
 		if a.State == notifier.StatePending {
 			return false
 		}
+		if a.IsMuted() {
+			return false
+		}
 		if a.ResolvedAt.After(a.LastSent) {
 			return true
 		}
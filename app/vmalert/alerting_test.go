@@ -972,6 +972,16 @@ func TestAlertsToSend(t *testing.T) {
 		[]*notifier.Alert{{LastSent: ts, End: ts}},
 		time.Minute, time.Minute,
 	)
+	f( // silenced alert mustn't be sent
+		[]*notifier.Alert{{State: notifier.StateFiring, SilencedBy: "foo"}},
+		nil,
+		time.Minute, 0,
+	)
+	f( // inhibited alert mustn't be sent. Names are added for deterministic sorting
+		[]*notifier.Alert{{Name: "a", State: notifier.StateFiring, Inhibited: true}, {Name: "b", State: notifier.StateFiring}},
+		[]*notifier.Alert{{Name: "b", LastSent: ts, End: ts.Add(time.Minute)}},
+		time.Minute, 0,
+	)
 }
 
 func newTestRuleWithLabels(name string, labels ...string) *AlertingRule {
@@ -999,3 +1009,42 @@ func newTestAlertingRuleWithKeepFiring(name string, waitFor, keepFiringFor time.
 	rule.KeepFiringFor = keepFiringFor
 	return rule
 }
+
+func TestAlertingRule_Eval(t *testing.T) {
+	ar := &AlertingRule{
+		Name: "common",
+		Labels: map[string]string{
+			"region": "east",
+		},
+		Annotations: map[string]string{
+			"summary": `{{ $labels.alertname }}: Too high connection number for "{{ $labels.instance }}"`,
+		},
+		alerts: make(map[uint64]*notifier.Alert),
+		state:  newRuleState(10),
+	}
+	fq := &fakeQuerier{}
+	fq.add(metricWithValueAndLabels(t, 1, "instance", "foo"))
+
+	re := ar.Eval(context.TODO(), fq, time.Now())
+	if re.Error != "" {
+		t.Fatalf("unexpected error: %s", re.Error)
+	}
+	if len(re.Series) != 1 {
+		t.Fatalf("expected to get 1 series; got %d instead", len(re.Series))
+	}
+	s := re.Series[0]
+	expLabels := map[string]string{alertNameLabel: "common", "region": "east", "instance": "foo"}
+	if !reflect.DeepEqual(s.Labels, expLabels) {
+		t.Fatalf("expected labels %v; got %v", expLabels, s.Labels)
+	}
+	expAnnotations := map[string]string{"summary": `common: Too high connection number for "foo"`}
+	if !reflect.DeepEqual(s.Annotations, expAnnotations) {
+		t.Fatalf("expected annotations %v; got %v", expAnnotations, s.Annotations)
+	}
+	if len(ar.alerts) != 0 {
+		t.Fatalf("expected no alerts to be created by Eval; got %d", len(ar.alerts))
+	}
+	if len(ar.state.getAll()) != 0 {
+		t.Fatalf("expected rule state to remain unchanged after Eval")
+	}
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/config"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/datasource"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/utils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+)
+
+const (
+	defaultBaselineSeason  = 7 * 24 * time.Hour
+	defaultBaselineSeasons = 4
+)
+
+// BaselineRule is a Rule that computes seasonal baseline
+// for every series returned by Expr. The baseline is the mean of the series
+// values at the same time during the previous Seasons.
+//
+// For every series it returns the following time series:
+//   - <Name>:baseline - the mean value during the previous seasons;
+//   - <Name>:deviation - the difference between the current value and the baseline;
+//   - <Name>:zscore - the deviation divided by the standard deviation during the previous seasons.
+type BaselineRule struct {
+	Type    config.Type
+	RuleID  uint64
+	Name    string
+	Expr    string
+	Season  time.Duration
+	Seasons int
+	Labels  map[string]string
+	GroupID uint64
+
+	// q is used for querying the current values
+	q datasource.Querier
+	// qRange is used for querying values during the previous seasons.
+	// Its evaluation interval is equal to Season, so every series
+	// contains one point per season.
+	qRange datasource.Querier
+
+	// store holds the last computed values for alerting rules templates
+	store *baselineStore
+
+	// state stores recent state changes
+	// during evaluations
+	state *ruleState
+
+	metrics *recordingRuleMetrics
+}
+
+// baselineValues contains values computed by BaselineRule for a single series
+type baselineValues struct {
+	Baseline  float64
+	Deviation float64
+	ZScore    float64
+}
+
+var emptyBaselineValues = baselineValues{
+	Baseline:  math.NaN(),
+	Deviation: math.NaN(),
+	ZScore:    math.NaN(),
+}
+
+// String implements Stringer interface
+func (br *BaselineRule) String() string {
+	return br.Name
+}
+
+// ID returns unique Rule ID
+// within the parent Group.
+func (br *BaselineRule) ID() uint64 {
+	return br.RuleID
+}
+
+func newBaselineRule(qb datasource.QuerierBuilder, group *Group, cfg config.Baseline) *BaselineRule {
+	br := &BaselineRule{
+		Type:    group.Type,
+		RuleID:  cfg.ID,
+		Name:    cfg.Record,
+		Expr:    cfg.Expr,
+		Season:  cfg.Season.Duration(),
+		Seasons: cfg.Seasons,
+		Labels:  cfg.Labels,
+		GroupID: group.ID(),
+		store:   group.baselines,
+		metrics: &recordingRuleMetrics{},
+		q: qb.BuildWithParams(datasource.QuerierParams{
+			DataSourceType:     group.Type.String(),
+			EvaluationInterval: group.Interval,
+			QueryParams:        group.Params,
+			Headers:            group.Headers,
+		}),
+		state: newRuleState(*ruleUpdateEntriesLimit),
+	}
+	if br.Season == 0 {
+		br.Season = defaultBaselineSeason
+	}
+	if br.Seasons == 0 {
+		br.Seasons = defaultBaselineSeasons
+	}
+	// prevent the datasource from aligning the time range
+	// to the step, so points match exactly the previous seasons
+	params := url.Values{}
+	for k, vs := range group.Params {
+		params[k] = vs
+	}
+	params.Set("nocache", "1")
+	br.qRange = qb.BuildWithParams(datasource.QuerierParams{
+		DataSourceType:     group.Type.String(),
+		EvaluationInterval: br.Season,
+		QueryParams:        params,
+		Headers:            group.Headers,
+	})
+
+	labels := fmt.Sprintf(`baseline=%q, group=%q, id="%d"`, br.Name, group.Name, br.ID())
+	br.metrics.errors = utils.GetOrCreateGauge(fmt.Sprintf(`vmalert_baseline_rules_error{%s}`, labels),
+		func() float64 {
+			e := br.state.getLast()
+			if e.err == nil {
+				return 0
+			}
+			return 1
+		})
+	br.metrics.samples = utils.GetOrCreateGauge(fmt.Sprintf(`vmalert_baseline_rules_last_evaluation_samples{%s}`, labels),
+		func() float64 {
+			e := br.state.getLast()
+			return float64(e.samples)
+		})
+	return br
+}
+
+// Close unregisters rule metrics
+func (br *BaselineRule) Close() {
+	br.metrics.errors.Unregister()
+	br.metrics.samples.Unregister()
+}
+
+// errBaselineReplay is returned by BaselineRule.ExecRange
+var errBaselineReplay = errors.New("baselines aren't supported in replay mode")
+
+// ExecRange isn't supported for BaselineRule, since computing
+// the baseline for every point on the time range would require
+// querying all the previous seasons for every point.
+func (br *BaselineRule) ExecRange(_ context.Context, _, _ time.Time) ([]prompbmarshal.TimeSeries, error) {
+	return nil, errBaselineReplay
+}
+
+// Exec computes the baseline for every series returned by Expr at ts.
+func (br *BaselineRule) Exec(ctx context.Context, ts time.Time, limit int) ([]prompbmarshal.TimeSeries, error) {
+	start := time.Now()
+	res, req, err := br.q.Query(ctx, br.Expr, ts)
+	curState := ruleStateEntry{
+		time:          start,
+		at:            ts,
+		samples:       len(res.Data),
+		seriesFetched: res.SeriesFetched,
+		curl:          requestToCurl(req),
+	}
+
+	defer func() {
+		curState.duration = time.Since(start)
+		br.state.add(curState)
+	}()
+
+	if err != nil {
+		curState.err = fmt.Errorf("failed to execute query %q: %w", br.Expr, err)
+		return nil, curState.err
+	}
+	if limit > 0 && len(res.Data) > limit {
+		curState.err = fmt.Errorf("exec exceeded limit of %d with %d series", limit, len(res.Data))
+		return nil, curState.err
+	}
+
+	tss, values, err := br.compute(ctx, res.Data, ts)
+	if err != nil {
+		curState.err = err
+		return nil, err
+	}
+	br.store.set(br.Name, br.Labels, values)
+	return tss, nil
+}
+
+// Eval computes the baseline similarly to Exec.
+// Unlike Exec, it doesn't update the state of the Rule.
+func (br *BaselineRule) Eval(ctx context.Context, q datasource.Querier, ts time.Time) APIRuleEval {
+	start := time.Now()
+	res, req, err := q.Query(ctx, br.Expr, ts)
+	if err != nil {
+		err = fmt.Errorf("failed to execute query %q: %w", br.Expr, err)
+	}
+	re := newRuleEval(br.Name, ts, start, res, req, err)
+	if err != nil {
+		return re
+	}
+	tss, _, err := br.compute(ctx, res.Data, ts)
+	if err != nil {
+		re.Error = err.Error()
+		return re
+	}
+	for _, series := range tss {
+		s := APIRuleEvalSeries{
+			Metric: make(map[string]string, len(series.Labels)),
+			Labels: make(map[string]string, len(series.Labels)),
+			Value:  strconv.FormatFloat(series.Samples[0].Value, 'f', -1, 64),
+		}
+		for _, l := range series.Labels {
+			s.Labels[l.Name] = l.Value
+			if l.Name != "__name__" {
+				s.Metric[l.Name] = l.Value
+			}
+		}
+		re.Series = append(re.Series, s)
+	}
+	return re
+}
+
+// compute queries values during the previous seasons and returns
+// the time series and baseline values for the given current series.
+func (br *BaselineRule) compute(ctx context.Context, cur []datasource.Metric, ts time.Time) ([]prompbmarshal.TimeSeries, map[string]baselineValues, error) {
+	from := ts.Add(-time.Duration(br.Seasons) * br.Season)
+	to := ts.Add(-br.Season)
+	past, err := br.qRange.QueryRange(ctx, br.Expr, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute range query %q for the previous %d seasons: %w", br.Expr, br.Seasons, err)
+	}
+	history := make(map[string][]float64, len(past.Data))
+	for _, m := range past.Data {
+		key := baselineKey(m.Labels, br.Labels)
+		for _, v := range m.Values {
+			if !math.IsNaN(v) {
+				history[key] = append(history[key], v)
+			}
+		}
+	}
+
+	values := make(map[string]baselineValues, len(cur))
+	var tss []prompbmarshal.TimeSeries
+	for _, m := range cur {
+		if len(m.Values) == 0 {
+			continue
+		}
+		key := baselineKey(m.Labels, br.Labels)
+		if _, ok := values[key]; ok {
+			return nil, nil, fmt.Errorf("original metric %v; resulting labels %q: %w", m.Labels, key, errDuplicate)
+		}
+		bv, ok := newBaselineValues(m.Values[len(m.Values)-1], history[key])
+		if !ok {
+			// there is no data for the previous seasons
+			continue
+		}
+		values[key] = bv
+		tss = append(tss, br.toTimeSeries(m, "baseline", bv.Baseline, ts))
+		tss = append(tss, br.toTimeSeries(m, "deviation", bv.Deviation, ts))
+		if !math.IsNaN(bv.ZScore) {
+			tss = append(tss, br.toTimeSeries(m, "zscore", bv.ZScore, ts))
+		}
+	}
+	return tss, values, nil
+}
+
+// newBaselineValues returns baseline values for the current value v
+// and the values during the previous seasons.
+// It returns false if history is empty.
+func newBaselineValues(v float64, history []float64) (baselineValues, bool) {
+	if len(history) == 0 {
+		return baselineValues{}, false
+	}
+	var sum float64
+	for _, h := range history {
+		sum += h
+	}
+	mean := sum / float64(len(history))
+	var sq float64
+	for _, h := range history {
+		sq += (h - mean) * (h - mean)
+	}
+	stddev := math.Sqrt(sq / float64(len(history)))
+	bv := baselineValues{
+		Baseline:  mean,
+		Deviation: v - mean,
+		ZScore:    math.NaN(),
+	}
+	if stddev > 0 {
+		bv.ZScore = bv.Deviation / stddev
+	}
+	return bv, true
+}
+
+func (br *BaselineRule) toTimeSeries(m datasource.Metric, suffix string, v float64, ts time.Time) prompbmarshal.TimeSeries {
+	labels := make(map[string]string)
+	for _, l := range m.Labels {
+		labels[l.Name] = l.Value
+	}
+	labels["__name__"] = br.Name + ":" + suffix
+	// override existing labels with configured ones
+	for k, v := range br.Labels {
+		labels[k] = v
+	}
+	return newTimeSeries([]float64{v}, []int64{ts.Unix()}, labels)
+}
+
+// UpdateWith copies all significant fields.
+func (br *BaselineRule) UpdateWith(r Rule) error {
+	nr, ok := r.(*BaselineRule)
+	if !ok {
+		return fmt.Errorf("BUG: attempt to update baseline rule with wrong type %#v", r)
+	}
+	br.Expr = nr.Expr
+	br.Season = nr.Season
+	br.Seasons = nr.Seasons
+	br.Labels = nr.Labels
+	br.q = nr.q
+	br.qRange = nr.qRange
+	br.store = nr.store
+	return nil
+}
+
+// ToAPI returns Rule's representation in form
+// of APIRule
+func (br *BaselineRule) ToAPI() APIRule {
+	lastState := br.state.getLast()
+	r := APIRule{
+		Type:              "baseline",
+		DatasourceType:    br.Type.String(),
+		Name:              br.Name,
+		Query:             br.Expr,
+		Labels:            br.Labels,
+		LastEvaluation:    lastState.time,
+		EvaluationTime:    lastState.duration.Seconds(),
+		Health:            "ok",
+		LastSamples:       lastState.samples,
+		LastSeriesFetched: lastState.seriesFetched,
+		MaxUpdates:        br.state.size(),
+		Updates:           br.state.getAll(),
+
+		// encode as strings to avoid rounding
+		ID:      fmt.Sprintf("%d", br.ID()),
+		GroupID: fmt.Sprintf("%d", br.GroupID),
+	}
+	if lastState.err != nil {
+		r.LastError = lastState.err.Error()
+		r.Health = "err"
+	}
+	return r
+}
+
+// baselineStore holds the last values computed by baseline rules of the Group.
+// It is used for exposing these values to alerting rules templates.
+type baselineStore struct {
+	mu sync.RWMutex
+	// baselines contains values per each baseline name
+	baselines map[string]*baselineEntry
+}
+
+type baselineEntry struct {
+	// labels contains labels set by the baseline rule
+	// to the produced series
+	labels map[string]string
+	// values contains computed values per series key
+	values map[string]baselineValues
+}
+
+func newBaselineStore() *baselineStore {
+	return &baselineStore{baselines: make(map[string]*baselineEntry)}
+}
+
+func (bs *baselineStore) set(name string, labels map[string]string, values map[string]baselineValues) {
+	if bs == nil {
+		return
+	}
+	bs.mu.Lock()
+	bs.baselines[name] = &baselineEntry{labels: labels, values: values}
+	bs.mu.Unlock()
+}
+
+// get returns values of the baseline with the given name for the series with the given labels.
+// It returns emptyBaselineValues if there are no such values.
+func (bs *baselineStore) get(name string, labels []datasource.Label) baselineValues {
+	if bs == nil || name == "" {
+		return emptyBaselineValues
+	}
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+	be, ok := bs.baselines[name]
+	if !ok {
+		return emptyBaselineValues
+	}
+	bv, ok := be.values[baselineKey(labels, be.labels)]
+	if !ok {
+		return emptyBaselineValues
+	}
+	return bv
+}
+
+// baselineKey returns the key for matching series with the given labels
+// between baseline and alerting rules. Metric name is ignored and labels
+// set by the baseline rule override the original labels, so both the original
+// series and series produced by the baseline rule have the same key.
+func baselineKey(labels []datasource.Label, overrides map[string]string) string {
+	ls := make([]datasource.Label, 0, len(labels)+len(overrides))
+	for _, l := range labels {
+		if l.Name == "__name__" {
+			continue
+		}
+		if _, ok := overrides[l.Name]; ok {
+			continue
+		}
+		ls = append(ls, l)
+	}
+	for k, v := range overrides {
+		ls = append(ls, datasource.Label{Name: k, Value: v})
+	}
+	sort.Slice(ls, func(i, j int) bool {
+		return ls[i].Name < ls[j].Name
+	})
+	var b strings.Builder
+	for i, l := range ls {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.Name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(l.Value))
+	}
+	return b.String()
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/config"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/datasource"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/notifier"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+// baselineQuerier returns cur on instant queries
+// and past on range queries
+type baselineQuerier struct {
+	cur  []datasource.Metric
+	past []datasource.Metric
+}
+
+func (bq *baselineQuerier) BuildWithParams(_ datasource.QuerierParams) datasource.Querier {
+	return bq
+}
+
+func (bq *baselineQuerier) Query(_ context.Context, _ string, _ time.Time) (datasource.Result, *http.Request, error) {
+	return datasource.Result{Data: bq.cur}, nil, nil
+}
+
+func (bq *baselineQuerier) QueryRange(_ context.Context, _ string, _, _ time.Time) (datasource.Result, error) {
+	return datasource.Result{Data: bq.past}, nil
+}
+
+func TestNewBaselineValues(t *testing.T) {
+	f := func(v float64, history []float64, exp baselineValues) {
+		t.Helper()
+		bv, ok := newBaselineValues(v, history)
+		if !ok {
+			t.Fatalf("expected to get baseline values for history %v", history)
+		}
+		eq := func(a, b float64) bool {
+			return a == b || (math.IsNaN(a) && math.IsNaN(b))
+		}
+		if !eq(bv.Baseline, exp.Baseline) || !eq(bv.Deviation, exp.Deviation) || !eq(bv.ZScore, exp.ZScore) {
+			t.Fatalf("unexpected baseline values for %v and %v; got %+v; want %+v", v, history, bv, exp)
+		}
+	}
+	f(10, []float64{2, 4, 4, 4, 5, 5, 7, 9}, baselineValues{Baseline: 5, Deviation: 5, ZScore: 2.5})
+	f(0, []float64{2, 4, 4, 4, 5, 5, 7, 9}, baselineValues{Baseline: 5, Deviation: -5, ZScore: -2.5})
+	// zero standard deviation
+	f(3, []float64{1, 1}, baselineValues{Baseline: 1, Deviation: 2, ZScore: math.NaN()})
+
+	if _, ok := newBaselineValues(1, nil); ok {
+		t.Fatalf("expected no baseline values for empty history")
+	}
+}
+
+func TestBaselineRule_Exec(t *testing.T) {
+	metric := func(values []float64, labels ...string) datasource.Metric {
+		m := datasource.Metric{Values: values}
+		for i := 0; i < len(labels); i += 2 {
+			m.AddLabel(labels[i], labels[i+1])
+		}
+		return m
+	}
+	bq := &baselineQuerier{
+		cur: []datasource.Metric{
+			metric([]float64{10}, "__name__", "http_requests", "job", "foo"),
+			metric([]float64{5}, "__name__", "http_requests", "job", "bar"),
+			// no history for this series
+			metric([]float64{1}, "__name__", "http_requests", "job", "baz"),
+		},
+		past: []datasource.Metric{
+			metric([]float64{2, 4, 4, 4, 5, 5, 7, 9}, "__name__", "http_requests", "job", "foo"),
+			metric([]float64{5, 5, math.NaN()}, "__name__", "http_requests", "job", "bar"),
+		},
+	}
+	g := &Group{Name: "test", baselines: newBaselineStore()}
+	br := newBaselineRule(bq, g, config.Baseline{
+		Record:  "job:requests",
+		Expr:    "http_requests",
+		Season:  promutils.NewDuration(24 * time.Hour),
+		Seasons: 8,
+		Labels:  map[string]string{"source": "baseline"},
+	})
+	defer br.Close()
+
+	ts := time.Now()
+	tss, err := br.Exec(context.Background(), ts, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exp := map[string]float64{
+		`job:requests:baseline{job="foo"}`:  5,
+		`job:requests:deviation{job="foo"}`: 5,
+		`job:requests:zscore{job="foo"}`:    2.5,
+		`job:requests:baseline{job="bar"}`:  5,
+		`job:requests:deviation{job="bar"}`: 0,
+	}
+	if len(tss) != len(exp) {
+		t.Fatalf("expected to get %d series; got %d: %v", len(exp), len(tss), tss)
+	}
+	for _, s := range tss {
+		var name, job string
+		for _, l := range s.Labels {
+			switch l.Name {
+			case "__name__":
+				name = l.Value
+			case "job":
+				job = l.Value
+			case "source":
+				if l.Value != "baseline" {
+					t.Fatalf("unexpected value of label %q: %q", l.Name, l.Value)
+				}
+			}
+		}
+		key := name + `{job="` + job + `"}`
+		v, ok := exp[key]
+		if !ok {
+			t.Fatalf("unexpected series %s", key)
+		}
+		if len(s.Samples) != 1 || s.Samples[0].Value != v || s.Samples[0].Timestamp != ts.Unix()*1e3 {
+			t.Fatalf("unexpected samples for %s: %v; want %v", key, s.Samples, v)
+		}
+	}
+
+	// series returned by alerting rules must match stored values
+	// regardless of the metric name and baseline labels
+	bv := g.baselines.get("job:requests", []datasource.Label{{Name: "__name__", Value: "foo"}, {Name: "job", Value: "foo"}})
+	if bv.Baseline != 5 || bv.Deviation != 5 || bv.ZScore != 2.5 {
+		t.Fatalf("unexpected stored values %+v", bv)
+	}
+	bv = g.baselines.get("job:requests", []datasource.Label{{Name: "job", Value: "baz"}})
+	if !math.IsNaN(bv.Baseline) {
+		t.Fatalf("expected no stored values for series without history; got %+v", bv)
+	}
+	bv = g.baselines.get("unknown", []datasource.Label{{Name: "job", Value: "foo"}})
+	if !math.IsNaN(bv.Baseline) {
+		t.Fatalf("expected no stored values for unknown baseline; got %+v", bv)
+	}
+}
+
+func TestGroupBaselineTemplates(t *testing.T) {
+	bq := &baselineQuerier{
+		cur:  []datasource.Metric{metricWithValueAndLabels(t, 10, "__name__", "errors", "job", "foo")},
+		past: []datasource.Metric{{Labels: []datasource.Label{{Name: "job", Value: "foo"}}, Values: []float64{1, 3}}},
+	}
+	cfg := config.Group{
+		Name: "baselines",
+		Baselines: []config.Baseline{
+			{ID: 1, Record: "job:errors", Expr: "errors"},
+		},
+		Rules: []config.Rule{
+			{
+				ID:       2,
+				Alert:    "ErrorsSpike",
+				Expr:     "errors",
+				Baseline: "job:errors",
+				Annotations: map[string]string{
+					"summary": "{{ $value }} vs {{ $baseline }}: deviation {{ $deviation }}, zscore {{ $zscore }}",
+				},
+			},
+		},
+	}
+	g := newGroup(cfg, bq, time.Minute, nil)
+	if len(g.Rules) != 2 {
+		t.Fatalf("expected to get 2 rules; got %d", len(g.Rules))
+	}
+	if _, ok := g.Rules[0].(*BaselineRule); !ok {
+		t.Fatalf("expected baseline rule to be evaluated first; got %T", g.Rules[0])
+	}
+	defer func() {
+		for _, r := range g.Rules {
+			r.Close()
+		}
+	}()
+
+	fn := &fakeNotifier{}
+	e := &executor{
+		notifiers: func() []notifier.Notifier { return []notifier.Notifier{fn} },
+	}
+	for err := range e.execConcurrently(context.Background(), g.Rules, time.Now(), g.Concurrency, 0, 0) {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	alerts := g.Rules[1].(*AlertingRule).AlertsToAPI()
+	if len(alerts) != 1 {
+		t.Fatalf("expected to get 1 alert; got %d", len(alerts))
+	}
+	exp := "10 vs 2: deviation 8, zscore 8"
+	if got := alerts[0].Annotations["summary"]; !strings.Contains(got, exp) {
+		t.Fatalf("expected annotation %q; got %q", exp, got)
+	}
+}
+
+func TestGroupBaselineConditions(t *testing.T) {
+	bq := &baselineQuerier{
+		cur:  []datasource.Metric{metricWithValueAndLabels(t, 10, "__name__", "errors", "job", "foo", "env", "dev")},
+		past: []datasource.Metric{{Labels: []datasource.Label{{Name: "job", Value: "foo"}, {Name: "env", Value: "dev"}}, Values: []float64{1, 3}}},
+	}
+	cfg := config.Group{
+		Name:        "baselines",
+		Concurrency: 2,
+		// group labels must be applied to the baseline series
+		// without breaking the matching of alerts to the original series
+		Labels: map[string]string{"env": "prod"},
+		Baselines: []config.Baseline{
+			{ID: 1, Record: "job:errors", Expr: "errors"},
+		},
+		Rules: []config.Rule{
+			{
+				ID:       2,
+				Alert:    "ErrorsSpike",
+				Expr:     "job:errors:zscore > 3 or job:errors:zscore < -3",
+				Baseline: "job:errors",
+				Annotations: map[string]string{
+					"summary": "{{ $value }}: baseline {{ $baseline }}",
+				},
+			},
+		},
+	}
+	g := newGroup(cfg, bq, time.Minute, nil)
+	defer func() {
+		for _, r := range g.Rules {
+			r.Close()
+		}
+	}()
+	if g.chain == nil {
+		t.Fatalf("expected group with baselines to have the chain cache")
+	}
+
+	fn := &fakeNotifier{}
+	e := &executor{
+		notifiers: func() []notifier.Notifier { return []notifier.Notifier{fn} },
+		chain:     g.chain,
+	}
+	baselines, rules := splitBaselineRules(g.Rules)
+	if len(baselines) != 1 || len(rules) != 1 {
+		t.Fatalf("expected to get 1 baseline and 1 rule; got %d and %d", len(baselines), len(rules))
+	}
+	for _, rs := range [][]Rule{baselines, rules} {
+		for err := range e.execConcurrently(context.Background(), rs, time.Now(), g.Concurrency, 0, 0) {
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		}
+	}
+	alerts := rules[0].(*AlertingRule).AlertsToAPI()
+	if len(alerts) != 1 {
+		t.Fatalf("expected to get 1 alert; got %d", len(alerts))
+	}
+	// the alert must be evaluated over the zscore series
+	// produced during the current evaluation round
+	exp := "8: baseline 2"
+	if got := alerts[0].Annotations["summary"]; got != exp {
+		t.Fatalf("expected annotation %q; got %q", exp, got)
+	}
+	if got := alerts[0].Labels["env"]; got != "prod" {
+		t.Fatalf("expected alert to have group label env=prod; got %q", got)
+	}
+
+	// the original series must match the baseline as well
+	bv := g.baselines.get("job:errors", []datasource.Label{{Name: "job", Value: "foo"}, {Name: "env", Value: "dev"}})
+	if bv.Baseline != 2 {
+		t.Fatalf("unexpected baseline for the original series: %+v", bv)
+	}
+}
+
+func TestBaselineRule_ExecRange(t *testing.T) {
+	g := &Group{Name: "test", baselines: newBaselineStore()}
+	br := newBaselineRule(&baselineQuerier{}, g, config.Baseline{Record: "job:requests", Expr: "http_requests"})
+	defer br.Close()
+	if _, err := br.ExecRange(context.Background(), time.Now().Add(-time.Hour), time.Now()); !errors.Is(err, errBaselineReplay) {
+		t.Fatalf("expected to get %q error; got %v", errBaselineReplay, err)
+	}
+	if got := br.ToAPI().Type; got != "baseline" {
+		t.Fatalf("expected type %q; got %q", "baseline", got)
+	}
+}
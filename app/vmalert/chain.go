@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/metricsql"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/config"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/datasource"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+)
+
+// chainCache holds series produced by recording and baseline rules of the Group
+// during the current evaluation round. It is used by chainQuerier
+// for serving queries to these series without sending requests
+// to the datasource, so subsequent rules of the Group don't depend
+// on the ingestion delay of the remote storage.
+type chainCache struct {
+	// recordings defines whether series produced by recording rules are cached.
+	// Series produced by baseline rules are always cached.
+	recordings bool
+
+	mu sync.RWMutex
+	// series contains series per each recorded metric name
+	series map[string][]datasource.Metric
+}
+
+func newChainCache(recordings bool) *chainCache {
+	return &chainCache{
+		recordings: recordings,
+		series:     make(map[string][]datasource.Metric),
+	}
+}
+
+// reset drops all the series from cc.
+// It must be called at the beginning of every evaluation round.
+func (cc *chainCache) reset() {
+	if cc == nil {
+		return
+	}
+	cc.mu.Lock()
+	cc.series = make(map[string][]datasource.Metric)
+	cc.mu.Unlock()
+}
+
+// addRecording stores the given series produced by recording rule with the given name
+// if cc caches results of recording rules.
+func (cc *chainCache) addRecording(name string, tss []prompbmarshal.TimeSeries) {
+	if cc == nil || !cc.recordings {
+		return
+	}
+	cc.add(name, tss)
+}
+
+// add stores the given series with the given name.
+// Multiple rules with the same name extend the list of series.
+func (cc *chainCache) add(name string, tss []prompbmarshal.TimeSeries) {
+	if cc == nil {
+		return
+	}
+	ms := make([]datasource.Metric, 0, len(tss))
+	for _, ts := range tss {
+		var m datasource.Metric
+		for _, l := range ts.Labels {
+			m.AddLabel(l.Name, l.Value)
+		}
+		for _, s := range ts.Samples {
+			m.Values = append(m.Values, s.Value)
+			m.Timestamps = append(m.Timestamps, s.Timestamp/1e3)
+		}
+		ms = append(ms, m)
+	}
+	cc.mu.Lock()
+	cc.series[name] = append(cc.series[name], ms...)
+	cc.mu.Unlock()
+}
+
+// query evaluates the given expr over the cached series.
+// Only plain series selectors, optionally compared with a scalar,
+// and their unions via `or` are supported, e.g. `job:up:sum{job="foo"}`,
+// `job:up:sum == 0` or `job:up:zscore > 3 or job:up:zscore < -3`.
+// It returns false if expr isn't supported or refers to a metric name,
+// which wasn't recorded during the current evaluation round.
+func (cc *chainCache) query(expr string) ([]datasource.Metric, bool) {
+	if cc == nil {
+		return nil, false
+	}
+	e, err := metricsql.Parse(expr)
+	if err != nil {
+		return nil, false
+	}
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+	return cc.eval(e)
+}
+
+// eval evaluates e over the cached series.
+// cc.mu must be locked by the caller.
+func (cc *chainCache) eval(e metricsql.Expr) ([]datasource.Metric, bool) {
+	var cmp func(v float64) bool
+	if be, ok := e.(*metricsql.BinaryOpExpr); ok {
+		if strings.ToLower(be.Op) == "or" {
+			return cc.evalOr(be)
+		}
+		me, fn, ok := parseChainComparison(be)
+		if !ok {
+			return nil, false
+		}
+		e, cmp = me, fn
+	}
+	me, ok := e.(*metricsql.MetricExpr)
+	if !ok || len(me.LabelFilterss) != 1 {
+		return nil, false
+	}
+	var name string
+	var lfs []metricsql.LabelFilter
+	for _, lf := range me.LabelFilterss[0] {
+		if lf.Label == "__name__" && !lf.IsRegexp && !lf.IsNegative {
+			name = lf.Value
+			continue
+		}
+		lfs = append(lfs, lf)
+	}
+	if name == "" {
+		return nil, false
+	}
+	ms, err := config.NewMatchers(lfs)
+	if err != nil {
+		return nil, false
+	}
+
+	series, ok := cc.series[name]
+	if !ok {
+		return nil, false
+	}
+	var result []datasource.Metric
+	for _, s := range series {
+		labels := make(map[string]string, len(s.Labels))
+		for _, l := range s.Labels {
+			labels[l.Name] = l.Value
+		}
+		if !ms.Match(labels) {
+			continue
+		}
+		if cmp != nil && (len(s.Values) == 0 || !cmp(s.Values[len(s.Values)-1])) {
+			continue
+		}
+		m := datasource.Metric{
+			Labels:     append([]datasource.Label{}, s.Labels...),
+			Values:     append([]float64{}, s.Values...),
+			Timestamps: append([]int64{}, s.Timestamps...),
+		}
+		result = append(result, m)
+	}
+	return result, true
+}
+
+// evalOr evaluates `left or right` expression without modifiers.
+// Similarly to Prometheus, it returns all the series from the left side
+// and the series from the right side, which have no matching label set
+// on the left side. Metric names are ignored during matching.
+func (cc *chainCache) evalOr(be *metricsql.BinaryOpExpr) ([]datasource.Metric, bool) {
+	if be.Bool || be.GroupModifier.Op != "" ||
+		be.JoinModifier.Op != "" || be.KeepMetricNames {
+		return nil, false
+	}
+	left, ok := cc.eval(be.Left)
+	if !ok {
+		return nil, false
+	}
+	right, ok := cc.eval(be.Right)
+	if !ok {
+		return nil, false
+	}
+	seen := make(map[string]struct{}, len(left))
+	for _, m := range left {
+		seen[baselineKey(m.Labels, nil)] = struct{}{}
+	}
+	result := left
+	for _, m := range right {
+		if _, ok := seen[baselineKey(m.Labels, nil)]; ok {
+			continue
+		}
+		result = append(result, m)
+	}
+	return result, true
+}
+
+// parseChainComparison returns the series selector and the comparison func
+// for expressions like `selector op scalar` or `scalar op selector`,
+// where op is one of the comparison operators without modifiers.
+func parseChainComparison(be *metricsql.BinaryOpExpr) (*metricsql.MetricExpr, func(v float64) bool, bool) {
+	if be.Bool || be.GroupModifier.Op != "" ||
+		be.JoinModifier.Op != "" || be.KeepMetricNames {
+		return nil, nil, false
+	}
+	op := be.Op
+	me, ok := be.Left.(*metricsql.MetricExpr)
+	ne, okN := be.Right.(*metricsql.NumberExpr)
+	if !ok || !okN {
+		// try the reversed form `scalar op selector`
+		me, ok = be.Right.(*metricsql.MetricExpr)
+		ne, okN = be.Left.(*metricsql.NumberExpr)
+		if !ok || !okN {
+			return nil, nil, false
+		}
+		switch op {
+		case ">":
+			op = "<"
+		case "<":
+			op = ">"
+		case ">=":
+			op = "<="
+		case "<=":
+			op = ">="
+		}
+	}
+	n := ne.N
+	var fn func(v float64) bool
+	switch op {
+	case "==":
+		fn = func(v float64) bool { return v == n }
+	case "!=":
+		fn = func(v float64) bool { return v != n }
+	case ">":
+		fn = func(v float64) bool { return v > n }
+	case "<":
+		fn = func(v float64) bool { return v < n }
+	case ">=":
+		fn = func(v float64) bool { return v >= n }
+	case "<=":
+		fn = func(v float64) bool { return v <= n }
+	default:
+		return nil, nil, false
+	}
+	return me, func(v float64) bool { return !math.IsNaN(v) && fn(v) }, true
+}
+
+// chainQuerierBuilder builds queriers, which serve queries
+// from chainCache if possible.
+type chainQuerierBuilder struct {
+	qb    datasource.QuerierBuilder
+	cache *chainCache
+}
+
+// BuildWithParams implements datasource.QuerierBuilder interface.
+func (cqb *chainQuerierBuilder) BuildWithParams(params datasource.QuerierParams) datasource.Querier {
+	return &chainQuerier{
+		Querier: cqb.qb.BuildWithParams(params),
+		cache:   cqb.cache,
+	}
+}
+
+// chainQuerier serves instant queries from chainCache
+// and falls back to the wrapped Querier otherwise.
+type chainQuerier struct {
+	datasource.Querier
+	cache *chainCache
+}
+
+// Query implements datasource.Querier interface.
+// The returned http.Request is nil if the query was served from cache.
+func (cq *chainQuerier) Query(ctx context.Context, query string, ts time.Time) (datasource.Result, *http.Request, error) {
+	if ms, ok := cq.cache.query(query); ok {
+		return datasource.Result{Data: ms}, nil, nil
+	}
+	return cq.Querier.Query(ctx, query, ts)
+}
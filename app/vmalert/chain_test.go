@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/config"
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/notifier"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+)
+
+func TestChainCache_Query(t *testing.T) {
+	ts := time.Now()
+	cc := newChainCache(true)
+	cc.add("job:up:sum", []prompbmarshal.TimeSeries{
+		newTimeSeries([]float64{0}, []int64{ts.Unix()}, map[string]string{"__name__": "job:up:sum", "job": "foo"}),
+		newTimeSeries([]float64{2}, []int64{ts.Unix()}, map[string]string{"__name__": "job:up:sum", "job": "bar"}),
+	})
+	cc.add("empty", nil)
+
+	f := func(expr string, expOK bool, expJobs ...string) {
+		t.Helper()
+		ms, ok := cc.query(expr)
+		if ok != expOK {
+			t.Fatalf("expected ok=%v for %q; got %v", expOK, expr, ok)
+		}
+		if len(ms) != len(expJobs) {
+			t.Fatalf("expected to get %d series for %q; got %d", len(expJobs), expr, len(ms))
+		}
+		for i, m := range ms {
+			if got := m.Label("job"); got != expJobs[i] {
+				t.Fatalf("expected series #%d for %q to have job=%q; got %q", i, expr, expJobs[i], got)
+			}
+			if len(m.Timestamps) != 1 || m.Timestamps[0] != ts.Unix() {
+				t.Fatalf("unexpected timestamps %v for %q", m.Timestamps, expr)
+			}
+		}
+	}
+
+	f(`job:up:sum`, true, "foo", "bar")
+	f(`job:up:sum{job="bar"}`, true, "bar")
+	f(`job:up:sum{job=~"f.*"}`, true, "foo")
+	f(`job:up:sum{job!="foo"}`, true, "bar")
+	f(`job:up:sum == 0`, true, "foo")
+	f(`job:up:sum > 1`, true, "bar")
+	f(`1 > job:up:sum`, true, "foo")
+	f(`job:up:sum{job="bar"} < 1`, true)
+	f(`empty`, true)
+	f(`job:up:sum == 0 or job:up:sum > 1`, true, "foo", "bar")
+	f(`job:up:sum > 1 or job:up:sum`, true, "bar", "foo")
+	f(`job:up:sum or empty`, true, "foo", "bar")
+
+	// unsupported expressions or metrics which weren't recorded
+	f(`up`, false)
+	f(`job:up:sum > bool 1`, false)
+	f(`job:up:sum + 1`, false)
+	f(`sum(job:up:sum)`, false)
+	f(`job:up:sum > on(job) up`, false)
+	f(`{__name__=~"job:.*"}`, false)
+	f(`job:up:sum or up`, false)
+	f(`job:up:sum or on(job) empty`, false)
+
+	cc.reset()
+	f(`job:up:sum`, false)
+
+	cc.addRecording("job:up:sum", []prompbmarshal.TimeSeries{
+		newTimeSeries([]float64{0}, []int64{ts.Unix()}, map[string]string{"__name__": "job:up:sum", "job": "foo"}),
+	})
+	f(`job:up:sum`, true, "foo")
+
+	cc = newChainCache(false)
+	cc.addRecording("job:up:sum", []prompbmarshal.TimeSeries{
+		newTimeSeries([]float64{0}, []int64{ts.Unix()}, map[string]string{"__name__": "job:up:sum", "job": "foo"}),
+	})
+	f(`job:up:sum`, false)
+
+	var nilCache *chainCache
+	if _, ok := nilCache.query(`job:up:sum`); ok {
+		t.Fatalf("expected nil cache to not serve queries")
+	}
+}
+
+func TestGroupChainRules(t *testing.T) {
+	fq := &fakeQuerier{}
+	fq.add(metricWithValueAndLabels(t, 0, "job", "foo"))
+	fq.add(metricWithValueAndLabels(t, 1, "job", "bar"))
+
+	cfg := config.Group{
+		Name:       "chained",
+		ChainRules: true,
+		Rules: []config.Rule{
+			{ID: 1, Record: "job:up:sum", Expr: "sum(up) by (job)"},
+			{ID: 2, Alert: "JobDown", Expr: "job:up:sum == 0"},
+		},
+	}
+	g := newGroup(cfg, fq, time.Minute, nil)
+	fn := &fakeNotifier{}
+	e := &executor{
+		notifiers: func() []notifier.Notifier { return []notifier.Notifier{fn} },
+		chain:     g.chain,
+	}
+
+	ts := time.Now()
+	e.chain.reset()
+	for err := range e.execConcurrently(context.Background(), g.Rules, ts, g.Concurrency, 0, 0) {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	ar := g.Rules[1].(*AlertingRule)
+	alerts := ar.AlertsToAPI()
+	if len(alerts) != 1 {
+		t.Fatalf("expected to get 1 alert from the chained result; got %d", len(alerts))
+	}
+	if alerts[0].Labels["job"] != "foo" {
+		t.Fatalf("expected alert for job=foo; got %v", alerts[0].Labels)
+	}
+
+	// the recording rule fails, so the alerting rule
+	// must fall back to the datasource
+	fq.setErr(context.DeadlineExceeded)
+	e.chain.reset()
+	for range e.execConcurrently(context.Background(), g.Rules, ts.Add(time.Minute), g.Concurrency, 0, 0) {
+	}
+	if _, ok := g.chain.query(`job:up:sum`); ok {
+		t.Fatalf("expected failed recording rule to not be cached")
+	}
+}
@@ -41,6 +41,15 @@ type Group struct {
 	NotifierHeaders []Header `yaml:"notifier_headers,omitempty"`
 	// EvalAlignment will make the timestamp of group query requests be aligned with interval
 	EvalAlignment *bool `yaml:"eval_alignment,omitempty"`
+	// InhibitRules contains rules for muting notifications for alerts of the group
+	// while other alerts of the group are firing
+	InhibitRules []InhibitRule `yaml:"inhibit_rules,omitempty"`
+	// ChainRules makes results of recording rules available to the subsequent
+	// rules of the group within the same evaluation round
+	ChainRules bool `yaml:"chain_rules,omitempty"`
+	// Baselines contains definitions of seasonal baselines computed
+	// for the group's series on every evaluation
+	Baselines []Baseline `yaml:"baselines,omitempty"`
 	// Catches all undefined fields and must be empty after parsing.
 	XXX map[string]interface{} `yaml:",inline"`
 }
@@ -87,6 +96,14 @@ func (g *Group) Validate(validateTplFn ValidateTplFn, validateExpressions bool)
 	if g.Concurrency < 0 {
 		return fmt.Errorf("invalid concurrency %d, shouldn't be less than 0", g.Concurrency)
 	}
+	if g.ChainRules {
+		if g.Concurrency > 1 {
+			return fmt.Errorf("chain_rules can't be used with concurrency %d, since chained rules must be evaluated sequentially", g.Concurrency)
+		}
+		if g.Type.String() == NewGraphiteType().String() {
+			return fmt.Errorf("chain_rules isn't supported for %q datasource type", g.Type.String())
+		}
+	}
 
 	uniqueRules := map[uint64]struct{}{}
 	for _, r := range g.Rules {
@@ -118,9 +135,138 @@ func (g *Group) Validate(validateTplFn ValidateTplFn, validateExpressions bool)
 			}
 		}
 	}
+	for i, ir := range g.InhibitRules {
+		if err := ir.Validate(); err != nil {
+			return fmt.Errorf("invalid inhibit rule #%d: %w", i, err)
+		}
+	}
+	if len(g.Baselines) > 0 && g.Type.String() == NewGraphiteType().String() {
+		return fmt.Errorf("baselines aren't supported for %q datasource type", g.Type.String())
+	}
+	baselines := make(map[string]struct{}, len(g.Baselines))
+	for _, b := range g.Baselines {
+		if _, ok := baselines[b.Record]; ok {
+			return fmt.Errorf("baseline %q is a duplicate in group", b.Record)
+		}
+		baselines[b.Record] = struct{}{}
+		if err := b.Validate(); err != nil {
+			return fmt.Errorf("invalid baseline %q: %w", b.Record, err)
+		}
+		if validateExpressions {
+			if err := g.Type.ValidateExpr(b.Expr); err != nil {
+				return fmt.Errorf("invalid expression for baseline %q: %w", b.Record, err)
+			}
+		}
+	}
+	for _, r := range g.Rules {
+		if r.Baseline == "" {
+			continue
+		}
+		if r.Alert == "" {
+			return fmt.Errorf("invalid rule %q: `baseline` can be set only for alerting rules", r.Name())
+		}
+		if _, ok := baselines[r.Baseline]; !ok {
+			return fmt.Errorf("invalid rule %q: baseline %q isn't defined in group", r.Name(), r.Baseline)
+		}
+	}
 	return checkOverflow(g.XXX, fmt.Sprintf("group %q", g.Name))
 }
 
+// InhibitRule mutes notifications for alerts matching TargetMatchers
+// while there is a firing alert matching SourceMatchers.
+// See https://prometheus.io/docs/alerting/latest/configuration/#inhibit_rule
+type InhibitRule struct {
+	// SourceMatchers is a list of matchers that have to be fulfilled by firing alerts
+	// for the inhibition to take effect
+	SourceMatchers []string `yaml:"source_matchers"`
+	// TargetMatchers is a list of matchers that have to be fulfilled by alerts to be muted
+	TargetMatchers []string `yaml:"target_matchers"`
+	// Equal is a list of labels that must have an equal value
+	// in the source and target alerts for the inhibition to take effect
+	Equal []string `yaml:"equal,omitempty"`
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline"`
+}
+
+// Validate checks InhibitRule configuration errors
+func (ir *InhibitRule) Validate() error {
+	if len(ir.SourceMatchers) == 0 {
+		return fmt.Errorf("`source_matchers` can't be empty")
+	}
+	if len(ir.TargetMatchers) == 0 {
+		return fmt.Errorf("`target_matchers` can't be empty")
+	}
+	if _, err := ParseMatchers(ir.SourceMatchers); err != nil {
+		return fmt.Errorf("invalid `source_matchers`: %w", err)
+	}
+	if _, err := ParseMatchers(ir.TargetMatchers); err != nil {
+		return fmt.Errorf("invalid `target_matchers`: %w", err)
+	}
+	return checkOverflow(ir.XXX, "inhibit rule")
+}
+
+// Baseline describes seasonal baseline computed for every series returned by Expr.
+// The baseline is the mean of the series values at the same time during the previous Seasons,
+// e.g. at the same hour during the last 4 weeks.
+type Baseline struct {
+	ID uint64
+	// Record is the name prefix for the produced series
+	// `<record>:baseline`, `<record>:deviation` and `<record>:zscore`
+	Record string `yaml:"record"`
+	Expr   string `yaml:"expr"`
+	// Season is the duration of the season. Defaults to 1w
+	Season *promutils.Duration `yaml:"season,omitempty"`
+	// Seasons is the number of previous seasons used for computing the baseline. Defaults to 4
+	Seasons int               `yaml:"seasons,omitempty"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (b *Baseline) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type baseline Baseline
+	if err := unmarshal((*baseline)(b)); err != nil {
+		return err
+	}
+	b.ID = HashBaseline(*b)
+	return nil
+}
+
+// HashBaseline hashes significant Baseline fields into
+// unique hash that supposed to define Baseline uniqueness
+func HashBaseline(b Baseline) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(b.Expr))
+	h.Write([]byte("baseline"))
+	h.Write([]byte(b.Record))
+	kv := sortMap(b.Labels)
+	for _, i := range kv {
+		h.Write([]byte(i.key))
+		h.Write([]byte(i.value))
+		h.Write([]byte("\xff"))
+	}
+	return h.Sum64()
+}
+
+// Validate checks Baseline configuration errors
+func (b *Baseline) Validate() error {
+	if b.Record == "" {
+		return fmt.Errorf("`record` can't be empty")
+	}
+	if b.Expr == "" {
+		return fmt.Errorf("expression can't be empty")
+	}
+	if b.Season.Duration() < 0 {
+		return fmt.Errorf("season shouldn't be lower than 0")
+	}
+	if b.Seasons < 0 || b.Seasons > 100 {
+		return fmt.Errorf("invalid seasons %d, must be in range [0..100]", b.Seasons)
+	}
+	return checkOverflow(b.XXX, "baseline")
+}
+
 // Rule describes entity that represent either
 // recording rule or alerting rule.
 type Rule struct {
@@ -134,6 +280,9 @@ type Rule struct {
 	Labels        map[string]string   `yaml:"labels,omitempty"`
 	Annotations   map[string]string   `yaml:"annotations,omitempty"`
 	Debug         bool                `yaml:"debug,omitempty"`
+	// Baseline is the name of the group's baseline, which values are available
+	// in alerting rule templates via $baseline, $deviation and $zscore variables
+	Baseline string `yaml:"baseline,omitempty"`
 	// UpdateEntriesLimit defines max number of rule's state updates stored in memory.
 	// Overrides `-rule.updateEntriesLimit`.
 	UpdateEntriesLimit *int `yaml:"update_entries_limit,omitempty"`
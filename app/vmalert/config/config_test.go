@@ -374,6 +374,119 @@ func TestGroup_Validate(t *testing.T) {
 			},
 			expErr: "invalid rule",
 		},
+		{
+			group: &Group{
+				Name: "inhibit rule without source matchers",
+				InhibitRules: []InhibitRule{
+					{TargetMatchers: []string{`severity="warning"`}},
+				},
+			},
+			expErr: "`source_matchers` can't be empty",
+		},
+		{
+			group: &Group{
+				Name: "inhibit rule with bad target matchers",
+				InhibitRules: []InhibitRule{
+					{
+						SourceMatchers: []string{`severity="critical"`},
+						TargetMatchers: []string{`severity=~"warning`},
+					},
+				},
+			},
+			expErr: "invalid `target_matchers`",
+		},
+		{
+			group: &Group{
+				Name: "inhibit rule",
+				InhibitRules: []InhibitRule{
+					{
+						SourceMatchers: []string{`severity="critical"`},
+						TargetMatchers: []string{`severity=~"warning|info"`},
+						Equal:          []string{"instance"},
+					},
+				},
+			},
+			expErr: "",
+		},
+		{
+			group: &Group{
+				Name:        "chain rules with concurrency",
+				ChainRules:  true,
+				Concurrency: 2,
+			},
+			expErr: "chain_rules can't be used with concurrency",
+		},
+		{
+			group: &Group{
+				Name:       "chain rules with graphite",
+				Type:       NewGraphiteType(),
+				ChainRules: true,
+			},
+			expErr: "chain_rules isn't supported",
+		},
+		{
+			group: &Group{
+				Name:        "chain rules",
+				ChainRules:  true,
+				Concurrency: 1,
+			},
+			expErr: "",
+		},
+		{
+			group: &Group{
+				Name:      "baseline without expr",
+				Baselines: []Baseline{{Record: "job:errors"}},
+			},
+			expErr: "expression can't be empty",
+		},
+		{
+			group: &Group{
+				Name: "duplicate baselines",
+				Baselines: []Baseline{
+					{Record: "job:errors", Expr: "errors"},
+					{Record: "job:errors", Expr: "errors"},
+				},
+			},
+			expErr: "is a duplicate in group",
+		},
+		{
+			group: &Group{
+				Name:      "baseline with too many seasons",
+				Baselines: []Baseline{{Record: "job:errors", Expr: "errors", Seasons: 101}},
+			},
+			expErr: "seasons",
+		},
+		{
+			group: &Group{
+				Name:      "baseline with graphite",
+				Type:      NewGraphiteType(),
+				Baselines: []Baseline{{Record: "job:errors", Expr: "errors"}},
+			},
+			expErr: "baselines aren't supported",
+		},
+		{
+			group: &Group{
+				Name:  "undefined baseline",
+				Rules: []Rule{{Alert: "alert", Expr: "errors > 0", Baseline: "job:errors"}},
+			},
+			expErr: "isn't defined in group",
+		},
+		{
+			group: &Group{
+				Name:      "baseline for recording rule",
+				Baselines: []Baseline{{Record: "job:errors", Expr: "errors"}},
+				Rules:     []Rule{{Record: "record", Expr: "errors", Baseline: "job:errors"}},
+			},
+			expErr: "can be set only for alerting rules",
+		},
+		{
+			group: &Group{
+				Name:      "baseline",
+				Baselines: []Baseline{{Record: "job:errors", Expr: "errors", Season: promutils.NewDuration(24 * time.Hour), Seasons: 7}},
+				Rules:     []Rule{{Alert: "alert", Expr: "job:errors:zscore > 3", Baseline: "job:errors"}},
+			},
+			expErr: "",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/VictoriaMetrics/metricsql"
+)
+
+// Matcher matches alert labels against the given label filter.
+// It supports the same syntax as label filters in MetricsQL selectors,
+// e.g. `severity="critical"`, `job!="test"`, `instance=~"host-.+"` or `env!~"dev|stage"`.
+type Matcher struct {
+	Label      string
+	Value      string
+	IsNegative bool
+	IsRegexp   bool
+
+	re *regexp.Regexp
+}
+
+// String returns string representation of m.
+func (m *Matcher) String() string {
+	op := "="
+	switch {
+	case m.IsNegative && m.IsRegexp:
+		op = "!~"
+	case m.IsNegative:
+		op = "!="
+	case m.IsRegexp:
+		op = "=~"
+	}
+	return fmt.Sprintf("%s%s%q", m.Label, op, m.Value)
+}
+
+// Match returns true if the given labels match m.
+// Missing label is treated as label with empty value.
+func (m *Matcher) Match(labels map[string]string) bool {
+	v := labels[m.Label]
+	var ok bool
+	if m.IsRegexp {
+		ok = m.re.MatchString(v)
+	} else {
+		ok = v == m.Value
+	}
+	if m.IsNegative {
+		return !ok
+	}
+	return ok
+}
+
+// Matchers is a list of Matcher joined by `and` operator
+type Matchers []*Matcher
+
+// Match returns true if the given labels match all the matchers from ms.
+func (ms Matchers) Match(labels map[string]string) bool {
+	for _, m := range ms {
+		if !m.Match(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns string representation of ms.
+func (ms Matchers) String() string {
+	a := make([]string, len(ms))
+	for i, m := range ms {
+		a[i] = m.String()
+	}
+	return "{" + strings.Join(a, ", ") + "}"
+}
+
+// ParseMatchers parses the given list of label filters into Matchers.
+// Every item in the list may contain one or multiple comma-delimited
+// label filters, optionally wrapped into curly braces.
+func ParseMatchers(ss []string) (Matchers, error) {
+	var ms Matchers
+	for _, s := range ss {
+		s = strings.TrimSpace(s)
+		if !strings.HasPrefix(s, "{") {
+			s = "{" + s + "}"
+		}
+		expr, err := metricsql.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse matcher %q: %w", s, err)
+		}
+		me, ok := expr.(*metricsql.MetricExpr)
+		if !ok {
+			return nil, fmt.Errorf("matcher %q must contain only label filters", s)
+		}
+		if len(me.LabelFilterss) != 1 {
+			return nil, fmt.Errorf("matcher %q mustn't contain `or` filters", s)
+		}
+		lms, err := NewMatchers(me.LabelFilterss[0])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse matcher %q: %w", s, err)
+		}
+		ms = append(ms, lms...)
+	}
+	return ms, nil
+}
+
+// NewMatchers returns Matchers for the given MetricsQL label filters.
+func NewMatchers(lfs []metricsql.LabelFilter) (Matchers, error) {
+	ms := make(Matchers, 0, len(lfs))
+	for _, lf := range lfs {
+		m := &Matcher{
+			Label:      lf.Label,
+			Value:      lf.Value,
+			IsNegative: lf.IsNegative,
+			IsRegexp:   lf.IsRegexp,
+		}
+		if m.IsRegexp {
+			re, err := regexp.Compile("^(?:" + lf.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("cannot compile regexp %q: %w", lf.Value, err)
+			}
+			m.re = re
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
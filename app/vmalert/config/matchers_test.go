@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMatchers_Failure(t *testing.T) {
+	f := func(s, expErr string) {
+		t.Helper()
+		_, err := ParseMatchers([]string{s})
+		if err == nil {
+			t.Fatalf("expected to get error for %q; got nil", s)
+		}
+		if !strings.Contains(err.Error(), expErr) {
+			t.Fatalf("expected err to contain %q; got %q instead", expErr, err)
+		}
+	}
+	f(`foo=`, "cannot parse matcher")
+	f(`sum(foo)`, "cannot parse matcher")
+	f(`foo="bar" or baz="qux"`, "mustn't contain `or` filters")
+	f(`foo=~"(bar"`, "cannot parse matcher")
+}
+
+func TestMatchers_Match(t *testing.T) {
+	f := func(ss []string, labels map[string]string, expected bool) {
+		t.Helper()
+		ms, err := ParseMatchers(ss)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got := ms.Match(labels); got != expected {
+			t.Fatalf("unexpected match result for %s and labels %v; got %v; want %v", ms, labels, got, expected)
+		}
+	}
+	labels := map[string]string{
+		"alertname": "HighLatency",
+		"severity":  "critical",
+		"instance":  "host-1",
+	}
+	f(nil, labels, true)
+	f([]string{`severity="critical"`}, labels, true)
+	f([]string{`{severity="critical"}`}, labels, true)
+	f([]string{`severity="warning"`}, labels, false)
+	f([]string{`severity!="warning"`}, labels, true)
+	f([]string{`instance=~"host-.+"`}, labels, true)
+	f([]string{`instance=~"host"`}, labels, false)
+	f([]string{`instance!~"host-1|host-2"`}, labels, false)
+	f([]string{`alertname="HighLatency", severity="critical"`}, labels, true)
+	f([]string{`alertname="HighLatency"`, `severity="warning"`}, labels, false)
+	f([]string{`team=""`}, labels, true)
+	f([]string{`team!=""`}, labels, false)
+}
@@ -32,6 +32,11 @@ type Result struct {
 	// If nil, then this feature is not supported by the datasource.
 	// SeriesFetched is supported by VictoriaMetrics since v1.90.
 	SeriesFetched *int
+	// Trace contains JSON-encoded query trace returned by datasource
+	// if the request was sent with `trace=1` param.
+	// See https://docs.victoriametrics.com/#query-tracing
+	// If empty, then tracing wasn't requested or isn't supported by the datasource.
+	Trace []byte
 }
 
 // QuerierBuilder builds Querier with given params.
@@ -29,6 +29,8 @@ type promResponse struct {
 	Stats struct {
 		SeriesFetched *string `json:"seriesFetched,omitempty"`
 	} `json:"stats,omitempty"`
+	// Trace is returned by VictoriaMetrics if `trace=1` param was set
+	Trace json.RawMessage `json:"trace,omitempty"`
 }
 
 type promInstant struct {
@@ -142,7 +144,7 @@ func parsePrometheusResponse(req *http.Request, resp *http.Response) (res Result
 	if err != nil {
 		return res, err
 	}
-	res = Result{Data: ms}
+	res = Result{Data: ms, Trace: r.Trace}
 	if r.Stats.SeriesFetched != nil {
 		intV, err := strconv.Atoi(*r.Stats.SeriesFetched)
 		if err != nil {
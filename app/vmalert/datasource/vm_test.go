@@ -69,12 +69,14 @@ func TestVMInstantQuery(t *testing.T) {
 			w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1583786142, "1"]}}`))
 		case 7:
 			w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1583786142, "1"]},"stats":{"seriesFetched": "42"}}`))
+		case 8:
+			w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1583786142, "1"]},"trace":{"duration_msec":0.5,"message":"/api/v1/query"}}`))
 		}
 	})
 	mux.HandleFunc("/render", func(w http.ResponseWriter, request *http.Request) {
 		c++
 		switch c {
-		case 8:
+		case 9:
 			w.Write([]byte(`[{"target":"constantLine(10)","tags":{"name":"constantLine(10)"},"datapoints":[[10,1611758343],[10,1611758373],[10,1611758403]]}]`))
 		}
 	})
@@ -174,10 +176,22 @@ func TestVMInstantQuery(t *testing.T) {
 		t.Fatalf("expected `seriesFetched` field to be 42; got %d instead",
 			*res.SeriesFetched)
 	}
+	if res.Trace != nil {
+		t.Fatalf("expected `trace` field to be nil when it is missing in datasource response; got %s instead", res.Trace)
+	}
+
+	res, _, err = pq.Query(ctx, query, ts) // 8 - scalar with trace
+	if err != nil {
+		t.Fatalf("unexpected %s", err)
+	}
+	expTrace := `{"duration_msec":0.5,"message":"/api/v1/query"}`
+	if string(res.Trace) != expTrace {
+		t.Fatalf("expected `trace` field to be %s; got %s instead", expTrace, res.Trace)
+	}
 
 	gq := s.BuildWithParams(QuerierParams{DataSourceType: string(datasourceGraphite)})
 
-	res, _, err = gq.Query(ctx, queryRender, ts) // 8 - graphite
+	res, _, err = gq.Query(ctx, queryRender, ts) // 9 - graphite
 	if err != nil {
 		t.Fatalf("unexpected %s", err)
 	}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -41,6 +42,8 @@ type Group struct {
 	Params          url.Values
 	Headers         map[string]string
 	NotifierHeaders map[string]string
+	InhibitRules    []config.InhibitRule
+	ChainRules      bool
 
 	doneCh     chan struct{}
 	finishedCh chan struct{}
@@ -55,6 +58,11 @@ type Group struct {
 	// evalAlignment will make the timestamp of group query
 	// requests be aligned with interval
 	evalAlignment *bool
+	// chain holds results of baseline rules and, if ChainRules is set,
+	// results of recording rules for the current evaluation round
+	chain *chainCache
+	// baselines holds the last values computed by baseline rules
+	baselines *baselineStore
 }
 
 type groupMetrics struct {
@@ -109,6 +117,8 @@ func newGroup(cfg config.Group, qb datasource.QuerierBuilder, defaultInterval ti
 		Headers:         make(map[string]string),
 		NotifierHeaders: make(map[string]string),
 		Labels:          cfg.Labels,
+		InhibitRules:    cfg.InhibitRules,
+		ChainRules:      cfg.ChainRules,
 		evalAlignment:   cfg.EvalAlignment,
 
 		doneCh:     make(chan struct{}),
@@ -130,9 +140,25 @@ func newGroup(cfg config.Group, qb datasource.QuerierBuilder, defaultInterval ti
 	for _, h := range cfg.NotifierHeaders {
 		g.NotifierHeaders[h.Key] = h.Value
 	}
+	if g.ChainRules || len(cfg.Baselines) > 0 {
+		// rules evaluate queries to the series recorded within
+		// the current evaluation round via the local cache
+		g.chain = newChainCache(g.ChainRules)
+		qb = &chainQuerierBuilder{qb: qb, cache: g.chain}
+	}
 	g.metrics = newGroupMetrics(g)
-	rules := make([]Rule, len(cfg.Rules))
-	for i, r := range cfg.Rules {
+	g.baselines = newBaselineStore()
+	// baselines are evaluated before other rules,
+	// so alerting rules can use their values
+	rules := make([]Rule, 0, len(cfg.Baselines)+len(cfg.Rules))
+	for _, b := range cfg.Baselines {
+		// apply external and group labels, baseline labels have priority on them
+		if len(labels) > 0 || len(cfg.Labels) > 0 {
+			b.Labels = mergeLabels(g.Name, b.Record, mergeLabels(g.Name, b.Record, labels, g.Labels), b.Labels)
+		}
+		rules = append(rules, newBaselineRule(qb, g, b))
+	}
+	for _, r := range cfg.Rules {
 		var extraLabels map[string]string
 		// apply external labels
 		if len(labels) > 0 {
@@ -147,7 +173,7 @@ func newGroup(cfg config.Group, qb datasource.QuerierBuilder, defaultInterval ti
 			r.Labels = mergeLabels(g.Name, r.Name(), extraLabels, r.Labels)
 		}
 
-		rules[i] = g.newRule(qb, r)
+		rules = append(rules, g.newRule(qb, r))
 	}
 	g.Rules = rules
 	return g
@@ -202,6 +228,40 @@ func (g *Group) Restore(ctx context.Context, qb datasource.QuerierBuilder, ts ti
 	return nil
 }
 
+// evalRule evaluates the rule with the given ID at ts without changing its state.
+// The rule is executed via a separate Querier with query tracing enabled,
+// so datasource could return the query trace.
+func (g *Group) evalRule(ctx context.Context, qb datasource.QuerierBuilder, rID uint64, ts time.Time) (APIRuleEval, error) {
+	g.mu.RLock()
+	var rule Rule
+	for _, r := range g.Rules {
+		if r.ID() == rID {
+			rule = r
+			break
+		}
+	}
+	params := url.Values{}
+	for k, vs := range g.Params {
+		params[k] = vs
+	}
+	if g.Type.String() != config.NewGraphiteType().String() {
+		params.Set("trace", "1")
+	}
+	qp := datasource.QuerierParams{
+		DataSourceType:     g.Type.String(),
+		EvaluationInterval: g.Interval,
+		QueryParams:        params,
+		Headers:            g.Headers,
+	}
+	ts = g.adjustReqTimestamp(ts)
+	g.mu.RUnlock()
+
+	if rule == nil {
+		return APIRuleEval{}, fmt.Errorf("can't find rule with id %d in group %q", rID, g.Name)
+	}
+	return rule.Eval(ctx, qb.BuildWithParams(qp), ts), nil
+}
+
 // updateWith updates existing group with
 // passed group object. This function ignores group
 // evaluation interval change. It supposed to be updated
@@ -240,6 +300,17 @@ func (g *Group) updateWith(newGroup *Group) error {
 	for _, nr := range rulesRegistry {
 		newRules = append(newRules, nr)
 	}
+	if newGroup.ChainRules {
+		// chained rules depend on the order of evaluation,
+		// so it must match the order in the new group
+		order := make(map[uint64]int, len(newGroup.Rules))
+		for i, nr := range newGroup.Rules {
+			order[nr.ID()] = i
+		}
+		sort.SliceStable(newRules, func(i, j int) bool {
+			return order[newRules[i].ID()] < order[newRules[j].ID()]
+		})
+	}
 	// note that g.Interval is not updated here
 	// so the value can be compared later in
 	// group.Start function
@@ -248,6 +319,11 @@ func (g *Group) updateWith(newGroup *Group) error {
 	g.Params = newGroup.Params
 	g.Headers = newGroup.Headers
 	g.NotifierHeaders = newGroup.NotifierHeaders
+	g.InhibitRules = newGroup.InhibitRules
+	g.ChainRules = newGroup.ChainRules
+	// rules were updated with queriers bound to the chain of the new group
+	g.chain = newGroup.chain
+	g.baselines = newGroup.baselines
 	g.Labels = newGroup.Labels
 	g.Limit = newGroup.Limit
 	g.Checksum = newGroup.Checksum
@@ -313,6 +389,8 @@ func (g *Group) start(ctx context.Context, nts func() []notifier.Notifier, rw *r
 		rw:                       rw,
 		notifiers:                nts,
 		notifierHeaders:          g.NotifierHeaders,
+		inhibitor:                newInhibitor(g.InhibitRules),
+		chain:                    g.chain,
 		previouslySentSeriesToRW: make(map[uint64]map[string][]prompbmarshal.Label),
 	}
 
@@ -331,10 +409,16 @@ func (g *Group) start(ctx context.Context, nts func() []notifier.Notifier, rw *r
 
 		resolveDuration := getResolveDuration(g.Interval, *resendDelay, *maxResolveDuration)
 		ts = g.adjustReqTimestamp(ts)
-		errs := e.execConcurrently(ctx, g.Rules, ts, g.Concurrency, resolveDuration, g.Limit)
-		for err := range errs {
-			if err != nil {
-				logger.Errorf("group %q: %s", g.Name, err)
+		e.chain.reset()
+		// baselines must be computed before other rules are evaluated,
+		// since these rules may refer to baseline series
+		baselines, rules := splitBaselineRules(g.Rules)
+		for _, rs := range [][]Rule{baselines, rules} {
+			errs := e.execConcurrently(ctx, rs, ts, g.Concurrency, resolveDuration, g.Limit)
+			for err := range errs {
+				if err != nil {
+					logger.Errorf("group %q: %s", g.Name, err)
+				}
 			}
 		}
 		g.metrics.iterationDuration.UpdateDuration(start)
@@ -389,6 +473,8 @@ func (g *Group) start(ctx context.Context, nts func() []notifier.Notifier, rw *r
 			// ensure that staleness is tracked for existing rules only
 			e.purgeStaleSeries(g.Rules)
 			e.notifierHeaders = g.NotifierHeaders
+			e.inhibitor = newInhibitor(g.InhibitRules)
+			e.chain = g.chain
 			g.mu.Unlock()
 
 			g.infof("re-started")
@@ -477,9 +563,27 @@ func (g *Group) adjustReqTimestamp(timestamp time.Time) time.Time {
 	return timestamp
 }
 
+// splitBaselineRules returns baseline rules and the rest of rules.
+func splitBaselineRules(rules []Rule) ([]Rule, []Rule) {
+	var baselines, others []Rule
+	for _, r := range rules {
+		if _, ok := r.(*BaselineRule); ok {
+			baselines = append(baselines, r)
+			continue
+		}
+		others = append(others, r)
+	}
+	return baselines, others
+}
+
 type executor struct {
 	notifiers       func() []notifier.Notifier
 	notifierHeaders map[string]string
+	// inhibitor mutes alerts according to group's inhibit rules
+	inhibitor *inhibitor
+	// chain stores results of baseline rules and, if chain_rules is enabled,
+	// results of recording rules for the subsequent rules of the group
+	chain *chainCache
 
 	rw *remotewrite.Client
 
@@ -544,6 +648,25 @@ func (e *executor) exec(ctx context.Context, rule Rule, ts time.Time, resolveDur
 		return fmt.Errorf("rule %q: failed to execute: %w", rule, err)
 	}
 
+	switch r := rule.(type) {
+	case *RecordingRule:
+		e.chain.addRecording(r.Name, tss)
+	case *BaselineRule:
+		// baseline rule produces series with different names
+		byName := make(map[string][]prompbmarshal.TimeSeries)
+		for _, ts := range tss {
+			for _, l := range ts.Labels {
+				if l.Name == "__name__" {
+					byName[l.Value] = append(byName[l.Value], ts)
+					break
+				}
+			}
+		}
+		for name, series := range byName {
+			e.chain.add(name, series)
+		}
+	}
+
 	if e.rw != nil {
 		pushToRW := func(tss []prompbmarshal.TimeSeries) error {
 			var lastErr error
@@ -571,6 +694,7 @@ func (e *executor) exec(ctx context.Context, rule Rule, ts time.Time, resolveDur
 		return nil
 	}
 
+	ar.updateMuted(ts, e.inhibitor)
 	alerts := ar.alertsToSend(ts, resolveDuration, *resendDelay)
 	if len(alerts) < 1 {
 		return nil
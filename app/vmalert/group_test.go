@@ -476,6 +476,65 @@ func TestFaultyNotifier(t *testing.T) {
 	t.Fatalf("alive notifier didn't receive notification by %v", deadline)
 }
 
+func TestExecMutedAlerts(t *testing.T) {
+	mustResetSilences(t)
+	defer mustResetSilences(t)
+
+	fqCritical := &fakeQuerier{}
+	fqCritical.add(metricWithValueAndLabels(t, 1, "instance", "foo"))
+	critical := newTestRuleWithLabels("HostDown", "severity", "critical")
+	critical.q = fqCritical
+
+	fqWarning := &fakeQuerier{}
+	fqWarning.add(metricWithValueAndLabels(t, 1, "instance", "foo"))
+	fqWarning.add(metricWithValueAndLabels(t, 1, "instance", "bar"))
+	warning := newTestRuleWithLabels("HighLatency", "severity", "warning")
+	warning.RuleID = 1
+	warning.q = fqWarning
+
+	fn := &fakeNotifier{}
+	e := &executor{
+		notifiers: func() []notifier.Notifier { return []notifier.Notifier{fn} },
+		inhibitor: newInhibitor([]config.InhibitRule{
+			{
+				SourceMatchers: []string{`severity="critical"`},
+				TargetMatchers: []string{`severity="warning"`},
+				Equal:          []string{"instance"},
+			},
+		}),
+	}
+	ts := time.Now()
+	if err := e.exec(context.Background(), critical, ts, 0, 0); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := fn.getCounter(); n != 1 {
+		t.Fatalf("expected to send 1 alert; got %d", n)
+	}
+	if err := e.exec(context.Background(), warning, ts, 0, 0); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	alerts := fn.getAlerts()
+	if len(alerts) != 1 || alerts[0].Labels["instance"] != "bar" {
+		t.Fatalf("expected to send only not inhibited alert; got %v", alerts)
+	}
+	for _, a := range warning.AlertsToAPI() {
+		if exp := a.Labels["instance"] == "foo"; a.Inhibited != exp {
+			t.Fatalf("expected alert %v to have inhibited=%v", a.Labels, exp)
+		}
+	}
+
+	if _, err := silences.add(&Silence{Matchers: []string{`alertname="HighLatency"`}, EndsAt: ts.Add(time.Hour)}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fn.counter = 0
+	if err := e.exec(context.Background(), warning, ts.Add(time.Second), 0, 0); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := fn.getCounter(); n != 0 {
+		t.Fatalf("expected silenced alerts to be not sent; got %d alerts sent", n)
+	}
+}
+
 func TestFaultyRW(t *testing.T) {
 	fq := &fakeQuerier{}
 	fq.add(metricWithValueAndLabels(t, 1, "__name__", "foo", "job", "bar"))
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/config"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+)
+
+// inhibitor mutes notifications for alerts of the Group
+// according to the Group's inhibit rules.
+type inhibitor struct {
+	rules []inhibitRule
+
+	mu sync.RWMutex
+	// firing contains labels of firing alerts per each alerting rule ID.
+	// It is updated after every rule evaluation, so the sources from
+	// the previous evaluation round are used for rules evaluated
+	// concurrently or before the source rule.
+	firing map[uint64][]map[string]string
+}
+
+type inhibitRule struct {
+	source config.Matchers
+	target config.Matchers
+	equal  []string
+}
+
+func newInhibitor(cfgs []config.InhibitRule) *inhibitor {
+	in := &inhibitor{
+		firing: make(map[uint64][]map[string]string),
+	}
+	for _, cfg := range cfgs {
+		// inhibit rules are validated on config parsing
+		source, err := config.ParseMatchers(cfg.SourceMatchers)
+		if err != nil {
+			logger.Panicf("BUG: unexpected error when parsing source_matchers: %s", err)
+		}
+		target, err := config.ParseMatchers(cfg.TargetMatchers)
+		if err != nil {
+			logger.Panicf("BUG: unexpected error when parsing target_matchers: %s", err)
+		}
+		in.rules = append(in.rules, inhibitRule{
+			source: source,
+			target: target,
+			equal:  cfg.Equal,
+		})
+	}
+	return in
+}
+
+// setFiring updates the list of firing alerts for the given rule ID.
+func (in *inhibitor) setFiring(ruleID uint64, firing []map[string]string) {
+	if in == nil || len(in.rules) == 0 {
+		return
+	}
+	in.mu.Lock()
+	in.firing[ruleID] = firing
+	in.mu.Unlock()
+}
+
+// isInhibited returns true if alert with the given labels
+// is inhibited by one of the firing alerts.
+func (in *inhibitor) isInhibited(labels map[string]string) bool {
+	if in == nil || len(in.rules) == 0 {
+		return false
+	}
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+
+	for _, ir := range in.rules {
+		if !ir.target.Match(labels) {
+			continue
+		}
+		for _, firing := range in.firing {
+			for _, source := range firing {
+				if ir.inhibits(source, labels) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (ir *inhibitRule) inhibits(source, target map[string]string) bool {
+	if !ir.source.Match(source) {
+		return false
+	}
+	for _, l := range ir.equal {
+		if source[l] != target[l] {
+			return false
+		}
+	}
+	// an alert can't inhibit itself
+	return hash(source) != hash(target)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/config"
+)
+
+func TestInhibitor(t *testing.T) {
+	in := newInhibitor([]config.InhibitRule{
+		{
+			SourceMatchers: []string{`severity="critical"`},
+			TargetMatchers: []string{`severity=~"warning|info"`},
+			Equal:          []string{"instance"},
+		},
+	})
+	critical := map[string]string{"alertname": "HostDown", "severity": "critical", "instance": "foo"}
+	warning := map[string]string{"alertname": "HighLatency", "severity": "warning", "instance": "foo"}
+	warningOther := map[string]string{"alertname": "HighLatency", "severity": "warning", "instance": "bar"}
+
+	if in.isInhibited(warning) {
+		t.Fatalf("alert mustn't be inhibited without firing sources")
+	}
+	in.setFiring(1, []map[string]string{critical})
+	if !in.isInhibited(warning) {
+		t.Fatalf("alert %v must be inhibited by %v", warning, critical)
+	}
+	if in.isInhibited(warningOther) {
+		t.Fatalf("alert %v mustn't be inhibited since `instance` label doesn't match", warningOther)
+	}
+	if in.isInhibited(critical) {
+		t.Fatalf("alert %v mustn't be inhibited since it doesn't match target matchers", critical)
+	}
+	in.setFiring(1, nil)
+	if in.isInhibited(warning) {
+		t.Fatalf("alert mustn't be inhibited once source alert is resolved")
+	}
+
+	// an alert can't inhibit itself
+	in = newInhibitor([]config.InhibitRule{
+		{
+			SourceMatchers: []string{`alertname="HighLatency"`},
+			TargetMatchers: []string{`alertname="HighLatency"`},
+		},
+	})
+	in.setFiring(1, []map[string]string{warning})
+	if in.isInhibited(warning) {
+		t.Fatalf("alert mustn't inhibit itself")
+	}
+	if !in.isInhibited(warningOther) {
+		t.Fatalf("alert %v must be inhibited by %v", warningOther, warning)
+	}
+
+	// nil inhibitor never inhibits
+	var nilInhibitor *inhibitor
+	nilInhibitor.setFiring(1, []map[string]string{critical})
+	if nilInhibitor.isInhibited(warning) {
+		t.Fatalf("nil inhibitor mustn't inhibit alerts")
+	}
+}
@@ -146,8 +146,8 @@ func main() {
 		if err != nil {
 			logger.Fatalf("failed to init remoteWrite: %s", err)
 		}
-		if rw == nil {
-			logger.Fatalf("remoteWrite.url can't be empty in replay mode")
+		if rw == nil && *replayOutputPath == "" {
+			logger.Fatalf("remoteWrite.url or replay.outputPath must be set in replay mode")
 		}
 		groupsCfg, err := config.Parse(*rulePath, validateTplFn, *validateExpressions)
 		if err != nil {
@@ -165,6 +165,10 @@ func main() {
 		return
 	}
 
+	if err := silences.load(*silencesPath); err != nil {
+		logger.Fatalf("failed to init silences: %s", err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	manager, err := newManager(ctx)
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/config"
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/datasource"
@@ -47,6 +48,18 @@ func (m *manager) RuleAPI(gID, rID uint64) (APIRule, error) {
 	return APIRule{}, fmt.Errorf("can't find rule with id %d in group %q", rID, g.Name)
 }
 
+// RuleEval evaluates the rule with the given IDs at the current time
+// without changing the rule's state.
+func (m *manager) RuleEval(ctx context.Context, gID, rID uint64) (APIRuleEval, error) {
+	m.groupsMu.RLock()
+	g, ok := m.groups[gID]
+	m.groupsMu.RUnlock()
+	if !ok {
+		return APIRuleEval{}, fmt.Errorf("can't find group with id %d", gID)
+	}
+	return g.evalRule(ctx, m.querierBuilder, rID, time.Now())
+}
+
 // AlertAPI generates APIAlert object from alert by its ID(hash)
 func (m *manager) AlertAPI(gID, aID uint64) (*APIAlert, error) {
 	m.groupsMu.RLock()
@@ -49,6 +49,22 @@ type Alert struct {
 	Restored bool
 	// For defines for how long Alert needs to be active to become StateFiring
 	For time.Duration
+	// SilencedBy contains the ID of the silence which mutes the Alert
+	SilencedBy string
+	// Inhibited is true if the Alert is muted by the group's inhibit rules
+	Inhibited bool
+	// Baseline, Deviation and ZScore contain values of the seasonal baseline
+	// for the Alert's series. They are NaN if the rule has no baseline
+	// or the baseline has no values for the series.
+	Baseline  float64
+	Deviation float64
+	ZScore    float64
+}
+
+// IsMuted returns true if Alert is silenced or inhibited.
+// Muted alerts aren't sent to notifiers.
+func (a *Alert) IsMuted() bool {
+	return a.SilencedBy != "" || a.Inhibited
 }
 
 // AlertState type indicates the Alert state
@@ -85,6 +101,10 @@ type AlertTplData struct {
 	GroupID  uint64
 	ActiveAt time.Time
 	For      time.Duration
+
+	Baseline  float64
+	Deviation float64
+	ZScore    float64
 }
 
 var tplHeaders = []string{
@@ -97,6 +117,9 @@ var tplHeaders = []string{
 	"{{ $groupID := .GroupID }}",
 	"{{ $activeAt := .ActiveAt }}",
 	"{{ $for := .For }}",
+	"{{ $baseline := .Baseline }}",
+	"{{ $deviation := .Deviation }}",
+	"{{ $zscore := .ZScore }}",
 }
 
 // ExecTemplate executes the Alert template for given
@@ -112,6 +135,10 @@ func (a *Alert) ExecTemplate(q templates.QueryFn, labels, annotations map[string
 		GroupID:  a.GroupID,
 		ActiveAt: a.ActiveAt,
 		For:      a.For,
+
+		Baseline:  a.Baseline,
+		Deviation: a.Deviation,
+		ZScore:    a.ZScore,
 	}
 	return ExecTemplate(q, annotations, tplData)
 }
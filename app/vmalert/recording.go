@@ -161,6 +161,29 @@ func (rr *RecordingRule) Exec(ctx context.Context, ts time.Time, limit int) ([]p
 	return tss, nil
 }
 
+// Eval executes RecordingRule expression via the given Querier similarly to Exec.
+// Unlike Exec, it doesn't update the state of the Rule.
+func (rr *RecordingRule) Eval(ctx context.Context, q datasource.Querier, ts time.Time) APIRuleEval {
+	start := time.Now()
+	res, req, err := q.Query(ctx, rr.Expr, ts)
+	if err != nil {
+		err = fmt.Errorf("failed to execute query %q: %w", rr.Expr, err)
+	}
+	re := newRuleEval(rr.Name, ts, start, res, req, err)
+	if err != nil {
+		return re
+	}
+	for _, m := range res.Data {
+		s := newRuleEvalSeries(m)
+		s.Labels = make(map[string]string)
+		for _, l := range rr.toTimeSeries(m).Labels {
+			s.Labels[l.Name] = l.Value
+		}
+		re.Series = append(re.Series, s)
+	}
+	return re
+}
+
 func stringifyLabels(ts prompbmarshal.TimeSeries) string {
 	labels := ts.Labels
 	if len(labels) > 1 {
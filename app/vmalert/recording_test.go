@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -249,3 +250,44 @@ func TestRecordingRule_ExecNegative(t *testing.T) {
 		t.Fatalf("expected to get err %q; got %q insterad", errDuplicate, err)
 	}
 }
+
+func TestRecordingRule_Eval(t *testing.T) {
+	rr := &RecordingRule{
+		Name:  "job:foo",
+		state: newRuleState(10),
+		Labels: map[string]string{
+			"source": "test",
+		},
+	}
+	fq := &fakeQuerier{}
+	fq.add(metricWithValueAndLabels(t, 2, "__name__", "foo", "job", "foo"))
+
+	re := rr.Eval(context.TODO(), fq, time.Now())
+	if re.Error != "" {
+		t.Fatalf("unexpected error: %s", re.Error)
+	}
+	if len(re.Series) != 1 {
+		t.Fatalf("expected to get 1 series; got %d instead", len(re.Series))
+	}
+	s := re.Series[0]
+	if s.Value != "2" {
+		t.Fatalf("expected value 2; got %q", s.Value)
+	}
+	if s.Metric["__name__"] != "foo" {
+		t.Fatalf("expected original metric name to be preserved; got %v", s.Metric)
+	}
+	expLabels := map[string]string{"__name__": "job:foo", "job": "foo", "source": "test"}
+	if !reflect.DeepEqual(s.Labels, expLabels) {
+		t.Fatalf("expected labels %v; got %v", expLabels, s.Labels)
+	}
+	if len(rr.state.getAll()) != 0 {
+		t.Fatalf("expected rule state to remain unchanged after Eval")
+	}
+
+	expErr := "connection reset by peer"
+	fq.setErr(errors.New(expErr))
+	re = rr.Eval(context.TODO(), fq, time.Now())
+	if !strings.Contains(re.Error, expErr) {
+		t.Fatalf("expected to get err %q; got %q instead", expErr, re.Error)
+	}
+}
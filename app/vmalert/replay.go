@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/VictoriaMetrics/metricsql"
 	"github.com/cheggaaa/pb/v3"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/config"
@@ -48,6 +49,22 @@ func replay(groupsCfg []config.Group, qb datasource.QuerierBuilder, rw *remotewr
 	if !tTo.After(tFrom) {
 		return fmt.Errorf("replay.timeTo must be bigger than replay.timeFrom")
 	}
+	if *replayOutputPath != "" {
+		if rw != nil {
+			return fmt.Errorf("-remoteWrite.url and -replay.outputPath cannot be set simultaneously")
+		}
+		// -replay.rulesDelay has no effect when writing results to files,
+		// since the results are never ingested into the datasource during the replay.
+		if rule, record := getChainedRule(groupsCfg); rule != "" {
+			return fmt.Errorf("rule %q depends on results of recording rule %q, which aren't available during the replay with -replay.outputPath; "+
+				"replay these rules separately and import the results of %q before replaying %q", rule, record, record, rule)
+		}
+	}
+	for _, cfg := range groupsCfg {
+		if len(cfg.Baselines) > 0 {
+			return fmt.Errorf("group %q: %w; remove baselines from the group before the replay", cfg.Name, errBaselineReplay)
+		}
+	}
 	labels := make(map[string]string)
 	for _, s := range *externalLabels {
 		if len(s) == 0 {
@@ -66,10 +83,15 @@ func replay(groupsCfg []config.Group, qb datasource.QuerierBuilder, rw *remotewr
 		"\nmax data points per request: %d\n",
 		tFrom, tTo, *replayMaxDatapoints)
 
+	if *replayOutputPath != "" {
+		fmt.Printf("output path: %s\n", *replayOutputPath)
+	}
+
+	w := newReplayWriter(rw)
 	var total int
 	for _, cfg := range groupsCfg {
 		ng := newGroup(cfg, qb, *evaluationInterval, labels)
-		total += ng.replay(tFrom, tTo, rw)
+		total += ng.replay(tFrom, tTo, w)
 	}
 	logger.Infof("replay finished! Imported %d samples", total)
 	if rw != nil {
@@ -78,7 +100,56 @@ func replay(groupsCfg []config.Group, qb datasource.QuerierBuilder, rw *remotewr
 	return nil
 }
 
-func (g *Group) replay(start, end time.Time, rw *remotewrite.Client) int {
+// getChainedRule returns the name of the first rule, which depends on results of the recording rule
+// replayed before it, together with the name of this recording rule.
+//
+// Empty strings are returned if there are no such rules.
+func getChainedRule(groupsCfg []config.Group) (string, string) {
+	records := make(map[string]struct{})
+	for _, g := range groupsCfg {
+		for _, r := range g.Rules {
+			if len(records) > 0 {
+				if record := getReferencedRecord(r.Expr, records); record != "" {
+					return r.Name(), record
+				}
+			}
+			if r.Record != "" {
+				records[r.Record] = struct{}{}
+			}
+		}
+	}
+	return "", ""
+}
+
+// getReferencedRecord returns the first metric name from records, which is referenced by expr.
+func getReferencedRecord(expr string, records map[string]struct{}) string {
+	e, err := metricsql.Parse(expr)
+	if err != nil {
+		// expressions for non-Prometheus datasources cannot be parsed
+		return ""
+	}
+	var record string
+	metricsql.VisitAll(e, func(e metricsql.Expr) {
+		me, ok := e.(*metricsql.MetricExpr)
+		if !ok || record != "" {
+			return
+		}
+		for _, lfs := range me.LabelFilterss {
+			for _, lf := range lfs {
+				if lf.Label != "__name__" || lf.IsRegexp || lf.IsNegative {
+					continue
+				}
+				if _, ok := records[lf.Value]; ok {
+					record = lf.Value
+					return
+				}
+			}
+		}
+	})
+	return record
+}
+
+func (g *Group) replay(start, end time.Time, w replayWriter) int {
 	var total int
 	step := g.Interval * time.Duration(*replayMaxDatapoints)
 	start = g.adjustReqTimestamp(start)
@@ -102,7 +173,14 @@ func (g *Group) replay(start, end time.Time, rw *remotewrite.Client) int {
 		}
 		ri.reset()
 		for ri.next() {
-			n, err := replayRule(rule, ri.s, ri.e, rw)
+			if w.isDone(g, rule, ri.s, ri.e) {
+				// the time range was processed during the previous replay run
+				if bar != nil {
+					bar.Increment()
+				}
+				continue
+			}
+			n, err := replayRule(g, rule, ri.s, ri.e, w)
 			if err != nil {
 				logger.Fatalf("rule %q: %s", rule, err)
 			}
@@ -114,14 +192,16 @@ func (g *Group) replay(start, end time.Time, rw *remotewrite.Client) int {
 		if bar != nil {
 			bar.Finish()
 		}
-		// sleep to let remote storage to flush data on-disk
-		// so chained rules could be calculated correctly
-		time.Sleep(*replayRulesDelay)
+		if *replayOutputPath == "" {
+			// sleep to let remote storage to flush data on-disk
+			// so chained rules could be calculated correctly
+			time.Sleep(*replayRulesDelay)
+		}
 	}
 	return total
 }
 
-func replayRule(rule Rule, start, end time.Time, rw *remotewrite.Client) (int, error) {
+func replayRule(g *Group, rule Rule, start, end time.Time, w replayWriter) (int, error) {
 	var err error
 	var tss []prompbmarshal.TimeSeries
 	for i := 0; i < *replayRuleRetryAttempts; i++ {
@@ -135,14 +215,11 @@ func replayRule(rule Rule, start, end time.Time, rw *remotewrite.Client) (int, e
 	if err != nil { // means all attempts failed
 		return 0, err
 	}
-	if len(tss) < 1 {
-		return 0, nil
+	if err := w.write(g, rule, start, end, tss); err != nil {
+		return 0, err
 	}
 	var n int
 	for _, ts := range tss {
-		if err := rw.Push(ts); err != nil {
-			return n, fmt.Errorf("remote write failure: %s", err)
-		}
 		n += len(ts.Samples)
 	}
 	return n, nil
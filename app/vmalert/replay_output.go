@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/remotewrite"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+)
+
+var replayOutputPath = flag.String("replay.outputPath", "", "Optional path to the directory for writing replay results to files "+
+	"in JSON line format accepted by /api/v1/import instead of sending them to -remoteWrite.url. "+
+	"Results are written into a separate file per each rule and each time range of -replay.maxDatapointsPerQuery data points. "+
+	"Already existing files are skipped, so the interrupted replay can be resumed by running it again with the same params. "+
+	"See https://docs.victoriametrics.com/vmalert.html#rules-backfilling")
+
+// replayWriter persists time series produced by rules during the replay.
+type replayWriter interface {
+	// isDone returns true if the results of the rule on the given time range
+	// were already persisted, so the range can be skipped.
+	isDone(g *Group, rule Rule, start, end time.Time) bool
+	// write persists tss produced by the rule on the given time range.
+	write(g *Group, rule Rule, start, end time.Time, tss []prompbmarshal.TimeSeries) error
+}
+
+func newReplayWriter(rw *remotewrite.Client) replayWriter {
+	if *replayOutputPath == "" {
+		return &rwReplayWriter{rw: rw}
+	}
+	return &fileReplayWriter{path: *replayOutputPath}
+}
+
+// rwReplayWriter sends replay results to the remote storage
+// via remote write protocol.
+type rwReplayWriter struct {
+	rw *remotewrite.Client
+}
+
+func (w *rwReplayWriter) isDone(_ *Group, _ Rule, _, _ time.Time) bool {
+	return false
+}
+
+func (w *rwReplayWriter) write(_ *Group, _ Rule, _, _ time.Time, tss []prompbmarshal.TimeSeries) error {
+	for _, ts := range tss {
+		if err := w.rw.Push(ts); err != nil {
+			return fmt.Errorf("remote write failure: %s", err)
+		}
+	}
+	return nil
+}
+
+// fileReplayWriter writes replay results to files in JSON line format
+// accepted by /api/v1/import. See https://docs.victoriametrics.com/#how-to-import-data-in-json-line-format
+//
+// Results of every rule on every time range are written atomically into a separate file
+// at <path>/<group_id>/<rule_id>/<start>-<end>.jsonl, where start and end are unix timestamps
+// in milliseconds. This allows skipping already processed time ranges on replay restart.
+type fileReplayWriter struct {
+	path string
+}
+
+func (w *fileReplayWriter) filePath(g *Group, rule Rule, start, end time.Time) string {
+	return filepath.Join(w.path,
+		strconv.FormatUint(g.ID(), 10),
+		strconv.FormatUint(rule.ID(), 10),
+		fmt.Sprintf("%d-%d.jsonl", start.UnixMilli(), end.UnixMilli()))
+}
+
+func (w *fileReplayWriter) isDone(g *Group, rule Rule, start, end time.Time) bool {
+	return fs.IsPathExist(w.filePath(g, rule, start, end))
+}
+
+func (w *fileReplayWriter) write(g *Group, rule Rule, start, end time.Time, tss []prompbmarshal.TimeSeries) error {
+	path := w.filePath(g, rule, start, end)
+	fs.MustMkdirIfNotExist(filepath.Dir(path))
+	var b []byte
+	for _, ts := range tss {
+		b = appendJSONLine(b, ts)
+	}
+	// the file is written even if tss is empty,
+	// so the time range is marked as processed
+	fs.MustWriteAtomic(path, b, true)
+	return nil
+}
+
+// appendJSONLine appends ts in JSON line format accepted by /api/v1/import to dst.
+func appendJSONLine(dst []byte, ts prompbmarshal.TimeSeries) []byte {
+	metric := make(map[string]string, len(ts.Labels))
+	for _, l := range ts.Labels {
+		metric[l.Name] = l.Value
+	}
+	// json.Marshal can't fail for map[string]string
+	b, _ := json.Marshal(metric)
+	dst = append(dst, `{"metric":`...)
+	dst = append(dst, b...)
+	dst = append(dst, `,"values":[`...)
+	for i, s := range ts.Samples {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONFloat(dst, s.Value)
+	}
+	dst = append(dst, `],"timestamps":[`...)
+	for i, s := range ts.Samples {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = strconv.AppendInt(dst, s.Timestamp, 10)
+	}
+	dst = append(dst, "]}\n"...)
+	return dst
+}
+
+func appendJSONFloat(dst []byte, v float64) []byte {
+	switch {
+	case math.IsNaN(v):
+		return append(dst, `"NaN"`...)
+	case math.IsInf(v, 1):
+		return append(dst, `"Inf"`...)
+	case math.IsInf(v, -1):
+		return append(dst, `"-Inf"`...)
+	}
+	return strconv.AppendFloat(dst, v, 'g', -1, 64)
+}
@@ -2,12 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/config"
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/datasource"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
 )
 
@@ -248,3 +255,145 @@ func parseTime(t *testing.T, s string) time.Time {
 	}
 	return tt
 }
+
+func TestReplayOutputPath(t *testing.T) {
+	from, to, maxDP := *replayFrom, *replayTo, *replayMaxDatapoints
+	retries, outputPath := *replayRuleRetryAttempts, *replayOutputPath
+	defer func() {
+		*replayFrom, *replayTo = from, to
+		*replayMaxDatapoints, *replayRuleRetryAttempts = maxDP, retries
+		*replayOutputPath = outputPath
+	}()
+
+	*replayFrom = "2021-01-01T12:00:00.000Z"
+	*replayTo = "2021-01-01T12:02:30.000Z"
+	*replayMaxDatapoints = 1
+	*replayRuleRetryAttempts = 1
+	*replayOutputPath = t.TempDir()
+
+	fq := &fakeQuerier{}
+	m := metricWithLabels(t, "__name__", "up", "job", "foo")
+	m.Values, m.Timestamps = []float64{1, 2}, []int64{0, 1}
+	fq.add(m)
+	cfg := []config.Group{
+		{Name: "test", Rules: []config.Rule{{ID: 1, Record: "foo", Expr: "sum(up)"}}},
+	}
+	if err := replay(cfg, fq, nil); err != nil {
+		t.Fatalf("replay failed: %s", err)
+	}
+
+	g := newGroup(cfg[0], fq, *evaluationInterval, nil)
+	files, err := filepath.Glob(filepath.Join(*replayOutputPath, strconv.FormatUint(g.ID(), 10), "1", "*.jsonl"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected to get 3 files, one per time range; got %d: %v", len(files), files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("cannot read file: %s", err)
+	}
+	exp := `{"metric":{"__name__":"foo","job":"foo"},"values":[1,2],"timestamps":[0,1000]}` + "\n"
+	if string(data) != exp {
+		t.Fatalf("unexpected file content;\ngot\n%s\nwant\n%s", data, exp)
+	}
+
+	// all the time ranges were already processed,
+	// so replay must succeed without querying the datasource
+	fq.setErr(fmt.Errorf("unexpected query"))
+	if err := replay(cfg, fq, nil); err != nil {
+		t.Fatalf("replay failed: %s", err)
+	}
+}
+
+func TestGetChainedRule(t *testing.T) {
+	f := func(groups []config.Group, ruleExpected, recordExpected string) {
+		t.Helper()
+		rule, record := getChainedRule(groups)
+		if rule != ruleExpected || record != recordExpected {
+			t.Fatalf("unexpected result; got rule=%q, record=%q; want rule=%q, record=%q", rule, record, ruleExpected, recordExpected)
+		}
+	}
+
+	// independent rules
+	f([]config.Group{{Rules: []config.Rule{
+		{Record: "job:up:sum", Expr: "sum(up) by (job)"},
+		{Alert: "HostDown", Expr: "up == 0"},
+	}}}, "", "")
+
+	// rule depends on the recording rule executed after it
+	f([]config.Group{{Rules: []config.Rule{
+		{Alert: "JobDown", Expr: "job:up:sum == 0"},
+		{Record: "job:up:sum", Expr: "sum(up) by (job)"},
+	}}}, "", "")
+
+	// chained rules in the same group
+	f([]config.Group{{Rules: []config.Rule{
+		{Record: "job:up:sum", Expr: "sum(up) by (job)"},
+		{Alert: "JobDown", Expr: `rate(foo[5m]) > 0 and job:up:sum{job="bar"} == 0`},
+	}}}, "JobDown", "job:up:sum")
+
+	// chained rules in different groups
+	f([]config.Group{
+		{Rules: []config.Rule{{Record: "job:up:sum", Expr: "sum(up) by (job)"}}},
+		{Rules: []config.Rule{{Record: "job:up:max", Expr: "max(job:up:sum)"}}},
+	}, "job:up:max", "job:up:sum")
+}
+
+func TestReplayOutputPathChainedRules(t *testing.T) {
+	from, to, outputPath := *replayFrom, *replayTo, *replayOutputPath
+	defer func() {
+		*replayFrom, *replayTo, *replayOutputPath = from, to, outputPath
+	}()
+
+	*replayFrom = "2021-01-01T12:00:00.000Z"
+	*replayTo = "2021-01-01T12:02:30.000Z"
+	*replayOutputPath = t.TempDir()
+
+	cfg := []config.Group{{Name: "test", Rules: []config.Rule{
+		{ID: 1, Record: "foo", Expr: "sum(up)"},
+		{ID: 2, Alert: "FooIsZero", Expr: "foo == 0"},
+	}}}
+	err := replay(cfg, &fakeQuerier{}, nil)
+	if err == nil {
+		t.Fatalf("expecting non-nil error for chained rules")
+	}
+	if !strings.Contains(err.Error(), `"FooIsZero" depends on results of recording rule "foo"`) {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReplayBaselines(t *testing.T) {
+	from, to := *replayFrom, *replayTo
+	defer func() {
+		*replayFrom, *replayTo = from, to
+	}()
+
+	*replayFrom = "2021-01-01T12:00:00.000Z"
+	*replayTo = "2021-01-01T12:02:30.000Z"
+
+	cfg := []config.Group{{
+		Name:      "test",
+		Baselines: []config.Baseline{{ID: 1, Record: "job:errors", Expr: "errors"}},
+		Rules:     []config.Rule{{ID: 2, Alert: "ErrorsSpike", Expr: "errors > 0", Baseline: "job:errors"}},
+	}}
+	err := replay(cfg, &fakeQuerier{}, nil)
+	if !errors.Is(err, errBaselineReplay) {
+		t.Fatalf("expected to get %q error; got %v", errBaselineReplay, err)
+	}
+}
+
+func TestAppendJSONLine(t *testing.T) {
+	f := func(ts prompbmarshal.TimeSeries, exp string) {
+		t.Helper()
+		got := string(appendJSONLine(nil, ts))
+		if got != exp {
+			t.Fatalf("unexpected result;\ngot\n%s\nwant\n%s", got, exp)
+		}
+	}
+	f(newTimeSeries([]float64{1.5, math.NaN(), math.Inf(-1)}, []int64{1, 2, 3}, map[string]string{
+		"__name__": "foo",
+		"path":     `/"bar"`,
+	}), `{"metric":{"__name__":"foo","path":"/\"bar\""},"values":[1.5,"NaN","-Inf"],"timestamps":[1000,2000,3000]}`+"\n")
+}
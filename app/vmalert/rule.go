@@ -3,10 +3,15 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/datasource"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/querytracer"
 )
 
 // Rule represents alerting or recording rule
@@ -26,6 +31,10 @@ type Rule interface {
 	UpdateWith(Rule) error
 	// ToAPI converts Rule into APIRule
 	ToAPI() APIRule
+	// Eval executes the rule via the given Querier at the given timestamp
+	// without changing the rule's state. It is used for on-demand
+	// rule evaluation from WEB UI.
+	Eval(ctx context.Context, q datasource.Querier, ts time.Time) APIRuleEval
 	// Close performs the shutdown procedures for rule
 	// such as metrics unregister
 	Close()
@@ -116,3 +125,41 @@ func (s *ruleState) add(e ruleStateEntry) {
 	}
 	s.entries[s.cur] = e
 }
+
+// newRuleEval returns APIRuleEval for the given datasource response.
+// The query trace, if present in res, is converted to plaintext.
+func newRuleEval(name string, ts, start time.Time, res datasource.Result, req *http.Request, err error) APIRuleEval {
+	re := APIRuleEval{
+		At:            ts,
+		Duration:      time.Since(start),
+		Curl:          requestToCurl(req),
+		SeriesFetched: res.SeriesFetched,
+		Series:        make([]APIRuleEvalSeries, 0, len(res.Data)),
+	}
+	if err != nil {
+		re.Error = err.Error()
+		return re
+	}
+	if len(res.Trace) > 0 {
+		qt := querytracer.New(true, "evaluate rule %q at %s", name, ts.Format(time.RFC3339))
+		if err := qt.AddJSON(res.Trace); err != nil {
+			re.Error = fmt.Sprintf("failed to parse query trace: %s", err)
+		}
+		qt.Done()
+		re.Trace = qt.String()
+	}
+	return re
+}
+
+func newRuleEvalSeries(m datasource.Metric) APIRuleEvalSeries {
+	s := APIRuleEvalSeries{
+		Metric: make(map[string]string, len(m.Labels)),
+	}
+	for _, l := range m.Labels {
+		s.Metric[l.Name] = l.Value
+	}
+	if len(m.Values) > 0 {
+		s.Value = strconv.FormatFloat(m.Values[0], 'f', -1, 64)
+	}
+	return s
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/datasource"
 )
 
 func TestRule_stateDisabled(t *testing.T) {
@@ -98,3 +102,32 @@ func TestRule_stateConcurrent(_ *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestNewRuleEval(t *testing.T) {
+	ts := time.Now()
+	res := datasource.Result{
+		Data:  []datasource.Metric{metricWithValueAndLabels(t, 1, "__name__", "foo")},
+		Trace: []byte(`{"duration_msec":1.5,"message":"/api/v1/query: query=foo"}`),
+	}
+	re := newRuleEval("foo", ts, ts, res, nil, nil)
+	if re.Error != "" {
+		t.Fatalf("unexpected error: %s", re.Error)
+	}
+	if !strings.Contains(re.Trace, `evaluate rule "foo"`) {
+		t.Fatalf("expected trace to contain root span; got %q", re.Trace)
+	}
+	if !strings.Contains(re.Trace, "1.500ms: /api/v1/query: query=foo") {
+		t.Fatalf("expected trace to contain datasource span; got %q", re.Trace)
+	}
+
+	res.Trace = []byte(`{"duration_msec":`)
+	re = newRuleEval("foo", ts, ts, res, nil, nil)
+	if !strings.Contains(re.Error, "failed to parse query trace") {
+		t.Fatalf("expected to get trace parsing error; got %q", re.Error)
+	}
+
+	re = newRuleEval("foo", ts, ts, datasource.Result{}, nil, fmt.Errorf("connection refused"))
+	if re.Error != "connection refused" || re.Trace != "" {
+		t.Fatalf("unexpected eval result for failed query: %+v", re)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/config"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+)
+
+var silencesPath = flag.String("silences.path", "", "Path to the file for persisting silences created via vmalert API or UI. "+
+	"Silences are loaded from this file on startup. If empty, silences are kept in memory only and are lost on restart. "+
+	"See https://docs.victoriametrics.com/vmalert.html#silences")
+
+// Silence mutes notifications for alerts matching all its Matchers
+// within the time range [StartsAt, EndsAt).
+// Muted alerts are evaluated as usual, but aren't sent to notifiers.
+type Silence struct {
+	ID string `json:"id"`
+	// Matchers contains list of label filters in MetricsQL format,
+	// e.g. `alertname="HighLatency"` or `instance=~"host-.+"`
+	Matchers  []string  `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+
+	matchers config.Matchers
+}
+
+// IsActive returns true if s mutes alerts at ts.
+func (s *Silence) IsActive(ts time.Time) bool {
+	return !ts.Before(s.StartsAt) && ts.Before(s.EndsAt)
+}
+
+func (s *Silence) init() error {
+	if len(s.Matchers) == 0 {
+		return fmt.Errorf("silence must contain at least one matcher")
+	}
+	ms, err := config.ParseMatchers(s.Matchers)
+	if err != nil {
+		return err
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("silence endsAt=%s must be after startsAt=%s",
+			s.EndsAt.Format(time.RFC3339), s.StartsAt.Format(time.RFC3339))
+	}
+	s.matchers = ms
+	return nil
+}
+
+// silenceStore holds the list of silences and persists them
+// to the file at path if it is set.
+type silenceStore struct {
+	path string
+
+	mu       sync.RWMutex
+	silences map[string]*Silence
+}
+
+// silences contains silences used by all groups for muting alerts.
+// It is loaded from -silences.path on startup.
+//
+// The variable must never be re-assigned, since it is accessed concurrently
+// by groups, http handlers and metrics.
+var silences = &silenceStore{silences: make(map[string]*Silence)}
+
+var _ = metrics.NewGauge(`vmalert_silences_active`, func() float64 {
+	return float64(silences.activeCount(time.Now()))
+})
+
+func newSilenceStore(path string) (*silenceStore, error) {
+	ss := &silenceStore{
+		silences: make(map[string]*Silence),
+	}
+	if err := ss.load(path); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// load replaces silences at ss with silences from the file at path
+// and makes ss to persist further changes to this file.
+//
+// Only the path is set if the file doesn't exist.
+func (ss *silenceStore) load(path string) error {
+	m := make(map[string]*Silence)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cannot read silences from %q: %w", path, err)
+		}
+		if err == nil {
+			var list []*Silence
+			if err := json.Unmarshal(data, &list); err != nil {
+				return fmt.Errorf("cannot parse silences from %q: %w", path, err)
+			}
+			now := time.Now()
+			for _, s := range list {
+				if err := s.init(); err != nil {
+					return fmt.Errorf("invalid silence %q in %q: %w", s.ID, path, err)
+				}
+				if !s.EndsAt.After(now) {
+					continue
+				}
+				m[s.ID] = s
+			}
+			logger.Infof("loaded %d silences from %q", len(m), path)
+		}
+	}
+
+	ss.mu.Lock()
+	ss.path = path
+	ss.silences = m
+	ss.mu.Unlock()
+	return nil
+}
+
+// add validates the given silence and adds it to ss.
+// It returns the ID of the added silence.
+func (ss *silenceStore) add(s *Silence) (string, error) {
+	now := time.Now()
+	if s.StartsAt.IsZero() {
+		s.StartsAt = now
+	}
+	if err := s.init(); err != nil {
+		return "", err
+	}
+	if !s.EndsAt.After(now) {
+		return "", fmt.Errorf("silence endsAt=%s must be in the future", s.EndsAt.Format(time.RFC3339))
+	}
+	id, err := newSilenceID()
+	if err != nil {
+		return "", err
+	}
+	s.ID = id
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.silences[id] = s
+	ss.purgeExpiredLocked(now)
+	ss.persistLocked()
+	return id, nil
+}
+
+// expire removes the silence with the given id from ss.
+func (ss *silenceStore) expire(id string) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if _, ok := ss.silences[id]; !ok {
+		return fmt.Errorf("can't find silence with id %q", id)
+	}
+	delete(ss.silences, id)
+	ss.purgeExpiredLocked(time.Now())
+	ss.persistLocked()
+	return nil
+}
+
+// list returns active and pending silences sorted by StartsAt.
+func (ss *silenceStore) list(ts time.Time) []*Silence {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	list := make([]*Silence, 0, len(ss.silences))
+	for _, s := range ss.silences {
+		if !s.EndsAt.After(ts) {
+			continue
+		}
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].StartsAt.Equal(list[j].StartsAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].StartsAt.Before(list[j].StartsAt)
+	})
+	return list
+}
+
+func (ss *silenceStore) activeCount(ts time.Time) int {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	n := 0
+	for _, s := range ss.silences {
+		if s.IsActive(ts) {
+			n++
+		}
+	}
+	return n
+}
+
+// silencedBy returns the ID of the active silence matching the given labels at ts.
+// It returns an empty string if there are no such silences.
+func (ss *silenceStore) silencedBy(labels map[string]string, ts time.Time) string {
+	if ss == nil {
+		return ""
+	}
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	var id string
+	for _, s := range ss.silences {
+		if !s.IsActive(ts) || !s.matchers.Match(labels) {
+			continue
+		}
+		// pick the smallest ID for deterministic output
+		if id == "" || s.ID < id {
+			id = s.ID
+		}
+	}
+	return id
+}
+
+func (ss *silenceStore) purgeExpiredLocked(ts time.Time) {
+	for id, s := range ss.silences {
+		if !s.EndsAt.After(ts) {
+			delete(ss.silences, id)
+		}
+	}
+}
+
+func (ss *silenceStore) persistLocked() {
+	if ss.path == "" {
+		return
+	}
+	list := make([]*Silence, 0, len(ss.silences))
+	for _, s := range ss.silences {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	data, err := json.Marshal(list)
+	if err != nil {
+		logger.Panicf("BUG: cannot marshal silences: %s", err)
+	}
+	fs.MustWriteAtomic(ss.path, data, true)
+}
+
+func newSilenceID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("cannot generate silence id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSilenceStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "silences.json")
+	ss, err := newSilenceStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	now := time.Now()
+	labels := map[string]string{"alertname": "HighLatency", "instance": "host-1"}
+	id, err := ss.add(&Silence{
+		Matchers: []string{`alertname="HighLatency"`, `instance=~"host-.+"`},
+		EndsAt:   now.Add(time.Hour),
+		Comment:  "maintenance",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := ss.silencedBy(labels, now.Add(time.Second)); got != id {
+		t.Fatalf("expected alert to be silenced by %q; got %q", id, got)
+	}
+	if got := ss.silencedBy(map[string]string{"alertname": "HostDown"}, now.Add(time.Second)); got != "" {
+		t.Fatalf("expected alert to be not silenced; got silenced by %q", got)
+	}
+	if got := ss.silencedBy(labels, now.Add(2*time.Hour)); got != "" {
+		t.Fatalf("expected silence to expire; got silenced by %q", got)
+	}
+
+	pendingID, err := ss.add(&Silence{
+		Matchers: []string{`alertname="HostDown"`},
+		StartsAt: now.Add(time.Hour),
+		EndsAt:   now.Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := ss.activeCount(now.Add(time.Second)); n != 1 {
+		t.Fatalf("expected 1 active silence; got %d", n)
+	}
+
+	// check silences are restored from disk
+	ss, err = newSilenceStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	list := ss.list(now)
+	if len(list) != 2 {
+		t.Fatalf("expected to restore 2 silences; got %d", len(list))
+	}
+	if list[0].ID != id || list[1].ID != pendingID {
+		t.Fatalf("unexpected order of silences: %q, %q", list[0].ID, list[1].ID)
+	}
+	if list[0].Comment != "maintenance" {
+		t.Fatalf("expected comment to be restored; got %q", list[0].Comment)
+	}
+	if got := ss.silencedBy(labels, now.Add(time.Second)); got != id {
+		t.Fatalf("expected restored silence to mute alert; got %q", got)
+	}
+
+	if err := ss.expire(id); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := ss.silencedBy(labels, now.Add(time.Second)); got != "" {
+		t.Fatalf("expected alert to be not silenced after expiration; got %q", got)
+	}
+	if err := ss.expire(id); err == nil {
+		t.Fatalf("expected error when expiring missing silence")
+	}
+	ss, err = newSilenceStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := len(ss.list(now)); n != 1 {
+		t.Fatalf("expected 1 silence after expiration; got %d", n)
+	}
+}
+
+func TestSilenceStore_AddFailure(t *testing.T) {
+	ss, err := newSilenceStore("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(s *Silence, expErr string) {
+		t.Helper()
+		_, err := ss.add(s)
+		if err == nil {
+			t.Fatalf("expected to get error %q; got nil", expErr)
+		}
+		if !strings.Contains(err.Error(), expErr) {
+			t.Fatalf("expected error to contain %q; got %q", expErr, err)
+		}
+	}
+	now := time.Now()
+	f(&Silence{EndsAt: now.Add(time.Hour)}, "at least one matcher")
+	f(&Silence{Matchers: []string{`foo=~"(bar"`}, EndsAt: now.Add(time.Hour)}, "cannot parse matcher")
+	f(&Silence{Matchers: []string{`foo="bar"`}, StartsAt: now, EndsAt: now.Add(-time.Hour)}, "must be after startsAt")
+	f(&Silence{Matchers: []string{`foo="bar"`}, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)}, "must be in the future")
+}
+
+func TestSilenceStoreLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "silences.json")
+	src, err := newSilenceStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	id, err := src.add(&Silence{Matchers: []string{`alertname="foo"`}, EndsAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// load must replace silences in place, so all the holders of ss see the loaded silences
+	ss, err := newSilenceStore("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := ss.load(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := ss.silencedBy(map[string]string{"alertname": "foo"}, time.Now()); got != id {
+		t.Fatalf("expected alert to be silenced by %q; got %q", id, got)
+	}
+	if err := ss.load(""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := ss.activeCount(time.Now()); n != 0 {
+		t.Fatalf("expected no silences after reset; got %d", n)
+	}
+}
+
+// mustResetSilences removes all the silences from the global silences store.
+func mustResetSilences(t *testing.T) {
+	t.Helper()
+	if err := silences.load(""); err != nil {
+		t.Fatalf("cannot reset silences: %s", err)
+	}
+}
@@ -8,12 +8,14 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/notifier"
 	"github.com/VictoriaMetrics/VictoriaMetrics/app/vmalert/tpl"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/procutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
 )
 
 var (
@@ -23,6 +25,7 @@ var (
 		{"api/v1/rules", "list all loaded groups and rules"},
 		{"api/v1/alerts", "list all active alerts"},
 		{fmt.Sprintf("api/v1/alert?%s=<int>&%s=<int>", paramGroupID, paramAlertID), "get alert status by group and alert ID"},
+		{"api/v1/silences", "list all active and pending silences"},
 	}
 	systemLinks = [][2]string{
 		{"/flags", "command-line flags"},
@@ -34,6 +37,7 @@ var (
 		{Name: "Groups", Url: "groups"},
 		{Name: "Alerts", Url: "alerts"},
 		{Name: "Notifiers", Url: "notifiers"},
+		{Name: "Silences", Url: "silences"},
 		{Name: "Docs", Url: "https://docs.victoriametrics.com/vmalert.html"},
 	}
 )
@@ -80,7 +84,15 @@ func (rh *requestHandler) handler(w http.ResponseWriter, r *http.Request) bool {
 			httpserver.Errorf(w, r, "%s", err)
 			return true
 		}
-		WriteRuleDetails(w, r, rule)
+		var re *APIRuleEval
+		if r.FormValue(paramEval) == "true" {
+			re, err = rh.evalRule(r)
+			if err != nil {
+				httpserver.Errorf(w, r, "%s", err)
+				return true
+			}
+		}
+		WriteRuleDetails(w, r, rule, re)
 		return true
 	case "/vmalert/groups":
 		WriteListGroups(w, r, rh.groups())
@@ -88,6 +100,19 @@ func (rh *requestHandler) handler(w http.ResponseWriter, r *http.Request) bool {
 	case "/vmalert/notifiers":
 		WriteListTargets(w, r, notifier.GetTargets())
 		return true
+	case "/vmalert/silences":
+		if r.Method == http.MethodPost {
+			if err := handleSilenceForm(r); err != nil {
+				httpserver.Errorf(w, r, "%s", err)
+				return true
+			}
+			// redirect to the same page in order to prevent
+			// form re-submission on page refresh
+			http.Redirect(w, r, "silences", http.StatusSeeOther)
+			return true
+		}
+		WriteListSilences(w, r, silences.list(time.Now()))
+		return true
 
 	// special cases for Grafana requests,
 	// served without `vmalert` prefix:
@@ -131,6 +156,36 @@ func (rh *requestHandler) handler(w http.ResponseWriter, r *http.Request) bool {
 		w.Header().Set("Content-Type", "application/json")
 		w.Write(data)
 		return true
+	case "/vmalert/api/v1/silences", "/api/v1/silences":
+		var data []byte
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			data, err = listSilences()
+		case http.MethodPost:
+			data, err = createSilence(r)
+		default:
+			err = errResponse(fmt.Errorf("path %q supports only GET and POST methods", r.URL.Path), http.StatusMethodNotAllowed)
+		}
+		if err != nil {
+			httpserver.Errorf(w, r, "%s", err)
+			return true
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+		return true
+	case "/vmalert/api/v1/silence", "/api/v1/silence":
+		if r.Method != http.MethodDelete {
+			httpserver.Errorf(w, r, "%s", errResponse(fmt.Errorf("path %q supports only DELETE method", r.URL.Path), http.StatusMethodNotAllowed))
+			return true
+		}
+		if err := silences.expire(r.FormValue(paramSilenceID)); err != nil {
+			httpserver.Errorf(w, r, "%s", errResponse(err, http.StatusNotFound))
+			return true
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success"}`))
+		return true
 	case "/-/reload":
 		logger.Infof("api config reload was called, sending sighup")
 		procutil.SelfSIGHUP()
@@ -147,6 +202,9 @@ const (
 	paramGroupID = "group_id"
 	paramAlertID = "alert_id"
 	paramRuleID  = "rule_id"
+	paramEval    = "eval"
+
+	paramSilenceID = "silence_id"
 )
 
 func (rh *requestHandler) getRule(r *http.Request) (APIRule, error) {
@@ -165,6 +223,23 @@ func (rh *requestHandler) getRule(r *http.Request) (APIRule, error) {
 	return rule, nil
 }
 
+// evalRule evaluates the requested rule without changing its state.
+func (rh *requestHandler) evalRule(r *http.Request) (*APIRuleEval, error) {
+	groupID, err := strconv.ParseUint(r.FormValue(paramGroupID), 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q param: %s", paramGroupID, err)
+	}
+	ruleID, err := strconv.ParseUint(r.FormValue(paramRuleID), 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q param: %s", paramRuleID, err)
+	}
+	re, err := rh.m.RuleEval(r.Context(), groupID, ruleID)
+	if err != nil {
+		return nil, errResponse(err, http.StatusNotFound)
+	}
+	return &re, nil
+}
+
 func (rh *requestHandler) getAlert(r *http.Request) (*APIAlert, error) {
 	groupID, err := strconv.ParseUint(r.FormValue(paramGroupID), 10, 0)
 	if err != nil {
@@ -283,6 +358,73 @@ func (rh *requestHandler) listAlerts() ([]byte, error) {
 	return b, nil
 }
 
+type listSilencesResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Silences []*Silence `json:"silences"`
+	} `json:"data"`
+}
+
+func listSilences() ([]byte, error) {
+	lr := listSilencesResponse{Status: "success"}
+	lr.Data.Silences = silences.list(time.Now())
+	b, err := json.Marshal(lr)
+	if err != nil {
+		return nil, &httpserver.ErrorWithStatusCode{
+			Err:        fmt.Errorf(`error encoding list of silences: %w`, err),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return b, nil
+}
+
+type createSilenceResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		SilenceID string `json:"silenceID"`
+	} `json:"data"`
+}
+
+func createSilence(r *http.Request) ([]byte, error) {
+	var s Silence
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		return nil, errResponse(fmt.Errorf("cannot parse silence: %w", err), http.StatusBadRequest)
+	}
+	id, err := silences.add(&s)
+	if err != nil {
+		return nil, errResponse(err, http.StatusBadRequest)
+	}
+	cr := createSilenceResponse{Status: "success"}
+	cr.Data.SilenceID = id
+	return json.Marshal(cr)
+}
+
+// handleSilenceForm creates or expires silence according to the submitted UI form.
+func handleSilenceForm(r *http.Request) error {
+	if r.FormValue("action") == "expire" {
+		return silences.expire(r.FormValue(paramSilenceID))
+	}
+	d, err := promutils.ParseDuration(r.FormValue("duration"))
+	if err != nil {
+		return fmt.Errorf("cannot parse silence duration: %w", err)
+	}
+	var matchers []string
+	for _, line := range strings.Split(r.FormValue("matchers"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			matchers = append(matchers, line)
+		}
+	}
+	now := time.Now()
+	_, err = silences.add(&Silence{
+		Matchers:  matchers,
+		StartsAt:  now,
+		EndsAt:    now.Add(d),
+		CreatedBy: r.FormValue("created_by"),
+		Comment:   r.FormValue("comment"),
+	})
+	return err
+}
+
 func errResponse(err error, sc int) *httpserver.ErrorWithStatusCode {
 	return &httpserver.ErrorWithStatusCode{
 		Err:        err,
@@ -832,158 +832,176 @@ func StreamListAlerts(qw422016 *qt422016.Writer, r *http.Request, groupAlerts []
 					}
 //line app/vmalert/web.qtpl:232
 					qw422016.N().S(`
+                                    `)
+//line app/vmalert/web.qtpl:233
+					if ar.SilencedBy != "" {
+//line app/vmalert/web.qtpl:233
+						streambadgeSilenced(qw422016, ar.SilencedBy)
+//line app/vmalert/web.qtpl:233
+					}
+//line app/vmalert/web.qtpl:233
+					qw422016.N().S(`
+                                    `)
+//line app/vmalert/web.qtpl:234
+					if ar.Inhibited {
+//line app/vmalert/web.qtpl:234
+						streambadgeInhibited(qw422016)
+//line app/vmalert/web.qtpl:234
+					}
+//line app/vmalert/web.qtpl:234
+					qw422016.N().S(`
                                 </td>
                                 <td>`)
-//line app/vmalert/web.qtpl:234
+//line app/vmalert/web.qtpl:236
 					qw422016.E().S(ar.Value)
-//line app/vmalert/web.qtpl:234
+//line app/vmalert/web.qtpl:236
 					qw422016.N().S(`</td>
                                 <td>
                                     <a href="`)
-//line app/vmalert/web.qtpl:236
+//line app/vmalert/web.qtpl:238
 					qw422016.E().S(prefix + ar.WebLink())
-//line app/vmalert/web.qtpl:236
+//line app/vmalert/web.qtpl:238
 					qw422016.N().S(`">Details</a>
                                 </td>
                             </tr>
                         `)
-//line app/vmalert/web.qtpl:239
+//line app/vmalert/web.qtpl:241
 				}
-//line app/vmalert/web.qtpl:239
+//line app/vmalert/web.qtpl:241
 				qw422016.N().S(`
                      </tbody>
                     </table>
                 `)
-//line app/vmalert/web.qtpl:242
+//line app/vmalert/web.qtpl:244
 			}
-//line app/vmalert/web.qtpl:242
+//line app/vmalert/web.qtpl:244
 			qw422016.N().S(`
             </div>
             <br>
         `)
-//line app/vmalert/web.qtpl:245
+//line app/vmalert/web.qtpl:247
 		}
-//line app/vmalert/web.qtpl:245
+//line app/vmalert/web.qtpl:247
 		qw422016.N().S(`
 
     `)
-//line app/vmalert/web.qtpl:247
+//line app/vmalert/web.qtpl:249
 	} else {
-//line app/vmalert/web.qtpl:247
+//line app/vmalert/web.qtpl:249
 		qw422016.N().S(`
         <div>
             <p>No active alerts...</p>
         </div>
     `)
-//line app/vmalert/web.qtpl:251
+//line app/vmalert/web.qtpl:253
 	}
-//line app/vmalert/web.qtpl:251
+//line app/vmalert/web.qtpl:253
 	qw422016.N().S(`
 
     `)
-//line app/vmalert/web.qtpl:253
+//line app/vmalert/web.qtpl:255
 	tpl.StreamFooter(qw422016, r)
-//line app/vmalert/web.qtpl:253
+//line app/vmalert/web.qtpl:255
 	qw422016.N().S(`
 
 `)
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 }
 
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 func WriteListAlerts(qq422016 qtio422016.Writer, r *http.Request, groupAlerts []GroupAlerts) {
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 	StreamListAlerts(qw422016, r, groupAlerts)
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 }
 
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 func ListAlerts(r *http.Request, groupAlerts []GroupAlerts) string {
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 	WriteListAlerts(qb422016, r, groupAlerts)
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 	qs422016 := string(qb422016.B)
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 	return qs422016
-//line app/vmalert/web.qtpl:255
+//line app/vmalert/web.qtpl:257
 }
 
-//line app/vmalert/web.qtpl:257
+//line app/vmalert/web.qtpl:259
 func StreamListTargets(qw422016 *qt422016.Writer, r *http.Request, targets map[notifier.TargetType][]notifier.Target) {
-//line app/vmalert/web.qtpl:257
+//line app/vmalert/web.qtpl:259
 	qw422016.N().S(`
     `)
-//line app/vmalert/web.qtpl:258
+//line app/vmalert/web.qtpl:260
 	tpl.StreamHeader(qw422016, r, navItems, "Notifiers", getLastConfigError())
-//line app/vmalert/web.qtpl:258
+//line app/vmalert/web.qtpl:260
 	qw422016.N().S(`
     `)
-//line app/vmalert/web.qtpl:259
+//line app/vmalert/web.qtpl:261
 	if len(targets) > 0 {
-//line app/vmalert/web.qtpl:259
+//line app/vmalert/web.qtpl:261
 		qw422016.N().S(`
          <a class="btn btn-primary" role="button" onclick="collapseAll()">Collapse All</a>
          <a class="btn btn-primary" role="button" onclick="expandAll()">Expand All</a>
 
          `)
-//line app/vmalert/web.qtpl:264
+//line app/vmalert/web.qtpl:266
 		var keys []string
 		for key := range targets {
 			keys = append(keys, string(key))
 		}
 		sort.Strings(keys)
 
-//line app/vmalert/web.qtpl:269
+//line app/vmalert/web.qtpl:271
 		qw422016.N().S(`
 
          `)
-//line app/vmalert/web.qtpl:271
+//line app/vmalert/web.qtpl:273
 		for i := range keys {
-//line app/vmalert/web.qtpl:271
+//line app/vmalert/web.qtpl:273
 			qw422016.N().S(`
            `)
-//line app/vmalert/web.qtpl:272
+//line app/vmalert/web.qtpl:274
 			typeK, ns := keys[i], targets[notifier.TargetType(keys[i])]
 			count := len(ns)
 
-//line app/vmalert/web.qtpl:274
+//line app/vmalert/web.qtpl:276
 			qw422016.N().S(`
            <div class="group-heading" data-bs-target="notifiers-`)
-//line app/vmalert/web.qtpl:275
+//line app/vmalert/web.qtpl:277
 			qw422016.E().S(typeK)
-//line app/vmalert/web.qtpl:275
+//line app/vmalert/web.qtpl:277
 			qw422016.N().S(`">
              <span class="anchor" id="group-`)
-//line app/vmalert/web.qtpl:276
+//line app/vmalert/web.qtpl:278
 			qw422016.E().S(typeK)
-//line app/vmalert/web.qtpl:276
+//line app/vmalert/web.qtpl:278
 			qw422016.N().S(`"></span>
              <a href="#group-`)
-//line app/vmalert/web.qtpl:277
+//line app/vmalert/web.qtpl:279
 			qw422016.E().S(typeK)
-//line app/vmalert/web.qtpl:277
+//line app/vmalert/web.qtpl:279
 			qw422016.N().S(`">`)
-//line app/vmalert/web.qtpl:277
+//line app/vmalert/web.qtpl:279
 			qw422016.E().S(typeK)
-//line app/vmalert/web.qtpl:277
+//line app/vmalert/web.qtpl:279
 			qw422016.N().S(` (`)
-//line app/vmalert/web.qtpl:277
+//line app/vmalert/web.qtpl:279
 			qw422016.N().D(count)
-//line app/vmalert/web.qtpl:277
+//line app/vmalert/web.qtpl:279
 			qw422016.N().S(`)</a>
          </div>
          <div class="collapse show" id="notifiers-`)
-//line app/vmalert/web.qtpl:279
+//line app/vmalert/web.qtpl:281
 			qw422016.E().S(typeK)
-//line app/vmalert/web.qtpl:279
+//line app/vmalert/web.qtpl:281
 			qw422016.N().S(`">
              <table class="table table-striped table-hover table-sm">
                  <thead>
@@ -994,119 +1012,304 @@ func StreamListTargets(qw422016 *qt422016.Writer, r *http.Request, targets map[n
                  </thead>
                  <tbody>
                  `)
-//line app/vmalert/web.qtpl:288
+//line app/vmalert/web.qtpl:290
 			for _, n := range ns {
-//line app/vmalert/web.qtpl:288
+//line app/vmalert/web.qtpl:290
 				qw422016.N().S(`
                      <tr>
                          <td>
                               `)
-//line app/vmalert/web.qtpl:291
+//line app/vmalert/web.qtpl:293
 				for _, l := range n.Labels.GetLabels() {
-//line app/vmalert/web.qtpl:291
+//line app/vmalert/web.qtpl:293
 					qw422016.N().S(`
                                       <span class="ms-1 badge bg-primary">`)
-//line app/vmalert/web.qtpl:292
+//line app/vmalert/web.qtpl:294
 					qw422016.E().S(l.Name)
-//line app/vmalert/web.qtpl:292
+//line app/vmalert/web.qtpl:294
 					qw422016.N().S(`=`)
-//line app/vmalert/web.qtpl:292
+//line app/vmalert/web.qtpl:294
 					qw422016.E().S(l.Value)
-//line app/vmalert/web.qtpl:292
+//line app/vmalert/web.qtpl:294
 					qw422016.N().S(`</span>
                               `)
-//line app/vmalert/web.qtpl:293
+//line app/vmalert/web.qtpl:295
 				}
-//line app/vmalert/web.qtpl:293
+//line app/vmalert/web.qtpl:295
 				qw422016.N().S(`
                           </td>
                          <td>`)
-//line app/vmalert/web.qtpl:295
+//line app/vmalert/web.qtpl:297
 				qw422016.E().S(n.Notifier.Addr())
-//line app/vmalert/web.qtpl:295
+//line app/vmalert/web.qtpl:297
 				qw422016.N().S(`</td>
                      </tr>
                  `)
-//line app/vmalert/web.qtpl:297
+//line app/vmalert/web.qtpl:299
 			}
-//line app/vmalert/web.qtpl:297
+//line app/vmalert/web.qtpl:299
 			qw422016.N().S(`
               </tbody>
              </table>
          </div>
      `)
-//line app/vmalert/web.qtpl:301
+//line app/vmalert/web.qtpl:303
 		}
-//line app/vmalert/web.qtpl:301
+//line app/vmalert/web.qtpl:303
 		qw422016.N().S(`
 
     `)
-//line app/vmalert/web.qtpl:303
+//line app/vmalert/web.qtpl:305
 	} else {
-//line app/vmalert/web.qtpl:303
+//line app/vmalert/web.qtpl:305
 		qw422016.N().S(`
         <div>
             <p>No targets...</p>
         </div>
     `)
-//line app/vmalert/web.qtpl:307
+//line app/vmalert/web.qtpl:309
 	}
-//line app/vmalert/web.qtpl:307
+//line app/vmalert/web.qtpl:309
 	qw422016.N().S(`
 
     `)
-//line app/vmalert/web.qtpl:309
+//line app/vmalert/web.qtpl:311
 	tpl.StreamFooter(qw422016, r)
-//line app/vmalert/web.qtpl:309
+//line app/vmalert/web.qtpl:311
 	qw422016.N().S(`
 
 `)
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 }
 
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 func WriteListTargets(qq422016 qtio422016.Writer, r *http.Request, targets map[notifier.TargetType][]notifier.Target) {
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 	StreamListTargets(qw422016, r, targets)
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 }
 
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 func ListTargets(r *http.Request, targets map[notifier.TargetType][]notifier.Target) string {
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 	WriteListTargets(qb422016, r, targets)
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 	qs422016 := string(qb422016.B)
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 	return qs422016
-//line app/vmalert/web.qtpl:311
+//line app/vmalert/web.qtpl:313
 }
 
-//line app/vmalert/web.qtpl:313
+//line app/vmalert/web.qtpl:315
+func StreamListSilences(qw422016 *qt422016.Writer, r *http.Request, silences []*Silence) {
+//line app/vmalert/web.qtpl:315
+	qw422016.N().S(`
+    `)
+//line app/vmalert/web.qtpl:316
+	tpl.StreamHeader(qw422016, r, navItems, "Silences", getLastConfigError())
+//line app/vmalert/web.qtpl:316
+	qw422016.N().S(`
+    `)
+//line app/vmalert/web.qtpl:317
+	now := time.Now()
+
+//line app/vmalert/web.qtpl:317
+	qw422016.N().S(`
+    <div class="display-6 pb-3">Create silence:</div>
+    <form method="post" class="mb-4">
+        <input type="hidden" name="action" value="create">
+        <div class="mb-2">
+            <label for="matchers" class="form-label">Matchers (one per line, e.g. <code>alertname="HighLatency"</code> or <code>instance=~"host-.+"</code>)</label>
+            <textarea class="form-control" id="matchers" name="matchers" rows="3" required></textarea>
+        </div>
+        <div class="row mb-2">
+            <div class="col-2">
+                <label for="duration" class="form-label">Duration</label>
+                <input class="form-control" id="duration" name="duration" value="2h" required>
+            </div>
+            <div class="col-3">
+                <label for="created_by" class="form-label">Created by</label>
+                <input class="form-control" id="created_by" name="created_by">
+            </div>
+            <div class="col">
+                <label for="comment" class="form-label">Comment</label>
+                <input class="form-control" id="comment" name="comment">
+            </div>
+        </div>
+        <button type="submit" class="btn btn-primary">Create</button>
+    </form>
+    `)
+//line app/vmalert/web.qtpl:341
+	if len(silences) > 0 {
+//line app/vmalert/web.qtpl:341
+		qw422016.N().S(`
+        <table class="table table-striped table-hover table-sm">
+            <thead>
+                <tr>
+                    <th scope="col">Matchers</th>
+                    <th scope="col" class="text-center">State</th>
+                    <th scope="col" class="text-center">Starts at</th>
+                    <th scope="col" class="text-center">Ends at</th>
+                    <th scope="col">Created by</th>
+                    <th scope="col">Comment</th>
+                    <th scope="col"></th>
+                </tr>
+            </thead>
+            <tbody>
+            `)
+//line app/vmalert/web.qtpl:355
+		for _, s := range silences {
+//line app/vmalert/web.qtpl:355
+			qw422016.N().S(`
+                <tr>
+                    <td>
+                        `)
+//line app/vmalert/web.qtpl:358
+			for _, m := range s.Matchers {
+//line app/vmalert/web.qtpl:358
+				qw422016.N().S(`
+                            <span class="ms-1 badge bg-primary">`)
+//line app/vmalert/web.qtpl:359
+				qw422016.E().S(m)
+//line app/vmalert/web.qtpl:359
+				qw422016.N().S(`</span>
+                        `)
+//line app/vmalert/web.qtpl:360
+			}
+//line app/vmalert/web.qtpl:360
+			qw422016.N().S(`
+                    </td>
+                    <td class="text-center">
+                        `)
+//line app/vmalert/web.qtpl:363
+			if s.IsActive(now) {
+//line app/vmalert/web.qtpl:363
+				qw422016.N().S(`
+                            <span class="badge bg-success">active</span>
+                        `)
+//line app/vmalert/web.qtpl:365
+			} else {
+//line app/vmalert/web.qtpl:365
+				qw422016.N().S(`
+                            <span class="badge bg-secondary">pending</span>
+                        `)
+//line app/vmalert/web.qtpl:367
+			}
+//line app/vmalert/web.qtpl:367
+			qw422016.N().S(`
+                    </td>
+                    <td class="text-center">`)
+//line app/vmalert/web.qtpl:369
+			qw422016.E().S(s.StartsAt.Format(time.RFC3339))
+//line app/vmalert/web.qtpl:369
+			qw422016.N().S(`</td>
+                    <td class="text-center">`)
+//line app/vmalert/web.qtpl:370
+			qw422016.E().S(s.EndsAt.Format(time.RFC3339))
+//line app/vmalert/web.qtpl:370
+			qw422016.N().S(`</td>
+                    <td>`)
+//line app/vmalert/web.qtpl:371
+			qw422016.E().S(s.CreatedBy)
+//line app/vmalert/web.qtpl:371
+			qw422016.N().S(`</td>
+                    <td>`)
+//line app/vmalert/web.qtpl:372
+			qw422016.E().S(s.Comment)
+//line app/vmalert/web.qtpl:372
+			qw422016.N().S(`</td>
+                    <td>
+                        <form method="post">
+                            <input type="hidden" name="action" value="expire">
+                            <input type="hidden" name="silence_id" value="`)
+//line app/vmalert/web.qtpl:376
+			qw422016.E().S(s.ID)
+//line app/vmalert/web.qtpl:376
+			qw422016.N().S(`">
+                            <button type="submit" class="btn btn-sm btn-outline-danger">Expire</button>
+                        </form>
+                    </td>
+                </tr>
+            `)
+//line app/vmalert/web.qtpl:381
+		}
+//line app/vmalert/web.qtpl:381
+		qw422016.N().S(`
+            </tbody>
+        </table>
+    `)
+//line app/vmalert/web.qtpl:384
+	} else {
+//line app/vmalert/web.qtpl:384
+		qw422016.N().S(`
+        <div>
+            <p>No silences...</p>
+        </div>
+    `)
+//line app/vmalert/web.qtpl:388
+	}
+//line app/vmalert/web.qtpl:388
+	qw422016.N().S(`
+    `)
+//line app/vmalert/web.qtpl:389
+	tpl.StreamFooter(qw422016, r)
+//line app/vmalert/web.qtpl:389
+	qw422016.N().S(`
+`)
+//line app/vmalert/web.qtpl:390
+}
+
+//line app/vmalert/web.qtpl:390
+func WriteListSilences(qq422016 qtio422016.Writer, r *http.Request, silences []*Silence) {
+//line app/vmalert/web.qtpl:390
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmalert/web.qtpl:390
+	StreamListSilences(qw422016, r, silences)
+//line app/vmalert/web.qtpl:390
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmalert/web.qtpl:390
+}
+
+//line app/vmalert/web.qtpl:390
+func ListSilences(r *http.Request, silences []*Silence) string {
+//line app/vmalert/web.qtpl:390
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmalert/web.qtpl:390
+	WriteListSilences(qb422016, r, silences)
+//line app/vmalert/web.qtpl:390
+	qs422016 := string(qb422016.B)
+//line app/vmalert/web.qtpl:390
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmalert/web.qtpl:390
+	return qs422016
+//line app/vmalert/web.qtpl:390
+}
+
+//line app/vmalert/web.qtpl:392
 func StreamAlert(qw422016 *qt422016.Writer, r *http.Request, alert *APIAlert) {
-//line app/vmalert/web.qtpl:313
+//line app/vmalert/web.qtpl:392
 	qw422016.N().S(`
     `)
-//line app/vmalert/web.qtpl:314
+//line app/vmalert/web.qtpl:393
 	prefix := utils.Prefix(r.URL.Path)
 
-//line app/vmalert/web.qtpl:314
+//line app/vmalert/web.qtpl:393
 	qw422016.N().S(`
     `)
-//line app/vmalert/web.qtpl:315
+//line app/vmalert/web.qtpl:394
 	tpl.StreamHeader(qw422016, r, navItems, "", getLastConfigError())
-//line app/vmalert/web.qtpl:315
+//line app/vmalert/web.qtpl:394
 	qw422016.N().S(`
     `)
-//line app/vmalert/web.qtpl:317
+//line app/vmalert/web.qtpl:396
 	var labelKeys []string
 	for k := range alert.Labels {
 		labelKeys = append(labelKeys, k)
@@ -1119,28 +1322,28 @@ func StreamAlert(qw422016 *qt422016.Writer, r *http.Request, alert *APIAlert) {
 	}
 	sort.Strings(annotationKeys)
 
-//line app/vmalert/web.qtpl:328
+//line app/vmalert/web.qtpl:407
 	qw422016.N().S(`
     <div class="display-6 pb-3 mb-3">Alert: `)
-//line app/vmalert/web.qtpl:329
+//line app/vmalert/web.qtpl:408
 	qw422016.E().S(alert.Name)
-//line app/vmalert/web.qtpl:329
+//line app/vmalert/web.qtpl:408
 	qw422016.N().S(`<span class="ms-2 badge `)
-//line app/vmalert/web.qtpl:329
+//line app/vmalert/web.qtpl:408
 	if alert.State == "firing" {
-//line app/vmalert/web.qtpl:329
+//line app/vmalert/web.qtpl:408
 		qw422016.N().S(`bg-danger`)
-//line app/vmalert/web.qtpl:329
+//line app/vmalert/web.qtpl:408
 	} else {
-//line app/vmalert/web.qtpl:329
+//line app/vmalert/web.qtpl:408
 		qw422016.N().S(` bg-warning text-dark`)
-//line app/vmalert/web.qtpl:329
+//line app/vmalert/web.qtpl:408
 	}
-//line app/vmalert/web.qtpl:329
+//line app/vmalert/web.qtpl:408
 	qw422016.N().S(`">`)
-//line app/vmalert/web.qtpl:329
+//line app/vmalert/web.qtpl:408
 	qw422016.E().S(alert.State)
-//line app/vmalert/web.qtpl:329
+//line app/vmalert/web.qtpl:408
 	qw422016.N().S(`</span></div>
     <div class="container border-bottom p-2">
       <div class="row">
@@ -1149,9 +1352,27 @@ func StreamAlert(qw422016 *qt422016.Writer, r *http.Request, alert *APIAlert) {
         </div>
         <div class="col">
           `)
-//line app/vmalert/web.qtpl:336
+//line app/vmalert/web.qtpl:415
 	qw422016.E().S(alert.ActiveAt.Format("2006-01-02T15:04:05Z07:00"))
-//line app/vmalert/web.qtpl:336
+//line app/vmalert/web.qtpl:415
+	qw422016.N().S(`
+          `)
+//line app/vmalert/web.qtpl:416
+	if alert.SilencedBy != "" {
+//line app/vmalert/web.qtpl:416
+		streambadgeSilenced(qw422016, alert.SilencedBy)
+//line app/vmalert/web.qtpl:416
+	}
+//line app/vmalert/web.qtpl:416
+	qw422016.N().S(`
+          `)
+//line app/vmalert/web.qtpl:417
+	if alert.Inhibited {
+//line app/vmalert/web.qtpl:417
+		streambadgeInhibited(qw422016)
+//line app/vmalert/web.qtpl:417
+	}
+//line app/vmalert/web.qtpl:417
 	qw422016.N().S(`
         </div>
       </div>
@@ -1163,9 +1384,9 @@ func StreamAlert(qw422016 *qt422016.Writer, r *http.Request, alert *APIAlert) {
         </div>
         <div class="col">
           <code><pre>`)
-//line app/vmalert/web.qtpl:346
+//line app/vmalert/web.qtpl:427
 	qw422016.E().S(alert.Expression)
-//line app/vmalert/web.qtpl:346
+//line app/vmalert/web.qtpl:427
 	qw422016.N().S(`</pre></code>
         </div>
       </div>
@@ -1177,23 +1398,23 @@ func StreamAlert(qw422016 *qt422016.Writer, r *http.Request, alert *APIAlert) {
         </div>
         <div class="col">
            `)
-//line app/vmalert/web.qtpl:356
+//line app/vmalert/web.qtpl:437
 	for _, k := range labelKeys {
-//line app/vmalert/web.qtpl:356
+//line app/vmalert/web.qtpl:437
 		qw422016.N().S(`
                 <span class="m-1 badge bg-primary">`)
-//line app/vmalert/web.qtpl:357
+//line app/vmalert/web.qtpl:438
 		qw422016.E().S(k)
-//line app/vmalert/web.qtpl:357
+//line app/vmalert/web.qtpl:438
 		qw422016.N().S(`=`)
-//line app/vmalert/web.qtpl:357
+//line app/vmalert/web.qtpl:438
 		qw422016.E().S(alert.Labels[k])
-//line app/vmalert/web.qtpl:357
+//line app/vmalert/web.qtpl:438
 		qw422016.N().S(`</span>
           `)
-//line app/vmalert/web.qtpl:358
+//line app/vmalert/web.qtpl:439
 	}
-//line app/vmalert/web.qtpl:358
+//line app/vmalert/web.qtpl:439
 	qw422016.N().S(`
         </div>
       </div>
@@ -1205,24 +1426,24 @@ func StreamAlert(qw422016 *qt422016.Writer, r *http.Request, alert *APIAlert) {
         </div>
         <div class="col">
            `)
-//line app/vmalert/web.qtpl:368
+//line app/vmalert/web.qtpl:449
 	for _, k := range annotationKeys {
-//line app/vmalert/web.qtpl:368
+//line app/vmalert/web.qtpl:449
 		qw422016.N().S(`
                 <b>`)
-//line app/vmalert/web.qtpl:369
+//line app/vmalert/web.qtpl:450
 		qw422016.E().S(k)
-//line app/vmalert/web.qtpl:369
+//line app/vmalert/web.qtpl:450
 		qw422016.N().S(`:</b><br>
                 <p>`)
-//line app/vmalert/web.qtpl:370
+//line app/vmalert/web.qtpl:451
 		qw422016.E().S(alert.Annotations[k])
-//line app/vmalert/web.qtpl:370
+//line app/vmalert/web.qtpl:451
 		qw422016.N().S(`</p>
           `)
-//line app/vmalert/web.qtpl:371
+//line app/vmalert/web.qtpl:452
 	}
-//line app/vmalert/web.qtpl:371
+//line app/vmalert/web.qtpl:452
 	qw422016.N().S(`
         </div>
       </div>
@@ -1234,17 +1455,17 @@ func StreamAlert(qw422016 *qt422016.Writer, r *http.Request, alert *APIAlert) {
         </div>
         <div class="col">
            <a target="_blank" href="`)
-//line app/vmalert/web.qtpl:381
+//line app/vmalert/web.qtpl:462
 	qw422016.E().S(prefix)
-//line app/vmalert/web.qtpl:381
+//line app/vmalert/web.qtpl:462
 	qw422016.N().S(`groups#group-`)
-//line app/vmalert/web.qtpl:381
+//line app/vmalert/web.qtpl:462
 	qw422016.E().S(alert.GroupID)
-//line app/vmalert/web.qtpl:381
+//line app/vmalert/web.qtpl:462
 	qw422016.N().S(`">`)
-//line app/vmalert/web.qtpl:381
+//line app/vmalert/web.qtpl:462
 	qw422016.E().S(alert.GroupID)
-//line app/vmalert/web.qtpl:381
+//line app/vmalert/web.qtpl:462
 	qw422016.N().S(`</a>
         </div>
       </div>
@@ -1256,66 +1477,66 @@ func StreamAlert(qw422016 *qt422016.Writer, r *http.Request, alert *APIAlert) {
         </div>
         <div class="col">
            <a target="_blank" href="`)
-//line app/vmalert/web.qtpl:391
+//line app/vmalert/web.qtpl:472
 	qw422016.E().S(alert.SourceLink)
-//line app/vmalert/web.qtpl:391
+//line app/vmalert/web.qtpl:472
 	qw422016.N().S(`">Link</a>
         </div>
       </div>
     </div>
     `)
-//line app/vmalert/web.qtpl:395
+//line app/vmalert/web.qtpl:476
 	tpl.StreamFooter(qw422016, r)
-//line app/vmalert/web.qtpl:395
+//line app/vmalert/web.qtpl:476
 	qw422016.N().S(`
 
 `)
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 }
 
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 func WriteAlert(qq422016 qtio422016.Writer, r *http.Request, alert *APIAlert) {
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 	StreamAlert(qw422016, r, alert)
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 }
 
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 func Alert(r *http.Request, alert *APIAlert) string {
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 	WriteAlert(qb422016, r, alert)
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 	qs422016 := string(qb422016.B)
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 	return qs422016
-//line app/vmalert/web.qtpl:397
+//line app/vmalert/web.qtpl:478
 }
 
-//line app/vmalert/web.qtpl:400
-func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule) {
-//line app/vmalert/web.qtpl:400
+//line app/vmalert/web.qtpl:481
+func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule, re *APIRuleEval) {
+//line app/vmalert/web.qtpl:481
 	qw422016.N().S(`
     `)
-//line app/vmalert/web.qtpl:401
+//line app/vmalert/web.qtpl:482
 	prefix := utils.Prefix(r.URL.Path)
 
-//line app/vmalert/web.qtpl:401
+//line app/vmalert/web.qtpl:482
 	qw422016.N().S(`
     `)
-//line app/vmalert/web.qtpl:402
+//line app/vmalert/web.qtpl:483
 	tpl.StreamHeader(qw422016, r, navItems, "", getLastConfigError())
-//line app/vmalert/web.qtpl:402
+//line app/vmalert/web.qtpl:483
 	qw422016.N().S(`
     `)
-//line app/vmalert/web.qtpl:404
+//line app/vmalert/web.qtpl:485
 	var labelKeys []string
 	for k := range rule.Labels {
 		labelKeys = append(labelKeys, k)
@@ -1339,29 +1560,35 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
 		}
 	}
 
-//line app/vmalert/web.qtpl:427
+//line app/vmalert/web.qtpl:508
 	qw422016.N().S(`
     <div class="display-6 pb-3 mb-3">Rule: `)
-//line app/vmalert/web.qtpl:428
+//line app/vmalert/web.qtpl:509
 	qw422016.E().S(rule.Name)
-//line app/vmalert/web.qtpl:428
+//line app/vmalert/web.qtpl:509
 	qw422016.N().S(`<span class="ms-2 badge `)
-//line app/vmalert/web.qtpl:428
+//line app/vmalert/web.qtpl:509
 	if rule.Health != "ok" {
-//line app/vmalert/web.qtpl:428
+//line app/vmalert/web.qtpl:509
 		qw422016.N().S(`bg-danger`)
-//line app/vmalert/web.qtpl:428
+//line app/vmalert/web.qtpl:509
 	} else {
-//line app/vmalert/web.qtpl:428
+//line app/vmalert/web.qtpl:509
 		qw422016.N().S(` bg-success text-dark`)
-//line app/vmalert/web.qtpl:428
+//line app/vmalert/web.qtpl:509
 	}
-//line app/vmalert/web.qtpl:428
+//line app/vmalert/web.qtpl:509
 	qw422016.N().S(`">`)
-//line app/vmalert/web.qtpl:428
+//line app/vmalert/web.qtpl:509
 	qw422016.E().S(rule.Health)
-//line app/vmalert/web.qtpl:428
-	qw422016.N().S(`</span></div>
+//line app/vmalert/web.qtpl:509
+	qw422016.N().S(`</span>
+        <a class="btn btn-sm btn-outline-primary ms-2" href="`)
+//line app/vmalert/web.qtpl:510
+	qw422016.E().S(prefix + rule.EvalLink())
+//line app/vmalert/web.qtpl:510
+	qw422016.N().S(`" title="Evaluate the rule now without changing its state">Evaluate now</a>
+    </div>
     <div class="container border-bottom p-2">
       <div class="row">
         <div class="col-2">
@@ -1369,17 +1596,17 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
         </div>
         <div class="col">
           <code><pre>`)
-//line app/vmalert/web.qtpl:435
+//line app/vmalert/web.qtpl:518
 	qw422016.E().S(rule.Query)
-//line app/vmalert/web.qtpl:435
+//line app/vmalert/web.qtpl:518
 	qw422016.N().S(`</pre></code>
         </div>
       </div>
     </div>
     `)
-//line app/vmalert/web.qtpl:439
+//line app/vmalert/web.qtpl:522
 	if rule.Type == "alerting" {
-//line app/vmalert/web.qtpl:439
+//line app/vmalert/web.qtpl:522
 		qw422016.N().S(`
     <div class="container border-bottom p-2">
       <div class="row">
@@ -1388,17 +1615,17 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
         </div>
         <div class="col">
          `)
-//line app/vmalert/web.qtpl:446
+//line app/vmalert/web.qtpl:529
 		qw422016.E().V(rule.Duration)
-//line app/vmalert/web.qtpl:446
+//line app/vmalert/web.qtpl:529
 		qw422016.N().S(` seconds
         </div>
       </div>
     </div>
     `)
-//line app/vmalert/web.qtpl:450
+//line app/vmalert/web.qtpl:533
 		if rule.KeepFiringFor > 0 {
-//line app/vmalert/web.qtpl:450
+//line app/vmalert/web.qtpl:533
 			qw422016.N().S(`
     <div class="container border-bottom p-2">
       <div class="row">
@@ -1407,22 +1634,22 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
         </div>
         <div class="col">
          `)
-//line app/vmalert/web.qtpl:457
+//line app/vmalert/web.qtpl:540
 			qw422016.E().V(rule.KeepFiringFor)
-//line app/vmalert/web.qtpl:457
+//line app/vmalert/web.qtpl:540
 			qw422016.N().S(` seconds
         </div>
       </div>
     </div>
     `)
-//line app/vmalert/web.qtpl:461
+//line app/vmalert/web.qtpl:544
 		}
-//line app/vmalert/web.qtpl:461
+//line app/vmalert/web.qtpl:544
 		qw422016.N().S(`
     `)
-//line app/vmalert/web.qtpl:462
+//line app/vmalert/web.qtpl:545
 	}
-//line app/vmalert/web.qtpl:462
+//line app/vmalert/web.qtpl:545
 	qw422016.N().S(`
     <div class="container border-bottom p-2">
       <div class="row">
@@ -1431,31 +1658,31 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
         </div>
         <div class="col">
           `)
-//line app/vmalert/web.qtpl:469
+//line app/vmalert/web.qtpl:552
 	for _, k := range labelKeys {
-//line app/vmalert/web.qtpl:469
+//line app/vmalert/web.qtpl:552
 		qw422016.N().S(`
                 <span class="m-1 badge bg-primary">`)
-//line app/vmalert/web.qtpl:470
+//line app/vmalert/web.qtpl:553
 		qw422016.E().S(k)
-//line app/vmalert/web.qtpl:470
+//line app/vmalert/web.qtpl:553
 		qw422016.N().S(`=`)
-//line app/vmalert/web.qtpl:470
+//line app/vmalert/web.qtpl:553
 		qw422016.E().S(rule.Labels[k])
-//line app/vmalert/web.qtpl:470
+//line app/vmalert/web.qtpl:553
 		qw422016.N().S(`</span>
           `)
-//line app/vmalert/web.qtpl:471
+//line app/vmalert/web.qtpl:554
 	}
-//line app/vmalert/web.qtpl:471
+//line app/vmalert/web.qtpl:554
 	qw422016.N().S(`
         </div>
       </div>
     </div>
     `)
-//line app/vmalert/web.qtpl:475
+//line app/vmalert/web.qtpl:558
 	if rule.Type == "alerting" {
-//line app/vmalert/web.qtpl:475
+//line app/vmalert/web.qtpl:558
 		qw422016.N().S(`
     <div class="container border-bottom p-2">
       <div class="row">
@@ -1464,24 +1691,24 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
         </div>
         <div class="col">
           `)
-//line app/vmalert/web.qtpl:482
+//line app/vmalert/web.qtpl:565
 		for _, k := range annotationKeys {
-//line app/vmalert/web.qtpl:482
+//line app/vmalert/web.qtpl:565
 			qw422016.N().S(`
                 <b>`)
-//line app/vmalert/web.qtpl:483
+//line app/vmalert/web.qtpl:566
 			qw422016.E().S(k)
-//line app/vmalert/web.qtpl:483
+//line app/vmalert/web.qtpl:566
 			qw422016.N().S(`:</b><br>
                 <p>`)
-//line app/vmalert/web.qtpl:484
+//line app/vmalert/web.qtpl:567
 			qw422016.E().S(rule.Annotations[k])
-//line app/vmalert/web.qtpl:484
+//line app/vmalert/web.qtpl:567
 			qw422016.N().S(`</p>
           `)
-//line app/vmalert/web.qtpl:485
+//line app/vmalert/web.qtpl:568
 		}
-//line app/vmalert/web.qtpl:485
+//line app/vmalert/web.qtpl:568
 		qw422016.N().S(`
         </div>
       </div>
@@ -1493,17 +1720,17 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
         </div>
         <div class="col">
            `)
-//line app/vmalert/web.qtpl:495
+//line app/vmalert/web.qtpl:578
 		qw422016.E().V(rule.Debug)
-//line app/vmalert/web.qtpl:495
+//line app/vmalert/web.qtpl:578
 		qw422016.N().S(`
         </div>
       </div>
     </div>
     `)
-//line app/vmalert/web.qtpl:499
+//line app/vmalert/web.qtpl:582
 	}
-//line app/vmalert/web.qtpl:499
+//line app/vmalert/web.qtpl:582
 	qw422016.N().S(`
     <div class="container border-bottom p-2">
       <div class="row">
@@ -1512,17 +1739,17 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
         </div>
         <div class="col">
            <a target="_blank" href="`)
-//line app/vmalert/web.qtpl:506
+//line app/vmalert/web.qtpl:589
 	qw422016.E().S(prefix)
-//line app/vmalert/web.qtpl:506
+//line app/vmalert/web.qtpl:589
 	qw422016.N().S(`groups#group-`)
-//line app/vmalert/web.qtpl:506
+//line app/vmalert/web.qtpl:589
 	qw422016.E().S(rule.GroupID)
-//line app/vmalert/web.qtpl:506
+//line app/vmalert/web.qtpl:589
 	qw422016.N().S(`">`)
-//line app/vmalert/web.qtpl:506
+//line app/vmalert/web.qtpl:589
 	qw422016.E().S(rule.GroupID)
-//line app/vmalert/web.qtpl:506
+//line app/vmalert/web.qtpl:589
 	qw422016.N().S(`</a>
         </div>
       </div>
@@ -1530,9 +1757,9 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
 
     <br>
     `)
-//line app/vmalert/web.qtpl:512
+//line app/vmalert/web.qtpl:595
 	if seriesFetchedWarning {
-//line app/vmalert/web.qtpl:512
+//line app/vmalert/web.qtpl:595
 		qw422016.N().S(`
     <div class="alert alert-warning" role="alert">
        <strong>Warning:</strong> some of updates have "Series fetched" equal to 0.<br>
@@ -1546,18 +1773,33 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
        See more details about this detection <a target="_blank" href="https://github.com/VictoriaMetrics/VictoriaMetrics/issues/4039">here</a>.
     </div>
     `)
-//line app/vmalert/web.qtpl:524
+//line app/vmalert/web.qtpl:607
+	}
+//line app/vmalert/web.qtpl:607
+	qw422016.N().S(`
+    `)
+//line app/vmalert/web.qtpl:608
+	if re != nil {
+//line app/vmalert/web.qtpl:608
+		qw422016.N().S(`
+        `)
+//line app/vmalert/web.qtpl:609
+		streamruleEval(qw422016, rule, re)
+//line app/vmalert/web.qtpl:609
+		qw422016.N().S(`
+    `)
+//line app/vmalert/web.qtpl:610
 	}
-//line app/vmalert/web.qtpl:524
+//line app/vmalert/web.qtpl:610
 	qw422016.N().S(`
     <div class="display-6 pb-3">Last `)
-//line app/vmalert/web.qtpl:525
+//line app/vmalert/web.qtpl:611
 	qw422016.N().D(len(rule.Updates))
-//line app/vmalert/web.qtpl:525
+//line app/vmalert/web.qtpl:611
 	qw422016.N().S(`/`)
-//line app/vmalert/web.qtpl:525
+//line app/vmalert/web.qtpl:611
 	qw422016.N().D(rule.MaxUpdates)
-//line app/vmalert/web.qtpl:525
+//line app/vmalert/web.qtpl:611
 	qw422016.N().S(` updates</span>:</div>
         <table class="table table-striped table-hover table-sm">
             <thead>
@@ -1565,13 +1807,13 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
                     <th scope="col" title="The time when event was created">Updated at</th>
                     <th scope="col" style="width: 10%" class="text-center" title="How many samples were returned">Samples</th>
                     `)
-//line app/vmalert/web.qtpl:531
+//line app/vmalert/web.qtpl:617
 	if seriesFetchedEnabled {
-//line app/vmalert/web.qtpl:531
+//line app/vmalert/web.qtpl:617
 		qw422016.N().S(`<th scope="col" style="width: 10%" class="text-center" title="How many series were scanned by datasource during the evaluation">Series fetched</th>`)
-//line app/vmalert/web.qtpl:531
+//line app/vmalert/web.qtpl:617
 	}
-//line app/vmalert/web.qtpl:531
+//line app/vmalert/web.qtpl:617
 	qw422016.N().S(`
                     <th scope="col" style="width: 10%" class="text-center" title="How many seconds request took">Duration</th>
                     <th scope="col" class="text-center" title="Time used for rule execution">Executed at</th>
@@ -1581,285 +1823,689 @@ func StreamRuleDetails(qw422016 *qt422016.Writer, r *http.Request, rule APIRule)
             <tbody>
 
      `)
-//line app/vmalert/web.qtpl:539
+//line app/vmalert/web.qtpl:625
 	for _, u := range rule.Updates {
-//line app/vmalert/web.qtpl:539
+//line app/vmalert/web.qtpl:625
 		qw422016.N().S(`
              <tr`)
-//line app/vmalert/web.qtpl:540
+//line app/vmalert/web.qtpl:626
 		if u.err != nil {
-//line app/vmalert/web.qtpl:540
+//line app/vmalert/web.qtpl:626
 			qw422016.N().S(` class="alert-danger"`)
-//line app/vmalert/web.qtpl:540
+//line app/vmalert/web.qtpl:626
 		}
-//line app/vmalert/web.qtpl:540
+//line app/vmalert/web.qtpl:626
 		qw422016.N().S(`>
                  <td>
                     <span class="badge bg-primary rounded-pill me-3" title="Updated at">`)
-//line app/vmalert/web.qtpl:542
+//line app/vmalert/web.qtpl:628
 		qw422016.E().S(u.time.Format(time.RFC3339))
-//line app/vmalert/web.qtpl:542
+//line app/vmalert/web.qtpl:628
 		qw422016.N().S(`</span>
                  </td>
                  <td class="text-center">`)
-//line app/vmalert/web.qtpl:544
+//line app/vmalert/web.qtpl:630
 		qw422016.N().D(u.samples)
-//line app/vmalert/web.qtpl:544
+//line app/vmalert/web.qtpl:630
 		qw422016.N().S(`</td>
                  `)
-//line app/vmalert/web.qtpl:545
+//line app/vmalert/web.qtpl:631
 		if seriesFetchedEnabled {
-//line app/vmalert/web.qtpl:545
+//line app/vmalert/web.qtpl:631
 			qw422016.N().S(`<td class="text-center">`)
-//line app/vmalert/web.qtpl:545
+//line app/vmalert/web.qtpl:631
 			if u.seriesFetched != nil {
-//line app/vmalert/web.qtpl:545
+//line app/vmalert/web.qtpl:631
 				qw422016.N().D(*u.seriesFetched)
-//line app/vmalert/web.qtpl:545
+//line app/vmalert/web.qtpl:631
 			}
-//line app/vmalert/web.qtpl:545
+//line app/vmalert/web.qtpl:631
 			qw422016.N().S(`</td>`)
-//line app/vmalert/web.qtpl:545
+//line app/vmalert/web.qtpl:631
 		}
-//line app/vmalert/web.qtpl:545
+//line app/vmalert/web.qtpl:631
 		qw422016.N().S(`
                  <td class="text-center">`)
-//line app/vmalert/web.qtpl:546
+//line app/vmalert/web.qtpl:632
 		qw422016.N().FPrec(u.duration.Seconds(), 3)
-//line app/vmalert/web.qtpl:546
+//line app/vmalert/web.qtpl:632
 		qw422016.N().S(`s</td>
                  <td class="text-center">`)
-//line app/vmalert/web.qtpl:547
+//line app/vmalert/web.qtpl:633
 		qw422016.E().S(u.at.Format(time.RFC3339))
-//line app/vmalert/web.qtpl:547
+//line app/vmalert/web.qtpl:633
 		qw422016.N().S(`</td>
                  <td>
                     <textarea class="curl-area" rows="1" onclick="this.focus();this.select()">`)
-//line app/vmalert/web.qtpl:549
+//line app/vmalert/web.qtpl:635
 		qw422016.E().S(u.curl)
-//line app/vmalert/web.qtpl:549
+//line app/vmalert/web.qtpl:635
 		qw422016.N().S(`</textarea>
                 </td>
              </tr>
           </li>
           `)
-//line app/vmalert/web.qtpl:553
+//line app/vmalert/web.qtpl:639
 		if u.err != nil {
-//line app/vmalert/web.qtpl:553
+//line app/vmalert/web.qtpl:639
 			qw422016.N().S(`
              <tr`)
-//line app/vmalert/web.qtpl:554
+//line app/vmalert/web.qtpl:640
 			if u.err != nil {
-//line app/vmalert/web.qtpl:554
+//line app/vmalert/web.qtpl:640
 				qw422016.N().S(` class="alert-danger"`)
-//line app/vmalert/web.qtpl:554
+//line app/vmalert/web.qtpl:640
 			}
-//line app/vmalert/web.qtpl:554
+//line app/vmalert/web.qtpl:640
 			qw422016.N().S(`>
                <td colspan="`)
-//line app/vmalert/web.qtpl:555
+//line app/vmalert/web.qtpl:641
 			if seriesFetchedEnabled {
-//line app/vmalert/web.qtpl:555
+//line app/vmalert/web.qtpl:641
 				qw422016.N().S(`6`)
-//line app/vmalert/web.qtpl:555
+//line app/vmalert/web.qtpl:641
 			} else {
-//line app/vmalert/web.qtpl:555
+//line app/vmalert/web.qtpl:641
 				qw422016.N().S(`5`)
-//line app/vmalert/web.qtpl:555
+//line app/vmalert/web.qtpl:641
 			}
-//line app/vmalert/web.qtpl:555
+//line app/vmalert/web.qtpl:641
 			qw422016.N().S(`">
                    <span class="alert-danger">`)
-//line app/vmalert/web.qtpl:556
+//line app/vmalert/web.qtpl:642
 			qw422016.E().V(u.err)
-//line app/vmalert/web.qtpl:556
+//line app/vmalert/web.qtpl:642
 			qw422016.N().S(`</span>
                </td>
              </tr>
           `)
-//line app/vmalert/web.qtpl:559
+//line app/vmalert/web.qtpl:645
 		}
-//line app/vmalert/web.qtpl:559
+//line app/vmalert/web.qtpl:645
 		qw422016.N().S(`
      `)
-//line app/vmalert/web.qtpl:560
+//line app/vmalert/web.qtpl:646
 	}
-//line app/vmalert/web.qtpl:560
+//line app/vmalert/web.qtpl:646
 	qw422016.N().S(`
 
     `)
-//line app/vmalert/web.qtpl:562
+//line app/vmalert/web.qtpl:648
 	tpl.StreamFooter(qw422016, r)
-//line app/vmalert/web.qtpl:562
+//line app/vmalert/web.qtpl:648
 	qw422016.N().S(`
 `)
-//line app/vmalert/web.qtpl:563
+//line app/vmalert/web.qtpl:649
 }
 
-//line app/vmalert/web.qtpl:563
-func WriteRuleDetails(qq422016 qtio422016.Writer, r *http.Request, rule APIRule) {
-//line app/vmalert/web.qtpl:563
+//line app/vmalert/web.qtpl:649
+func WriteRuleDetails(qq422016 qtio422016.Writer, r *http.Request, rule APIRule, re *APIRuleEval) {
+//line app/vmalert/web.qtpl:649
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmalert/web.qtpl:563
-	StreamRuleDetails(qw422016, r, rule)
-//line app/vmalert/web.qtpl:563
+//line app/vmalert/web.qtpl:649
+	StreamRuleDetails(qw422016, r, rule, re)
+//line app/vmalert/web.qtpl:649
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmalert/web.qtpl:563
+//line app/vmalert/web.qtpl:649
 }
 
-//line app/vmalert/web.qtpl:563
-func RuleDetails(r *http.Request, rule APIRule) string {
-//line app/vmalert/web.qtpl:563
+//line app/vmalert/web.qtpl:649
+func RuleDetails(r *http.Request, rule APIRule, re *APIRuleEval) string {
+//line app/vmalert/web.qtpl:649
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmalert/web.qtpl:563
-	WriteRuleDetails(qb422016, r, rule)
-//line app/vmalert/web.qtpl:563
+//line app/vmalert/web.qtpl:649
+	WriteRuleDetails(qb422016, r, rule, re)
+//line app/vmalert/web.qtpl:649
 	qs422016 := string(qb422016.B)
-//line app/vmalert/web.qtpl:563
+//line app/vmalert/web.qtpl:649
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmalert/web.qtpl:563
+//line app/vmalert/web.qtpl:649
 	return qs422016
-//line app/vmalert/web.qtpl:563
+//line app/vmalert/web.qtpl:649
 }
 
-//line app/vmalert/web.qtpl:567
+//line app/vmalert/web.qtpl:653
+func streamruleEval(qw422016 *qt422016.Writer, rule APIRule, re *APIRuleEval) {
+//line app/vmalert/web.qtpl:653
+	qw422016.N().S(`
+    <div class="display-6 pb-3">Evaluation at `)
+//line app/vmalert/web.qtpl:654
+	qw422016.E().S(re.At.Format(time.RFC3339))
+//line app/vmalert/web.qtpl:654
+	qw422016.N().S(`:</div>
+    <p class="text-muted">The result of on-demand evaluation isn't stored and doesn't affect the rule's state.</p>
+    `)
+//line app/vmalert/web.qtpl:656
+	if re.Error != "" {
+//line app/vmalert/web.qtpl:656
+		qw422016.N().S(`
+    <div class="alert alert-danger" role="alert">`)
+//line app/vmalert/web.qtpl:657
+		qw422016.E().S(re.Error)
+//line app/vmalert/web.qtpl:657
+		qw422016.N().S(`</div>
+    `)
+//line app/vmalert/web.qtpl:658
+	}
+//line app/vmalert/web.qtpl:658
+	qw422016.N().S(`
+    <div class="container border-bottom p-2">
+      <div class="row">
+        <div class="col-2">
+          Duration
+        </div>
+        <div class="col">
+          `)
+//line app/vmalert/web.qtpl:665
+	qw422016.N().FPrec(re.Duration.Seconds(), 3)
+//line app/vmalert/web.qtpl:665
+	qw422016.N().S(`s
+        </div>
+      </div>
+    </div>
+    `)
+//line app/vmalert/web.qtpl:669
+	if re.SeriesFetched != nil {
+//line app/vmalert/web.qtpl:669
+		qw422016.N().S(`
+    <div class="container border-bottom p-2">
+      <div class="row">
+        <div class="col-2">
+          Series fetched
+        </div>
+        <div class="col">
+          `)
+//line app/vmalert/web.qtpl:676
+		qw422016.N().D(*re.SeriesFetched)
+//line app/vmalert/web.qtpl:676
+		qw422016.N().S(`
+        </div>
+      </div>
+    </div>
+    `)
+//line app/vmalert/web.qtpl:680
+	}
+//line app/vmalert/web.qtpl:680
+	qw422016.N().S(`
+    <div class="container border-bottom p-2">
+      <div class="row">
+        <div class="col-2">
+          cURL
+        </div>
+        <div class="col">
+          <textarea class="curl-area" rows="1" onclick="this.focus();this.select()">`)
+//line app/vmalert/web.qtpl:687
+	qw422016.E().S(re.Curl)
+//line app/vmalert/web.qtpl:687
+	qw422016.N().S(`</textarea>
+        </div>
+      </div>
+    </div>
+    <br>
+    <table class="table table-striped table-hover table-sm">
+        <thead>
+            <tr>
+                <th scope="col" title="Labels of time series returned by datasource">Series</th>
+                <th scope="col" style="width: 10%" class="text-center" title="Time series value">Value</th>
+                <th scope="col" title="Resulting labels after applying rule's labels">Labels</th>
+                `)
+//line app/vmalert/web.qtpl:698
+	if rule.Type == "alerting" {
+//line app/vmalert/web.qtpl:698
+		qw422016.N().S(`<th scope="col" title="Rule's annotations expanded for the series">Annotations</th>`)
+//line app/vmalert/web.qtpl:698
+	}
+//line app/vmalert/web.qtpl:698
+	qw422016.N().S(`
+            </tr>
+        </thead>
+        <tbody>
+        `)
+//line app/vmalert/web.qtpl:702
+	for _, s := range re.Series {
+//line app/vmalert/web.qtpl:702
+		qw422016.N().S(`
+            <tr`)
+//line app/vmalert/web.qtpl:703
+		if s.Error != "" {
+//line app/vmalert/web.qtpl:703
+			qw422016.N().S(` class="alert-danger"`)
+//line app/vmalert/web.qtpl:703
+		}
+//line app/vmalert/web.qtpl:703
+		qw422016.N().S(`>
+                <td>`)
+//line app/vmalert/web.qtpl:704
+		streamlabelBadges(qw422016, s.Metric, "bg-secondary")
+//line app/vmalert/web.qtpl:704
+		qw422016.N().S(`</td>
+                <td class="text-center">`)
+//line app/vmalert/web.qtpl:705
+		qw422016.E().S(s.Value)
+//line app/vmalert/web.qtpl:705
+		qw422016.N().S(`</td>
+                <td>`)
+//line app/vmalert/web.qtpl:706
+		streamlabelBadges(qw422016, s.Labels, "bg-primary")
+//line app/vmalert/web.qtpl:706
+		qw422016.N().S(`</td>
+                `)
+//line app/vmalert/web.qtpl:707
+		if rule.Type == "alerting" {
+//line app/vmalert/web.qtpl:707
+			qw422016.N().S(`
+                <td>
+                `)
+//line app/vmalert/web.qtpl:710
+			var annotationKeys []string
+			for k := range s.Annotations {
+				annotationKeys = append(annotationKeys, k)
+			}
+			sort.Strings(annotationKeys)
+
+//line app/vmalert/web.qtpl:715
+			qw422016.N().S(`
+                `)
+//line app/vmalert/web.qtpl:716
+			for _, k := range annotationKeys {
+//line app/vmalert/web.qtpl:716
+				qw422016.N().S(`
+                    <b>`)
+//line app/vmalert/web.qtpl:717
+				qw422016.E().S(k)
+//line app/vmalert/web.qtpl:717
+				qw422016.N().S(`:</b><br>
+                    <p>`)
+//line app/vmalert/web.qtpl:718
+				qw422016.E().S(s.Annotations[k])
+//line app/vmalert/web.qtpl:718
+				qw422016.N().S(`</p>
+                `)
+//line app/vmalert/web.qtpl:719
+			}
+//line app/vmalert/web.qtpl:719
+			qw422016.N().S(`
+                </td>
+                `)
+//line app/vmalert/web.qtpl:721
+		}
+//line app/vmalert/web.qtpl:721
+		qw422016.N().S(`
+            </tr>
+            `)
+//line app/vmalert/web.qtpl:723
+		if s.Error != "" {
+//line app/vmalert/web.qtpl:723
+			qw422016.N().S(`
+            <tr class="alert-danger">
+                <td colspan="`)
+//line app/vmalert/web.qtpl:725
+			if rule.Type == "alerting" {
+//line app/vmalert/web.qtpl:725
+				qw422016.N().S(`4`)
+//line app/vmalert/web.qtpl:725
+			} else {
+//line app/vmalert/web.qtpl:725
+				qw422016.N().S(`3`)
+//line app/vmalert/web.qtpl:725
+			}
+//line app/vmalert/web.qtpl:725
+			qw422016.N().S(`">
+                    <span class="alert-danger">`)
+//line app/vmalert/web.qtpl:726
+			qw422016.E().S(s.Error)
+//line app/vmalert/web.qtpl:726
+			qw422016.N().S(`</span>
+                </td>
+            </tr>
+            `)
+//line app/vmalert/web.qtpl:729
+		}
+//line app/vmalert/web.qtpl:729
+		qw422016.N().S(`
+        `)
+//line app/vmalert/web.qtpl:730
+	}
+//line app/vmalert/web.qtpl:730
+	qw422016.N().S(`
+        </tbody>
+    </table>
+    `)
+//line app/vmalert/web.qtpl:733
+	if len(re.Series) == 0 && re.Error == "" {
+//line app/vmalert/web.qtpl:733
+		qw422016.N().S(`
+    <p>The rule's expression returned no time series.</p>
+    `)
+//line app/vmalert/web.qtpl:735
+	}
+//line app/vmalert/web.qtpl:735
+	qw422016.N().S(`
+    `)
+//line app/vmalert/web.qtpl:736
+	if re.Trace != "" {
+//line app/vmalert/web.qtpl:736
+		qw422016.N().S(`
+    <div class="display-6 pb-3">Query trace:</div>
+    <code><pre>`)
+//line app/vmalert/web.qtpl:738
+		qw422016.E().S(re.Trace)
+//line app/vmalert/web.qtpl:738
+		qw422016.N().S(`</pre></code>
+    `)
+//line app/vmalert/web.qtpl:739
+	}
+//line app/vmalert/web.qtpl:739
+	qw422016.N().S(`
+`)
+//line app/vmalert/web.qtpl:740
+}
+
+//line app/vmalert/web.qtpl:740
+func writeruleEval(qq422016 qtio422016.Writer, rule APIRule, re *APIRuleEval) {
+//line app/vmalert/web.qtpl:740
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmalert/web.qtpl:740
+	streamruleEval(qw422016, rule, re)
+//line app/vmalert/web.qtpl:740
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmalert/web.qtpl:740
+}
+
+//line app/vmalert/web.qtpl:740
+func ruleEval(rule APIRule, re *APIRuleEval) string {
+//line app/vmalert/web.qtpl:740
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmalert/web.qtpl:740
+	writeruleEval(qb422016, rule, re)
+//line app/vmalert/web.qtpl:740
+	qs422016 := string(qb422016.B)
+//line app/vmalert/web.qtpl:740
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmalert/web.qtpl:740
+	return qs422016
+//line app/vmalert/web.qtpl:740
+}
+
+//line app/vmalert/web.qtpl:742
+func streamlabelBadges(qw422016 *qt422016.Writer, labels map[string]string, badgeClass string) {
+//line app/vmalert/web.qtpl:742
+	qw422016.N().S(`
+`)
+//line app/vmalert/web.qtpl:744
+	var keys []string
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+//line app/vmalert/web.qtpl:749
+	qw422016.N().S(`
+`)
+//line app/vmalert/web.qtpl:750
+	for _, k := range keys {
+//line app/vmalert/web.qtpl:750
+		qw422016.N().S(`
+    <span class="m-1 badge `)
+//line app/vmalert/web.qtpl:751
+		qw422016.E().S(badgeClass)
+//line app/vmalert/web.qtpl:751
+		qw422016.N().S(`">`)
+//line app/vmalert/web.qtpl:751
+		qw422016.E().S(k)
+//line app/vmalert/web.qtpl:751
+		qw422016.N().S(`=`)
+//line app/vmalert/web.qtpl:751
+		qw422016.E().S(labels[k])
+//line app/vmalert/web.qtpl:751
+		qw422016.N().S(`</span>
+`)
+//line app/vmalert/web.qtpl:752
+	}
+//line app/vmalert/web.qtpl:752
+	qw422016.N().S(`
+`)
+//line app/vmalert/web.qtpl:753
+}
+
+//line app/vmalert/web.qtpl:753
+func writelabelBadges(qq422016 qtio422016.Writer, labels map[string]string, badgeClass string) {
+//line app/vmalert/web.qtpl:753
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmalert/web.qtpl:753
+	streamlabelBadges(qw422016, labels, badgeClass)
+//line app/vmalert/web.qtpl:753
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmalert/web.qtpl:753
+}
+
+//line app/vmalert/web.qtpl:753
+func labelBadges(labels map[string]string, badgeClass string) string {
+//line app/vmalert/web.qtpl:753
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmalert/web.qtpl:753
+	writelabelBadges(qb422016, labels, badgeClass)
+//line app/vmalert/web.qtpl:753
+	qs422016 := string(qb422016.B)
+//line app/vmalert/web.qtpl:753
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmalert/web.qtpl:753
+	return qs422016
+//line app/vmalert/web.qtpl:753
+}
+
+//line app/vmalert/web.qtpl:755
 func streambadgeState(qw422016 *qt422016.Writer, state string) {
-//line app/vmalert/web.qtpl:567
+//line app/vmalert/web.qtpl:755
 	qw422016.N().S(`
 `)
-//line app/vmalert/web.qtpl:569
+//line app/vmalert/web.qtpl:757
 	badgeClass := "bg-warning text-dark"
 	if state == "firing" {
 		badgeClass = "bg-danger"
 	}
 
-//line app/vmalert/web.qtpl:573
+//line app/vmalert/web.qtpl:761
 	qw422016.N().S(`
 <span class="badge `)
-//line app/vmalert/web.qtpl:574
+//line app/vmalert/web.qtpl:762
 	qw422016.E().S(badgeClass)
-//line app/vmalert/web.qtpl:574
+//line app/vmalert/web.qtpl:762
 	qw422016.N().S(`">`)
-//line app/vmalert/web.qtpl:574
+//line app/vmalert/web.qtpl:762
 	qw422016.E().S(state)
-//line app/vmalert/web.qtpl:574
+//line app/vmalert/web.qtpl:762
 	qw422016.N().S(`</span>
 `)
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 }
 
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 func writebadgeState(qq422016 qtio422016.Writer, state string) {
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 	streambadgeState(qw422016, state)
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 }
 
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 func badgeState(state string) string {
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 	writebadgeState(qb422016, state)
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 	qs422016 := string(qb422016.B)
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 	return qs422016
-//line app/vmalert/web.qtpl:575
+//line app/vmalert/web.qtpl:763
 }
 
-//line app/vmalert/web.qtpl:577
+//line app/vmalert/web.qtpl:765
 func streambadgeRestored(qw422016 *qt422016.Writer) {
-//line app/vmalert/web.qtpl:577
+//line app/vmalert/web.qtpl:765
 	qw422016.N().S(`
 <span class="badge bg-warning text-dark" title="Alert state was restored after the service restart from remote storage">restored</span>
 `)
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:767
 }
 
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:767
 func writebadgeRestored(qq422016 qtio422016.Writer) {
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:767
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:767
 	streambadgeRestored(qw422016)
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:767
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:767
 }
 
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:767
 func badgeRestored() string {
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:767
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:767
 	writebadgeRestored(qb422016)
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:767
+	qs422016 := string(qb422016.B)
+//line app/vmalert/web.qtpl:767
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmalert/web.qtpl:767
+	return qs422016
+//line app/vmalert/web.qtpl:767
+}
+
+//line app/vmalert/web.qtpl:769
+func streambadgeSilenced(qw422016 *qt422016.Writer, silenceID string) {
+//line app/vmalert/web.qtpl:769
+	qw422016.N().S(`
+<span class="badge bg-secondary" title="Alert is muted by silence `)
+//line app/vmalert/web.qtpl:770
+	qw422016.E().S(silenceID)
+//line app/vmalert/web.qtpl:770
+	qw422016.N().S(` and isn't sent to notifiers">silenced</span>
+`)
+//line app/vmalert/web.qtpl:771
+}
+
+//line app/vmalert/web.qtpl:771
+func writebadgeSilenced(qq422016 qtio422016.Writer, silenceID string) {
+//line app/vmalert/web.qtpl:771
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmalert/web.qtpl:771
+	streambadgeSilenced(qw422016, silenceID)
+//line app/vmalert/web.qtpl:771
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmalert/web.qtpl:771
+}
+
+//line app/vmalert/web.qtpl:771
+func badgeSilenced(silenceID string) string {
+//line app/vmalert/web.qtpl:771
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmalert/web.qtpl:771
+	writebadgeSilenced(qb422016, silenceID)
+//line app/vmalert/web.qtpl:771
 	qs422016 := string(qb422016.B)
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:771
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:771
 	return qs422016
-//line app/vmalert/web.qtpl:579
+//line app/vmalert/web.qtpl:771
 }
 
-//line app/vmalert/web.qtpl:581
+//line app/vmalert/web.qtpl:773
+func streambadgeInhibited(qw422016 *qt422016.Writer) {
+//line app/vmalert/web.qtpl:773
+	qw422016.N().S(`
+<span class="badge bg-secondary" title="Alert is muted by the group's inhibit rules and isn't sent to notifiers">inhibited</span>
+`)
+//line app/vmalert/web.qtpl:775
+}
+
+//line app/vmalert/web.qtpl:775
+func writebadgeInhibited(qq422016 qtio422016.Writer) {
+//line app/vmalert/web.qtpl:775
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmalert/web.qtpl:775
+	streambadgeInhibited(qw422016)
+//line app/vmalert/web.qtpl:775
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmalert/web.qtpl:775
+}
+
+//line app/vmalert/web.qtpl:775
+func badgeInhibited() string {
+//line app/vmalert/web.qtpl:775
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmalert/web.qtpl:775
+	writebadgeInhibited(qb422016)
+//line app/vmalert/web.qtpl:775
+	qs422016 := string(qb422016.B)
+//line app/vmalert/web.qtpl:775
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmalert/web.qtpl:775
+	return qs422016
+//line app/vmalert/web.qtpl:775
+}
+
+//line app/vmalert/web.qtpl:777
 func streambadgeStabilizing(qw422016 *qt422016.Writer) {
-//line app/vmalert/web.qtpl:581
+//line app/vmalert/web.qtpl:777
 	qw422016.N().S(`
 <span class="badge bg-warning text-dark" title="This firing state is kept because of `)
-//line app/vmalert/web.qtpl:581
+//line app/vmalert/web.qtpl:777
 	qw422016.N().S("`")
-//line app/vmalert/web.qtpl:581
+//line app/vmalert/web.qtpl:777
 	qw422016.N().S(`keep_firing_for`)
-//line app/vmalert/web.qtpl:581
+//line app/vmalert/web.qtpl:777
 	qw422016.N().S("`")
-//line app/vmalert/web.qtpl:581
+//line app/vmalert/web.qtpl:777
 	qw422016.N().S(`">stabilizing</span>
 `)
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 }
 
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 func writebadgeStabilizing(qq422016 qtio422016.Writer) {
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 	streambadgeStabilizing(qw422016)
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 }
 
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 func badgeStabilizing() string {
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 	writebadgeStabilizing(qb422016)
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 	qs422016 := string(qb422016.B)
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 	return qs422016
-//line app/vmalert/web.qtpl:583
+//line app/vmalert/web.qtpl:779
 }
 
-//line app/vmalert/web.qtpl:585
+//line app/vmalert/web.qtpl:781
 func streamseriesFetchedWarn(qw422016 *qt422016.Writer, r APIRule) {
-//line app/vmalert/web.qtpl:585
+//line app/vmalert/web.qtpl:781
 	qw422016.N().S(`
 `)
-//line app/vmalert/web.qtpl:586
+//line app/vmalert/web.qtpl:782
 	if isNoMatch(r) {
-//line app/vmalert/web.qtpl:586
+//line app/vmalert/web.qtpl:782
 		qw422016.N().S(`
 <svg xmlns="http://www.w3.org/2000/svg"
     data-bs-toggle="tooltip"
@@ -1870,41 +2516,41 @@ func streamseriesFetchedWarn(qw422016 *qt422016.Writer, r APIRule) {
        <path d="M8 16A8 8 0 1 0 8 0a8 8 0 0 0 0 16zm.93-9.412-1 4.705c-.07.34.029.533.304.533.194 0 .487-.07.686-.246l-.088.416c-.287.346-.92.598-1.465.598-.703 0-1.002-.422-.808-1.319l.738-3.468c.064-.293.006-.399-.287-.47l-.451-.081.082-.381 2.29-.287zM8 5.5a1 1 0 1 1 0-2 1 1 0 0 1 0 2z"/>
 </svg>
 `)
-//line app/vmalert/web.qtpl:595
+//line app/vmalert/web.qtpl:791
 	}
-//line app/vmalert/web.qtpl:595
+//line app/vmalert/web.qtpl:791
 	qw422016.N().S(`
 `)
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 }
 
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 func writeseriesFetchedWarn(qq422016 qtio422016.Writer, r APIRule) {
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 	streamseriesFetchedWarn(qw422016, r)
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 	qt422016.ReleaseWriter(qw422016)
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 }
 
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 func seriesFetchedWarn(r APIRule) string {
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 	qb422016 := qt422016.AcquireByteBuffer()
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 	writeseriesFetchedWarn(qb422016, r)
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 	qs422016 := string(qb422016.B)
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 	qt422016.ReleaseByteBuffer(qb422016)
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 	return qs422016
-//line app/vmalert/web.qtpl:596
+//line app/vmalert/web.qtpl:792
 }
 
-//line app/vmalert/web.qtpl:599
+//line app/vmalert/web.qtpl:795
 func isNoMatch(r APIRule) bool {
 	return r.LastSamples == 0 && r.LastSeriesFetched != nil && *r.LastSeriesFetched == 0
 }
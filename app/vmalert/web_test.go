@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -33,7 +34,7 @@ func TestHandler(t *testing.T) {
 		Name:  "group",
 		Rules: []Rule{ar, rr},
 	}
-	m := &manager{groups: make(map[uint64]*Group)}
+	m := &manager{groups: make(map[uint64]*Group), querierBuilder: &fakeQuerier{}}
 	m.groups[0] = g
 	rh := &requestHandler{m: m}
 
@@ -75,6 +76,12 @@ func TestHandler(t *testing.T) {
 		r := rr.ToAPI()
 		getResp(ts.URL+"/vmalert/"+r.WebLink(), nil, 200)
 	})
+	t.Run("/vmalert/rule?eval", func(t *testing.T) {
+		a := ar.ToAPI()
+		getResp(ts.URL+"/vmalert/"+a.EvalLink(), nil, 200)
+		r := rr.ToAPI()
+		getResp(ts.URL+"/vmalert/"+r.EvalLink(), nil, 200)
+	})
 	t.Run("/vmalert/alert", func(t *testing.T) {
 		alerts := ar.AlertsToAPI()
 		for _, a := range alerts {
@@ -223,3 +230,58 @@ func TestEmptyResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestSilencesHandler(t *testing.T) {
+	mustResetSilences(t)
+	defer mustResetSilences(t)
+
+	rh := &requestHandler{m: &manager{groups: make(map[uint64]*Group)}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { rh.handler(w, r) }))
+	defer ts.Close()
+
+	do := func(method, url, body string, to interface{}, code int) {
+		t.Helper()
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected err %s", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected err %s", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if code != resp.StatusCode {
+			t.Fatalf("unexpected status code %d want %d", resp.StatusCode, code)
+		}
+		if to != nil {
+			if err = json.NewDecoder(resp.Body).Decode(to); err != nil {
+				t.Fatalf("unexpected err %s", err)
+			}
+		}
+	}
+
+	endsAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+	cr := createSilenceResponse{}
+	do(http.MethodPost, ts.URL+"/api/v1/silences",
+		fmt.Sprintf(`{"matchers":["alertname=\"foo\""],"endsAt":%q,"createdBy":"admin"}`, endsAt), &cr, 200)
+	if cr.Data.SilenceID == "" {
+		t.Fatalf("expected to get non-empty silence ID")
+	}
+	do(http.MethodPost, ts.URL+"/vmalert/api/v1/silences", `{"matchers":["alertname=\"foo\""]}`, nil, 400)
+	do(http.MethodPut, ts.URL+"/vmalert/api/v1/silences", "", nil, 405)
+
+	lr := listSilencesResponse{}
+	do(http.MethodGet, ts.URL+"/vmalert/api/v1/silences", "", &lr, 200)
+	if len(lr.Data.Silences) != 1 || lr.Data.Silences[0].ID != cr.Data.SilenceID {
+		t.Fatalf("expected to get silence %q; got %+v", cr.Data.SilenceID, lr.Data.Silences)
+	}
+	do(http.MethodGet, ts.URL+"/vmalert/silences", "", nil, 200)
+
+	do(http.MethodDelete, ts.URL+"/api/v1/silence?silence_id="+cr.Data.SilenceID, "", nil, 200)
+	do(http.MethodDelete, ts.URL+"/api/v1/silence?silence_id="+cr.Data.SilenceID, "", nil, 404)
+	lr = listSilencesResponse{}
+	do(http.MethodGet, ts.URL+"/api/v1/silences", "", &lr, 200)
+	if len(lr.Data.Silences) != 0 {
+		t.Fatalf("expected to get no silences; got %d", len(lr.Data.Silences))
+	}
+}
@@ -35,6 +35,10 @@ type APIAlert struct {
 	// Stabilizing shows when firing state is kept because of
 	// `keep_firing_for` instead of real alert
 	Stabilizing bool `json:"stabilizing"`
+	// SilencedBy contains the ID of the silence which mutes the alert
+	SilencedBy string `json:"silenced_by,omitempty"`
+	// Inhibited shows whether the alert is muted by group's inhibit rules
+	Inhibited bool `json:"inhibited,omitempty"`
 }
 
 // WebLink returns a link to the alert which can be used in UI.
@@ -54,7 +58,7 @@ func (aa *APIAlert) APILink() string {
 type APIGroup struct {
 	// Name is the group name as present in the config
 	Name string `json:"name"`
-	// Rules contains both recording and alerting rules
+	// Rules contains recording, alerting and baseline rules
 	Rules []APIRule `json:"rules"`
 	// Interval is the Group's evaluation interval in float seconds as present in the file.
 	Interval float64 `json:"interval"`
@@ -115,7 +119,7 @@ type APIRule struct {
 	// Health is the health of rule evaluation.
 	// It MUST be one of "ok", "err", "unknown"
 	Health string `json:"health"`
-	// Type of the rule: recording or alerting
+	// Type of the rule: recording, alerting or baseline
 	Type string `json:"type"`
 
 	// Additional fields
@@ -146,3 +150,47 @@ func (ar APIRule) WebLink() string {
 	return fmt.Sprintf("rule?%s=%s&%s=%s",
 		paramGroupID, ar.GroupID, paramRuleID, ar.ID)
 }
+
+// EvalLink returns a link to the rule which triggers
+// on-demand rule evaluation in UI.
+func (ar APIRule) EvalLink() string {
+	return fmt.Sprintf("%s&%s=true", ar.WebLink(), paramEval)
+}
+
+// APIRuleEval represents the result of on-demand Rule evaluation
+// for WEB view. The evaluation doesn't change the Rule's state.
+type APIRuleEval struct {
+	// At is the timestamp Rule was evaluated at
+	At time.Time `json:"at"`
+	// Duration is the time taken to execute the Rule's expression
+	Duration time.Duration `json:"duration"`
+	// Curl contains the curl command reflecting the HTTP request
+	// used for evaluation
+	Curl string `json:"curl"`
+	// Error contains the error faced during evaluation
+	Error string `json:"error,omitempty"`
+	// Series contains time series returned by datasource
+	Series []APIRuleEvalSeries `json:"series"`
+	// SeriesFetched stores the amount of time series fetched by datasource
+	SeriesFetched *int `json:"seriesFetched,omitempty"`
+	// Trace contains the query trace in plaintext.
+	// It is empty if datasource doesn't support query tracing.
+	Trace string `json:"trace,omitempty"`
+}
+
+// APIRuleEvalSeries represents a single time series
+// received during on-demand Rule evaluation
+type APIRuleEvalSeries struct {
+	// Metric contains the original labels of time series
+	Metric map[string]string `json:"metric"`
+	// Value is the time series value
+	Value string `json:"value"`
+	// Labels contains resulting labels after applying
+	// Rule's labels templates
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations contains expanded Rule's annotations templates.
+	// Is set for alerting rules only.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Error contains the error faced during templates expansion
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/valyala/fastrand"
+)
+
+var (
+	accessLog = flag.Bool("accessLog", false, "Whether to write access log for requests proxied by vmauth to stdout. "+
+		"Every line contains a JSON object with the user name, the target backend, request path, response status code, request duration "+
+		"and the number of request and response bytes. See also -accessLog.sampleRate and https://docs.victoriametrics.com/vmauth.html#access-log")
+	accessLogSampleRate = flag.Float64("accessLog.sampleRate", 1, "The share of requests to write to access log if -accessLog is set. "+
+		"For example, 0.1 means that only 10% of randomly selected requests are logged. The value must be in the range (0..1]")
+)
+
+func initAccessLog() {
+	if *accessLogSampleRate <= 0 || *accessLogSampleRate > 1 {
+		logger.Fatalf("-accessLog.sampleRate must be in the range (0..1]; got %v", *accessLogSampleRate)
+	}
+}
+
+// accountingResponseWriter collects per-request stats used for per-user accounting and access log.
+type accountingResponseWriter struct {
+	http.ResponseWriter
+
+	statusCode    int
+	responseBytes int64
+
+	// backend is the url of the last backend the request has been sent to
+	backend *url.URL
+
+	// backendErrors is the number of failed attempts to proxy the request to backends
+	backendErrors int
+}
+
+// WriteHeader implements http.ResponseWriter interface.
+func (arw *accountingResponseWriter) WriteHeader(statusCode int) {
+	if arw.statusCode == 0 {
+		arw.statusCode = statusCode
+	}
+	arw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter interface.
+func (arw *accountingResponseWriter) Write(p []byte) (int, error) {
+	if arw.statusCode == 0 {
+		arw.statusCode = http.StatusOK
+	}
+	n, err := arw.ResponseWriter.Write(p)
+	arw.responseBytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher interface.
+func (arw *accountingResponseWriter) Flush() {
+	if f, ok := arw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// setBackend registers the backend the request is sent to.
+//
+// It is safe calling setBackend on nil arw.
+func (arw *accountingResponseWriter) setBackend(u *url.URL) {
+	if arw != nil {
+		arw.backend = u
+	}
+}
+
+// registerBackendError registers failed attempt to proxy the request to backend.
+//
+// It is safe calling registerBackendError on nil arw.
+func (arw *accountingResponseWriter) registerBackendError() {
+	if arw != nil {
+		arw.backendErrors++
+	}
+}
+
+// getBackendAddr returns backend address without path and query args,
+// since they may contain sensitive information.
+func (arw *accountingResponseWriter) getBackendAddr() string {
+	if arw.backend == nil {
+		return ""
+	}
+	u := url.URL{
+		Scheme: arw.backend.Scheme,
+		Host:   arw.backend.Host,
+	}
+	return u.String()
+}
+
+// updateUserStats updates per-user accounting metrics for the request served by ui.
+func (ui *UserInfo) updateUserStats(arw *accountingResponseWriter, requestBytes int64) {
+	ui.requestBytes.Add(int(requestBytes))
+	ui.responseBytes.Add(int(arw.responseBytes))
+	n := arw.backendErrors
+	if arw.backend != nil && arw.statusCode >= 500 {
+		// The backend returned an error or all the backends were unavailable.
+		n++
+	}
+	ui.backendErrors.Add(n)
+}
+
+type accessLogEntry struct {
+	Time            string  `json:"ts"`
+	User            string  `json:"user"`
+	RemoteAddr      string  `json:"remote_addr"`
+	Method          string  `json:"method"`
+	Path            string  `json:"path"`
+	Backend         string  `json:"backend"`
+	Status          int     `json:"status"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	RequestBytes    int64   `json:"request_bytes"`
+	ResponseBytes   int64   `json:"response_bytes"`
+}
+
+// writeAccessLog writes access log entry for the request r served by ui if -accessLog is set.
+func writeAccessLog(r *http.Request, ui *UserInfo, arw *accountingResponseWriter, requestBytes int64, startTime time.Time) {
+	if !*accessLog {
+		return
+	}
+	if *accessLogSampleRate < 1 && float64(fastrand.Uint32()) >= *accessLogSampleRate*(1<<32) {
+		return
+	}
+	user := ui.name()
+	if user == "" {
+		user = "unauthorized_user"
+	}
+	statusCode := arw.statusCode
+	if statusCode == 0 {
+		// The request has been canceled by the client before sending the response.
+		statusCode = 499
+	}
+	e := &accessLogEntry{
+		Time:            startTime.UTC().Format("2006-01-02T15:04:05.000Z"),
+		User:            user,
+		RemoteAddr:      r.RemoteAddr,
+		Method:          r.Method,
+		Path:            r.URL.Path,
+		Backend:         arw.getBackendAddr(),
+		Status:          statusCode,
+		DurationSeconds: time.Since(startTime).Seconds(),
+		RequestBytes:    requestBytes,
+		ResponseBytes:   arw.responseBytes,
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		logger.Panicf("BUG: cannot marshal access log entry: %s", err)
+	}
+	data = append(data, '\n')
+	accessLogMu.Lock()
+	_, _ = os.Stdout.Write(data)
+	accessLogMu.Unlock()
+}
+
+var accessLogMu sync.Mutex
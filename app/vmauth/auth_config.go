@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"flag"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"regexp"
@@ -19,6 +20,7 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/procutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
 	"github.com/VictoriaMetrics/metrics"
 	"gopkg.in/yaml.v2"
 )
@@ -34,26 +36,50 @@ var (
 type AuthConfig struct {
 	Users            []UserInfo `yaml:"users,omitempty"`
 	UnauthorizedUser *UserInfo  `yaml:"unauthorized_user,omitempty"`
+	IPFilters        *IPFilters `yaml:"ip_filters,omitempty"`
+
+	// jwtUsers contains users with `jwt` auth in the order they are defined in the config
+	jwtUsers []*UserInfo
+
+	// passwordHashUsers contains users with `password_hash` keyed by username
+	passwordHashUsers map[string]*UserInfo
+
+	// tlsClientCertUsers contains users with `tls_client_cert` in the order they are defined in the config
+	tlsClientCertUsers []*UserInfo
 }
 
 // UserInfo is user information read from authConfigPath
 type UserInfo struct {
-	Name                  string      `yaml:"name,omitempty"`
-	BearerToken           string      `yaml:"bearer_token,omitempty"`
-	Username              string      `yaml:"username,omitempty"`
-	Password              string      `yaml:"password,omitempty"`
-	URLPrefix             *URLPrefix  `yaml:"url_prefix,omitempty"`
-	URLMaps               []URLMap    `yaml:"url_map,omitempty"`
-	HeadersConf           HeadersConf `yaml:",inline"`
-	MaxConcurrentRequests int         `yaml:"max_concurrent_requests,omitempty"`
-	DefaultURL            *URLPrefix  `yaml:"default_url,omitempty"`
-	RetryStatusCodes      []int       `yaml:"retry_status_codes,omitempty"`
+	Name                  string                `yaml:"name,omitempty"`
+	BearerToken           string                `yaml:"bearer_token,omitempty"`
+	Username              string                `yaml:"username,omitempty"`
+	Password              string                `yaml:"password,omitempty"`
+	PasswordHash          string                `yaml:"password_hash,omitempty"`
+	JWT                   *JWTConfig            `yaml:"jwt,omitempty"`
+	TLSClientCert         *TLSClientCertMatcher `yaml:"tls_client_cert,omitempty"`
+	URLPrefix             *URLPrefix            `yaml:"url_prefix,omitempty"`
+	URLMaps               []URLMap              `yaml:"url_map,omitempty"`
+	HeadersConf           HeadersConf           `yaml:",inline"`
+	MaxConcurrentRequests int                   `yaml:"max_concurrent_requests,omitempty"`
+	RateLimitConf         RateLimitConf         `yaml:",inline"`
+	DefaultURL            *URLPrefix            `yaml:"default_url,omitempty"`
+	RetryStatusCodes      []int                 `yaml:"retry_status_codes,omitempty"`
+	LoadBalancingPolicy   string                `yaml:"load_balancing_policy,omitempty"`
+	HealthCheck           *HealthCheckConfig    `yaml:"health_check,omitempty"`
+	InjectLabelFilters    *InjectLabelFilters   `yaml:"inject_label_filters,omitempty"`
+	IPFilters             *IPFilters            `yaml:"ip_filters,omitempty"`
+
+	passwordHash *passwordHash
 
 	concurrencyLimitCh      chan struct{}
 	concurrencyLimitReached *metrics.Counter
+	rateLimitReached        *metrics.Counter
 
 	requests         *metrics.Counter
 	requestsDuration *metrics.Summary
+	requestBytes     *metrics.Counter
+	responseBytes    *metrics.Counter
+	backendErrors    *metrics.Counter
 }
 
 // HeadersConf represents config for request and response headers.
@@ -113,10 +139,31 @@ func (h *Header) MarshalYAML() (interface{}, error) {
 
 // URLMap is a mapping from source paths to target urls.
 type URLMap struct {
-	SrcPaths         []*SrcPath  `yaml:"src_paths,omitempty"`
-	URLPrefix        *URLPrefix  `yaml:"url_prefix,omitempty"`
-	HeadersConf      HeadersConf `yaml:",inline"`
-	RetryStatusCodes []int       `yaml:"retry_status_codes,omitempty"`
+	SrcPaths         []*SrcPath       `yaml:"src_paths,omitempty"`
+	SrcQueryArgs     []*QueryArg      `yaml:"src_query_args,omitempty"`
+	SrcHeaders       []*HeaderMatcher `yaml:"src_headers,omitempty"`
+	SrcMethods       []string         `yaml:"src_methods,omitempty"`
+	URLPrefix        *URLPrefix       `yaml:"url_prefix,omitempty"`
+	HeadersConf      HeadersConf      `yaml:",inline"`
+	RetryStatusCodes []int            `yaml:"retry_status_codes,omitempty"`
+	RateLimitConf    RateLimitConf    `yaml:",inline"`
+	// LoadBalancingPolicy and HealthCheck override the corresponding user-level options for url_prefix
+	LoadBalancingPolicy string             `yaml:"load_balancing_policy,omitempty"`
+	HealthCheck         *HealthCheckConfig `yaml:"health_check,omitempty"`
+	// ResponseCacheTTL enables caching of responses for the given duration.
+	ResponseCacheTTL *promutils.Duration `yaml:"response_cache_ttl,omitempty"`
+	// SrcMaxAge and SrcMinAge match requests by the age of the time range from `start`, `end` and `time` query args.
+	SrcMaxAge *promutils.Duration `yaml:"src_max_age,omitempty"`
+	SrcMinAge *promutils.Duration `yaml:"src_min_age,omitempty"`
+	// SplitTimeRange enables splitting /api/v1/query_range requests, which start before SrcMaxAge.
+	SplitTimeRange bool `yaml:"split_time_range,omitempty"`
+}
+
+func (e *URLMap) getResponseCacheTTL() time.Duration {
+	if e == nil {
+		return 0
+	}
+	return e.ResponseCacheTTL.Duration()
 }
 
 // SrcPath represents an src path
@@ -129,15 +176,33 @@ type SrcPath struct {
 type URLPrefix struct {
 	n   uint32
 	bus []*backendURL
+
+	// mu protects currentWeight at bus for round_robin load balancing policy
+	mu sync.Mutex
+
+	loadBalancingPolicy string
+	healthCheck         *HealthCheckConfig
 }
 
 type backendURL struct {
 	brokenDeadline     uint64
 	concurrentRequests int32
 	url                *url.URL
+
+	// weight is the relative weight of the backend for load balancing
+	weight int
+
+	// currentWeight is used by round_robin load balancing policy
+	currentWeight int
+
+	// health is updated by active health checks. It is nil if health checks are disabled for the backend.
+	health *backendHealth
 }
 
 func (bu *backendURL) isBroken() bool {
+	if bh := bu.health; bh != nil && bh.unhealthy.Load() {
+		return true
+	}
 	ct := fasttime.UnixTimestamp()
 	return ct < atomic.LoadUint64(&bu.brokenDeadline)
 }
@@ -155,7 +220,7 @@ func (up *URLPrefix) getBackendsCount() int {
 	return len(up.bus)
 }
 
-// getLeastLoadedBackendURL returns the backendURL with the minimum number of concurrent requests.
+// getLeastLoadedBackendURL returns the backendURL with the minimum number of concurrent requests per weight unit.
 //
 // backendURL.put() must be called on the returned backendURL after the request is complete.
 func (up *URLPrefix) getLeastLoadedBackendURL() *backendURL {
@@ -185,13 +250,17 @@ func (up *URLPrefix) getLeastLoadedBackendURL() *backendURL {
 	// Slow path - return the backend with the minimum number of concurrently executed requests.
 	buMin := bus[n%uint32(len(bus))]
 	minRequests := atomic.LoadInt32(&buMin.concurrentRequests)
+	minWeight := buMin.getWeight()
 	for _, bu := range bus {
 		if bu.isBroken() {
 			continue
 		}
-		if n := atomic.LoadInt32(&bu.concurrentRequests); n < minRequests {
+		// Compare n/weight with minRequests/minWeight without division.
+		n, weight := atomic.LoadInt32(&bu.concurrentRequests), bu.getWeight()
+		if int64(n)*int64(minWeight) < int64(minRequests)*int64(weight) {
 			buMin = bu
 			minRequests = n
+			minWeight = weight
 		}
 	}
 	atomic.AddInt32(&buMin.concurrentRequests, 1)
@@ -204,42 +273,76 @@ func (up *URLPrefix) UnmarshalYAML(f func(interface{}) error) error {
 	if err := f(&v); err != nil {
 		return err
 	}
-	var urls []string
+	var bcs []backendURLConfig
 	switch x := v.(type) {
 	case string:
-		urls = []string{x}
+		bcs = []backendURLConfig{{URL: x}}
 	case []interface{}:
 		if len(x) == 0 {
 			return fmt.Errorf("`url_prefix` must contain at least a single url")
 		}
-		us := make([]string, len(x))
+		bcs = make([]backendURLConfig, len(x))
 		for i, xx := range x {
-			s, ok := xx.(string)
-			if !ok {
-				return fmt.Errorf("`url_prefix` must contain array of strings; got %T", xx)
+			switch t := xx.(type) {
+			case string:
+				bcs[i].URL = t
+			case map[interface{}]interface{}:
+				// Weighted backend in the form `{url: "...", weight: N}`
+				data, err := yaml.Marshal(t)
+				if err != nil {
+					return fmt.Errorf("cannot marshal `url_prefix` entry: %w", err)
+				}
+				if err := yaml.UnmarshalStrict(data, &bcs[i]); err != nil {
+					return fmt.Errorf("cannot unmarshal `url_prefix` entry: %w", err)
+				}
+				if bcs[i].URL == "" {
+					return fmt.Errorf("missing `url` in `url_prefix` entry")
+				}
+				if bcs[i].Weight <= 0 {
+					return fmt.Errorf("`weight` must be positive for `url_prefix` entry %q; got %d", bcs[i].URL, bcs[i].Weight)
+				}
+			default:
+				return fmt.Errorf("`url_prefix` must contain array of strings or {url, weight} objects; got %T", xx)
 			}
-			us[i] = s
 		}
-		urls = us
 	default:
 		return fmt.Errorf("unexpected type for `url_prefix`: %T; want string or []string", v)
 	}
-	bus := make([]*backendURL, len(urls))
-	for i, u := range urls {
-		pu, err := url.Parse(u)
+	bus := make([]*backendURL, len(bcs))
+	for i, bc := range bcs {
+		pu, err := url.Parse(bc.URL)
 		if err != nil {
-			return fmt.Errorf("cannot unmarshal %q into url: %w", u, err)
+			return fmt.Errorf("cannot unmarshal %q into url: %w", bc.URL, err)
 		}
 		bus[i] = &backendURL{
-			url: pu,
+			url:    pu,
+			weight: bc.Weight,
 		}
 	}
 	up.bus = bus
 	return nil
 }
 
+// backendURLConfig represents weighted `url_prefix` entry.
+type backendURLConfig struct {
+	URL    string `yaml:"url"`
+	Weight int    `yaml:"weight,omitempty"`
+}
+
 // MarshalYAML marshals up to yaml.
 func (up *URLPrefix) MarshalYAML() (interface{}, error) {
+	for _, bu := range up.bus {
+		if bu.weight > 0 {
+			bcs := make([]backendURLConfig, len(up.bus))
+			for i, bu := range up.bus {
+				bcs[i] = backendURLConfig{
+					URL:    bu.url.String(),
+					Weight: bu.weight,
+				}
+			}
+			return bcs, nil
+		}
+	}
 	var b []byte
 	if len(up.bus) == 1 {
 		u := up.bus[0].url.String()
@@ -258,16 +361,24 @@ func (up *URLPrefix) MarshalYAML() (interface{}, error) {
 	return string(b), nil
 }
 
+// QueryArg represents `src_query_args` entry in the form `name=value`,
+// where value may contain regexp.
+type QueryArg struct {
+	sOriginal string
+	name      string
+	re        *regexp.Regexp
+}
+
+// HeaderMatcher represents `src_headers` entry in the form `Name: value`,
+// where value may contain regexp.
+type HeaderMatcher struct {
+	sOriginal string
+	name      string
+	re        *regexp.Regexp
+}
+
 func (sp *SrcPath) match(s string) bool {
-	prefix, ok := sp.re.LiteralPrefix()
-	if ok {
-		// Fast path - literal match
-		return s == prefix
-	}
-	if !strings.HasPrefix(s, prefix) {
-		return false
-	}
-	return sp.re.MatchString(s)
+	return matchAnchoredRegexp(sp.re, s)
 }
 
 // UnmarshalYAML implements yaml.Unmarshaler
@@ -276,10 +387,9 @@ func (sp *SrcPath) UnmarshalYAML(f func(interface{}) error) error {
 	if err := f(&s); err != nil {
 		return err
 	}
-	sAnchored := "^(?:" + s + ")$"
-	re, err := regexp.Compile(sAnchored)
+	re, err := compileAnchoredRegexp(s)
 	if err != nil {
-		return fmt.Errorf("cannot build regexp from %q: %w", s, err)
+		return err
 	}
 	sp.sOriginal = s
 	sp.re = re
@@ -291,6 +401,95 @@ func (sp *SrcPath) MarshalYAML() (interface{}, error) {
 	return sp.sOriginal, nil
 }
 
+func (qa *QueryArg) match(args url.Values) bool {
+	for _, v := range args[qa.name] {
+		if matchAnchoredRegexp(qa.re, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (qa *QueryArg) UnmarshalYAML(f func(interface{}) error) error {
+	var s string
+	if err := f(&s); err != nil {
+		return err
+	}
+	n := strings.IndexByte(s, '=')
+	if n <= 0 {
+		return fmt.Errorf("missing separator char '=' between name and value in the query arg %q; expected format - 'name=value'", s)
+	}
+	re, err := compileAnchoredRegexp(s[n+1:])
+	if err != nil {
+		return err
+	}
+	qa.sOriginal = s
+	qa.name = s[:n]
+	qa.re = re
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (qa *QueryArg) MarshalYAML() (interface{}, error) {
+	return qa.sOriginal, nil
+}
+
+func (hm *HeaderMatcher) match(h http.Header) bool {
+	for _, v := range h.Values(hm.name) {
+		if matchAnchoredRegexp(hm.re, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (hm *HeaderMatcher) UnmarshalYAML(f func(interface{}) error) error {
+	var s string
+	if err := f(&s); err != nil {
+		return err
+	}
+	n := strings.IndexByte(s, ':')
+	if n <= 0 {
+		return fmt.Errorf("missing separator char ':' between Name and Value in the header %q; expected format - 'Name: Value'", s)
+	}
+	re, err := compileAnchoredRegexp(strings.TrimSpace(s[n+1:]))
+	if err != nil {
+		return err
+	}
+	hm.sOriginal = s
+	hm.name = strings.TrimSpace(s[:n])
+	hm.re = re
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (hm *HeaderMatcher) MarshalYAML() (interface{}, error) {
+	return hm.sOriginal, nil
+}
+
+func compileAnchoredRegexp(s string) (*regexp.Regexp, error) {
+	sAnchored := "^(?:" + s + ")$"
+	re, err := regexp.Compile(sAnchored)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build regexp from %q: %w", s, err)
+	}
+	return re, nil
+}
+
+func matchAnchoredRegexp(re *regexp.Regexp, s string) bool {
+	prefix, ok := re.LiteralPrefix()
+	if ok {
+		// Fast path - literal match
+		return s == prefix
+	}
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	return re.MatchString(s)
+}
+
 var (
 	configReloads      = metrics.NewCounter(`vmauth_config_last_reload_total`)
 	configReloadErrors = metrics.NewCounter(`vmauth_config_last_reload_errors_total`)
@@ -327,6 +526,7 @@ func initAuthConfig() {
 func stopAuthConfig() {
 	close(stopCh)
 	authConfigWG.Wait()
+	healthChecks.stop()
 }
 
 func authConfigReloader(sighupCh <-chan os.Signal) {
@@ -402,6 +602,7 @@ func loadAuthConfig() (bool, error) {
 	}
 	logger.Infof("loaded information about %d users from -auth.config=%q", len(m), *authConfigPath)
 
+	healthChecks.update(ac)
 	authConfig.Store(ac)
 	authConfigData.Store(&data)
 	authUsers.Store(&m)
@@ -418,12 +619,39 @@ func parseAuthConfig(data []byte) (*AuthConfig, error) {
 	if err = yaml.UnmarshalStrict(data, &ac); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal AuthConfig data: %w", err)
 	}
+	if ac.IPFilters != nil {
+		if err := ac.IPFilters.init(); err != nil {
+			return nil, fmt.Errorf("invalid `ip_filters`: %w", err)
+		}
+	}
 	ui := ac.UnauthorizedUser
 	if ui != nil {
+		if err := ui.initIPFilters(); err != nil {
+			return nil, err
+		}
+		if ui.JWT != nil {
+			return nil, fmt.Errorf("`jwt` cannot be set for `unauthorized_user`")
+		}
+		if ui.PasswordHash != "" {
+			return nil, fmt.Errorf("`password_hash` cannot be set for `unauthorized_user`")
+		}
+		if ui.TLSClientCert != nil {
+			return nil, fmt.Errorf("`tls_client_cert` cannot be set for `unauthorized_user`")
+		}
 		ui.requests = metrics.GetOrCreateCounter(`vmauth_unauthorized_user_requests_total`)
 		ui.requestsDuration = metrics.GetOrCreateSummary(`vmauth_unauthorized_user_request_duration_seconds`)
+		ui.requestBytes = metrics.GetOrCreateCounter(`vmauth_unauthorized_user_request_bytes_total`)
+		ui.responseBytes = metrics.GetOrCreateCounter(`vmauth_unauthorized_user_response_bytes_total`)
+		ui.backendErrors = metrics.GetOrCreateCounter(`vmauth_unauthorized_user_backend_errors_total`)
 		ui.concurrencyLimitCh = make(chan struct{}, ui.getMaxConcurrentRequests())
 		ui.concurrencyLimitReached = metrics.GetOrCreateCounter(`vmauth_unauthorized_user_concurrent_requests_limit_reached_total`)
+		ui.rateLimitReached = metrics.GetOrCreateCounter(`vmauth_unauthorized_user_rate_limit_reached_total`)
+		if err := ui.initRateLimits(); err != nil {
+			return nil, err
+		}
+		if err := ui.initLoadBalancing(); err != nil {
+			return nil, err
+		}
 		_ = metrics.GetOrCreateGauge(`vmauth_unauthorized_user_concurrent_requests_capacity`, func() float64 {
 			return float64(cap(ui.concurrencyLimitCh))
 		})
@@ -440,19 +668,69 @@ func parseAuthConfigUsers(ac *AuthConfig) (map[string]*UserInfo, error) {
 		return nil, fmt.Errorf("Missing `users` or `unauthorized_user` sections")
 	}
 	byAuthToken := make(map[string]*UserInfo, len(uis))
+	var jwtUsers []*UserInfo
+	passwordHashUsers := make(map[string]*UserInfo)
+	plainUsernames := make(map[string]bool)
+	var tlsClientCertUsers []*UserInfo
 	for i := range uis {
 		ui := &uis[i]
-		if ui.BearerToken == "" && ui.Username == "" {
-			return nil, fmt.Errorf("either bearer_token or username must be set")
+		switch {
+		case ui.JWT != nil:
+			if ui.BearerToken != "" || ui.Username != "" || ui.TLSClientCert != nil {
+				return nil, fmt.Errorf("`jwt` cannot be set simultaneously with bearer_token, username or tls_client_cert")
+			}
+			if ui.Name == "" {
+				return nil, fmt.Errorf("`name` must be set for user with `jwt` auth")
+			}
+			if err := ui.JWT.init(); err != nil {
+				return nil, fmt.Errorf("cannot initialize `jwt` for user %q: %w", ui.Name, err)
+			}
+		case ui.TLSClientCert != nil:
+			if ui.BearerToken != "" || ui.Username != "" {
+				return nil, fmt.Errorf("`tls_client_cert` cannot be set simultaneously with bearer_token or username")
+			}
+			if ui.Name == "" {
+				return nil, fmt.Errorf("`name` must be set for user with `tls_client_cert` auth")
+			}
+			if err := ui.TLSClientCert.validate(); err != nil {
+				return nil, fmt.Errorf("invalid `tls_client_cert` for user %q: %w", ui.Name, err)
+			}
+		default:
+			if ui.BearerToken == "" && ui.Username == "" {
+				return nil, fmt.Errorf("either bearer_token, username, jwt or tls_client_cert must be set")
+			}
+			if ui.BearerToken != "" && ui.Username != "" {
+				return nil, fmt.Errorf("bearer_token=%q and username=%q cannot be set simultaneously", ui.BearerToken, ui.Username)
+			}
+		}
+		if ui.PasswordHash != "" {
+			if ui.Username == "" {
+				return nil, fmt.Errorf("`password_hash` can be set only for user with `username`")
+			}
+			if ui.Password != "" {
+				return nil, fmt.Errorf("password and password_hash cannot be set simultaneously for username=%q", ui.Username)
+			}
+			if passwordHashUsers[ui.Username] != nil {
+				return nil, fmt.Errorf("duplicate username=%q found for users with `password_hash`", ui.Username)
+			}
+			if plainUsernames[ui.Username] {
+				return nil, fmt.Errorf("username=%q cannot be used simultaneously by users with `password` and `password_hash`", ui.Username)
+			}
+			ph, err := newPasswordHash(ui.PasswordHash)
+			if err != nil {
+				return nil, fmt.Errorf("invalid `password_hash` for username=%q: %w", ui.Username, err)
+			}
+			ui.passwordHash = ph
 		}
-		if ui.BearerToken != "" && ui.Username != "" {
-			return nil, fmt.Errorf("bearer_token=%q and username=%q cannot be set simultaneously", ui.BearerToken, ui.Username)
+		hasAuthToken := ui.JWT == nil && ui.TLSClientCert == nil && ui.passwordHash == nil
+		if hasAuthToken && ui.Username != "" && passwordHashUsers[ui.Username] != nil {
+			return nil, fmt.Errorf("username=%q cannot be used simultaneously by users with `password` and `password_hash`", ui.Username)
 		}
 		at1, at2 := getAuthTokens(ui.BearerToken, ui.Username, ui.Password)
-		if byAuthToken[at1] != nil {
+		if hasAuthToken && byAuthToken[at1] != nil {
 			return nil, fmt.Errorf("duplicate auth token found for bearer_token=%q, username=%q: %q", ui.BearerToken, ui.Username, at1)
 		}
-		if byAuthToken[at2] != nil {
+		if hasAuthToken && byAuthToken[at2] != nil {
 			return nil, fmt.Errorf("duplicate auth token found for bearer_token=%q, username=%q: %q", ui.BearerToken, ui.Username, at2)
 		}
 		if ui.URLPrefix != nil {
@@ -466,8 +744,11 @@ func parseAuthConfigUsers(ac *AuthConfig) (map[string]*UserInfo, error) {
 			}
 		}
 		for _, e := range ui.URLMaps {
-			if len(e.SrcPaths) == 0 {
-				return nil, fmt.Errorf("missing `src_paths` in `url_map`")
+			if len(e.SrcPaths) == 0 && len(e.SrcQueryArgs) == 0 && len(e.SrcHeaders) == 0 && len(e.SrcMethods) == 0 && !e.hasTimeRangeMatchers() {
+				return nil, fmt.Errorf("missing `src_paths`, `src_query_args`, `src_headers`, `src_methods`, `src_max_age` or `src_min_age` in `url_map`")
+			}
+			if err := e.validateTimeRangeMatchers(); err != nil {
+				return nil, fmt.Errorf("invalid `url_map`: %w", err)
 			}
 			if e.URLPrefix == nil {
 				return nil, fmt.Errorf("missing `url_prefix` in `url_map`")
@@ -475,6 +756,18 @@ func parseAuthConfigUsers(ac *AuthConfig) (map[string]*UserInfo, error) {
 			if err := e.URLPrefix.sanitize(); err != nil {
 				return nil, err
 			}
+			if ttl := e.ResponseCacheTTL.Duration(); ttl != 0 && ttl < time.Second {
+				return nil, fmt.Errorf("`response_cache_ttl` must be at least 1s; got %s", ttl)
+			}
+		}
+		if err := ui.initRateLimits(); err != nil {
+			return nil, err
+		}
+		if err := ui.initLoadBalancing(); err != nil {
+			return nil, err
+		}
+		if err := ui.initIPFilters(); err != nil {
+			return nil, err
 		}
 		if len(ui.URLMaps) == 0 && ui.URLPrefix == nil {
 			return nil, fmt.Errorf("missing `url_prefix`")
@@ -487,25 +780,97 @@ func parseAuthConfigUsers(ac *AuthConfig) (map[string]*UserInfo, error) {
 			ui.requests = metrics.GetOrCreateCounter(fmt.Sprintf(`vmauth_user_requests_total{username=%q}`, name))
 			ui.requestsDuration = metrics.GetOrCreateSummary(fmt.Sprintf(`vmauth_user_request_duration_seconds{username=%q}`, name))
 		}
-		if ui.Username != "" {
+		if ui.Username != "" || ui.JWT != nil || ui.TLSClientCert != nil {
 			ui.requests = metrics.GetOrCreateCounter(fmt.Sprintf(`vmauth_user_requests_total{username=%q}`, name))
 			ui.requestsDuration = metrics.GetOrCreateSummary(fmt.Sprintf(`vmauth_user_request_duration_seconds{username=%q}`, name))
 		}
+		ui.requestBytes = metrics.GetOrCreateCounter(fmt.Sprintf(`vmauth_user_request_bytes_total{username=%q}`, name))
+		ui.responseBytes = metrics.GetOrCreateCounter(fmt.Sprintf(`vmauth_user_response_bytes_total{username=%q}`, name))
+		ui.backendErrors = metrics.GetOrCreateCounter(fmt.Sprintf(`vmauth_user_backend_errors_total{username=%q}`, name))
 		mcr := ui.getMaxConcurrentRequests()
 		ui.concurrencyLimitCh = make(chan struct{}, mcr)
 		ui.concurrencyLimitReached = metrics.GetOrCreateCounter(fmt.Sprintf(`vmauth_user_concurrent_requests_limit_reached_total{username=%q}`, name))
+		ui.rateLimitReached = metrics.GetOrCreateCounter(fmt.Sprintf(`vmauth_user_rate_limit_reached_total{username=%q}`, name))
 		_ = metrics.GetOrCreateGauge(fmt.Sprintf(`vmauth_user_concurrent_requests_capacity{username=%q}`, name), func() float64 {
 			return float64(cap(ui.concurrencyLimitCh))
 		})
 		_ = metrics.GetOrCreateGauge(fmt.Sprintf(`vmauth_user_concurrent_requests_current{username=%q}`, name), func() float64 {
 			return float64(len(ui.concurrencyLimitCh))
 		})
-		byAuthToken[at1] = ui
-		byAuthToken[at2] = ui
+		switch {
+		case ui.JWT != nil:
+			jwtUsers = append(jwtUsers, ui)
+		case ui.TLSClientCert != nil:
+			tlsClientCertUsers = append(tlsClientCertUsers, ui)
+		case ui.passwordHash != nil:
+			passwordHashUsers[ui.Username] = ui
+		default:
+			byAuthToken[at1] = ui
+			byAuthToken[at2] = ui
+			if ui.Username != "" {
+				plainUsernames[ui.Username] = true
+			}
+		}
 	}
+	ac.jwtUsers = jwtUsers
+	ac.passwordHashUsers = passwordHashUsers
+	ac.tlsClientCertUsers = tlsClientCertUsers
 	return byAuthToken, nil
 }
 
+func (ui *UserInfo) initRateLimits() error {
+	if err := ui.RateLimitConf.init(); err != nil {
+		return err
+	}
+	for i := range ui.URLMaps {
+		if err := ui.URLMaps[i].RateLimitConf.init(); err != nil {
+			return fmt.Errorf("invalid `url_map`: %w", err)
+		}
+	}
+	return nil
+}
+
+func (ui *UserInfo) initIPFilters() error {
+	if ui.IPFilters == nil {
+		return nil
+	}
+	if err := ui.IPFilters.init(); err != nil {
+		return fmt.Errorf("invalid `ip_filters` for user %q: %w", ui.name(), err)
+	}
+	return nil
+}
+
+func (ui *UserInfo) initLoadBalancing() error {
+	if ui.URLPrefix != nil {
+		if err := ui.URLPrefix.initLoadBalancing(ui.LoadBalancingPolicy, ui.HealthCheck); err != nil {
+			return err
+		}
+	}
+	if ui.DefaultURL != nil {
+		if err := ui.DefaultURL.initLoadBalancing(ui.LoadBalancingPolicy, ui.HealthCheck); err != nil {
+			return fmt.Errorf("invalid `default_url`: %w", err)
+		}
+	}
+	for i := range ui.URLMaps {
+		e := &ui.URLMaps[i]
+		if e.URLPrefix == nil {
+			continue
+		}
+		policy := e.LoadBalancingPolicy
+		if policy == "" {
+			policy = ui.LoadBalancingPolicy
+		}
+		hc := e.HealthCheck
+		if hc == nil {
+			hc = ui.HealthCheck
+		}
+		if err := e.URLPrefix.initLoadBalancing(policy, hc); err != nil {
+			return fmt.Errorf("invalid `url_map`: %w", err)
+		}
+	}
+	return nil
+}
+
 func (ui *UserInfo) name() string {
 	if ui.Name != "" {
 		return ui.Name
@@ -6,7 +6,9 @@ import (
 	"net/url"
 	"regexp"
 	"testing"
+	"time"
 
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
 	"gopkg.in/yaml.v2"
 )
 
@@ -201,6 +203,280 @@ users:
     headers:
       aaa: bbb
 `)
+
+	// Invalid src_query_args
+	f(`
+users:
+- username: a
+  url_map:
+  - src_query_args: ['foo']
+    url_prefix: http://foobar
+`)
+	f(`
+users:
+- username: a
+  url_map:
+  - src_query_args: ['foo=b[ar']
+    url_prefix: http://foobar
+`)
+	// Invalid src_headers
+	f(`
+users:
+- username: a
+  url_map:
+  - src_headers: ['foobar']
+    url_prefix: http://foobar
+`)
+	// Too small response_cache_ttl
+	f(`
+users:
+- username: a
+  url_map:
+  - src_paths: ['/api/v1/query']
+    url_prefix: http://foobar
+    response_cache_ttl: 100ms
+`)
+
+	// negative rate limits
+	f(`
+users:
+- username: foo
+  url_prefix: http://foobar
+  max_requests_per_second: -1
+`)
+	f(`
+users:
+- username: foo
+  url_map:
+  - src_paths: ['/foobar']
+    url_prefix: http://foobar
+    max_request_bytes_per_second: -1
+`)
+
+	// jwt with bearer_token
+	f(`
+users:
+- name: foo
+  bearer_token: foo
+  jwt:
+    hmac_secret: secret
+  url_prefix: http://foobar
+`)
+	// jwt without name
+	f(`
+users:
+- jwt:
+    hmac_secret: secret
+  url_prefix: http://foobar
+`)
+	// jwt with multiple key sources
+	f(`
+users:
+- name: foo
+  jwt:
+    hmac_secret: secret
+    jwks_file: /path/to/jwks.json
+  url_prefix: http://foobar
+`)
+	// jwt with missing jwks file
+	f(`
+users:
+- name: foo
+  jwt:
+    jwks_file: /non-existing/jwks.json
+  url_prefix: http://foobar
+`)
+	// jwt for unauthorized_user
+	f(`
+unauthorized_user:
+  jwt:
+    hmac_secret: secret
+  url_prefix: http://foobar
+`)
+
+	// invalid password_hash
+	f(`
+users:
+- username: foo
+  password_hash: foobar
+  url_prefix: http://foobar
+`)
+	// password_hash with password
+	f(`
+users:
+- username: foo
+  password: bar
+  password_hash: $2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy
+  url_prefix: http://foobar
+`)
+	// password_hash with bearer_token
+	f(`
+users:
+- bearer_token: foo
+  password_hash: $2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy
+  url_prefix: http://foobar
+`)
+	// duplicate username with password_hash
+	f(`
+users:
+- username: foo
+  password_hash: $2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy
+  url_prefix: http://foobar
+- username: foo
+  password_hash: $2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy
+  url_prefix: http://foobar
+`)
+	// the same username for users with password_hash and password
+	f(`
+users:
+- username: foo
+  password_hash: $2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy
+  url_prefix: http://foobar
+- username: foo
+  password: bar
+  url_prefix: http://foobar
+`)
+	f(`
+users:
+- username: foo
+  url_prefix: http://foobar
+- username: foo
+  password_hash: $2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy
+  url_prefix: http://foobar
+`)
+
+	// tls_client_cert without name
+	f(`
+users:
+- tls_client_cert:
+    common_name: foo
+  url_prefix: http://foobar
+`)
+	// empty tls_client_cert
+	f(`
+users:
+- name: foo
+  tls_client_cert: {}
+  url_prefix: http://foobar
+`)
+	// tls_client_cert with username
+	f(`
+users:
+- name: foo
+  username: foo
+  tls_client_cert:
+    common_name: foo
+  url_prefix: http://foobar
+`)
+	// tls_client_cert for unauthorized_user
+	f(`
+unauthorized_user:
+  tls_client_cert:
+    common_name: foo
+  url_prefix: http://foobar
+`)
+
+	// unsupported load_balancing_policy
+	f(`
+users:
+- username: foo
+  url_prefix: http://foobar
+  load_balancing_policy: random
+`)
+	f(`
+users:
+- username: foo
+  url_map:
+  - src_paths: ["/api/v1/query"]
+    url_prefix: http://foobar
+    load_balancing_policy: random
+`)
+	// non-positive weight
+	f(`
+users:
+- username: foo
+  url_prefix:
+  - url: http://foo
+    weight: 0
+`)
+	// missing url in weighted url_prefix
+	f(`
+users:
+- username: foo
+  url_prefix:
+  - weight: 1
+`)
+	// unknown field in weighted url_prefix
+	f(`
+users:
+- username: foo
+  url_prefix:
+  - url: http://foo
+    weight: 1
+    foo: bar
+`)
+	// invalid health_check
+	f(`
+users:
+- username: foo
+  url_prefix: http://foobar
+  health_check:
+    path: health
+`)
+	f(`
+users:
+- username: foo
+  url_prefix: http://foobar
+  health_check:
+    interval: 1s
+    timeout: 5s
+`)
+
+	// invalid ip_filters
+	f(`
+ip_filters:
+  allow_list: [foobar]
+users:
+- username: foo
+  url_prefix: http://foobar
+`)
+	f(`
+users:
+- username: foo
+  url_prefix: http://foobar
+  ip_filters:
+    deny_list: ["1.2.3.4/40"]
+`)
+	f(`
+unauthorized_user:
+  url_prefix: http://foobar
+  ip_filters:
+    allow_list: ["1.2.3"]
+`)
+
+	// invalid time range matchers
+	f(`
+users:
+- username: foo
+  url_map:
+  - src_max_age: -1h
+    url_prefix: http://foobar
+`)
+	f(`
+users:
+- username: foo
+  url_map:
+  - src_min_age: 0s
+    url_prefix: http://foobar
+`)
+	f(`
+users:
+- username: foo
+  url_map:
+  - src_paths: ["/api/v1/query_range"]
+    split_time_range: true
+    url_prefix: http://foobar
+`)
 }
 
 func TestParseAuthConfigSuccess(t *testing.T) {
@@ -255,6 +531,37 @@ users:
 		},
 	})
 
+	// Weighted url_prefix entries with load balancing policy and health check
+	upWeighted := mustParseURLs([]string{
+		"http://node1:343/bbb",
+		"http://node2:343/bbb",
+	})
+	upWeighted.bus[0].weight = 3
+	upWeighted.bus[1].weight = 1
+	f(`
+users:
+- username: foo
+  url_prefix:
+  - url: http://node1:343/bbb
+    weight: 3
+  - url: http://node2:343/bbb
+    weight: 1
+  load_balancing_policy: round_robin
+  health_check:
+    path: /health
+    interval: 10s
+`, map[string]*UserInfo{
+		getAuthToken("", "foo", ""): {
+			Username:            "foo",
+			URLPrefix:           upWeighted,
+			LoadBalancingPolicy: "round_robin",
+			HealthCheck: &HealthCheckConfig{
+				Path:     "/health",
+				Interval: promutils.NewDuration(10 * time.Second),
+			},
+		},
+	})
+
 	// Multiple users
 	f(`
 users:
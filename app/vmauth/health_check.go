@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+	"github.com/VictoriaMetrics/metrics"
+)
+
+// HealthCheckConfig represents `health_check` config for active health checks of backends at `url_prefix`.
+type HealthCheckConfig struct {
+	// Path is the path to probe at every backend. It is relative to the backend host root.
+	Path string `yaml:"path,omitempty"`
+	// Interval is the interval between probes.
+	Interval *promutils.Duration `yaml:"interval,omitempty"`
+	// Timeout is the timeout for a single probe.
+	Timeout *promutils.Duration `yaml:"timeout,omitempty"`
+	// HealthyThreshold is the number of consecutive successful probes needed for marking unhealthy backend as healthy.
+	HealthyThreshold int `yaml:"healthy_threshold,omitempty"`
+	// UnhealthyThreshold is the number of consecutive failed probes needed for marking healthy backend as unhealthy.
+	UnhealthyThreshold int `yaml:"unhealthy_threshold,omitempty"`
+
+	path               string
+	interval           time.Duration
+	timeout            time.Duration
+	healthyThreshold   int
+	unhealthyThreshold int
+}
+
+func (hc *HealthCheckConfig) init() error {
+	hc.path = hc.Path
+	if hc.path == "" {
+		hc.path = "/health"
+	}
+	if !strings.HasPrefix(hc.path, "/") {
+		return fmt.Errorf("`path` must start with `/`; got %q", hc.path)
+	}
+	hc.interval = hc.Interval.Duration()
+	if hc.interval == 0 {
+		hc.interval = 5 * time.Second
+	}
+	if hc.interval < 0 {
+		return fmt.Errorf("`interval` cannot be negative; got %s", hc.interval)
+	}
+	hc.timeout = hc.Timeout.Duration()
+	if hc.timeout == 0 {
+		hc.timeout = 2 * time.Second
+		if hc.timeout > hc.interval {
+			hc.timeout = hc.interval
+		}
+	}
+	if hc.timeout < 0 || hc.timeout > hc.interval {
+		return fmt.Errorf("`timeout` must be in the range (0...interval]; got %s", hc.timeout)
+	}
+	if hc.HealthyThreshold < 0 || hc.UnhealthyThreshold < 0 {
+		return fmt.Errorf("`healthy_threshold` and `unhealthy_threshold` cannot be negative")
+	}
+	hc.healthyThreshold = hc.HealthyThreshold
+	if hc.healthyThreshold == 0 {
+		hc.healthyThreshold = 1
+	}
+	hc.unhealthyThreshold = hc.UnhealthyThreshold
+	if hc.unhealthyThreshold == 0 {
+		hc.unhealthyThreshold = 3
+	}
+	return nil
+}
+
+var (
+	healthChecksTotal        = metrics.NewCounter(`vmauth_backend_health_checks_total`)
+	healthCheckFailuresTotal = metrics.NewCounter(`vmauth_backend_health_check_failures_total`)
+)
+
+// backendHealth contains the health state of a backend, which is updated by active health checks.
+//
+// The state is shared among all the backends with the same probe and it is preserved across config reloads.
+type backendHealth struct {
+	// unhealthy is set if the backend is marked as unhealthy according to health check thresholds.
+	unhealthy atomic.Bool
+
+	// lastErr contains the error from the last failed probe. It is nil if the last probe was successful.
+	lastErr atomic.Pointer[string]
+}
+
+// healthCheckProbe periodically probes a single backend.
+type healthCheckProbe struct {
+	hc      *HealthCheckConfig
+	u       *url.URL
+	headers []Header
+	health  *backendHealth
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newHealthCheckProbe(hc *HealthCheckConfig, bu *backendURL, headers []Header) *healthCheckProbe {
+	u := *bu.url
+	u.Path = hc.path
+	u.RawPath = ""
+	u.RawQuery = ""
+	return &healthCheckProbe{
+		hc:      hc,
+		u:       &u,
+		headers: headers,
+		health:  &backendHealth{},
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// getHealthCheckProbeKey returns the key for deduplicating probes for bu with the given hc and headers.
+func getHealthCheckProbeKey(hc *HealthCheckConfig, bu *backendURL, headers []Header) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s://%s%s\n", bu.url.Scheme, bu.url.Host, hc.path)
+	fmt.Fprintf(&sb, "interval=%s,timeout=%s,healthy_threshold=%d,unhealthy_threshold=%d\n", hc.interval, hc.timeout, hc.healthyThreshold, hc.unhealthyThreshold)
+	if bu.url.User != nil {
+		fmt.Fprintf(&sb, "user=%s\n", bu.url.User.String())
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&sb, "%s: %s\n", h.Name, h.Value)
+	}
+	return sb.String()
+}
+
+// probe sends health check request to the backend.
+func (p *healthCheckProbe) probe() error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.hc.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("cannot create health check request: %w", err)
+	}
+	if ui := p.u.User; ui != nil {
+		password, _ := ui.Password()
+		req.SetBasicAuth(ui.Username(), password)
+	}
+	updateHeadersByConfig(req.Header, p.headers)
+	transportOnce.Do(transportInit)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	// Drain the response body, so the connection could be re-used.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status code %d from %s; want 2xx", resp.StatusCode, p.u.Redacted())
+	}
+	return nil
+}
+
+// run periodically probes the backend until p.stopCh is closed and updates p.health according to thresholds.
+func (p *healthCheckProbe) run() {
+	hc := p.hc
+	ticker := time.NewTicker(hc.interval)
+	defer ticker.Stop()
+	successes, failures := 0, 0
+	for {
+		healthChecksTotal.Inc()
+		if err := p.probe(); err != nil {
+			healthCheckFailuresTotal.Inc()
+			errStr := err.Error()
+			p.health.lastErr.Store(&errStr)
+			successes = 0
+			failures++
+			if failures >= hc.unhealthyThreshold && !p.health.unhealthy.Load() {
+				logger.Warnf("marking backend %s as unhealthy after %d failed health checks; last error: %s", p.u.Redacted(), failures, err)
+				p.health.unhealthy.Store(true)
+			}
+		} else {
+			p.health.lastErr.Store(nil)
+			failures = 0
+			successes++
+			if successes >= hc.healthyThreshold && p.health.unhealthy.Load() {
+				logger.Infof("marking backend %s as healthy after %d successful health checks", p.u.Redacted(), successes)
+				p.health.unhealthy.Store(false)
+			}
+		}
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *healthCheckProbe) start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.run()
+	}()
+}
+
+func (p *healthCheckProbe) stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+// healthChecker runs active health checks for backends.
+//
+// Every unique probe runs only once, even if the same backend is referred by multiple users and routes.
+type healthChecker struct {
+	mu     sync.Mutex
+	probes map[string]*healthCheckProbe
+}
+
+func newHealthChecker() *healthChecker {
+	return &healthChecker{
+		probes: make(map[string]*healthCheckProbe),
+	}
+}
+
+// healthChecks runs active health checks for backends from the currently loaded -auth.config.
+var healthChecks = newHealthChecker()
+
+// update starts health checks for backends with `health_check` config at ac and stops health checks, which are missing in ac.
+//
+// The health state of backends is preserved for probes, which exist both in the previous config and in ac.
+func (hcr *healthChecker) update(ac *AuthConfig) {
+	hcr.mu.Lock()
+	defer hcr.mu.Unlock()
+
+	probes := make(map[string]*healthCheckProbe)
+	for _, r := range ac.getURLPrefixRoutes() {
+		hc := r.up.healthCheck
+		if hc == nil {
+			continue
+		}
+		for _, bu := range r.up.bus {
+			key := getHealthCheckProbeKey(hc, bu, r.headers)
+			p := probes[key]
+			if p == nil {
+				p = hcr.probes[key]
+				if p == nil {
+					p = newHealthCheckProbe(hc, bu, r.headers)
+					p.start()
+				}
+				probes[key] = p
+			}
+			bu.health = p.health
+		}
+	}
+	for key, p := range hcr.probes {
+		if probes[key] == nil {
+			p.stop()
+		}
+	}
+	hcr.probes = probes
+}
+
+// stop stops all the health checks started via update.
+func (hcr *healthChecker) stop() {
+	hcr.mu.Lock()
+	defer hcr.mu.Unlock()
+
+	for _, p := range hcr.probes {
+		p.stop()
+	}
+	hcr.probes = make(map[string]*healthCheckProbe)
+}
+
+// urlPrefixRoute is a url_prefix for the given user and route.
+type urlPrefixRoute struct {
+	user    string
+	route   string
+	up      *URLPrefix
+	headers []Header
+}
+
+// getURLPrefixRoutes returns all the url_prefix entries from ac.
+func (ac *AuthConfig) getURLPrefixRoutes() []urlPrefixRoute {
+	var rs []urlPrefixRoute
+	addUser := func(user string, ui *UserInfo) {
+		if ui.URLPrefix != nil {
+			rs = append(rs, urlPrefixRoute{user: user, route: "url_prefix", up: ui.URLPrefix, headers: ui.HeadersConf.RequestHeaders})
+		}
+		for i, e := range ui.URLMaps {
+			if e.URLPrefix != nil {
+				rs = append(rs, urlPrefixRoute{user: user, route: fmt.Sprintf("url_map[%d]", i), up: e.URLPrefix, headers: e.HeadersConf.RequestHeaders})
+			}
+		}
+		if ui.DefaultURL != nil {
+			rs = append(rs, urlPrefixRoute{user: user, route: "default_url", up: ui.DefaultURL, headers: ui.HeadersConf.RequestHeaders})
+		}
+	}
+	for i := range ac.Users {
+		ui := &ac.Users[i]
+		addUser(ui.name(), ui)
+	}
+	if ac.UnauthorizedUser != nil {
+		addUser("unauthorized_user", ac.UnauthorizedUser)
+	}
+	return rs
+}
+
+type backendStatus struct {
+	User                string `json:"user"`
+	Route               string `json:"route"`
+	LoadBalancingPolicy string `json:"load_balancing_policy"`
+	URL                 string `json:"url"`
+	Weight              int    `json:"weight"`
+	ConcurrentRequests  int32  `json:"concurrent_requests"`
+	Broken              bool   `json:"broken"`
+	Healthy             *bool  `json:"healthy,omitempty"`
+	HealthCheckError    string `json:"health_check_error,omitempty"`
+}
+
+// writeBackendsStatus writes the status of all the backends from ac in JSON to w.
+func writeBackendsStatus(w http.ResponseWriter, ac *AuthConfig) {
+	bss := []backendStatus{}
+	for _, r := range ac.getURLPrefixRoutes() {
+		for _, bu := range r.up.bus {
+			bs := backendStatus{
+				User:                r.user,
+				Route:               r.route,
+				LoadBalancingPolicy: r.up.loadBalancingPolicy,
+				URL:                 bu.url.Redacted(),
+				Weight:              bu.getWeight(),
+				ConcurrentRequests:  atomic.LoadInt32(&bu.concurrentRequests),
+				Broken:              bu.isBroken(),
+			}
+			if bh := bu.health; bh != nil {
+				healthy := !bh.unhealthy.Load()
+				bs.Healthy = &healthy
+				if errStr := bh.lastErr.Load(); errStr != nil {
+					bs.HealthCheckError = *errStr
+				}
+			}
+			bss = append(bss, bs)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(bss)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestHealthCheckConfigInit(t *testing.T) {
+	f := func(s string, resultExpected bool) {
+		t.Helper()
+		var hc HealthCheckConfig
+		if err := yaml.UnmarshalStrict([]byte(s), &hc); err != nil {
+			t.Fatalf("cannot parse %q: %s", s, err)
+		}
+		err := hc.init()
+		if resultExpected && err != nil {
+			t.Fatalf("unexpected error for %q: %s", s, err)
+		}
+		if !resultExpected && err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+
+	f(`{}`, true)
+	f(`{path: /ready, interval: 10s, timeout: 1s, healthy_threshold: 2, unhealthy_threshold: 5}`, true)
+	f(`{interval: 1s}`, true)
+
+	f(`{path: health}`, false)
+	f(`{interval: 1s, timeout: 2s}`, false)
+	f(`{healthy_threshold: -1}`, false)
+	f(`{unhealthy_threshold: -1}`, false)
+}
+
+func TestHealthCheckRun(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	hc := &HealthCheckConfig{
+		HealthyThreshold:   2,
+		UnhealthyThreshold: 2,
+	}
+	up := mustParseURL(backend.URL + "/select/0/prometheus")
+	if err := up.initLoadBalancing("", hc); err != nil {
+		t.Fatalf("cannot init load balancing: %s", err)
+	}
+	// Speed up the test
+	hc.interval = 10 * time.Millisecond
+	hc.timeout = time.Second
+	ac := &AuthConfig{
+		Users: []UserInfo{{
+			Name:      "foo",
+			URLPrefix: up,
+		}},
+	}
+	hcr := newHealthChecker()
+	hcr.update(ac)
+	defer hcr.stop()
+
+	bu := up.bus[0]
+	waitFor := func(unhealthy bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for bu.health.unhealthy.Load() != unhealthy {
+			if time.Now().After(deadline) {
+				t.Fatalf("timeout when waiting for unhealthy=%v", unhealthy)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	healthy.Store(false)
+	waitFor(true)
+	if !bu.isBroken() {
+		t.Fatalf("unhealthy backend must be broken")
+	}
+
+	w := httptest.NewRecorder()
+	writeBackendsStatus(w, ac)
+	var bss []backendStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &bss); err != nil {
+		t.Fatalf("cannot parse backends status %q: %s", w.Body.String(), err)
+	}
+	if len(bss) != 1 {
+		t.Fatalf("unexpected number of backends; got %d; want 1", len(bss))
+	}
+	bs := bss[0]
+	if bs.User != "foo" || bs.Route != "url_prefix" || bs.LoadBalancingPolicy != "least_loaded" || !bs.Broken || bs.Healthy == nil || *bs.Healthy {
+		t.Fatalf("unexpected backend status: %s", w.Body.String())
+	}
+	if !strings.Contains(bs.HealthCheckError, "503") {
+		t.Fatalf("unexpected health check error: %q", bs.HealthCheckError)
+	}
+
+	healthy.Store(true)
+	waitFor(false)
+}
+
+func TestHealthCheckProbeRequest(t *testing.T) {
+	var lastReq atomic.Pointer[http.Request]
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastReq.Store(r)
+	}))
+	defer backend.Close()
+
+	hc := &HealthCheckConfig{Path: "/ready"}
+	if err := hc.init(); err != nil {
+		t.Fatalf("cannot init health check: %s", err)
+	}
+	u, err := url.Parse(backend.URL + "/select/0/prometheus?extra_label=team=dev")
+	if err != nil {
+		t.Fatalf("cannot parse url: %s", err)
+	}
+	u.User = url.UserPassword("foo", "bar")
+	headers := []Header{{Name: "X-Scope-OrgID", Value: "tenant1"}}
+	p := newHealthCheckProbe(hc, &backendURL{url: u}, headers)
+	if err := p.probe(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	r := lastReq.Load()
+	if uri := r.URL.RequestURI(); uri != "/ready" {
+		t.Fatalf("unexpected health check path; got %q; want %q", uri, "/ready")
+	}
+	if v := r.Header.Get("X-Scope-OrgID"); v != "tenant1" {
+		t.Fatalf("unexpected X-Scope-OrgID header; got %q; want %q", v, "tenant1")
+	}
+	if username, password, ok := r.BasicAuth(); !ok || username != "foo" || password != "bar" {
+		t.Fatalf("unexpected basic auth; got username=%q, password=%q", username, password)
+	}
+}
+
+func TestHealthCheckerUpdate(t *testing.T) {
+	var probes atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+	}))
+	defer backend.Close()
+
+	mustParse := func(data string) *AuthConfig {
+		t.Helper()
+		ac, err := parseAuthConfig([]byte(data))
+		if err != nil {
+			t.Fatalf("cannot parse auth config: %s", err)
+		}
+		if _, err := parseAuthConfigUsers(ac); err != nil {
+			t.Fatalf("cannot parse auth config users: %s", err)
+		}
+		return ac
+	}
+	getHealth := func(ac *AuthConfig) []*backendHealth {
+		var bhs []*backendHealth
+		for _, r := range ac.getURLPrefixRoutes() {
+			for _, bu := range r.up.bus {
+				bhs = append(bhs, bu.health)
+			}
+		}
+		return bhs
+	}
+
+	hcr := newHealthChecker()
+	defer hcr.stop()
+
+	// The same backend referred by multiple users must be probed only once
+	cfg := `
+users:
+- username: foo
+  url_prefix: ` + backend.URL + `/select/0/prometheus
+  health_check: {interval: 1h}
+- username: bar
+  url_prefix: ` + backend.URL + `/select/1/prometheus
+  health_check: {interval: 1h}
+- username: baz
+  url_prefix: ` + backend.URL + `/select/2/prometheus
+  headers:
+  - "X-Scope-OrgID: tenant2"
+  health_check: {interval: 1h}
+`
+	ac := mustParse(cfg)
+	hcr.update(ac)
+	if n := len(hcr.probes); n != 2 {
+		t.Fatalf("unexpected number of probes; got %d; want 2", n)
+	}
+	bhs := getHealth(ac)
+	if bhs[0] == nil || bhs[0] != bhs[1] || bhs[0] == bhs[2] {
+		t.Fatalf("unexpected health state sharing among backends")
+	}
+	bhs[0].unhealthy.Store(true)
+
+	// The health state must be preserved across config reloads
+	acNew := mustParse(cfg)
+	hcr.update(acNew)
+	if n := len(hcr.probes); n != 2 {
+		t.Fatalf("unexpected number of probes after reload; got %d; want 2", n)
+	}
+	bhsNew := getHealth(acNew)
+	if bhsNew[0] != bhs[0] || !bhsNew[0].unhealthy.Load() {
+		t.Fatalf("the health state must be preserved across config reloads")
+	}
+
+	// Probes must be stopped for backends without health_check
+	acNew = mustParse(`
+users:
+- username: foo
+  url_prefix: ` + backend.URL + `/select/0/prometheus
+`)
+	hcr.update(acNew)
+	if n := len(hcr.probes); n != 0 {
+		t.Fatalf("unexpected number of probes; got %d; want 0", n)
+	}
+	if bh := getHealth(acNew)[0]; bh != nil {
+		t.Fatalf("unexpected health state for backend without health_check")
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/VictoriaMetrics/metricsql"
+)
+
+var injectLabelFiltersRejectedRequests = metrics.NewCounter(`vmauth_inject_label_filters_rejected_requests_total`)
+
+// InjectLabelFilters represents `inject_label_filters` option in the form `{label1="value1",...,labelN="valueN"}`.
+//
+// The label filters are added to every series selector in MetricsQL queries and `match[]` (or `match`) args proxied for the user.
+type InjectLabelFilters struct {
+	sOriginal string
+	lfs       []metricsql.LabelFilter
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (ilf *InjectLabelFilters) UnmarshalYAML(f func(interface{}) error) error {
+	var s string
+	if err := f(&s); err != nil {
+		return err
+	}
+	e, err := metricsql.Parse(s)
+	if err != nil {
+		return fmt.Errorf("cannot parse `inject_label_filters`: %w", err)
+	}
+	me, ok := e.(*metricsql.MetricExpr)
+	if !ok || len(me.LabelFilterss) != 1 || len(me.LabelFilterss[0]) == 0 {
+		return fmt.Errorf("`inject_label_filters` must contain label filters in the form `{label1=\"value1\",...,labelN=\"valueN\"}`; got %q", s)
+	}
+	for _, lf := range me.LabelFilterss[0] {
+		if lf.Label == "__name__" {
+			return fmt.Errorf("`inject_label_filters` cannot contain filters on metric name; got %q", s)
+		}
+	}
+	ilf.sOriginal = s
+	ilf.lfs = me.LabelFilterss[0]
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (ilf *InjectLabelFilters) MarshalYAML() (interface{}, error) {
+	return ilf.sOriginal, nil
+}
+
+// rewriteRequest injects ilf into MetricsQL queries and series selectors at r.
+//
+// Only requests to read endpoints with series selectors are allowed, since other requests cannot be safely rewritten.
+func (ilf *InjectLabelFilters) rewriteRequest(r *http.Request) error {
+	if err := ilf.rewriteRequestInternal(r); err != nil {
+		injectLabelFiltersRejectedRequests.Inc()
+		return err
+	}
+	return nil
+}
+
+func (ilf *InjectLabelFilters) rewriteRequestInternal(r *http.Request) error {
+	// Use normalized path in order to prevent from bypassing the checks below with `..` in the path.
+	path := normalizeURL(r.URL).Path
+	var argNames []string
+	addMatch := false
+	switch {
+	case strings.HasSuffix(path, "/api/v1/query"), strings.HasSuffix(path, "/api/v1/query_range"):
+		argNames = queryArgNames
+	case strings.HasSuffix(path, "/api/v1/series"), strings.HasSuffix(path, "/api/v1/export"):
+		argNames = matchArgNames
+	case strings.HasSuffix(path, "/api/v1/labels"), isLabelValuesPath(path):
+		argNames = matchArgNames
+		// Restrict label names and values to series matching ilf if match[] args are missing
+		addMatch = true
+	case strings.HasSuffix(path, "/api/v1/status/buildinfo"):
+		// This endpoint doesn't expose any data. It is requested by Grafana.
+		return nil
+	default:
+		return &httpserver.ErrorWithStatusCode{
+			Err:        fmt.Errorf("requests to %q aren't allowed for users with `inject_label_filters`", path),
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	// Rewrite url-encoded request body, since the backend merges it with query args.
+	if hasRequestBody(r) && !isFormBody(r) {
+		return &httpserver.ErrorWithStatusCode{
+			Err:        fmt.Errorf("unsupported Content-Type=%q for request to %q; only application/x-www-form-urlencoded is supported for users with `inject_label_filters`", r.Header.Get("Content-Type"), path),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	form, err := getFormBody(r)
+	if err != nil {
+		return err
+	}
+	if form != nil {
+		if hasArgs(form, argNames) {
+			// match[] args from request body are taken into account by the backend, so there is no need in adding match[] to query args.
+			addMatch = false
+		}
+		if err := ilf.rewriteArgs(form, argNames, false); err != nil {
+			return err
+		}
+		setFormBody(r, form)
+	}
+
+	args := r.URL.Query()
+	if err := ilf.rewriteArgs(args, argNames, addMatch); err != nil {
+		return err
+	}
+	r.URL.RawQuery = args.Encode()
+	return nil
+}
+
+func isLabelValuesPath(path string) bool {
+	n := strings.LastIndex(path, "/api/v1/label/")
+	if n < 0 {
+		return false
+	}
+	tail := path[n+len("/api/v1/label/"):]
+	return strings.HasSuffix(tail, "/values") && strings.Count(tail, "/") == 1
+}
+
+var (
+	queryArgNames = []string{"query"}
+
+	// matchArgNames contains arg names with series selectors. The backend accepts both `match[]` and `match` args.
+	matchArgNames = []string{"match[]", "match"}
+)
+
+func hasArgs(args url.Values, argNames []string) bool {
+	for _, argName := range argNames {
+		if len(args[argName]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteArgs injects ilf into all the args with the given argNames.
+//
+// If addMatch is set and args have none of argNames, then match[] arg with ilf is added.
+func (ilf *InjectLabelFilters) rewriteArgs(args url.Values, argNames []string, addMatch bool) error {
+	if !hasArgs(args, argNames) {
+		if addMatch {
+			args.Set("match[]", string(ilf.appendSelector(nil)))
+		}
+		return nil
+	}
+	for _, argName := range argNames {
+		values := args[argName]
+		for i, v := range values {
+			var s string
+			var err error
+			if argName == "query" {
+				s, err = ilf.injectQuery(v)
+			} else {
+				s, err = ilf.injectSelector(v)
+			}
+			if err != nil {
+				return &httpserver.ErrorWithStatusCode{
+					Err:        fmt.Errorf("cannot inject label filters into %s=%q: %w", argName, v, err),
+					StatusCode: http.StatusBadRequest,
+				}
+			}
+			values[i] = s
+		}
+	}
+	return nil
+}
+
+// injectQuery adds ilf to every series selector in MetricsQL query q.
+func (ilf *InjectLabelFilters) injectQuery(q string) (string, error) {
+	e, err := metricsql.Parse(q)
+	if err != nil {
+		return "", err
+	}
+	metricsql.VisitAll(e, func(expr metricsql.Expr) {
+		if me, ok := expr.(*metricsql.MetricExpr); ok {
+			ilf.injectMetricExpr(me)
+		}
+	})
+	return string(e.AppendString(nil)), nil
+}
+
+// injectSelector adds ilf to series selector s.
+func (ilf *InjectLabelFilters) injectSelector(s string) (string, error) {
+	e, err := metricsql.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	me, ok := e.(*metricsql.MetricExpr)
+	if !ok {
+		return "", fmt.Errorf("expecting series selector; got %q", e.AppendString(nil))
+	}
+	ilf.injectMetricExpr(me)
+	return string(me.AppendString(nil)), nil
+}
+
+func (ilf *InjectLabelFilters) injectMetricExpr(me *metricsql.MetricExpr) {
+	if len(me.LabelFilterss) == 0 {
+		me.LabelFilterss = [][]metricsql.LabelFilter{append([]metricsql.LabelFilter{}, ilf.lfs...)}
+		return
+	}
+	for i, lfs := range me.LabelFilterss {
+		// Copy label filters, since they may be shared among multiple expressions after WITH templates expansion.
+		lfsNew := make([]metricsql.LabelFilter, 0, len(lfs)+len(ilf.lfs))
+		lfsNew = append(lfsNew, lfs...)
+		lfsNew = append(lfsNew, ilf.lfs...)
+		me.LabelFilterss[i] = lfsNew
+	}
+}
+
+func (ilf *InjectLabelFilters) appendSelector(dst []byte) []byte {
+	me := &metricsql.MetricExpr{}
+	ilf.injectMetricExpr(me)
+	return me.AppendString(dst)
+}
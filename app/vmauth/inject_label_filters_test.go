@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
+	"gopkg.in/yaml.v2"
+)
+
+func mustParseInjectLabelFilters(s string) *InjectLabelFilters {
+	var ilf InjectLabelFilters
+	if err := yaml.UnmarshalStrict([]byte(s), &ilf); err != nil {
+		panic(err)
+	}
+	return &ilf
+}
+
+func TestInjectLabelFiltersUnmarshalFailure(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		var ilf InjectLabelFilters
+		if err := yaml.UnmarshalStrict([]byte(s), &ilf); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+
+	f(`'{'`)
+	f(`'foo'`)
+	f(`'{__name__="foo"}'`)
+	f(`'{a="b" or c="d"}'`)
+	f(`'sum(foo{a="b"})'`)
+	f(`'1'`)
+}
+
+func TestInjectLabelFiltersInjectQuery(t *testing.T) {
+	ilf := mustParseInjectLabelFilters(`'{customer="X"}'`)
+	f := func(q, expected string) {
+		t.Helper()
+		result, err := ilf.injectQuery(q)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", q, err)
+		}
+		if result != expected {
+			t.Fatalf("unexpected result for %q;\ngot\n%s\nwant\n%s", q, result, expected)
+		}
+	}
+
+	f(`up`, `up{customer="X"}`)
+	f(`{job="foo"}`, `{job="foo",customer="X"}`)
+	f(`{job="foo" or job="bar"}`, `{job="foo",customer="X" or job="bar",customer="X"}`)
+	f(`rate(http_requests_total{job="foo"}[5m])`, `rate(http_requests_total{job="foo",customer="X"}[5m])`)
+	f(`sum(a) by (job) / on(job) group_left() count(b{customer="Y"})`,
+		`sum(a{customer="X"}) by(job) / on(job) group_left() count(b{customer="Y",customer="X"})`)
+	f(`max_over_time(rate(foo[1m])[1h:5m])`, `max_over_time(rate(foo{customer="X"}[1m])[1h:5m])`)
+	// WITH templates are expanded
+	f(`WITH (x = foo{a="b"}) x + x`, `foo{a="b",customer="X"} + foo{a="b",customer="X"}`)
+	f(`1 + 2`, `3`)
+}
+
+func TestInjectLabelFiltersRewriteRequestSuccess(t *testing.T) {
+	ilf := mustParseInjectLabelFilters(`'{customer="X",env=~"prod|dev"}'`)
+	f := func(method, requestURI, body, expectedQuery, expectedBody string) {
+		t.Helper()
+		r := httptest.NewRequest(method, requestURI, strings.NewReader(body))
+		if body != "" {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if err := ilf.rewriteRequest(r); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		query, err := url.QueryUnescape(r.URL.RawQuery)
+		if err != nil {
+			t.Fatalf("cannot unescape query: %s", err)
+		}
+		if query != expectedQuery {
+			t.Fatalf("unexpected query args;\ngot\n%s\nwant\n%s", query, expectedQuery)
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("cannot read body: %s", err)
+		}
+		bodyStr, err := url.QueryUnescape(string(data))
+		if err != nil {
+			t.Fatalf("cannot unescape body: %s", err)
+		}
+		if bodyStr != expectedBody {
+			t.Fatalf("unexpected body;\ngot\n%s\nwant\n%s", bodyStr, expectedBody)
+		}
+		if body != "" && r.ContentLength != int64(len(data)) {
+			t.Fatalf("unexpected ContentLength; got %d; want %d", r.ContentLength, len(data))
+		}
+	}
+
+	f("GET", "/api/v1/query?query=up&time=123", "", `query=up{customer="X",env=~"prod|dev"}&time=123`, "")
+	f("GET", "/select/0/prometheus/api/v1/query_range?query=sum(rate(foo[5m]))&step=1m", "",
+		`query=sum(rate(foo{customer="X",env=~"prod|dev"}[5m]))&step=1m`, "")
+	// all the query args are rewritten
+	f("GET", "/api/v1/query?query=up&query=foo", "", `query=up{customer="X",env=~"prod|dev"}&query=foo{customer="X",env=~"prod|dev"}`, "")
+	// POST form
+	f("POST", "/api/v1/query?step=1m", "query=up", `step=1m`, `query=up{customer="X",env=~"prod|dev"}`)
+	f("POST", "/api/v1/query?query=foo", "query=up", `query=foo{customer="X",env=~"prod|dev"}`, `query=up{customer="X",env=~"prod|dev"}`)
+
+	f("GET", "/api/v1/series?match[]=up&match[]={job=~\"a.*\"}", "", `match[]=up{customer="X",env=~"prod|dev"}&match[]={job=~"a.*",customer="X",env=~"prod|dev"}`, "")
+	f("GET", "/api/v1/export?match[]=up", "", `match[]=up{customer="X",env=~"prod|dev"}`, "")
+	// match[] is added to labels requests
+	f("GET", "/api/v1/labels", "", `match[]={customer="X",env=~"prod|dev"}`, "")
+	f("GET", "/api/v1/label/job/values?start=1", "", `match[]={customer="X",env=~"prod|dev"}&start=1`, "")
+	f("GET", "/api/v1/label/job/values?match[]=up", "", `match[]=up{customer="X",env=~"prod|dev"}`, "")
+	f("POST", "/api/v1/labels", "match[]=up", ``, `match[]=up{customer="X",env=~"prod|dev"}`)
+	// match args are rewritten in the same way as match[] args
+	f("GET", "/api/v1/export?match=up", "", `match=up{customer="X",env=~"prod|dev"}`, "")
+	f("GET", "/api/v1/series?match[]=up&match=foo", "", `match=foo{customer="X",env=~"prod|dev"}&match[]=up{customer="X",env=~"prod|dev"}`, "")
+	f("GET", "/api/v1/labels?match=up", "", `match=up{customer="X",env=~"prod|dev"}`, "")
+	f("GET", "/api/v1/label/job/values?match=up", "", `match=up{customer="X",env=~"prod|dev"}`, "")
+	f("POST", "/api/v1/export", "match=up", ``, `match=up{customer="X",env=~"prod|dev"}`)
+	f("POST", "/api/v1/series?match=foo", "match=up", `match=foo{customer="X",env=~"prod|dev"}`, `match=up{customer="X",env=~"prod|dev"}`)
+	f("POST", "/api/v1/label/job/values", "match=up", ``, `match=up{customer="X",env=~"prod|dev"}`)
+
+	f("GET", "/api/v1/status/buildinfo", "", "", "")
+}
+
+func TestInjectLabelFiltersRewriteRequestFailure(t *testing.T) {
+	ilf := mustParseInjectLabelFilters(`'{customer="X"}'`)
+	f := func(method, requestURI, contentType, body string, expectedStatusCode int) {
+		t.Helper()
+		r := httptest.NewRequest(method, requestURI, strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		err := ilf.rewriteRequest(r)
+		if err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+		var esc *httpserver.ErrorWithStatusCode
+		if !errors.As(err, &esc) {
+			t.Fatalf("expecting error with status code; got %T: %s", err, err)
+		}
+		if esc.StatusCode != expectedStatusCode {
+			t.Fatalf("unexpected status code; got %d; want %d; error: %s", esc.StatusCode, expectedStatusCode, err)
+		}
+	}
+
+	// unsupported paths
+	f("GET", "/federate?match[]=up", "", "", http.StatusForbidden)
+	f("GET", "/api/v1/export/native?match[]=up", "", "", http.StatusForbidden)
+	f("GET", "/api/v1/query/../export/native?match[]=up", "", "", http.StatusForbidden)
+	f("GET", "/api/v1/status/tsdb", "", "", http.StatusForbidden)
+	f("POST", "/api/v1/write", "application/x-protobuf", "foo", http.StatusForbidden)
+	// invalid queries
+	f("GET", "/api/v1/query?query=sum(", "", "", http.StatusBadRequest)
+	f("GET", "/api/v1/series?match[]=sum(up)", "", "", http.StatusBadRequest)
+	f("GET", "/api/v1/export?match=sum(up)", "", "", http.StatusBadRequest)
+	f("POST", "/api/v1/labels", "application/x-www-form-urlencoded", "match=sum(up)", http.StatusBadRequest)
+	f("POST", "/api/v1/query", "application/x-www-form-urlencoded", "query=sum(", http.StatusBadRequest)
+	// unsupported body
+	f("POST", "/api/v1/query", "multipart/form-data", "query=up", http.StatusBadRequest)
+}
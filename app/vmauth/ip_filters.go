@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/flagutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+)
+
+var trustedProxies = flagutil.NewArrayString("ipFilters.trustedProxies", "Optional list of IP addresses or CIDRs of trusted proxies in front of vmauth. "+
+	"Client IP for ip_filters is determined from X-Forwarded-For request header if the request is received from a trusted proxy. "+
+	"See https://docs.victoriametrics.com/vmauth.html#ip-filters")
+
+// trustedProxyPrefixes contains parsed -ipFilters.trustedProxies
+var trustedProxyPrefixes []netip.Prefix
+
+func initTrustedProxies() {
+	prefixes, err := parseIPPrefixes(*trustedProxies)
+	if err != nil {
+		logger.Fatalf("cannot parse -ipFilters.trustedProxies: %s", err)
+	}
+	trustedProxyPrefixes = prefixes
+}
+
+// IPFilters represents `ip_filters` config.
+type IPFilters struct {
+	// AllowList contains IP addresses and CIDRs, which are allowed to send requests.
+	// All the addresses are allowed if AllowList is empty.
+	AllowList []string `yaml:"allow_list,omitempty"`
+	// DenyList contains IP addresses and CIDRs, which are denied to send requests.
+	// DenyList has priority over AllowList.
+	DenyList []string `yaml:"deny_list,omitempty"`
+
+	allowList []netip.Prefix
+	denyList  []netip.Prefix
+}
+
+func (ipf *IPFilters) init() error {
+	allowList, err := parseIPPrefixes(ipf.AllowList)
+	if err != nil {
+		return fmt.Errorf("cannot parse `allow_list`: %w", err)
+	}
+	denyList, err := parseIPPrefixes(ipf.DenyList)
+	if err != nil {
+		return fmt.Errorf("cannot parse `deny_list`: %w", err)
+	}
+	ipf.allowList = allowList
+	ipf.denyList = denyList
+	return nil
+}
+
+// isAllowed returns true if requests from the given ip are allowed by ipf.
+//
+// Requests are always allowed if ipf is nil.
+func (ipf *IPFilters) isAllowed(ip netip.Addr) bool {
+	if ipf == nil {
+		return true
+	}
+	if len(ipf.allowList) > 0 && !containsIP(ipf.allowList, ip) {
+		return false
+	}
+	return !containsIP(ipf.denyList, ip)
+}
+
+// checkRequest returns non-nil error if the request r isn't allowed by ipf.
+func (ipf *IPFilters) checkRequest(r *http.Request) error {
+	if ipf == nil {
+		return nil
+	}
+	ip, ok := getClientIP(r, trustedProxyPrefixes)
+	if !ok {
+		return fmt.Errorf("cannot determine client ip for remoteAddr=%q", r.RemoteAddr)
+	}
+	if !ipf.isAllowed(ip) {
+		return fmt.Errorf("access from ip %s is denied by ip_filters", ip)
+	}
+	return nil
+}
+
+// getClientIP returns the client ip for r.
+//
+// X-Forwarded-For header is taken into account only if the request is received from a trusted proxy.
+// In this case the rightmost address from X-Forwarded-For, which doesn't belong to trusted proxies, is returned,
+// since the addresses to the left of it can be spoofed by the client.
+func getClientIP(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	ip = ip.Unmap()
+	if !containsIP(trusted, ip) {
+		return ip, true
+	}
+	xffs := r.Header.Values("X-Forwarded-For")
+	for i := len(xffs) - 1; i >= 0; i-- {
+		addrs := strings.Split(xffs[i], ",")
+		for j := len(addrs) - 1; j >= 0; j-- {
+			addr := strings.TrimSpace(addrs[j])
+			if addr == "" {
+				continue
+			}
+			xffIP, err := netip.ParseAddr(addr)
+			if err != nil {
+				// The address has been added by trusted proxy, but it cannot be parsed.
+				return netip.Addr{}, false
+			}
+			ip = xffIP.Unmap()
+			if !containsIP(trusted, ip) {
+				return ip, true
+			}
+		}
+	}
+	// All the addresses belong to trusted proxies. Return the leftmost address.
+	return ip, true
+}
+
+func containsIP(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPPrefixes parses IP addresses and CIDRs from a.
+func parseIPPrefixes(a []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, s := range a {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if strings.Contains(s, "/") {
+			p, err := netip.ParsePrefix(s)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		ip, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, err
+		}
+		ip = ip.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(ip, ip.BitLen()))
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestIPFiltersIsAllowed(t *testing.T) {
+	f := func(allowList, denyList []string, ip string, resultExpected bool) {
+		t.Helper()
+		ipf := &IPFilters{
+			AllowList: allowList,
+			DenyList:  denyList,
+		}
+		if err := ipf.init(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result := ipf.isAllowed(netip.MustParseAddr(ip))
+		if result != resultExpected {
+			t.Fatalf("unexpected result for ip=%s; got %v; want %v", ip, result, resultExpected)
+		}
+	}
+
+	// empty filters
+	f(nil, nil, "1.2.3.4", true)
+
+	// allow list
+	f([]string{"10.0.0.0/8", "192.168.1.1"}, nil, "10.1.2.3", true)
+	f([]string{"10.0.0.0/8", "192.168.1.1"}, nil, "192.168.1.1", true)
+	f([]string{"10.0.0.0/8", "192.168.1.1"}, nil, "192.168.1.2", false)
+	f([]string{"2001:db8::/32"}, nil, "2001:db8::1", true)
+	f([]string{"2001:db8::/32"}, nil, "1.2.3.4", false)
+
+	// deny list
+	f(nil, []string{"1.2.3.0/24"}, "1.2.3.4", false)
+	f(nil, []string{"1.2.3.0/24"}, "1.2.4.4", true)
+
+	// deny list has priority over allow list
+	f([]string{"10.0.0.0/8"}, []string{"10.0.0.1"}, "10.0.0.1", false)
+	f([]string{"10.0.0.0/8"}, []string{"10.0.0.1"}, "10.0.0.2", true)
+
+	// nil filters allow everything
+	var ipf *IPFilters
+	if !ipf.isAllowed(netip.MustParseAddr("1.2.3.4")) {
+		t.Fatalf("nil ip_filters must allow all the requests")
+	}
+}
+
+func TestIPFiltersInitFailure(t *testing.T) {
+	f := func(allowList, denyList []string) {
+		t.Helper()
+		ipf := &IPFilters{
+			AllowList: allowList,
+			DenyList:  denyList,
+		}
+		if err := ipf.init(); err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+
+	f([]string{"foobar"}, nil)
+	f([]string{"1.2.3.4/33"}, nil)
+	f(nil, []string{"1.2.3"})
+	f(nil, []string{"::1/129"})
+}
+
+func TestGetClientIP(t *testing.T) {
+	f := func(remoteAddr string, xffs []string, trustedProxies []string, ipExpected string) {
+		t.Helper()
+		trusted, err := parseIPPrefixes(trustedProxies)
+		if err != nil {
+			t.Fatalf("cannot parse trusted proxies: %s", err)
+		}
+		r := &http.Request{
+			RemoteAddr: remoteAddr,
+			Header:     make(http.Header),
+		}
+		for _, xff := range xffs {
+			r.Header.Add("X-Forwarded-For", xff)
+		}
+		ip, ok := getClientIP(r, trusted)
+		if ipExpected == "" {
+			if ok {
+				t.Fatalf("expecting failure; got ip=%s", ip)
+			}
+			return
+		}
+		if !ok {
+			t.Fatalf("cannot determine client ip")
+		}
+		if ip.String() != ipExpected {
+			t.Fatalf("unexpected client ip; got %s; want %s", ip, ipExpected)
+		}
+	}
+
+	// no trusted proxies - X-Forwarded-For is ignored
+	f("1.2.3.4:1234", nil, nil, "1.2.3.4")
+	f("1.2.3.4:1234", []string{"5.6.7.8"}, nil, "1.2.3.4")
+	f("[2001:db8::1]:1234", nil, nil, "2001:db8::1")
+	f("[::ffff:1.2.3.4]:1234", nil, nil, "1.2.3.4")
+
+	// request from untrusted address - X-Forwarded-For is ignored
+	f("1.2.3.4:1234", []string{"5.6.7.8"}, []string{"10.0.0.0/8"}, "1.2.3.4")
+
+	// request from trusted proxy
+	f("10.0.0.1:1234", []string{"5.6.7.8"}, []string{"10.0.0.0/8"}, "5.6.7.8")
+	f("10.0.0.1:1234", nil, []string{"10.0.0.0/8"}, "10.0.0.1")
+
+	// chain of trusted proxies
+	f("10.0.0.1:1234", []string{"5.6.7.8, 10.0.0.2"}, []string{"10.0.0.0/8"}, "5.6.7.8")
+	f("10.0.0.1:1234", []string{"5.6.7.8", "10.0.0.2"}, []string{"10.0.0.0/8"}, "5.6.7.8")
+
+	// spoofed addresses to the left of the real client address are ignored
+	f("10.0.0.1:1234", []string{"10.0.0.5, 1.1.1.1, 5.6.7.8"}, []string{"10.0.0.0/8"}, "5.6.7.8")
+
+	// all the addresses belong to trusted proxies
+	f("10.0.0.1:1234", []string{"10.0.0.3, 10.0.0.2"}, []string{"10.0.0.0/8"}, "10.0.0.3")
+
+	// invalid address in X-Forwarded-For
+	f("10.0.0.1:1234", []string{"foobar"}, []string{"10.0.0.0/8"}, "")
+
+	// invalid remote address
+	f("foobar", nil, nil, "")
+}
+
+func TestRequestHandlerIPFilters(t *testing.T) {
+	ac, err := parseAuthConfig([]byte(`
+users:
+- username: foo
+  password: secret
+  url_prefix: http://foo
+  ip_filters:
+    allow_list: [127.0.0.1]
+- username: bar
+  password_hash: $2a$04$smgWKrIvuAVI.vRMMZRL8.0sGZdVJGQ8L6yVqC0piVlQYaPgrHdNq
+  url_prefix: http://bar
+  ip_filters:
+    allow_list: [127.0.0.1]
+unauthorized_user:
+  url_prefix: http://unauthorized
+  ip_filters:
+    allow_list: [127.0.0.1]
+ip_filters:
+  deny_list: [10.0.0.1]
+`))
+	if err != nil {
+		t.Fatalf("cannot parse auth config: %s", err)
+	}
+	m, err := parseAuthConfigUsers(ac)
+	if err != nil {
+		t.Fatalf("cannot parse auth config users: %s", err)
+	}
+	acPrev := authConfig.Swap(ac)
+	mPrev := authUsers.Swap(&m)
+	defer func() {
+		authConfig.Store(acPrev)
+		authUsers.Store(mPrev)
+	}()
+
+	f := func(remoteAddr, path, username, password string, statusCodeExpected int, ipFiltersDeniedExpected bool) {
+		t.Helper()
+		r := httptest.NewRequest("GET", path, nil)
+		r.RemoteAddr = remoteAddr
+		if username != "" {
+			r.SetBasicAuth(username, password)
+		}
+		ipFiltersDeniedPrev := ipFiltersDeniedRequests.Get()
+		invalidAuthTokenPrev := invalidAuthTokenRequests.Get()
+		w := httptest.NewRecorder()
+		requestHandler(w, r)
+		if w.Code != statusCodeExpected {
+			t.Fatalf("unexpected status code; got %d; want %d; response: %q", w.Code, statusCodeExpected, w.Body.String())
+		}
+		// The rejected request must be counted only once either as denied by ip_filters or as having invalid auth token
+		var ipFiltersDeniedExpectedN, invalidAuthTokenExpectedN uint64
+		if ipFiltersDeniedExpected {
+			ipFiltersDeniedExpectedN = 1
+		} else {
+			invalidAuthTokenExpectedN = 1
+		}
+		if n := ipFiltersDeniedRequests.Get() - ipFiltersDeniedPrev; n != ipFiltersDeniedExpectedN {
+			t.Fatalf("unexpected number of requests denied by ip_filters; got %d; want %d", n, ipFiltersDeniedExpectedN)
+		}
+		if n := invalidAuthTokenRequests.Get() - invalidAuthTokenPrev; n != invalidAuthTokenExpectedN {
+			t.Fatalf("unexpected number of requests with invalid auth token; got %d; want %d", n, invalidAuthTokenExpectedN)
+		}
+	}
+
+	// global ip_filters are applied to service endpoints
+	f("10.0.0.1:1234", "/-/reload", "", "", http.StatusForbidden, true)
+	f("10.0.0.1:1234", "/backends", "", "", http.StatusForbidden, true)
+	f("10.0.0.1:1234", "/-/backends", "", "", http.StatusForbidden, true)
+
+	// requests denied by per-user ip_filters are rejected in the same way as requests with invalid credentials
+	f("10.0.0.2:1234", "/api/v1/query", "foo", "invalid", http.StatusUnauthorized, false)
+	f("10.0.0.2:1234", "/api/v1/query", "foo", "secret", http.StatusUnauthorized, true)
+	f("10.0.0.2:1234", "/api/v1/query", "bar", "invalid", http.StatusUnauthorized, true)
+	f("10.0.0.2:1234", "/api/v1/query", "bar", "secret", http.StatusUnauthorized, true)
+
+	// requests from unauthorized_user denied by per-user ip_filters are rejected with the same status code
+	f("10.0.0.2:1234", "/api/v1/query", "", "", http.StatusUnauthorized, true)
+}
@@ -0,0 +1,618 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+	"github.com/VictoriaMetrics/metrics"
+)
+
+// JWTConfig is the config for authenticating users via JSON Web Tokens passed in `Authorization: Bearer <token>` header.
+//
+// Exactly one of JWKSFile, OIDCDiscoveryURL or HMACSecret must be set.
+type JWTConfig struct {
+	// JWKSFile is the path to JSON Web Key Set. It can point either to local file or to http url.
+	JWKSFile string `yaml:"jwks_file,omitempty"`
+	// OIDCDiscoveryURL is the url of OpenID Connect discovery document,
+	// e.g. https://issuer/.well-known/openid-configuration
+	OIDCDiscoveryURL string `yaml:"oidc_discovery_url,omitempty"`
+	// HMACSecret is the secret for HS256 signed tokens.
+	HMACSecret string `yaml:"hmac_secret,omitempty"`
+
+	// Issuer must match `iss` claim if set.
+	// It is taken from the discovery document if OIDCDiscoveryURL is set.
+	Issuer string `yaml:"issuer,omitempty"`
+	// Audience must be present in `aud` claim if set.
+	Audience string `yaml:"audience,omitempty"`
+	// MatchClaims contains claims, which must have the given values for the token to match the user.
+	MatchClaims map[string]string `yaml:"match_claims,omitempty"`
+	// KeysRefreshInterval is the interval for re-reading keys from JWKSFile or OIDCDiscoveryURL.
+	KeysRefreshInterval *promutils.Duration `yaml:"keys_refresh_interval,omitempty"`
+
+	keys            atomic.Pointer[[]jwtKey]
+	keysLastRefresh atomic.Uint64
+	keysRefreshing  atomic.Bool
+	// oidcIssuer contains the issuer from OIDC discovery document
+	oidcIssuer atomic.Pointer[string]
+}
+
+const (
+	defaultJWTKeysRefreshInterval = 5 * time.Minute
+	// minJWTKeysRefreshInterval limits the rate of keys refreshes
+	// triggered by tokens with unknown key ids
+	minJWTKeysRefreshInterval = 10 * time.Second
+)
+
+var jwtKeysRefreshErrors = metrics.NewCounter(`vmauth_jwt_keys_refresh_errors_total`)
+
+type jwtKey struct {
+	kid string
+	// key is either *rsa.PublicKey, *ecdsa.PublicKey or []byte for HMAC
+	key interface{}
+}
+
+func (jc *JWTConfig) init() error {
+	n := 0
+	for _, s := range []string{jc.JWKSFile, jc.OIDCDiscoveryURL, jc.HMACSecret} {
+		if s != "" {
+			n++
+		}
+	}
+	if n != 1 {
+		return fmt.Errorf("exactly one of `jwks_file`, `oidc_discovery_url` or `hmac_secret` must be set in `jwt` section")
+	}
+	if jc.KeysRefreshInterval.Duration() < 0 {
+		return fmt.Errorf("`keys_refresh_interval` cannot be negative")
+	}
+	return jc.refreshKeys()
+}
+
+func (jc *JWTConfig) getKeysRefreshInterval() time.Duration {
+	if d := jc.KeysRefreshInterval.Duration(); d > 0 {
+		return d
+	}
+	return defaultJWTKeysRefreshInterval
+}
+
+// refreshKeys re-reads keys for jc.
+func (jc *JWTConfig) refreshKeys() error {
+	jc.keysLastRefresh.Store(fasttime.UnixTimestamp())
+	if jc.HMACSecret != "" {
+		keys := []jwtKey{{key: []byte(jc.HMACSecret)}}
+		jc.keys.Store(&keys)
+		return nil
+	}
+	jwksPath := jc.JWKSFile
+	if jc.OIDCDiscoveryURL != "" {
+		data, err := fs.ReadFileOrHTTP(jc.OIDCDiscoveryURL)
+		if err != nil {
+			return fmt.Errorf("cannot read OIDC discovery document: %w", err)
+		}
+		var doc struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("cannot parse OIDC discovery document from %q: %w", jc.OIDCDiscoveryURL, err)
+		}
+		if doc.JWKSURI == "" {
+			return fmt.Errorf("missing `jwks_uri` in OIDC discovery document from %q", jc.OIDCDiscoveryURL)
+		}
+		jc.oidcIssuer.Store(&doc.Issuer)
+		jwksPath = doc.JWKSURI
+	}
+	data, err := fs.ReadFileOrHTTP(jwksPath)
+	if err != nil {
+		return fmt.Errorf("cannot read JWKS: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("cannot parse JWKS from %q: %w", jwksPath, err)
+	}
+	jc.keys.Store(&keys)
+	return nil
+}
+
+// mayBeRefreshKeys refreshes keys in background if they are older than minInterval.
+// The previously loaded keys are used until the refresh is complete.
+func (jc *JWTConfig) mayBeRefreshKeys(minInterval time.Duration) {
+	if jc.HMACSecret != "" {
+		return
+	}
+	if fasttime.UnixTimestamp()-jc.keysLastRefresh.Load() < uint64(minInterval.Seconds()) {
+		return
+	}
+	if !jc.keysRefreshing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer jc.keysRefreshing.Store(false)
+		if err := jc.refreshKeys(); err != nil {
+			jwtKeysRefreshErrors.Inc()
+			logger.Errorf("cannot refresh JWT keys; using the previously loaded keys; error: %s", err)
+		}
+	}()
+}
+
+func parseJWKS(data []byte) ([]jwtKey, error) {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, err
+	}
+	var keys []jwtKey
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key interface{}
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeJWTBigInt(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse `n` for key %q: %w", k.Kid, err)
+			}
+			e, err := decodeJWTBigInt(k.E)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse `e` for key %q: %w", k.Kid, err)
+			}
+			key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			if k.Crv != "P-256" {
+				// only ES256 is supported
+				continue
+			}
+			x, err := decodeJWTBigInt(k.X)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse `x` for key %q: %w", k.Kid, err)
+			}
+			y, err := decodeJWTBigInt(k.Y)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse `y` for key %q: %w", k.Kid, err)
+			}
+			key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		case "oct":
+			b, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse `k` for key %q: %w", k.Kid, err)
+			}
+			key = b
+		default:
+			continue
+		}
+		keys = append(keys, jwtKey{kid: k.Kid, key: key})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("missing supported keys")
+	}
+	return keys, nil
+}
+
+func decodeJWTBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// jwtClaims contains claims of the verified token
+type jwtClaims map[string]interface{}
+
+// verify checks the signature of the given token and validates its claims.
+// It returns token claims on success.
+func (jc *JWTConfig) verify(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token must contain 3 parts separated by dots; got %d parts", len(parts))
+	}
+	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode token header: %w", err)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerData, &header); err != nil {
+		return nil, fmt.Errorf("cannot parse token header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode token signature: %w", err)
+	}
+	h := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	jc.mayBeRefreshKeys(jc.getKeysRefreshInterval())
+	keys := *jc.keys.Load()
+	verified := false
+	kidFound := false
+	for _, k := range keys {
+		if header.Kid != "" && k.kid != "" && k.kid != header.Kid {
+			continue
+		}
+		kidFound = true
+		if verifyJWTSignature(header.Alg, k.key, h[:], []byte(parts[0]+"."+parts[1]), sig) {
+			verified = true
+			break
+		}
+	}
+	if !kidFound {
+		// keys may be rotated - try refreshing them
+		jc.mayBeRefreshKeys(minJWTKeysRefreshInterval)
+		return nil, fmt.Errorf("cannot find key with kid=%q", header.Kid)
+	}
+	if !verified {
+		return nil, fmt.Errorf("cannot verify token signature with alg=%q", header.Alg)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode token payload: %w", err)
+	}
+	var claims jwtClaims
+	d := json.NewDecoder(bytes.NewReader(payload))
+	d.UseNumber()
+	if err := d.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("cannot parse token claims: %w", err)
+	}
+	if err := jc.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func verifyJWTSignature(alg string, key interface{}, hash, signed, sig []byte) bool {
+	switch alg {
+	case "RS256":
+		pk, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pk, crypto.SHA256, hash, sig) == nil
+	case "ES256":
+		pk, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pk, hash, r, s)
+	case "HS256":
+		secret, ok := key.([]byte)
+		if !ok {
+			return false
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), sig)
+	default:
+		return false
+	}
+}
+
+func (jc *JWTConfig) validateClaims(claims jwtClaims) error {
+	now := time.Now().Unix()
+	if v, ok := claims["exp"]; ok {
+		exp, err := getJWTNumericClaim(v)
+		if err != nil {
+			return fmt.Errorf("invalid `exp` claim: %w", err)
+		}
+		if now >= exp {
+			return fmt.Errorf("token is expired")
+		}
+	}
+	if v, ok := claims["nbf"]; ok {
+		nbf, err := getJWTNumericClaim(v)
+		if err != nil {
+			return fmt.Errorf("invalid `nbf` claim: %w", err)
+		}
+		if now < nbf {
+			return fmt.Errorf("token isn't valid yet")
+		}
+	}
+	issuer := jc.Issuer
+	if issuer == "" {
+		if p := jc.oidcIssuer.Load(); p != nil {
+			issuer = *p
+		}
+	}
+	if issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != issuer {
+			return fmt.Errorf("unexpected `iss` claim %q; want %q", iss, issuer)
+		}
+	}
+	if jc.Audience != "" && !hasJWTAudience(claims["aud"], jc.Audience) {
+		return fmt.Errorf("`aud` claim doesn't contain %q", jc.Audience)
+	}
+	return nil
+}
+
+func getJWTNumericClaim(v interface{}) (int64, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("expecting number; got %T", v)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, err
+	}
+	return int64(f), nil
+}
+
+func hasJWTAudience(v interface{}, audience string) bool {
+	switch x := v.(type) {
+	case string:
+		return x == audience
+	case []interface{}:
+		for _, xx := range x {
+			if s, ok := xx.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matches returns true if claims contain all the jc.MatchClaims.
+func (jc *JWTConfig) matches(claims jwtClaims) bool {
+	for name, value := range jc.MatchClaims {
+		v, ok := claims.get(name)
+		if !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// get returns string representation of the claim with the given name.
+//
+// Nested claims can be referred via dots, e.g. `realm_access.roles`.
+// Array values are joined with `|`.
+func (claims jwtClaims) get(name string) (string, bool) {
+	a, ok := claims.getValues(name)
+	if !ok {
+		return "", false
+	}
+	return strings.Join(a, "|"), true
+}
+
+// getValues returns string representations of the claim with the given name.
+//
+// It returns multiple values for array claims.
+func (claims jwtClaims) getValues(name string) ([]string, bool) {
+	var v interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(name, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		v, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return appendJWTClaimStrings(nil, v)
+}
+
+func appendJWTClaimStrings(dst []string, v interface{}) ([]string, bool) {
+	switch x := v.(type) {
+	case string:
+		return append(dst, x), true
+	case json.Number:
+		return append(dst, x.String()), true
+	case bool:
+		return append(dst, strconv.FormatBool(x)), true
+	case []interface{}:
+		for _, xx := range x {
+			var ok bool
+			dst, ok = appendJWTClaimStrings(dst, xx)
+			if !ok {
+				return nil, false
+			}
+		}
+		return dst, true
+	default:
+		return nil, false
+	}
+}
+
+var jwtClaimPlaceholderRe = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_.\-]+)\s*\}\}`)
+
+// replaceJWTClaimPlaceholders returns a copy of u with `{{claim}}` placeholders
+// in the path and query args replaced with the corresponding claims values.
+//
+// Claims in query args are escaped according to the placement of the placeholder:
+//   - inside double-quoted strings claims are escaped, so they cannot close the string;
+//   - inside double-quoted regexp filters, e.g. `{group=~"{{groups}}"}`, regexp metacharacters are escaped as well
+//     and array claims are joined with `|`;
+//   - outside double-quoted strings claims may contain only alphanumeric chars and `_.:@-`.
+func replaceJWTClaimPlaceholders(u *url.URL, claims jwtClaims) (*url.URL, error) {
+	var err error
+	uNew := *u
+	uNew.Path = jwtClaimPlaceholderRe.ReplaceAllStringFunc(u.Path, func(placeholder string) string {
+		name := jwtClaimPlaceholderRe.FindStringSubmatch(placeholder)[1]
+		v, ok := claims.get(name)
+		if !ok {
+			err = fmt.Errorf("missing claim %q in the token", name)
+			return ""
+		}
+		if strings.Contains(v, "/") || v == ".." || v == "." {
+			// prevent from changing the path structure via claims
+			err = fmt.Errorf("claim %q with value %q cannot be used in the url path", name, v)
+			return ""
+		}
+		return v
+	})
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(u.RawQuery, "{{") || strings.Contains(u.RawQuery, "%7B%7B") {
+		q := u.Query()
+		for _, vs := range q {
+			for i := range vs {
+				vs[i], err = replaceJWTClaimPlaceholdersInArg(vs[i], claims)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		uNew.RawQuery = q.Encode()
+	}
+	return &uNew, nil
+}
+
+// replaceJWTClaimPlaceholdersInArg replaces `{{claim}}` placeholders in query arg value s with escaped claims values.
+func replaceJWTClaimPlaceholdersInArg(s string, claims jwtClaims) (string, error) {
+	var dst []byte
+	inString := false
+	isRegexp := false
+	prevEnd := 0
+	for _, loc := range jwtClaimPlaceholderRe.FindAllStringSubmatchIndex(s, -1) {
+		// Detect whether the placeholder is located inside double-quoted string.
+		for i := prevEnd; i < loc[0]; i++ {
+			switch s[i] {
+			case '\\':
+				if inString {
+					// skip the escaped char
+					i++
+				}
+			case '"':
+				inString = !inString
+				if inString {
+					prefix := strings.TrimRight(s[:i], " ")
+					isRegexp = strings.HasSuffix(prefix, "=~") || strings.HasSuffix(prefix, "!~")
+				}
+			}
+		}
+		dst = append(dst, s[prevEnd:loc[0]]...)
+		prevEnd = loc[1]
+
+		name := s[loc[2]:loc[3]]
+		a, ok := claims.getValues(name)
+		if !ok {
+			return "", fmt.Errorf("missing claim %q in the token", name)
+		}
+		if !inString {
+			v := strings.Join(a, "|")
+			if !isSafeJWTClaimValue(v) {
+				return "", fmt.Errorf("claim %q with value %q can be used only inside double-quoted string in query args, "+
+					"since it contains chars other than alphanumeric and `_.:@-`", name, v)
+			}
+			dst = append(dst, v...)
+			continue
+		}
+		if isRegexp {
+			for i := range a {
+				a[i] = regexp.QuoteMeta(a[i])
+			}
+		}
+		v := strconv.Quote(strings.Join(a, "|"))
+		dst = append(dst, v[1:len(v)-1]...)
+	}
+	dst = append(dst, s[prevEnd:]...)
+	return string(dst), nil
+}
+
+func isSafeJWTClaimValue(s string) bool {
+	for _, c := range s {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			continue
+		}
+		if !strings.ContainsRune("_.:@-", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// jwtClientArgs contains query args, which cannot be passed by users authenticated via `jwt`,
+// since they are used for restricting access to data via `url_prefix`.
+var jwtClientArgs = []string{"extra_label", "extra_label[]", "extra_filters", "extra_filters[]"}
+
+// removeJWTClientArgs removes jwtClientArgs from r query args and url-encoded request body.
+//
+// The backend merges these args with the args set via `url_prefix`, so clients could widen the access otherwise.
+func removeJWTClientArgs(r *http.Request) error {
+	q := r.URL.Query()
+	if hasAnyArg(q, jwtClientArgs) {
+		for _, k := range jwtClientArgs {
+			q.Del(k)
+		}
+		r.URL.RawQuery = q.Encode()
+	}
+	form, err := getFormBody(r)
+	if err != nil {
+		return err
+	}
+	if form == nil || !hasAnyArg(form, jwtClientArgs) {
+		return nil
+	}
+	for _, k := range jwtClientArgs {
+		form.Del(k)
+	}
+	setFormBody(r, form)
+	return nil
+}
+
+func hasAnyArg(args url.Values, names []string) bool {
+	for _, k := range names {
+		if _, ok := args[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getJWTUser returns the first user from uis, which can verify the given bearer token
+// and which match_claims match the token claims.
+func getJWTUser(uis []*UserInfo, token string) (*UserInfo, jwtClaims, error) {
+	var lastErr error
+	for _, ui := range uis {
+		claims, err := ui.JWT.verify(token)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !ui.JWT.matches(claims) {
+			lastErr = fmt.Errorf("token claims don't match `match_claims`")
+			continue
+		}
+		return ui, claims, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("missing users with `jwt` auth")
+	}
+	return nil, nil, lastErr
+}
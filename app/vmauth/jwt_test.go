@@ -0,0 +1,300 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestJWT(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	t.Helper()
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	hb, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("cannot marshal header: %s", err)
+	}
+	cb, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("cannot marshal claims: %s", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(hb) + "." + base64.RawURLEncoding.EncodeToString(cb)
+	h := sha256.Sum256([]byte(signed))
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, h[:])
+		if err != nil {
+			t.Fatalf("cannot sign token: %s", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, h[:])
+		if err != nil {
+			t.Fatalf("cannot sign token: %s", err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	default:
+		t.Fatalf("unsupported key type %T", key)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTConfigVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate RSA key: %s", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate EC key: %s", err)
+	}
+	b64 := func(b []byte) string {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	jwks := fmt.Sprintf(`{"keys":[
+{"kty":"RSA","kid":"rsa1","use":"sig","n":%q,"e":%q},
+{"kty":"EC","kid":"ec1","crv":"P-256","x":%q,"y":%q},
+{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"}
+]}`, b64(rsaKey.N.Bytes()), b64([]byte{1, 0, 1}), b64(ecKey.X.Bytes()), b64(ecKey.Y.Bytes()))
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(jwksFile, []byte(jwks), 0644); err != nil {
+		t.Fatalf("cannot write JWKS file: %s", err)
+	}
+
+	jc := &JWTConfig{
+		JWKSFile: jwksFile,
+		Issuer:   "https://issuer",
+		Audience: "vmauth",
+	}
+	if err := jc.init(); err != nil {
+		t.Fatalf("cannot init JWT config: %s", err)
+	}
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":  "https://issuer",
+			"aud":  []string{"grafana", "vmauth"},
+			"exp":  time.Now().Add(time.Hour).Unix(),
+			"team": "dev",
+		}
+	}
+	f := func(token string, resultExpected bool) {
+		t.Helper()
+		claims, err := jc.verify(token)
+		if resultExpected && err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !resultExpected && err == nil {
+			t.Fatalf("expecting non-nil error; got claims %v", claims)
+		}
+	}
+
+	f(newTestJWT(t, "RS256", "rsa1", rsaKey, validClaims()), true)
+	f(newTestJWT(t, "ES256", "ec1", ecKey, validClaims()), true)
+	// missing kid
+	f(newTestJWT(t, "RS256", "", rsaKey, validClaims()), true)
+
+	// unknown kid
+	f(newTestJWT(t, "RS256", "unknown", rsaKey, validClaims()), false)
+	// key with the wrong type
+	f(newTestJWT(t, "RS256", "ec1", rsaKey, validClaims()), false)
+	// HS256 signed with the RSA public key must be rejected
+	f(newTestJWT(t, "HS256", "rsa1", rsaKey.N.Bytes(), validClaims()), false)
+	// invalid signature
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate RSA key: %s", err)
+	}
+	f(newTestJWT(t, "RS256", "rsa1", otherKey, validClaims()), false)
+
+	// expired token
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	f(newTestJWT(t, "RS256", "rsa1", rsaKey, claims), false)
+	// not valid yet
+	claims = validClaims()
+	claims["nbf"] = time.Now().Add(time.Hour).Unix()
+	f(newTestJWT(t, "RS256", "rsa1", rsaKey, claims), false)
+	// wrong issuer
+	claims = validClaims()
+	claims["iss"] = "https://other"
+	f(newTestJWT(t, "RS256", "rsa1", rsaKey, claims), false)
+	// wrong audience
+	claims = validClaims()
+	claims["aud"] = "grafana"
+	f(newTestJWT(t, "RS256", "rsa1", rsaKey, claims), false)
+
+	// malformed tokens
+	f("", false)
+	f("foo.bar", false)
+	f("foo.bar.baz", false)
+}
+
+func TestJWTConfigHMAC(t *testing.T) {
+	jc := &JWTConfig{
+		HMACSecret:  "secret",
+		MatchClaims: map[string]string{"team": "dev"},
+	}
+	if err := jc.init(); err != nil {
+		t.Fatalf("cannot init JWT config: %s", err)
+	}
+	claims, err := jc.verify(newTestJWT(t, "HS256", "", []byte("secret"), map[string]interface{}{"team": "dev"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !jc.matches(claims) {
+		t.Fatalf("expecting claims %v to match %v", claims, jc.MatchClaims)
+	}
+	claims, err = jc.verify(newTestJWT(t, "HS256", "", []byte("secret"), map[string]interface{}{"team": "prod"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if jc.matches(claims) {
+		t.Fatalf("unexpected match of claims %v with %v", claims, jc.MatchClaims)
+	}
+	if _, err := jc.verify(newTestJWT(t, "HS256", "", []byte("wrong secret"), map[string]interface{}{"team": "dev"})); err == nil {
+		t.Fatalf("expecting non-nil error for token with wrong secret")
+	}
+	if _, err := jc.verify(newTestJWT(t, "none", "", []byte("secret"), map[string]interface{}{"team": "dev"})); err == nil {
+		t.Fatalf("expecting non-nil error for token with alg=none")
+	}
+}
+
+func TestReplaceJWTClaimPlaceholders(t *testing.T) {
+	claims := jwtClaims{
+		"account_id": json.Number("42"),
+		"project":    "dev",
+		"groups":     []interface{}{"a", "b"},
+		"org": map[string]interface{}{
+			"name": "foo",
+		},
+		"path": "../admin",
+		"evil": `a"} or {x="`,
+		"wide": []interface{}{"a.*", "b"},
+	}
+	f := func(u, expected string) {
+		t.Helper()
+		pu, err := url.Parse(u)
+		if err != nil {
+			t.Fatalf("cannot parse %q: %s", u, err)
+		}
+		result, err := replaceJWTClaimPlaceholders(pu, claims)
+		if expected == "" {
+			if err == nil {
+				t.Fatalf("expecting non-nil error for %q; got %q", u, result)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", u, err)
+		}
+		if s := result.String(); s != expected {
+			t.Fatalf("unexpected url for %q;\ngot\n%s\nwant\n%s", u, s, expected)
+		}
+	}
+
+	f("http://vmselect:8481/select/{{account_id}}:{{project}}/prometheus",
+		"http://vmselect:8481/select/42:dev/prometheus")
+	f("http://vmselect:8481/select/0/prometheus?extra_label=org={{org.name}}&extra_filters={group=~\"{{groups}}\"}",
+		"http://vmselect:8481/select/0/prometheus?extra_filters=%7Bgroup%3D~%22a%7Cb%22%7D&extra_label=org%3Dfoo")
+	f("http://vmselect:8481/select/0/prometheus", "http://vmselect:8481/select/0/prometheus")
+
+	// missing claim
+	f("http://vmselect:8481/select/{{missing}}/prometheus", "")
+	f("http://vmselect:8481/select/0/prometheus?extra_label=team={{missing}}", "")
+	// claims cannot change the path structure
+	f("http://vmselect:8481/select/{{path}}/prometheus", "")
+
+	// claims are escaped inside double-quoted strings
+	f(`http://vmselect:8481/select/0/prometheus?extra_filters={team="{{evil}}"}`,
+		"http://vmselect:8481/select/0/prometheus?extra_filters=%7Bteam%3D%22a%5C%22%7D+or+%7Bx%3D%5C%22%22%7D")
+	// regexp metacharacters are escaped in regexp filters
+	f(`http://vmselect:8481/select/0/prometheus?extra_filters={group=~"{{wide}}"}`,
+		"http://vmselect:8481/select/0/prometheus?extra_filters=%7Bgroup%3D~%22a%5C%5C.%5C%5C%2A%7Cb%22%7D")
+	f(`http://vmselect:8481/select/0/prometheus?extra_filters={group!~ "{{wide}}"}`,
+		"http://vmselect:8481/select/0/prometheus?extra_filters=%7Bgroup%21~+%22a%5C%5C.%5C%5C%2A%7Cb%22%7D")
+	// regexp metacharacters aren't escaped in non-regexp filters after escaped quotes
+	f(`http://vmselect:8481/select/0/prometheus?extra_filters={x=~"\"",group="{{wide}}"}`,
+		"http://vmselect:8481/select/0/prometheus?extra_filters=%7Bx%3D~%22%5C%22%22%2Cgroup%3D%22a.%2A%7Cb%22%7D")
+	// claims with unsafe chars cannot be used outside double-quoted strings
+	f("http://vmselect:8481/select/0/prometheus?extra_label=team={{evil}}", "")
+	f("http://vmselect:8481/select/0/prometheus?extra_label=team={{groups}}", "")
+	f("http://vmselect:8481/select/0/prometheus?extra_filters={team={{evil}}}", "")
+}
+
+func TestRemoveJWTClientArgs(t *testing.T) {
+	f := func(method, requestURI, body, expectedQuery, expectedBody string) {
+		t.Helper()
+		r := httptest.NewRequest(method, requestURI, strings.NewReader(body))
+		if body != "" {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if err := removeJWTClientArgs(r); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if r.URL.RawQuery != expectedQuery {
+			t.Fatalf("unexpected query args; got %q; want %q", r.URL.RawQuery, expectedQuery)
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("cannot read body: %s", err)
+		}
+		if string(data) != expectedBody {
+			t.Fatalf("unexpected body; got %q; want %q", data, expectedBody)
+		}
+	}
+	f(http.MethodGet, "/api/v1/query?query=up", "", "query=up", "")
+	f(http.MethodGet, "/api/v1/query?query=up&extra_label=a=b&extra_filters[]={a=\"b\"}&extra_filters={a=\"b\"}", "", "query=up", "")
+	f(http.MethodPost, "/api/v1/query?extra_label[]=a=b", "query=up&extra_filters%5B%5D=%7Ba%3D%22b%22%7D", "", "query=up")
+	// body without the args is proxied as is
+	f(http.MethodPost, "/api/v1/query", "query=up&b=c", "", "query=up&b=c")
+}
+
+func TestGetJWTUser(t *testing.T) {
+	newUser := func(name, team string) *UserInfo {
+		jc := &JWTConfig{
+			HMACSecret:  "secret",
+			MatchClaims: map[string]string{"team": team},
+		}
+		if err := jc.init(); err != nil {
+			t.Fatalf("cannot init JWT config: %s", err)
+		}
+		return &UserInfo{Name: name, JWT: jc}
+	}
+	uis := []*UserInfo{newUser("dev", "dev"), newUser("prod", "prod")}
+
+	ui, _, err := getJWTUser(uis, newTestJWT(t, "HS256", "", []byte("secret"), map[string]interface{}{"team": "prod"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if ui.Name != "prod" {
+		t.Fatalf("unexpected user %q; want %q", ui.Name, "prod")
+	}
+	if _, _, err := getJWTUser(uis, newTestJWT(t, "HS256", "", []byte("secret"), map[string]interface{}{"team": "qa"})); err == nil {
+		t.Fatalf("expecting non-nil error for token without matching user")
+	}
+}
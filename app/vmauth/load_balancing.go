@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Supported values for `load_balancing_policy` option.
+const (
+	// loadBalancingPolicyLeastLoaded sends requests to the backend with the minimum number of concurrent requests per weight unit.
+	loadBalancingPolicyLeastLoaded = "least_loaded"
+	// loadBalancingPolicyRoundRobin spreads requests among backends proportionally to their weights.
+	loadBalancingPolicyRoundRobin = "round_robin"
+	// loadBalancingPolicyFirstAvailable sends requests to the first available backend in the order they are listed at `url_prefix`.
+	loadBalancingPolicyFirstAvailable = "first_available"
+)
+
+func (up *URLPrefix) initLoadBalancing(policy string, hc *HealthCheckConfig) error {
+	switch policy {
+	case "":
+		policy = loadBalancingPolicyLeastLoaded
+	case loadBalancingPolicyLeastLoaded, loadBalancingPolicyRoundRobin, loadBalancingPolicyFirstAvailable:
+	default:
+		return fmt.Errorf("unsupported `load_balancing_policy`: %q; supported values: %q, %q, %q",
+			policy, loadBalancingPolicyLeastLoaded, loadBalancingPolicyRoundRobin, loadBalancingPolicyFirstAvailable)
+	}
+	if hc != nil {
+		if err := hc.init(); err != nil {
+			return fmt.Errorf("invalid `health_check`: %w", err)
+		}
+	}
+	up.loadBalancingPolicy = policy
+	up.healthCheck = hc
+	return nil
+}
+
+// getBackendURL returns the backendURL for the next request according to the load balancing policy for up.
+//
+// backendURL.put() must be called on the returned backendURL after the request is complete.
+func (up *URLPrefix) getBackendURL() *backendURL {
+	switch up.loadBalancingPolicy {
+	case loadBalancingPolicyRoundRobin:
+		return up.getRoundRobinBackendURL()
+	case loadBalancingPolicyFirstAvailable:
+		return up.getFirstAvailableBackendURL()
+	default:
+		return up.getLeastLoadedBackendURL()
+	}
+}
+
+// getRoundRobinBackendURL returns the next available backendURL according to smooth weighted round-robin algorithm,
+// which is used by nginx. See https://github.com/phusion/nginx/commit/27e94984486058d73157038f7950a0a36ecc6e35
+//
+// Broken backends are skipped. The next backend in the round-robin order is returned if all the backends are broken.
+func (up *URLPrefix) getRoundRobinBackendURL() *backendURL {
+	bus := up.bus
+	up.mu.Lock()
+	var buMax *backendURL
+	totalWeight := 0
+	for _, bu := range bus {
+		if bu.isBroken() {
+			continue
+		}
+		w := bu.getWeight()
+		bu.currentWeight += w
+		totalWeight += w
+		if buMax == nil || bu.currentWeight > buMax.currentWeight {
+			buMax = bu
+		}
+	}
+	if buMax != nil {
+		buMax.currentWeight -= totalWeight
+	} else {
+		// All the backends are broken. Try them in turn.
+		n := atomic.AddUint32(&up.n, 1)
+		buMax = bus[n%uint32(len(bus))]
+	}
+	up.mu.Unlock()
+
+	atomic.AddInt32(&buMax.concurrentRequests, 1)
+	return buMax
+}
+
+// getFirstAvailableBackendURL returns the first non-broken backendURL.
+//
+// This is useful for primary/standby setups. The first backend is returned if all the backends are broken.
+func (up *URLPrefix) getFirstAvailableBackendURL() *backendURL {
+	bus := up.bus
+	buFirst := bus[0]
+	for _, bu := range bus {
+		if !bu.isBroken() {
+			buFirst = bu
+			break
+		}
+	}
+	atomic.AddInt32(&buFirst.concurrentRequests, 1)
+	return buFirst
+}
+
+func (bu *backendURL) getWeight() int {
+	if bu.weight <= 0 {
+		return 1
+	}
+	return bu.weight
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newTestURLPrefix(t *testing.T, policy string, urls []string, weights []int) *URLPrefix {
+	t.Helper()
+	up := mustParseURLs(urls)
+	for i, w := range weights {
+		up.bus[i].weight = w
+	}
+	for _, bu := range up.bus {
+		bu.health = &backendHealth{}
+	}
+	if err := up.initLoadBalancing(policy, nil); err != nil {
+		t.Fatalf("cannot init load balancing: %s", err)
+	}
+	return up
+}
+
+func getBackendHits(up *URLPrefix, n int) string {
+	hits := make(map[string]int)
+	for i := 0; i < n; i++ {
+		bu := up.getBackendURL()
+		hits[bu.url.Host]++
+		bu.put()
+	}
+	var s string
+	for _, bu := range up.bus {
+		s += fmt.Sprintf("%s:%d ", bu.url.Host, hits[bu.url.Host])
+	}
+	return s
+}
+
+func TestURLPrefixRoundRobin(t *testing.T) {
+	f := func(weights []int, brokenIdx int, expectedHits string) {
+		t.Helper()
+		up := newTestURLPrefix(t, "round_robin", []string{"http://a", "http://b", "http://c"}, weights)
+		if brokenIdx >= 0 {
+			up.bus[brokenIdx].health.unhealthy.Store(true)
+		}
+		if hits := getBackendHits(up, 60); hits != expectedHits {
+			t.Fatalf("unexpected hits; got %q; want %q", hits, expectedHits)
+		}
+	}
+
+	f(nil, -1, "a:20 b:20 c:20 ")
+	f([]int{1, 2, 3}, -1, "a:10 b:20 c:30 ")
+	f([]int{1, 2, 3}, 2, "a:20 b:40 c:0 ")
+
+	// All the backends are broken
+	up := newTestURLPrefix(t, "round_robin", []string{"http://a", "http://b"}, nil)
+	for _, bu := range up.bus {
+		bu.setBroken()
+	}
+	if hits := getBackendHits(up, 10); hits != "a:5 b:5 " {
+		t.Fatalf("unexpected hits for broken backends; got %q", hits)
+	}
+}
+
+func TestURLPrefixFirstAvailable(t *testing.T) {
+	up := newTestURLPrefix(t, "first_available", []string{"http://primary", "http://standby1", "http://standby2"}, nil)
+	f := func(expectedHits string) {
+		t.Helper()
+		if hits := getBackendHits(up, 10); hits != expectedHits {
+			t.Fatalf("unexpected hits; got %q; want %q", hits, expectedHits)
+		}
+	}
+
+	f("primary:10 standby1:0 standby2:0 ")
+	up.bus[0].health.unhealthy.Store(true)
+	f("primary:0 standby1:10 standby2:0 ")
+	up.bus[1].setBroken()
+	f("primary:0 standby1:0 standby2:10 ")
+	// All the backends are broken
+	up.bus[2].health.unhealthy.Store(true)
+	f("primary:10 standby1:0 standby2:0 ")
+	up.bus[0].health.unhealthy.Store(false)
+	f("primary:10 standby1:0 standby2:0 ")
+}
+
+func TestURLPrefixLeastLoadedWeighted(t *testing.T) {
+	up := newTestURLPrefix(t, "", []string{"http://a", "http://b"}, []int{1, 3})
+	if up.loadBalancingPolicy != "least_loaded" {
+		t.Fatalf("unexpected default load balancing policy; got %q; want %q", up.loadBalancingPolicy, "least_loaded")
+	}
+	// Do not release backends, so the number of concurrent requests grows
+	hits := make(map[string]int)
+	for i := 0; i < 40; i++ {
+		bu := up.getBackendURL()
+		hits[bu.url.Host]++
+	}
+	if hits["a"] != 10 || hits["b"] != 30 {
+		t.Fatalf("unexpected distribution of concurrent requests; got %v; want a:10, b:30", hits)
+	}
+}
+
+func TestURLPrefixInitLoadBalancingFailure(t *testing.T) {
+	up := mustParseURL("http://foo")
+	if err := up.initLoadBalancing("random", nil); err == nil {
+		t.Fatalf("expecting non-nil error for unsupported policy")
+	}
+}
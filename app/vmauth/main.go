@@ -11,6 +11,7 @@ import (
 	"net/textproto"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -41,6 +42,7 @@ var (
 		"Other requests are rejected with '429 Too Many Requests' http status code. See also -maxConcurrentRequests command-line option and max_concurrent_requests option "+
 		"in per-user config")
 	reloadAuthKey        = flag.String("reloadAuthKey", "", "Auth key for /-/reload http endpoint. It must be passed as authKey=...")
+	backendsAuthKey      = flag.String("backendsAuthKey", "", "Auth key for /backends http endpoint with the status of backends. It must be passed as authKey=...")
 	logInvalidAuthTokens = flag.Bool("logInvalidAuthTokens", false, "Whether to log requests with invalid auth tokens. "+
 		`Such requests are always counted at vmauth_http_request_errors_total{reason="invalid_auth_token"} metric, which is exposed at /metrics page`)
 	failTimeout               = flag.Duration("failTimeout", 3*time.Second, "Sets a delay period for load balancing to skip a malfunctioning backend")
@@ -59,8 +61,10 @@ func main() {
 
 	logger.Infof("starting vmauth at %q...", *httpListenAddr)
 	startTime := time.Now()
+	initTrustedProxies()
+	initAccessLog()
 	initAuthConfig()
-	go httpserver.Serve(*httpListenAddr, *useProxyProtocol, requestHandler)
+	go httpserver.ServeWithTLSConfigUpdater(*httpListenAddr, *useProxyProtocol, requestHandler, updateServerTLSConfig)
 	logger.Infof("started vmauth in %.3f seconds", time.Since(startTime).Seconds())
 
 	sig := procutil.WaitForSigterm()
@@ -77,6 +81,11 @@ func main() {
 }
 
 func requestHandler(w http.ResponseWriter, r *http.Request) bool {
+	if err := authConfig.Load().IPFilters.checkRequest(r); err != nil {
+		handleIPFiltersError(w, r, err)
+		return true
+	}
+
 	switch r.URL.Path {
 	case "/-/reload":
 		if !httpserver.CheckAuthFlag(w, r, *reloadAuthKey, "reloadAuthKey") {
@@ -86,13 +95,26 @@ func requestHandler(w http.ResponseWriter, r *http.Request) bool {
 		procutil.SelfSIGHUP()
 		w.WriteHeader(http.StatusOK)
 		return true
+	case "/backends", "/-/backends":
+		if !httpserver.CheckAuthFlag(w, r, *backendsAuthKey, "backendsAuthKey") {
+			return true
+		}
+		writeBackendsStatus(w, authConfig.Load())
+		return true
 	}
+
 	authToken := r.Header.Get("Authorization")
 	if authToken == "" {
+		// Process requests from users identified by the verified TLS client certificate
+		if ui := getTLSClientCertUser(authConfig.Load().tlsClientCertUsers, r); ui != nil {
+			processIdentifiedUserRequest(w, r, ui, nil)
+			return true
+		}
+
 		// Process requests for unauthorized users
 		ui := authConfig.Load().UnauthorizedUser
 		if ui != nil {
-			processUserRequest(w, r, ui)
+			processIdentifiedUserRequest(w, r, ui, nil)
 			return true
 		}
 
@@ -108,6 +130,38 @@ func requestHandler(w http.ResponseWriter, r *http.Request) bool {
 
 	ac := *authUsers.Load()
 	ui := ac[authToken]
+	if ui == nil && strings.HasPrefix(authToken, "Basic ") {
+		phUI, err := getPasswordHashUser(authConfig.Load().passwordHashUsers, r)
+		if err != nil {
+			handleUserIPFiltersError(w, r, err)
+			return true
+		}
+		if phUI != nil {
+			// Per-user ip_filters are already checked by getPasswordHashUser before the password verification.
+			processUserRequest(w, r, phUI, nil)
+			return true
+		}
+	}
+	if ui == nil && strings.HasPrefix(authToken, "Bearer ") {
+		if jwtUsers := authConfig.Load().jwtUsers; len(jwtUsers) > 0 {
+			jwtUI, claims, err := getJWTUser(jwtUsers, strings.TrimPrefix(authToken, "Bearer "))
+			if err == nil {
+				processIdentifiedUserRequest(w, r, jwtUI, claims)
+				return true
+			}
+			invalidJWTRequests.Inc()
+			if *logInvalidAuthTokens {
+				err = &httpserver.ErrorWithStatusCode{
+					Err:        fmt.Errorf("cannot authenticate the provided JWT: %w", err),
+					StatusCode: http.StatusUnauthorized,
+				}
+				httpserver.Errorf(w, r, "%s", err)
+			} else {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			}
+			return true
+		}
+	}
 	if ui == nil {
 		invalidAuthTokenRequests.Inc()
 		if *logInvalidAuthTokens {
@@ -123,16 +177,58 @@ func requestHandler(w http.ResponseWriter, r *http.Request) bool {
 		return true
 	}
 
-	processUserRequest(w, r, ui)
+	processIdentifiedUserRequest(w, r, ui, nil)
 	return true
 }
 
-func processUserRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo) {
+// processIdentifiedUserRequest checks per-user ip_filters for r and proxies r according to ui config.
+func processIdentifiedUserRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo, claims jwtClaims) {
+	if err := ui.IPFilters.checkRequest(r); err != nil {
+		handleUserIPFiltersError(w, r, err)
+		return
+	}
+	processUserRequest(w, r, ui, claims)
+}
+
+// processUserRequest proxies r according to ui config.
+//
+// Per-user ip_filters must be checked by the caller.
+// claims must contain claims of the verified JWT if ui is authenticated via `jwt`.
+func processUserRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo, claims jwtClaims) {
 	startTime := time.Now()
 	defer ui.requestsDuration.UpdateDuration(startTime)
 
 	ui.requests.Inc()
 
+	arw := &accountingResponseWriter{ResponseWriter: w}
+	w = arw
+	var crc *countingReadCloser
+	if r.Body != nil && r.Body != http.NoBody {
+		// Do not wrap http.NoBody, since otherwise the request is proxied with chunked Transfer-Encoding.
+		crc = &countingReadCloser{r: r.Body}
+		r.Body = crc
+	}
+	var rr *rateLimitReservation
+	defer func() {
+		var requestBytes int64
+		if crc != nil {
+			requestBytes = crc.n.Load()
+		}
+		rr.finish(requestBytes)
+		ui.updateUserStats(arw, requestBytes)
+		writeAccessLog(r, ui, arw, requestBytes, startTime)
+	}()
+
+	rr, rlErr := ui.RateLimitConf.beginRequest(r.ContentLength)
+	if rlErr != nil {
+		ui.rateLimitReached.Inc()
+		handleRateLimitError(w, r, rlErr)
+		return
+	}
+	// The reservation is cancelled if the request is rejected by `url_map` rate limits.
+	r = withUserRateLimitReservation(r, rr)
+	r = withFormBodyCache(r)
+
 	// Limit the concurrency of requests to backends
 	concurrencyLimitOnce.Do(concurrencyLimitInit)
 	select {
@@ -148,14 +244,100 @@ func processUserRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo) {
 		handleConcurrencyLimitError(w, r, err)
 		return
 	}
-	processRequest(w, r, ui)
+	processRequest(w, r, ui, claims)
 	ui.endConcurrencyLimit()
 	<-concurrencyLimitCh
 }
 
-func processRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo) {
+func processRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo, claims jwtClaims) {
+	if claims != nil {
+		if err := removeJWTClientArgs(r); err != nil {
+			httpserver.Errorf(w, r, "%s", err)
+			return
+		}
+	}
+	if ui.InjectLabelFilters != nil {
+		if err := ui.InjectLabelFilters.rewriteRequest(r); err != nil {
+			httpserver.Errorf(w, r, "%s", err)
+			return
+		}
+	}
+	var args url.Values
+	var tr *timeRange
+	if ui.hasTimeRangeMatchers() || ui.hasSrcQueryArgs() {
+		// Query args may be passed in url-encoded request body, e.g. by Grafana.
+		// Requests with too big body are rejected, since they cannot be routed properly.
+		var err error
+		args, err = getRequestArgs(r)
+		if err != nil {
+			httpserver.Errorf(w, r, "%s", err)
+			return
+		}
+		if ui.hasTimeRangeMatchers() {
+			tr, err = getRequestTimeRange(r, args, time.Now())
+			if err != nil {
+				httpserver.Errorf(w, r, "%s", err)
+				return
+			}
+		}
+	}
+	processRequestWithTimeRange(w, r, ui, claims, args, tr)
+}
+
+// processRequestWithTimeRange proxies r according to ui config.
+//
+// args contains query args of r including args from url-encoded request body. It is nil if ui has no `url_map` entries,
+// which need these args.
+// tr is the time range of r. It is nil if ui has no `url_map` entries with time range matchers or r has no time range args.
+func processRequestWithTimeRange(w http.ResponseWriter, r *http.Request, ui *UserInfo, claims jwtClaims, args url.Values, tr *timeRange) {
+	// arw is nil if the request isn't passed via processUserRequest.
+	arw, _ := w.(*accountingResponseWriter)
 	u := normalizeURL(r.URL)
-	up, hc, retryStatusCodes := ui.getURLPrefixAndHeaders(u)
+	up, hc, retryStatusCodes, um := ui.getURLPrefixAndHeaders(u, args, r.Header, r.Method, tr)
+	if ts, ok := um.getSplitTimestamp(u.Path, tr); ok {
+		processSplitTimeRangeRequest(w, r, ui, claims, tr, ts)
+		return
+	}
+	if um != nil {
+		rr, err := um.RateLimitConf.beginRequest(r.ContentLength)
+		if err != nil {
+			// The request isn't processed, so it mustn't be accounted in user rate limits.
+			getUserRateLimitReservation(r).cancel()
+			ui.rateLimitReached.Inc()
+			handleRateLimitError(w, r, err)
+			return
+		}
+		var crc *countingReadCloser
+		if r.Body != nil && r.Body != http.NoBody && um.RateLimitConf.bytesLimiter != nil {
+			crc = &countingReadCloser{r: r.Body}
+			r.Body = crc
+		}
+		defer func() {
+			var n int64
+			if crc != nil {
+				n = crc.n.Load()
+			}
+			rr.finish(n)
+		}()
+	}
+	if ttl := um.getResponseCacheTTL(); ttl > 0 {
+		key, ok, err := getResponseCacheKey(ui, claims, r, u)
+		if err != nil {
+			httpserver.Errorf(w, r, "%s", err)
+			return
+		}
+		if ok {
+			if e := getResponseCacheEntry(key); e != nil {
+				responseCacheHits.Inc()
+				writeResponseCacheEntry(w, e)
+				return
+			}
+			responseCacheMisses.Inc()
+			rcw := &responseCacheWriter{ResponseWriter: w}
+			w = rcw
+			defer rcw.put(key, ttl)
+		}
+	}
 	isDefault := false
 	if up == nil {
 		missingRouteRequests.Inc()
@@ -173,8 +355,23 @@ func processRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo) {
 		}
 	}
 	for i := 0; i < maxAttempts; i++ {
-		bu := up.getLeastLoadedBackendURL()
+		bu := up.getBackendURL()
 		targetURL := bu.url
+		if claims != nil {
+			// Substitute claims before merging with the request url,
+			// so placeholders cannot be passed via the request.
+			u, err := replaceJWTClaimPlaceholders(targetURL, claims)
+			if err != nil {
+				bu.put()
+				err = &httpserver.ErrorWithStatusCode{
+					Err:        fmt.Errorf("cannot build target url for the user %q: %w", ui.name(), err),
+					StatusCode: http.StatusForbidden,
+				}
+				httpserver.Errorf(w, r, "%s", err)
+				return
+			}
+			targetURL = u
+		}
 		// Don't change path and add request_path query param for default route.
 		if isDefault {
 			query := targetURL.Query()
@@ -183,11 +380,13 @@ func processRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo) {
 		} else { // Update path for regular routes.
 			targetURL = mergeURLs(targetURL, u)
 		}
+		arw.setBackend(targetURL)
 		ok := tryProcessingRequest(w, r, targetURL, hc, retryStatusCodes)
 		bu.put()
 		if ok {
 			return
 		}
+		arw.registerBackendError()
 		bu.setBroken()
 	}
 	err := &httpserver.ErrorWithStatusCode{
@@ -342,7 +541,10 @@ var hopHeaders = []string{
 var (
 	configReloadRequests     = metrics.NewCounter(`vmauth_http_requests_total{path="/-/reload"}`)
 	invalidAuthTokenRequests = metrics.NewCounter(`vmauth_http_request_errors_total{reason="invalid_auth_token"}`)
+	invalidJWTRequests       = metrics.NewCounter(`vmauth_http_request_errors_total{reason="invalid_jwt"}`)
+	ipFiltersDeniedRequests  = metrics.NewCounter(`vmauth_http_request_errors_total{reason="ip_filters"}`)
 	missingRouteRequests     = metrics.NewCounter(`vmauth_http_request_errors_total{reason="missing_route"}`)
+	splitTimeRangeRequests   = metrics.NewCounter(`vmauth_split_time_range_requests_total`)
 )
 
 var (
@@ -379,6 +581,32 @@ func concurrencyLimitInit() {
 	})
 }
 
+func handleIPFiltersError(w http.ResponseWriter, r *http.Request, err error) {
+	ipFiltersDeniedRequests.Inc()
+	err = &httpserver.ErrorWithStatusCode{
+		Err:        err,
+		StatusCode: http.StatusForbidden,
+	}
+	httpserver.Errorf(w, r, "%s", err)
+}
+
+// handleUserIPFiltersError rejects the request denied by per-user ip_filters.
+//
+// The request is rejected in the same way as the request with invalid credentials,
+// so the client cannot determine whether the provided credentials are valid.
+func handleUserIPFiltersError(w http.ResponseWriter, r *http.Request, err error) {
+	ipFiltersDeniedRequests.Inc()
+	if *logInvalidAuthTokens {
+		err = &httpserver.ErrorWithStatusCode{
+			Err:        err,
+			StatusCode: http.StatusUnauthorized,
+		}
+		httpserver.Errorf(w, r, "%s", err)
+		return
+	}
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
+
 var concurrentRequestsLimitReached = metrics.NewCounter("vmauth_concurrent_requests_limit_reached_total")
 
 func usage() {
@@ -399,6 +627,15 @@ func handleConcurrencyLimitError(w http.ResponseWriter, r *http.Request, err err
 	httpserver.Errorf(w, r, "%s", err)
 }
 
+func handleRateLimitError(w http.ResponseWriter, r *http.Request, err *rateLimitError) {
+	w.Header().Set("Retry-After", strconv.Itoa(err.retryAfterSeconds()))
+	e := &httpserver.ErrorWithStatusCode{
+		Err:        err,
+		StatusCode: http.StatusTooManyRequests,
+	}
+	httpserver.Errorf(w, r, "%s", e)
+}
+
 type readTrackingBody struct {
 	// r contains reader for initial data reading
 	r io.ReadCloser
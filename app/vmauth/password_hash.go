@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/cgroup"
+)
+
+// passwordHashVerifyCh limits the number of concurrent password hash verifications.
+//
+// Every verification is CPU-expensive by design, so the limit prevents from CPU exhaustion
+// when somebody sends many requests with invalid passwords.
+var passwordHashVerifyCh = make(chan struct{}, cgroup.AvailableCPUs())
+
+// passwordHash is a hash of the user password in bcrypt or argon2 format.
+type passwordHash struct {
+	// s is the original hash string
+	s string
+
+	// argon2 is set if s is in argon2 format
+	argon2 *argon2Hash
+
+	// verifiedPassword contains sha256 hash of the last password, which was successfully verified against s.
+	//
+	// Password verification is intentionally slow, so the last successful verification is cached
+	// in order to avoid spending CPU on every request from the legitimate user.
+	verifiedPassword atomic.Pointer[[sha256.Size]byte]
+}
+
+type argon2Hash struct {
+	variant string
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func newPasswordHash(s string) (*passwordHash, error) {
+	ph := &passwordHash{
+		s: s,
+	}
+	switch {
+	case strings.HasPrefix(s, "$2a$"), strings.HasPrefix(s, "$2b$"), strings.HasPrefix(s, "$2y$"):
+		if _, err := bcrypt.Cost([]byte(s)); err != nil {
+			return nil, fmt.Errorf("cannot parse bcrypt hash: %w", err)
+		}
+	case strings.HasPrefix(s, "$argon2id$"), strings.HasPrefix(s, "$argon2i$"):
+		ah, err := parseArgon2Hash(s)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse argon2 hash: %w", err)
+		}
+		ph.argon2 = ah
+	default:
+		return nil, fmt.Errorf("unsupported password hash format; supported formats: bcrypt ($2a$, $2b$, $2y$), argon2id ($argon2id$) and argon2i ($argon2i$)")
+	}
+	return ph, nil
+}
+
+// parseArgon2Hash parses argon2 hash in the PHC string format, e.g. `$argon2id$v=19$m=65536,t=3,p=4$salt$key`
+func parseArgon2Hash(s string) (*argon2Hash, error) {
+	parts := strings.Split(s, "$")
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("unexpected number of `$`-delimited parts; got %d; want 6", len(parts))
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, fmt.Errorf("cannot parse version from %q: %w", parts[2], err)
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version %d; want %d", version, argon2.Version)
+	}
+	ah := &argon2Hash{
+		variant: parts[1],
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &ah.memory, &ah.time, &ah.threads); err != nil {
+		return nil, fmt.Errorf("cannot parse params from %q: %w", parts[3], err)
+	}
+	if ah.memory == 0 || ah.time == 0 || ah.threads == 0 {
+		return nil, fmt.Errorf("params in %q must be positive", parts[3])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode key: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+	ah.salt = salt
+	ah.key = key
+	return ah, nil
+}
+
+func (ah *argon2Hash) matches(password string) bool {
+	var key []byte
+	if ah.variant == "argon2id" {
+		key = argon2.IDKey([]byte(password), ah.salt, ah.time, ah.memory, ah.threads, uint32(len(ah.key)))
+	} else {
+		key = argon2.Key([]byte(password), ah.salt, ah.time, ah.memory, ah.threads, uint32(len(ah.key)))
+	}
+	return subtle.ConstantTimeCompare(key, ah.key) == 1
+}
+
+// matches returns true if password matches ph.
+//
+// false is returned if ctx is canceled while waiting for the concurrency limit for password hash verifications.
+func (ph *passwordHash) matches(ctx context.Context, password string) bool {
+	h := sha256.Sum256([]byte(password))
+	if vp := ph.verifiedPassword.Load(); vp != nil && *vp == h {
+		return true
+	}
+	select {
+	case passwordHashVerifyCh <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+	defer func() {
+		<-passwordHashVerifyCh
+	}()
+	var ok bool
+	if ph.argon2 != nil {
+		ok = ph.argon2.matches(password)
+	} else {
+		ok = bcrypt.CompareHashAndPassword([]byte(ph.s), []byte(password)) == nil
+	}
+	if ok {
+		ph.verifiedPassword.Store(&h)
+	}
+	return ok
+}
+
+// getPasswordHashUser returns the user from uis with `password_hash` matching Basic Auth credentials from r.
+//
+// uis must be keyed by username. nil is returned if r has no matching credentials.
+// Per-user ip_filters are checked before the password verification, so requests from denied ip addresses
+// cannot consume CPU on password verification. The error is returned if r is denied by per-user ip_filters.
+func getPasswordHashUser(uis map[string]*UserInfo, r *http.Request) (*UserInfo, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+	ui := uis[username]
+	if ui == nil {
+		return nil, nil
+	}
+	if err := ui.IPFilters.checkRequest(r); err != nil {
+		return nil, err
+	}
+	if !ui.passwordHash.matches(r.Context(), password) {
+		return nil, nil
+	}
+	return ui, nil
+}
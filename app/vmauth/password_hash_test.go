@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHashMatches(t *testing.T) {
+	f := func(hash, password string, resultExpected bool) {
+		t.Helper()
+		ph, err := newPasswordHash(hash)
+		if err != nil {
+			t.Fatalf("cannot parse password hash %q: %s", hash, err)
+		}
+		// Verify twice in order to check the cached result
+		for i := 0; i < 2; i++ {
+			if result := ph.matches(context.Background(), password); result != resultExpected {
+				t.Fatalf("unexpected result for password %q; got %v; want %v", password, result, resultExpected)
+			}
+		}
+	}
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("cannot generate bcrypt hash: %s", err)
+	}
+	f(string(bcryptHash), "secret", true)
+	f(string(bcryptHash), "Secret", false)
+	f(string(bcryptHash), "", false)
+
+	salt := []byte("0123456789abcdef")
+	b64 := base64.RawStdEncoding.EncodeToString
+	argon2idHash := fmt.Sprintf("$argon2id$v=19$m=1024,t=1,p=1$%s$%s", b64(salt), b64(argon2.IDKey([]byte("secret"), salt, 1, 1024, 1, 32)))
+	f(argon2idHash, "secret", true)
+	f(argon2idHash, "Secret", false)
+	argon2iHash := fmt.Sprintf("$argon2i$v=19$m=1024,t=1,p=1$%s$%s", b64(salt), b64(argon2.Key([]byte("secret"), salt, 1, 1024, 1, 32)))
+	f(argon2iHash, "secret", true)
+	f(argon2iHash, "Secret", false)
+}
+
+func TestNewPasswordHashFailure(t *testing.T) {
+	f := func(hash string) {
+		t.Helper()
+		if _, err := newPasswordHash(hash); err == nil {
+			t.Fatalf("expecting non-nil error for %q", hash)
+		}
+	}
+
+	f("")
+	f("secret")
+	f("$2a$10$foo")
+	f("$argon2id$v=19$m=1024,t=1,p=1$c2FsdA")
+	f("$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5")
+	f("$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5")
+	f("$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5")
+	f("$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$")
+}
+
+func TestGetPasswordHashUser(t *testing.T) {
+	ac, err := parseAuthConfig([]byte(`
+users:
+- username: foo
+  password_hash: $2a$04$smgWKrIvuAVI.vRMMZRL8.0sGZdVJGQ8L6yVqC0piVlQYaPgrHdNq
+  url_prefix: http://foo
+`))
+	if err != nil {
+		t.Fatalf("cannot parse auth config: %s", err)
+	}
+	if _, err := parseAuthConfigUsers(ac); err != nil {
+		t.Fatalf("cannot parse auth config users: %s", err)
+	}
+	f := func(username, password string, resultExpected bool) {
+		t.Helper()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.SetBasicAuth(username, password)
+		ui, err := getPasswordHashUser(ac.passwordHashUsers, r)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if resultExpected && ui == nil {
+			t.Fatalf("expecting non-nil user for username=%q, password=%q", username, password)
+		}
+		if !resultExpected && ui != nil {
+			t.Fatalf("unexpected user %q for username=%q, password=%q", ui.name(), username, password)
+		}
+	}
+
+	f("foo", "secret", true)
+	f("foo", "bar", false)
+	f("bar", "secret", false)
+}
+
+func TestGetPasswordHashUserIPFilters(t *testing.T) {
+	ac, err := parseAuthConfig([]byte(`
+users:
+- username: foo
+  password_hash: $2a$04$smgWKrIvuAVI.vRMMZRL8.0sGZdVJGQ8L6yVqC0piVlQYaPgrHdNq
+  url_prefix: http://foo
+  ip_filters:
+    allow_list: [127.0.0.1]
+`))
+	if err != nil {
+		t.Fatalf("cannot parse auth config: %s", err)
+	}
+	if _, err := parseAuthConfigUsers(ac); err != nil {
+		t.Fatalf("cannot parse auth config users: %s", err)
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.SetBasicAuth("foo", "secret")
+	ui, err := getPasswordHashUser(ac.passwordHashUsers, r)
+	if err == nil {
+		t.Fatalf("expecting non-nil error for the request denied by ip_filters")
+	}
+	if ui != nil {
+		t.Fatalf("unexpected user %q for the request denied by ip_filters", ui.name())
+	}
+}
+
+func TestPasswordHashMatchesConcurrencyLimit(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("cannot generate bcrypt hash: %s", err)
+	}
+	ph, err := newPasswordHash(string(bcryptHash))
+	if err != nil {
+		t.Fatalf("cannot parse password hash: %s", err)
+	}
+
+	// Occupy all the verification slots
+	for i := 0; i < cap(passwordHashVerifyCh); i++ {
+		passwordHashVerifyCh <- struct{}{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if ph.matches(ctx, "secret") {
+		t.Fatalf("expecting false result when the verification concurrency limit is reached")
+	}
+	for i := 0; i < cap(passwordHashVerifyCh); i++ {
+		<-passwordHashVerifyCh
+	}
+
+	// Successfully verified password must be served from cache without waiting for the concurrency limit
+	if !ph.matches(context.Background(), "secret") {
+		t.Fatalf("expecting true result for valid password")
+	}
+	for i := 0; i < cap(passwordHashVerifyCh); i++ {
+		passwordHashVerifyCh <- struct{}{}
+	}
+	defer func() {
+		for i := 0; i < cap(passwordHashVerifyCh); i++ {
+			<-passwordHashVerifyCh
+		}
+	}()
+	if !ph.matches(ctx, "secret") {
+		t.Fatalf("expecting true result for cached password")
+	}
+	if ph.matches(ctx, "Secret") {
+		t.Fatalf("expecting false result for invalid password when the verification concurrency limit is reached")
+	}
+}
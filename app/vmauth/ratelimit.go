@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimitConf represents config for limiting the rate of requests and the rate of request body bytes.
+type RateLimitConf struct {
+	// MaxRequestsPerSecond is the maximum number of requests per second.
+	MaxRequestsPerSecond float64 `yaml:"max_requests_per_second,omitempty"`
+	// MaxRequestBytesPerSecond is the maximum number of request body bytes per second.
+	// It is useful for limiting the ingestion rate.
+	MaxRequestBytesPerSecond int64 `yaml:"max_request_bytes_per_second,omitempty"`
+
+	requestsLimiter *rateLimiter
+	bytesLimiter    *rateLimiter
+}
+
+func (rlc *RateLimitConf) init() error {
+	if rlc.MaxRequestsPerSecond < 0 {
+		return fmt.Errorf("`max_requests_per_second` cannot be negative; got %v", rlc.MaxRequestsPerSecond)
+	}
+	if rlc.MaxRequestBytesPerSecond < 0 {
+		return fmt.Errorf("`max_request_bytes_per_second` cannot be negative; got %d", rlc.MaxRequestBytesPerSecond)
+	}
+	if rlc.MaxRequestsPerSecond > 0 {
+		rlc.requestsLimiter = newRateLimiter(rlc.MaxRequestsPerSecond)
+	}
+	if rlc.MaxRequestBytesPerSecond > 0 {
+		rlc.bytesLimiter = newRateLimiter(float64(rlc.MaxRequestBytesPerSecond))
+	}
+	return nil
+}
+
+// beginRequest returns non-nil error if the request cannot be processed because of rate limits.
+//
+// contentLength request body bytes are reserved up front if contentLength is known.
+// The returned reservation must be finished via rateLimitReservation.finish after the request is processed
+// or cancelled via rateLimitReservation.cancel if the request is rejected afterwards.
+func (rlc *RateLimitConf) beginRequest(contentLength int64) (*rateLimitReservation, *rateLimitError) {
+	if rlc.bytesLimiter != nil {
+		// Reject requests until the exceeded limit is recovered. The request body size may exceed the limiter capacity,
+		// so the body bytes are reserved below without waiting for the needed number of tokens.
+		if d := rlc.bytesLimiter.waitNonNegative(); d > 0 {
+			return nil, &rateLimitError{
+				err:        fmt.Errorf("request body bytes rate exceeds max_request_bytes_per_second=%d", rlc.MaxRequestBytesPerSecond),
+				retryAfter: d,
+			}
+		}
+	}
+	if rlc.requestsLimiter != nil {
+		if d := rlc.requestsLimiter.take(1); d > 0 {
+			return nil, &rateLimitError{
+				err:        fmt.Errorf("requests rate exceeds max_requests_per_second=%v", rlc.MaxRequestsPerSecond),
+				retryAfter: d,
+			}
+		}
+	}
+	rr := &rateLimitReservation{
+		rlc: rlc,
+	}
+	if rlc.bytesLimiter != nil && contentLength > 0 {
+		rlc.bytesLimiter.consume(float64(contentLength))
+		rr.bytes = contentLength
+	}
+	return rr, nil
+}
+
+// rateLimitReservation holds tokens reserved by RateLimitConf.beginRequest for a single request.
+type rateLimitReservation struct {
+	rlc   *RateLimitConf
+	bytes int64
+	done  atomic.Bool
+}
+
+// finish accounts n request body bytes read for the request.
+//
+// The difference between n and the reserved bytes is taken from or returned to the limiter.
+func (rr *rateLimitReservation) finish(n int64) {
+	if rr == nil || rr.done.Swap(true) {
+		return
+	}
+	if bl := rr.rlc.bytesLimiter; bl != nil && n != rr.bytes {
+		bl.consume(float64(n - rr.bytes))
+	}
+}
+
+// cancel returns the reserved tokens, since the request has been rejected.
+//
+// It is safe calling cancel concurrently and multiple times.
+func (rr *rateLimitReservation) cancel() {
+	if rr == nil || rr.done.Swap(true) {
+		return
+	}
+	if rl := rr.rlc.requestsLimiter; rl != nil {
+		rl.refund(1)
+	}
+	if bl := rr.rlc.bytesLimiter; bl != nil && rr.bytes > 0 {
+		bl.refund(float64(rr.bytes))
+	}
+}
+
+type userRateLimitReservationKey struct{}
+
+// withUserRateLimitReservation returns a copy of r holding the user-level rate limit reservation rr.
+func withUserRateLimitReservation(r *http.Request, rr *rateLimitReservation) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), userRateLimitReservationKey{}, rr))
+}
+
+// getUserRateLimitReservation returns the user-level rate limit reservation for r.
+//
+// nil is returned if r has no reservation.
+func getUserRateLimitReservation(r *http.Request) *rateLimitReservation {
+	rr, _ := r.Context().Value(userRateLimitReservationKey{}).(*rateLimitReservation)
+	return rr
+}
+
+type rateLimitError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return e.err.Error()
+}
+
+// retryAfterSeconds returns the value for Retry-After response header.
+func (e *rateLimitError) retryAfterSeconds() int {
+	return int(math.Ceil(e.retryAfter.Seconds()))
+}
+
+// rateLimiter implements token bucket algorithm.
+//
+// The bucket capacity equals to the number of tokens added per second,
+// so it allows bursts of up to one second of the limit.
+// The capacity is at least one token, so limits lower than 1 allow single requests.
+type rateLimiter struct {
+	limit    float64
+	capacity float64
+
+	mu        sync.Mutex
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newRateLimiter(limit float64) *rateLimiter {
+	capacity := math.Max(limit, 1)
+	return &rateLimiter{
+		limit:     limit,
+		capacity:  capacity,
+		tokens:    capacity,
+		updatedAt: time.Now(),
+	}
+}
+
+func (rl *rateLimiter) refillLocked() {
+	now := time.Now()
+	rl.tokens += now.Sub(rl.updatedAt).Seconds() * rl.limit
+	if rl.tokens > rl.capacity {
+		rl.tokens = rl.capacity
+	}
+	rl.updatedAt = now
+}
+
+// take takes n tokens from rl.
+//
+// It returns non-zero duration to wait until n tokens become available if rl has no enough tokens.
+func (rl *rateLimiter) take(n float64) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.refillLocked()
+	if rl.tokens >= n {
+		rl.tokens -= n
+		return 0
+	}
+	return rl.durationForTokensLocked(n - rl.tokens)
+}
+
+// consume unconditionally takes n tokens from rl. The number of tokens may become negative.
+func (rl *rateLimiter) consume(n float64) {
+	rl.mu.Lock()
+	rl.refillLocked()
+	rl.tokens -= n
+	rl.mu.Unlock()
+}
+
+// refund returns n tokens to rl.
+func (rl *rateLimiter) refund(n float64) {
+	rl.mu.Lock()
+	rl.refillLocked()
+	rl.tokens += n
+	if rl.tokens > rl.capacity {
+		rl.tokens = rl.capacity
+	}
+	rl.mu.Unlock()
+}
+
+// waitNonNegative returns non-zero duration to wait until the number of tokens becomes positive.
+func (rl *rateLimiter) waitNonNegative() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.refillLocked()
+	if rl.tokens > 0 {
+		return 0
+	}
+	return rl.durationForTokensLocked(-rl.tokens)
+}
+
+func (rl *rateLimiter) durationForTokensLocked(n float64) time.Duration {
+	d := time.Duration(n / rl.limit * float64(time.Second))
+	if d <= 0 {
+		d = time.Millisecond
+	}
+	return d
+}
+
+// countingReadCloser counts the number of bytes read from r.
+//
+// The counter is updated atomically, since the request body may be read
+// by http.Transport in a separate goroutine.
+type countingReadCloser struct {
+	r io.ReadCloser
+	n atomic.Int64
+}
+
+// Read implements io.Reader interface.
+func (crc *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := crc.r.Read(p)
+	crc.n.Add(int64(n))
+	return n, err
+}
+
+// Close implements io.Closer interface.
+func (crc *countingReadCloser) Close() error {
+	return crc.r.Close()
+}
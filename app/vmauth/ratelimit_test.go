@@ -0,0 +1,147 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterTake(t *testing.T) {
+	rl := newRateLimiter(2)
+	for i := 0; i < 2; i++ {
+		if d := rl.take(1); d != 0 {
+			t.Fatalf("unexpected wait duration for request #%d: %s", i, d)
+		}
+	}
+	d := rl.take(1)
+	if d <= 0 || d > 500*time.Millisecond {
+		t.Fatalf("unexpected wait duration after exhausting the limit: %s", d)
+	}
+
+	// limits lower than 1 must allow a single request
+	rl = newRateLimiter(0.1)
+	if d := rl.take(1); d != 0 {
+		t.Fatalf("unexpected wait duration for the first request: %s", d)
+	}
+	d = rl.take(1)
+	if d <= 9*time.Second || d > 10*time.Second {
+		t.Fatalf("unexpected wait duration for the second request: %s", d)
+	}
+}
+
+func TestRateLimiterConsume(t *testing.T) {
+	rl := newRateLimiter(100)
+	if d := rl.waitNonNegative(); d != 0 {
+		t.Fatalf("unexpected wait duration for full bucket: %s", d)
+	}
+	rl.consume(300)
+	d := rl.waitNonNegative()
+	if d <= time.Second || d > 2*time.Second {
+		t.Fatalf("unexpected wait duration after exceeding the limit: %s", d)
+	}
+}
+
+func TestRateLimitConfBeginRequest(t *testing.T) {
+	rlc := &RateLimitConf{
+		MaxRequestsPerSecond:     1,
+		MaxRequestBytesPerSecond: 1024,
+	}
+	if err := rlc.init(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := rlc.beginRequest(0); err != nil {
+		t.Fatalf("unexpected error for the first request: %s", err)
+	}
+	_, err := rlc.beginRequest(0)
+	if err == nil {
+		t.Fatalf("expecting non-nil error when exceeding max_requests_per_second")
+	}
+	if n := err.retryAfterSeconds(); n != 1 {
+		t.Fatalf("unexpected Retry-After; got %d; want 1", n)
+	}
+
+	// request body bytes are reserved up front according to Content-Length
+	rlc = &RateLimitConf{
+		MaxRequestBytesPerSecond: 1024,
+	}
+	if err := rlc.init(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rr, err := rlc.beginRequest(4 * 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = rlc.beginRequest(0)
+	if err == nil {
+		t.Fatalf("expecting non-nil error when exceeding max_request_bytes_per_second")
+	}
+	if n := err.retryAfterSeconds(); n != 3 {
+		t.Fatalf("unexpected Retry-After; got %d; want 3", n)
+	}
+	// the body was read partially, so the rest of reserved bytes is returned
+	rr.finish(1024)
+	if _, err := rlc.beginRequest(0); err != nil {
+		t.Fatalf("unexpected error after returning reserved bytes: %s", err)
+	}
+
+	// bytes of requests without Content-Length are accounted after the request is processed
+	rlc = &RateLimitConf{
+		MaxRequestBytesPerSecond: 1024,
+	}
+	if err := rlc.init(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rr, err = rlc.beginRequest(-1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rr.finish(4 * 1024)
+	if _, err := rlc.beginRequest(0); err == nil {
+		t.Fatalf("expecting non-nil error when exceeding max_request_bytes_per_second")
+	}
+
+	// empty config must allow all the requests
+	rlc = &RateLimitConf{}
+	if err := rlc.init(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := 0; i < 100; i++ {
+		rr, err := rlc.beginRequest(1024)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		rr.finish(1024)
+	}
+}
+
+func TestRateLimitReservationCancel(t *testing.T) {
+	rlc := &RateLimitConf{
+		MaxRequestsPerSecond:     1,
+		MaxRequestBytesPerSecond: 1024,
+	}
+	if err := rlc.init(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rr, err := rlc.beginRequest(4 * 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rr.cancel()
+	// the second call must be no-op
+	rr.cancel()
+	rr.finish(4 * 1024)
+	for i := 0; i < 2; i++ {
+		rr, err := rlc.beginRequest(0)
+		if i == 0 && err != nil {
+			t.Fatalf("unexpected error after cancelling the reservation: %s", err)
+		}
+		if i == 1 && err == nil {
+			t.Fatalf("expecting non-nil error when exceeding max_requests_per_second")
+		}
+		rr.finish(0)
+	}
+
+	// nil reservation must be ignored
+	var rrNil *rateLimitReservation
+	rrNil.cancel()
+	rrNil.finish(1)
+}
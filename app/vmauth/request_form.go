@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
+)
+
+// maxFormBodySize is the maximum size of url-encoded request body, which can be parsed by vmauth.
+//
+// Query args from url-encoded request body are used for routing, for response caching
+// and they are rewritten for users with `jwt` and `inject_label_filters`.
+const maxFormBodySize = 1024 * 1024
+
+// formBodyCache holds args parsed from url-encoded request body, so the body is read and parsed only once per request.
+type formBodyCache struct {
+	parsed bool
+	form   url.Values
+	err    error
+}
+
+type formBodyCacheKey struct{}
+
+// withFormBodyCache returns a copy of r, which caches args parsed from url-encoded request body by getFormBody.
+//
+// The returned request must be processed by a single goroutine.
+func withFormBodyCache(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), formBodyCacheKey{}, &formBodyCache{}))
+}
+
+func getFormBodyCache(r *http.Request) *formBodyCache {
+	fbc, _ := r.Context().Value(formBodyCacheKey{}).(*formBodyCache)
+	return fbc
+}
+
+func hasRequestBody(r *http.Request) bool {
+	return r.Method != http.MethodGet && r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+func isFormBody(r *http.Request) bool {
+	return hasRequestBody(r) && strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded")
+}
+
+// getFormBody returns args from url-encoded request body of r.
+//
+// nil is returned if r has no url-encoded body. The body is left readable at r, so it can be proxied to backend.
+// The parsed args are cached if r is obtained via withFormBodyCache, so the body is read only once per request.
+// The caller must call setFormBody if the returned args are modified.
+//
+// Requests with url-encoded body exceeding maxFormBodySize are rejected with `413 Request Entity Too Large`,
+// since their args cannot be taken into account.
+func getFormBody(r *http.Request) (url.Values, error) {
+	fbc := getFormBodyCache(r)
+	if fbc != nil && fbc.parsed {
+		return fbc.form, fbc.err
+	}
+	form, err := readFormBody(r)
+	if fbc != nil {
+		fbc.parsed = true
+		fbc.form = form
+		fbc.err = err
+	}
+	return form, err
+}
+
+func readFormBody(r *http.Request) (url.Values, error) {
+	if !isFormBody(r) {
+		return nil, nil
+	}
+	if r.ContentLength > maxFormBodySize {
+		return nil, newFormBodyTooLargeError()
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxFormBodySize+1))
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read request body: %w", err)
+	}
+	if len(body) > maxFormBodySize {
+		return nil, newFormBodyTooLargeError()
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, &httpserver.ErrorWithStatusCode{
+			Err:        fmt.Errorf("cannot parse request body: %w", err),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return form, nil
+}
+
+func newFormBodyTooLargeError() error {
+	return &httpserver.ErrorWithStatusCode{
+		Err:        fmt.Errorf("url-encoded request body size exceeds %d bytes", maxFormBodySize),
+		StatusCode: http.StatusRequestEntityTooLarge,
+	}
+}
+
+// setFormBody sets url-encoded form as request body for r.
+func setFormBody(r *http.Request, form url.Values) {
+	newBody := form.Encode()
+	r.Body = io.NopCloser(bytes.NewBufferString(newBody))
+	r.ContentLength = int64(len(newBody))
+	r.Header.Set("Content-Length", strconv.Itoa(len(newBody)))
+	if fbc := getFormBodyCache(r); fbc != nil {
+		fbc.parsed = true
+		fbc.form = form
+		fbc.err = nil
+	}
+}
+
+// getRequestArgs returns query args for r including args from url-encoded request body.
+//
+// See getFormBody for details on reading the request body.
+func getRequestArgs(r *http.Request) (url.Values, error) {
+	args := r.URL.Query()
+	form, err := getFormBody(r)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range form {
+		args[k] = append(args[k], vs...)
+	}
+	return args, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
+)
+
+func newFormRequest(body string) *http.Request {
+	r := httptest.NewRequest("POST", "/api/v1/query", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestGetFormBodyCache(t *testing.T) {
+	r := withFormBodyCache(newFormRequest("query=up"))
+	form, err := getFormBody(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := form.Encode(); s != "query=up" {
+		t.Fatalf("unexpected form; got %q; want %q", s, "query=up")
+	}
+	// The request body must be restored
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(data) != "query=up" {
+		t.Fatalf("unexpected request body; got %q; want %q", data, "query=up")
+	}
+
+	// The body must be parsed only once per request
+	r.Body = io.NopCloser(strings.NewReader("query=foo"))
+	form, err = getFormBody(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := form.Encode(); s != "query=up" {
+		t.Fatalf("unexpected cached form; got %q; want %q", s, "query=up")
+	}
+
+	// setFormBody must update the cached form
+	setFormBody(r, url.Values{"query": {"bar"}})
+	form, err = getFormBody(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := form.Encode(); s != "query=bar" {
+		t.Fatalf("unexpected form after update; got %q; want %q", s, "query=bar")
+	}
+
+	// Requests without url-encoded body have no form
+	r = withFormBodyCache(httptest.NewRequest("GET", "/api/v1/query?query=up", nil))
+	form, err = getFormBody(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if form != nil {
+		t.Fatalf("expecting nil form; got %v", form)
+	}
+}
+
+func TestGetFormBodyTooLarge(t *testing.T) {
+	f := func(r *http.Request) {
+		t.Helper()
+		_, err := getFormBody(r)
+		var esc *httpserver.ErrorWithStatusCode
+		if !errors.As(err, &esc) || esc.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expecting error with status code %d; got %v", http.StatusRequestEntityTooLarge, err)
+		}
+	}
+	body := "query=" + strings.Repeat("x", maxFormBodySize)
+
+	// The request with known Content-Length
+	f(newFormRequest(body))
+
+	// The request with unknown Content-Length
+	r := newFormRequest(body)
+	r.ContentLength = -1
+	f(r)
+}
+
+func TestProcessRequestFormBodyTooLarge(t *testing.T) {
+	var requests atomic.Int64
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer backend.Close()
+
+	f := func(config string, statusCodeExpected int) {
+		t.Helper()
+		ac, err := parseAuthConfig([]byte(config))
+		if err != nil {
+			t.Fatalf("cannot parse auth config: %s", err)
+		}
+		uis, err := parseAuthConfigUsers(ac)
+		if err != nil {
+			t.Fatalf("cannot parse users: %s", err)
+		}
+		var ui *UserInfo
+		for _, v := range uis {
+			ui = v
+		}
+		requests.Store(0)
+		r := withFormBodyCache(newFormRequest("query=" + strings.Repeat("x", maxFormBodySize)))
+		w := httptest.NewRecorder()
+		processRequest(w, r, ui, nil)
+		if w.Code != statusCodeExpected {
+			t.Fatalf("unexpected status code; got %d; want %d; response: %s", w.Code, statusCodeExpected, w.Body.String())
+		}
+		requestsExpected := int64(0)
+		if statusCodeExpected == http.StatusOK {
+			requestsExpected = 1
+		}
+		if n := requests.Load(); n != requestsExpected {
+			t.Fatalf("unexpected number of requests to backend; got %d; want %d", n, requestsExpected)
+		}
+	}
+
+	// The body args are needed for routing by src_query_args
+	f(fmt.Sprintf(`
+users:
+- username: foo
+  url_map:
+  - src_query_args: ["query=up"]
+    url_prefix: %q
+  url_prefix: %q
+`, backend.URL, backend.URL), http.StatusRequestEntityTooLarge)
+
+	// The body args are needed for response caching
+	f(fmt.Sprintf(`
+users:
+- username: foo
+  url_map:
+  - src_paths: ["/api/v1/query"]
+    response_cache_ttl: 1m
+    url_prefix: %q
+`, backend.URL), http.StatusRequestEntityTooLarge)
+
+	// The body args aren't needed, so the request is proxied as is
+	f(fmt.Sprintf(`
+users:
+- username: foo
+  url_prefix: %q
+`, backend.URL), http.StatusOK)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/flagutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/lrucache"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+	"github.com/VictoriaMetrics/metrics"
+)
+
+var responseCacheMaxSize = flagutil.NewBytes("responseCache.maxSize", 64*1024*1024, "The maximum size of in-memory cache for responses "+
+	"of url_map entries with response_cache_ttl option. See https://docs.victoriametrics.com/vmauth.html#response-caching")
+
+var (
+	responseCache     *lrucache.Cache
+	responseCacheOnce sync.Once
+)
+
+func responseCacheInit() {
+	responseCache = lrucache.NewCache(responseCacheMaxSize.IntN)
+	_ = metrics.NewGauge(`vmauth_response_cache_size_bytes`, func() float64 {
+		return float64(responseCache.SizeBytes())
+	})
+	_ = metrics.NewGauge(`vmauth_response_cache_size_max_bytes`, func() float64 {
+		return float64(responseCache.SizeMaxBytes())
+	})
+	_ = metrics.NewGauge(`vmauth_response_cache_entries`, func() float64 {
+		return float64(responseCache.Len())
+	})
+}
+
+var (
+	responseCacheHits   = metrics.NewCounter(`vmauth_response_cache_requests_total{result="hit"}`)
+	responseCacheMisses = metrics.NewCounter(`vmauth_response_cache_requests_total{result="miss"}`)
+	responseCacheBypass = metrics.NewCounter(`vmauth_response_cache_requests_total{result="bypass"}`)
+)
+
+// responseCacheEntry is a cached response.
+type responseCacheEntry struct {
+	// deadline is the unix timestamp in seconds when the entry expires
+	deadline uint64
+	header   http.Header
+	body     []byte
+}
+
+// SizeBytes implements lrucache.Entry interface
+func (e *responseCacheEntry) SizeBytes() int {
+	n := len(e.body) + 64
+	for k, vs := range e.header {
+		n += len(k)
+		for _, v := range vs {
+			n += len(v)
+		}
+	}
+	return n
+}
+
+// getResponseCacheKey returns the key for caching the response for r.
+//
+// It returns false if the response for r cannot be cached.
+// The key contains the user name, claims of the user token, Accept-Encoding request header,
+// the request path and the sorted request args.
+// Args from url-encoded request body of POST requests are obtained via getFormBody,
+// so requests with too big body are rejected with the returned error.
+//
+// The time range of range queries is aligned to step at u, r.URL and r body,
+// so the backend returns the response matching the key.
+func getResponseCacheKey(ui *UserInfo, claims jwtClaims, r *http.Request, u *url.URL) (string, bool, error) {
+	args := u.Query()
+	var form url.Values
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if hasRequestBody(r) && !isFormBody(r) {
+			return "", false, nil
+		}
+		var err error
+		form, err = getFormBody(r)
+		if err != nil {
+			return "", false, err
+		}
+		for k, vs := range form {
+			args[k] = append(args[k], vs...)
+		}
+	default:
+		return "", false, nil
+	}
+	if args.Get("nocache") == "1" {
+		responseCacheBypass.Inc()
+		return "", false, nil
+	}
+	alignResponseCacheTimeRange(r, u, args, form)
+
+	var b strings.Builder
+	b.WriteString(ui.name())
+	b.WriteByte('\n')
+	if claims != nil {
+		// Users authenticated via jwt may be routed to distinct tenants depending on claims.
+		// json.Marshal sorts map keys, so the result is stable.
+		data, _ := json.Marshal(claims)
+		b.Write(data)
+	}
+	b.WriteByte('\n')
+	// The cached response may be compressed according to Accept-Encoding header,
+	// so it cannot be returned to clients, which accept other encodings.
+	b.WriteString(r.Header.Get("Accept-Encoding"))
+	b.WriteByte('\n')
+	b.WriteString(u.Path)
+	b.WriteByte('?')
+	b.WriteString(args.Encode())
+	return b.String(), true, nil
+}
+
+// alignResponseCacheTimeRange aligns start and end args to step,
+// so range queries with close time ranges share the same cache entry.
+//
+// The aligned args are updated at args, at u and r.URL query args and at url-encoded request body form,
+// so the backend returns the response for the aligned time range.
+func alignResponseCacheTimeRange(r *http.Request, u *url.URL, args, form url.Values) {
+	step, ok := parseResponseCacheDuration(args.Get("step"))
+	if !ok || step <= 0 {
+		return
+	}
+	query := u.Query()
+	queryUpdated := false
+	formUpdated := false
+	for _, name := range []string{"start", "end"} {
+		t, ok := parseResponseCacheTime(args.Get(name))
+		if !ok {
+			continue
+		}
+		t = math.Floor(t/step) * step
+		v := strconv.FormatFloat(t, 'f', -1, 64)
+		if v == args.Get(name) {
+			continue
+		}
+		args.Set(name, v)
+		if query.Has(name) {
+			query.Set(name, v)
+			queryUpdated = true
+		}
+		if form.Has(name) {
+			form.Set(name, v)
+			formUpdated = true
+		}
+	}
+	if queryUpdated {
+		u.RawQuery = query.Encode()
+		r.URL.RawQuery = u.RawQuery
+	}
+	if formUpdated {
+		setFormBody(r, form)
+	}
+}
+
+func parseResponseCacheDuration(s string) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	d, err := promutils.ParseDuration(s)
+	if err != nil {
+		return 0, false
+	}
+	return d.Seconds(), true
+}
+
+func parseResponseCacheTime(s string) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, false
+	}
+	return float64(t.UnixNano()) / 1e9, true
+}
+
+// getResponseCacheEntry returns non-expired entry for the given key.
+func getResponseCacheEntry(key string) *responseCacheEntry {
+	responseCacheOnce.Do(responseCacheInit)
+	e := responseCache.GetEntry(key)
+	if e == nil {
+		return nil
+	}
+	rce := e.(*responseCacheEntry)
+	if fasttime.UnixTimestamp() >= rce.deadline {
+		return nil
+	}
+	return rce
+}
+
+func writeResponseCacheEntry(w http.ResponseWriter, e *responseCacheEntry) {
+	h := w.Header()
+	for k, vs := range e.header {
+		h[k] = vs
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(e.body)
+}
+
+// responseCacheWriter writes the response to the underlying http.ResponseWriter
+// and collects successful responses for caching.
+type responseCacheWriter struct {
+	http.ResponseWriter
+
+	statusCode int
+	header     http.Header
+	buf        bytes.Buffer
+	// overflow is set to true if the response is too big for caching
+	overflow bool
+}
+
+// WriteHeader implements http.ResponseWriter interface.
+func (rcw *responseCacheWriter) WriteHeader(statusCode int) {
+	rcw.statusCode = statusCode
+	rcw.header = rcw.ResponseWriter.Header().Clone()
+	rcw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter interface.
+func (rcw *responseCacheWriter) Write(p []byte) (int, error) {
+	if rcw.statusCode == 0 {
+		rcw.WriteHeader(http.StatusOK)
+	}
+	if !rcw.overflow && rcw.statusCode == http.StatusOK {
+		// Do not cache responses, which occupy more than 1/16 of the cache.
+		if rcw.buf.Len()+len(p) > responseCacheMaxSize.IntN()/16 {
+			rcw.overflow = true
+			rcw.buf = bytes.Buffer{}
+		} else {
+			rcw.buf.Write(p)
+		}
+	}
+	return rcw.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher interface.
+func (rcw *responseCacheWriter) Flush() {
+	if f, ok := rcw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// put stores the collected response in the cache under the given key with the given ttl.
+func (rcw *responseCacheWriter) put(key string, ttl time.Duration) {
+	if rcw.statusCode != http.StatusOK || rcw.overflow {
+		return
+	}
+	e := &responseCacheEntry{
+		deadline: fasttime.UnixTimestamp() + uint64(ttl.Seconds()),
+		header:   rcw.header,
+		body:     append([]byte{}, rcw.buf.Bytes()...),
+	}
+	responseCache.PutEntry(key, e)
+}
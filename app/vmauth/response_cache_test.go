@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+func TestGetResponseCacheKey(t *testing.T) {
+	ui := &UserInfo{Name: "foo"}
+	f := func(r *http.Request, claims jwtClaims, expectedKey string) {
+		t.Helper()
+		key, ok, err := getResponseCacheKey(ui, claims, r, normalizeURL(r.URL))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if expectedKey == "" {
+			if ok {
+				t.Fatalf("expecting the response to be non-cacheable; got key %q", key)
+			}
+			return
+		}
+		if !ok {
+			t.Fatalf("expecting the response to be cacheable")
+		}
+		if key != expectedKey {
+			t.Fatalf("unexpected key;\ngot\n%q\nwant\n%q", key, expectedKey)
+		}
+	}
+	newRequest := func(method, uri, body string) *http.Request {
+		r := httptest.NewRequest(method, uri, strings.NewReader(body))
+		if body != "" {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		return r
+	}
+
+	f(newRequest("GET", "/api/v1/query?query=up&time=123", ""), nil, "foo\n\n\n/api/v1/query?query=up&time=123")
+	// start and end are aligned to step
+	f(newRequest("GET", "/api/v1/query_range?query=up&start=1001&end=2009&step=10", ""), nil,
+		"foo\n\n\n/api/v1/query_range?end=2000&query=up&start=1000&step=10")
+	f(newRequest("GET", "/api/v1/query_range?query=up&start=1005.5&end=2001&step=1m", ""), nil,
+		"foo\n\n\n/api/v1/query_range?end=1980&query=up&start=960&step=1m")
+	f(newRequest("GET", "/api/v1/query_range?query=up&start=1970-01-01T00:16:45Z&step=10s", ""), nil,
+		"foo\n\n\n/api/v1/query_range?query=up&start=1000&step=10s")
+	// POST form args are added to the key
+	r := newRequest("POST", "/api/v1/query_range?query=up", "start=1001&end=2009&step=10")
+	f(r, nil, "foo\n\n\n/api/v1/query_range?end=2000&query=up&start=1000&step=10")
+	// the request body must be restored with the aligned time range
+	body := make([]byte, 100)
+	n, _ := r.Body.Read(body)
+	if string(body[:n]) != "end=2000&start=1000&step=10" {
+		t.Fatalf("unexpected request body after reading the key: %q", body[:n])
+	}
+	// the aligned time range must be sent to the backend
+	r = newRequest("GET", "/api/v1/query_range?query=up&start=1001&end=2009&step=10", "")
+	u := normalizeURL(r.URL)
+	if _, ok, err := getResponseCacheKey(ui, nil, r, u); err != nil || !ok {
+		t.Fatalf("expecting the response to be cacheable; ok=%v, err=%v", ok, err)
+	}
+	for _, q := range []string{u.RawQuery, r.URL.RawQuery} {
+		if q != "end=2000&query=up&start=1000&step=10" {
+			t.Fatalf("unexpected query args after aligning the time range: %q", q)
+		}
+	}
+	// Accept-Encoding is added to the key
+	r = newRequest("GET", "/api/v1/query?query=up", "")
+	r.Header.Set("Accept-Encoding", "gzip")
+	f(r, nil, "foo\n\ngzip\n/api/v1/query?query=up")
+	// claims are added to the key
+	f(newRequest("GET", "/api/v1/query?query=up", ""), jwtClaims{"team": "dev"}, "foo\n{\"team\":\"dev\"}\n\n/api/v1/query?query=up")
+
+	// non-cacheable requests
+	f(newRequest("GET", "/api/v1/query?query=up&nocache=1", ""), nil, "")
+	f(newRequest("POST", "/api/v1/query_range?query=up", "step=10&nocache=1"), nil, "")
+	f(newRequest("PUT", "/api/v1/query?query=up", ""), nil, "")
+	r = httptest.NewRequest("POST", "/api/v1/write", strings.NewReader("foo"))
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	f(r, nil, "")
+}
+
+func TestResponseCache(t *testing.T) {
+	var requests atomic.Int64
+	var lastStart atomic.Value
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		lastStart.Store(r.URL.Query().Get("start"))
+		if r.URL.Query().Get("query") == "error" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"request":%d}`, n)
+	}))
+	defer backend.Close()
+
+	u, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatalf("cannot parse backend url: %s", err)
+	}
+	ui := &UserInfo{
+		Name: "cached",
+		URLMaps: []URLMap{
+			{
+				SrcPaths:         getSrcPaths([]string{"/api/v1/query_range"}),
+				URLPrefix:        &URLPrefix{bus: []*backendURL{{url: u}}},
+				ResponseCacheTTL: promutils.NewDuration(time.Minute),
+			},
+		},
+		URLPrefix: &URLPrefix{bus: []*backendURL{{url: u}}},
+	}
+	f := func(uri string, expectedStatusCode int, expectedBody string) {
+		t.Helper()
+		r := httptest.NewRequest("GET", uri, nil)
+		w := httptest.NewRecorder()
+		processRequest(w, r, ui, nil)
+		if w.Code != expectedStatusCode {
+			t.Fatalf("unexpected status code for %q; got %d; want %d", uri, w.Code, expectedStatusCode)
+		}
+		if expectedBody != "" && w.Body.String() != expectedBody {
+			t.Fatalf("unexpected response body for %q; got %q; want %q", uri, w.Body.String(), expectedBody)
+		}
+	}
+
+	f("/api/v1/query_range?query=up&start=1001&step=10", http.StatusOK, `{"request":1}`)
+	if start := lastStart.Load(); start != "1000" {
+		t.Fatalf("expecting the aligned start to be sent to the backend; got %q", start)
+	}
+	// time range is aligned to the same step
+	f("/api/v1/query_range?query=up&start=1009&step=10", http.StatusOK, `{"request":1}`)
+	// bypass cache
+	f("/api/v1/query_range?query=up&start=1001&step=10&nocache=1", http.StatusOK, `{"request":2}`)
+	f("/api/v1/query_range?query=up&start=1011&step=10", http.StatusOK, `{"request":3}`)
+	// routes without response_cache_ttl aren't cached
+	f("/api/v1/query?query=up", http.StatusOK, `{"request":4}`)
+	f("/api/v1/query?query=up", http.StatusOK, `{"request":5}`)
+	// failed responses aren't cached
+	f("/api/v1/query_range?query=error&step=10", http.StatusBadGateway, "")
+	f("/api/v1/query_range?query=error&step=10", http.StatusBadGateway, "")
+	if n := requests.Load(); n != 7 {
+		t.Fatalf("unexpected number of backend requests; got %d; want 7", n)
+	}
+}
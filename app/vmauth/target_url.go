@@ -1,11 +1,20 @@
 package main
 
 import (
+	"net/http"
 	"net/url"
 	"path"
 	"strings"
 )
 
+// equivalentArgs contains query args, which are treated by the backend as the same arg.
+var equivalentArgs = map[string]string{
+	"extra_filters":   "extra_filters[]",
+	"extra_filters[]": "extra_filters",
+	"extra_label":     "extra_label[]",
+	"extra_label[]":   "extra_label",
+}
+
 func mergeURLs(uiURL, requestURI *url.URL) *url.URL {
 	targetURL := *uiURL
 	if strings.HasPrefix(requestURI.Path, "/") {
@@ -24,6 +33,10 @@ func mergeURLs(uiURL, requestURI *url.URL) *url.URL {
 		if exist := uiParams.Get(k); len(exist) > 0 {
 			continue
 		}
+		// `extra_filters` and `extra_filters[]` are equivalent for the backend
+		if k2, ok := equivalentArgs[k]; ok && len(uiParams.Get(k2)) > 0 {
+			continue
+		}
 		for i := range v {
 			uiParams.Add(k, v[i])
 		}
@@ -32,18 +45,88 @@ func mergeURLs(uiURL, requestURI *url.URL) *url.URL {
 	return &targetURL
 }
 
-func (ui *UserInfo) getURLPrefixAndHeaders(u *url.URL) (*URLPrefix, HeadersConf, []int) {
-	for _, e := range ui.URLMaps {
-		for _, sp := range e.SrcPaths {
-			if sp.match(u.Path) {
-				return e.URLPrefix, e.HeadersConf, e.RetryStatusCodes
-			}
+// getURLPrefixAndHeaders returns the route config for the request with the given url u, query args, headers h and method.
+//
+// args must contain query args from u and from url-encoded request body. u.Query() is used if args is nil.
+// The returned URLMap is non-nil only if the request matches `url_map` entry.
+func (ui *UserInfo) getURLPrefixAndHeaders(u *url.URL, args url.Values, h http.Header, method string, tr *timeRange) (*URLPrefix, HeadersConf, []int, *URLMap) {
+	for i := range ui.URLMaps {
+		e := &ui.URLMaps[i]
+		if e.match(u, args, h, method, tr) {
+			return e.URLPrefix, e.HeadersConf, e.RetryStatusCodes, e
 		}
 	}
 	if ui.URLPrefix != nil {
-		return ui.URLPrefix, ui.HeadersConf, ui.RetryStatusCodes
+		return ui.URLPrefix, ui.HeadersConf, ui.RetryStatusCodes, nil
+	}
+	return nil, HeadersConf{}, nil, nil
+}
+
+// match returns true if the request with the given url u, query args, headers h, method and time range tr matches e.
+//
+// Every non-empty list of matchers must contain at least a single matching entry.
+func (e *URLMap) match(u *url.URL, args url.Values, h http.Header, method string, tr *timeRange) bool {
+	if len(e.SrcPaths) > 0 && !matchAnySrcPath(e.SrcPaths, u.Path) {
+		return false
+	}
+	if len(e.SrcQueryArgs) > 0 {
+		if args == nil {
+			args = u.Query()
+		}
+		ok := false
+		for _, qa := range e.SrcQueryArgs {
+			if qa.match(args) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(e.SrcHeaders) > 0 {
+		ok := false
+		for _, hm := range e.SrcHeaders {
+			if hm.match(h) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(e.SrcMethods) > 0 {
+		ok := false
+		for _, m := range e.SrcMethods {
+			if strings.EqualFold(m, method) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return e.matchTimeRange(u.Path, tr)
+}
+
+func (ui *UserInfo) hasSrcQueryArgs() bool {
+	for i := range ui.URLMaps {
+		if len(ui.URLMaps[i].SrcQueryArgs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnySrcPath(sps []*SrcPath, path string) bool {
+	for _, sp := range sps {
+		if sp.match(path) {
+			return true
+		}
 	}
-	return nil, HeadersConf{}, nil
+	return false
 }
 
 func normalizeURL(uOrig *url.URL) *url.URL {
@@ -2,9 +2,15 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v2"
 )
 
 func TestCreateTargetURLSuccess(t *testing.T) {
@@ -15,7 +21,7 @@ func TestCreateTargetURLSuccess(t *testing.T) {
 			t.Fatalf("cannot parse %q: %s", requestURI, err)
 		}
 		u = normalizeURL(u)
-		up, hc, retryStatusCodes := ui.getURLPrefixAndHeaders(u)
+		up, hc, retryStatusCodes, _ := ui.getURLPrefixAndHeaders(u, nil, nil, http.MethodGet, nil)
 		if up == nil {
 			t.Fatalf("cannot determie backend: %s", err)
 		}
@@ -136,6 +142,10 @@ func TestCreateTargetURLSuccess(t *testing.T) {
 	f(&UserInfo{
 		URLPrefix: mustParseURL("http://foo.bar?extra_label=team=mobile"),
 	}, "/api/v1/query?extra_label=team=dev", "http://foo.bar/api/v1/query?extra_label=team%3Dmobile", "[]", "[]", nil)
+	// extra_filters[] cannot be used for extending extra_filters from url_prefix
+	f(&UserInfo{
+		URLPrefix: mustParseURL(`http://foo.bar?extra_filters={team="dev"}`),
+	}, `/api/v1/query?extra_filters[]={team="prod"}`, "http://foo.bar/api/v1/query?extra_filters=%7Bteam%3D%22dev%22%7D", "[]", "[]", nil)
 }
 
 func TestCreateTargetURLFailure(t *testing.T) {
@@ -146,7 +156,7 @@ func TestCreateTargetURLFailure(t *testing.T) {
 			t.Fatalf("cannot parse %q: %s", requestURI, err)
 		}
 		u = normalizeURL(u)
-		up, hc, retryStatusCodes := ui.getURLPrefixAndHeaders(u)
+		up, hc, retryStatusCodes, _ := ui.getURLPrefixAndHeaders(u, nil, nil, http.MethodGet, nil)
 		if up != nil {
 			t.Fatalf("unexpected non-empty up=%#v", up)
 		}
@@ -170,3 +180,88 @@ func TestCreateTargetURLFailure(t *testing.T) {
 		},
 	}, "/api/v1/write")
 }
+
+func TestURLMapMatch(t *testing.T) {
+	mustParseURLMap := func(s string) URLMap {
+		var e URLMap
+		if err := yaml.UnmarshalStrict([]byte(s), &e); err != nil {
+			t.Fatalf("cannot unmarshal url_map: %s", err)
+		}
+		return e
+	}
+	ui := &UserInfo{
+		URLMaps: []URLMap{
+			mustParseURLMap(`
+src_paths: ["/api/v1/query_range"]
+src_query_args: ["step=[0-9]+h", "nocache=1"]
+url_prefix: http://long-term`),
+			mustParseURLMap(`
+src_headers: ["X-Scope-OrgID: team-(a|b)"]
+url_prefix: http://teams`),
+			mustParseURLMap(`
+src_paths: ["/api/v1/.+"]
+src_methods: [POST]
+url_prefix: http://post`),
+		},
+		URLPrefix: mustParseURL("http://default"),
+	}
+	f := func(requestURI string, h http.Header, method, expectedTarget string) {
+		t.Helper()
+		u, err := url.Parse(requestURI)
+		if err != nil {
+			t.Fatalf("cannot parse %q: %s", requestURI, err)
+		}
+		u = normalizeURL(u)
+		up, _, _, _ := ui.getURLPrefixAndHeaders(u, nil, h, method, nil)
+		bu := up.getLeastLoadedBackendURL()
+		target := mergeURLs(bu.url, u)
+		bu.put()
+		if target.String() != expectedTarget {
+			t.Fatalf("unexpected target; got %q; want %q", target, expectedTarget)
+		}
+	}
+
+	f("/api/v1/query_range?step=1h", nil, "GET", "http://long-term/api/v1/query_range?step=1h")
+	f("/api/v1/query_range?step=15s&nocache=1", nil, "GET", "http://long-term/api/v1/query_range?nocache=1&step=15s")
+	f("/api/v1/query_range?step=15s", nil, "GET", "http://default/api/v1/query_range?step=15s")
+	// query args must match together with src_paths
+	f("/api/v1/query?step=1h", nil, "GET", "http://default/api/v1/query?step=1h")
+
+	f("/api/v1/query", http.Header{"X-Scope-Orgid": {"team-a"}}, "GET", "http://teams/api/v1/query")
+	f("/api/v1/query", http.Header{"X-Scope-Orgid": {"team-c"}}, "GET", "http://default/api/v1/query")
+
+	f("/api/v1/query", nil, "POST", "http://post/api/v1/query")
+	f("/api/v1/query", nil, "post", "http://post/api/v1/query")
+	f("/api/v1/query", nil, "GET", "http://default/api/v1/query")
+	// routes are evaluated in order
+	f("/api/v1/query_range?step=1h", nil, "POST", "http://long-term/api/v1/query_range?step=1h")
+
+	// query args from url-encoded request body must be matched
+	fBody := func(body, expectedTarget string) {
+		t.Helper()
+		r := httptest.NewRequest("POST", "/api/v1/query_range", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		args, err := getRequestArgs(r)
+		if err != nil {
+			t.Fatalf("cannot get request args: %s", err)
+		}
+		u := normalizeURL(r.URL)
+		up, _, _, _ := ui.getURLPrefixAndHeaders(u, args, r.Header, r.Method, nil)
+		bu := up.getLeastLoadedBackendURL()
+		target := mergeURLs(bu.url, u)
+		bu.put()
+		if target.String() != expectedTarget {
+			t.Fatalf("unexpected target; got %q; want %q", target, expectedTarget)
+		}
+		// the request body must be restored
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("cannot read request body: %s", err)
+		}
+		if string(data) != body {
+			t.Fatalf("unexpected request body; got %q; want %q", data, body)
+		}
+	}
+	fBody("query=up&step=1h", "http://long-term/api/v1/query_range")
+	fBody("query=up&step=15s", "http://post/api/v1/query_range")
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+// timeRange is the time range for the request to Prometheus querying API.
+//
+// All the timestamps are in seconds.
+type timeRange struct {
+	start float64
+	end   float64
+
+	// now is the current time at the moment the request has been received.
+	// It is used for determining the age of start and end,
+	// so all the parts of split request are routed consistently.
+	now float64
+
+	// step is the value of `step` query arg. It is 0 if the arg is missing.
+	step float64
+
+	// args contains all the query args for the request including args from url-encoded request body.
+	args url.Values
+}
+
+// getStartAge returns the age of tr start in seconds.
+func (tr *timeRange) getStartAge() float64 {
+	return tr.now - tr.start
+}
+
+// getEndAge returns the age of tr end in seconds.
+func (tr *timeRange) getEndAge() float64 {
+	return tr.now - tr.end
+}
+
+func (ui *UserInfo) hasTimeRangeMatchers() bool {
+	for i := range ui.URLMaps {
+		if ui.URLMaps[i].hasTimeRangeMatchers() {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *URLMap) hasTimeRangeMatchers() bool {
+	return e.SrcMaxAge != nil || e.SrcMinAge != nil
+}
+
+func (e *URLMap) validateTimeRangeMatchers() error {
+	if e.SrcMaxAge != nil && e.SrcMaxAge.Duration() <= 0 {
+		return fmt.Errorf("`src_max_age` must be positive; got %s", e.SrcMaxAge.Duration())
+	}
+	if e.SrcMinAge != nil && e.SrcMinAge.Duration() <= 0 {
+		return fmt.Errorf("`src_min_age` must be positive; got %s", e.SrcMinAge.Duration())
+	}
+	if e.SplitTimeRange && e.SrcMaxAge == nil {
+		return fmt.Errorf("`split_time_range` requires `src_max_age`")
+	}
+	return nil
+}
+
+// matchTimeRange returns true if the request to path with the given time range tr matches src_max_age and src_min_age of e.
+//
+// Requests without time range never match time range matchers.
+func (e *URLMap) matchTimeRange(path string, tr *timeRange) bool {
+	if !e.hasTimeRangeMatchers() {
+		return true
+	}
+	if tr == nil {
+		return false
+	}
+	if e.SrcMaxAge != nil {
+		maxAge := e.SrcMaxAge.Duration().Seconds()
+		if e.canSplitTimeRange(path, tr) {
+			// The older part of the time range is routed via a separate request.
+			if tr.getEndAge() > maxAge {
+				return false
+			}
+		} else if tr.getStartAge() > maxAge {
+			return false
+		}
+	}
+	if e.SrcMinAge != nil && tr.getEndAge() < e.SrcMinAge.Duration().Seconds() {
+		return false
+	}
+	return true
+}
+
+// canSplitTimeRange returns true if the request to path with the given time range tr can be split by e.
+func (e *URLMap) canSplitTimeRange(path string, tr *timeRange) bool {
+	return e != nil && e.SplitTimeRange && tr != nil && tr.step > 0 && strings.HasSuffix(path, "/api/v1/query_range")
+}
+
+// getSplitTimestamp returns the start of the recent part of tr, which must be routed to e.
+//
+// The returned timestamp is aligned to tr.step, so the split request returns the same points as the original request.
+// false is returned if tr doesn't need to be split.
+func (e *URLMap) getSplitTimestamp(path string, tr *timeRange) (float64, bool) {
+	if !e.canSplitTimeRange(path, tr) {
+		return 0, false
+	}
+	minTimestamp := tr.now - e.SrcMaxAge.Duration().Seconds()
+	if tr.start >= minTimestamp {
+		return 0, false
+	}
+	n := math.Ceil((minTimestamp - tr.start) / tr.step)
+	ts := tr.start + n*tr.step
+	if ts > tr.end {
+		return 0, false
+	}
+	return ts, true
+}
+
+// getRequestTimeRange returns the time range for r with the given args obtained via getRequestArgs.
+//
+// nil is returned if args don't contain time range args.
+func getRequestTimeRange(r *http.Request, args url.Values, now time.Time) (*timeRange, error) {
+	nowSecs := float64(now.UnixNano()) / 1e9
+	tr := &timeRange{
+		start: math.Inf(-1),
+		end:   nowSecs,
+		now:   nowSecs,
+		args:  args,
+	}
+	hasTimeRange := false
+	if s := args.Get("time"); s != "" {
+		ts, err := parseTimeRangeArg("time", s, nowSecs)
+		if err != nil {
+			return nil, err
+		}
+		tr.start = ts
+		tr.end = ts
+		hasTimeRange = true
+	} else if strings.HasSuffix(normalizeURL(r.URL).Path, "/api/v1/query") {
+		// Instant queries without `time` arg are executed at the current time.
+		tr.start = nowSecs
+		hasTimeRange = true
+	}
+	if s := args.Get("start"); s != "" {
+		ts, err := parseTimeRangeArg("start", s, nowSecs)
+		if err != nil {
+			return nil, err
+		}
+		tr.start = ts
+		hasTimeRange = true
+	}
+	if s := args.Get("end"); s != "" {
+		ts, err := parseTimeRangeArg("end", s, nowSecs)
+		if err != nil {
+			return nil, err
+		}
+		tr.end = ts
+		hasTimeRange = true
+	}
+	if !hasTimeRange {
+		return nil, nil
+	}
+	if tr.start > tr.end {
+		tr.start = tr.end
+	}
+	if s := args.Get("step"); s != "" {
+		step, err := parseStep(s)
+		if err != nil {
+			return nil, &httpserver.ErrorWithStatusCode{
+				Err:        fmt.Errorf("cannot parse `step` arg %q: %w", s, err),
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+		tr.step = step
+	}
+	return tr, nil
+}
+
+func parseTimeRangeArg(argName, s string, nowSecs float64) (float64, error) {
+	ts, err := promutils.ParseTimeAt(s, nowSecs)
+	if err != nil {
+		return 0, &httpserver.ErrorWithStatusCode{
+			Err:        fmt.Errorf("cannot parse `%s` arg %q: %w", argName, s, err),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return ts, nil
+}
+
+// parseStep parses step in seconds from s.
+func parseStep(s string) (float64, error) {
+	step, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		d, err := promutils.ParseDuration(s)
+		if err != nil {
+			return 0, err
+		}
+		step = d.Seconds()
+	}
+	if step <= 0 || math.IsNaN(step) || math.IsInf(step, 0) {
+		return 0, fmt.Errorf("step must be positive")
+	}
+	return step, nil
+}
+
+// processSplitTimeRangeRequest splits the request r with the time range tr at splitTimestamp,
+// proxies both parts according to ui config and writes the merged response to w.
+//
+// The recent part of the request is routed to the url_map with `split_time_range`,
+// while the older part is routed according to the remaining url_map entries.
+func processSplitTimeRangeRequest(w http.ResponseWriter, r *http.Request, ui *UserInfo, claims jwtClaims, tr *timeRange, splitTimestamp float64) {
+	oldTR := *tr
+	oldTR.end = splitTimestamp - tr.step
+	recentTR := *tr
+	recentTR.start = splitTimestamp
+
+	trs := []*timeRange{&oldTR, &recentTR}
+	bws := make([]*bufferedResponseWriter, len(trs))
+	var wg sync.WaitGroup
+	for i, tr := range trs {
+		req := newTimeRangeRequest(r, tr)
+		// The user-level rate limit reservation is shared by both parts of the request,
+		// so it mustn't be cancelled if one of the parts is rejected by `url_map` rate limits,
+		// since the other part may be already proxied to backend.
+		req = withUserRateLimitReservation(req, nil)
+		bw := &bufferedResponseWriter{
+			header: make(http.Header),
+		}
+		bws[i] = bw
+		wg.Add(1)
+		go func(tr *timeRange) {
+			defer wg.Done()
+			processRequestWithTimeRange(bw, req, ui, claims, tr.args, tr)
+		}(tr)
+	}
+	wg.Wait()
+	splitTimeRangeRequests.Inc()
+
+	for _, bw := range bws {
+		if bw.getStatusCode() != http.StatusOK {
+			// Return the first error as is.
+			bw.writeTo(w)
+			return
+		}
+	}
+	data, err := mergePrometheusMatrixResponses(bws[0].buf.Bytes(), bws[1].buf.Bytes())
+	if err != nil {
+		err = &httpserver.ErrorWithStatusCode{
+			Err:        fmt.Errorf("cannot merge responses for split time range request: %w", err),
+			StatusCode: http.StatusBadGateway,
+		}
+		httpserver.Errorf(w, r, "%s", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// newTimeRangeRequest returns a copy of r with the time range set to tr.
+//
+// All the query args are passed in the request url, so the args from `url_prefix` take precedence
+// over the args from the original request in the same way as for ordinary requests. See mergeURLs.
+func newTimeRangeRequest(r *http.Request, tr *timeRange) *http.Request {
+	args := make(url.Values, len(tr.args))
+	for k, vs := range tr.args {
+		args[k] = vs
+	}
+	args.Set("start", formatTimestamp(tr.start))
+	args.Set("end", formatTimestamp(tr.end))
+
+	req := r.Clone(r.Context())
+	req.Method = http.MethodGet
+	req.URL.RawQuery = args.Encode()
+	req.Body = http.NoBody
+	req.ContentLength = 0
+	req.Header.Del("Content-Type")
+	req.Header.Del("Content-Length")
+	// The response must be uncompressed in order to be merged.
+	req.Header.Del("Accept-Encoding")
+	// The original url-encoded body args are passed in the request url, so the cached body args mustn't be used for req.
+	return withFormBodyCache(req)
+}
+
+func formatTimestamp(ts float64) string {
+	return strconv.FormatFloat(ts, 'f', -1, 64)
+}
+
+// bufferedResponseWriter collects the response in memory.
+type bufferedResponseWriter struct {
+	header     http.Header
+	statusCode int
+	buf        bytes.Buffer
+}
+
+// Header implements http.ResponseWriter interface.
+func (bw *bufferedResponseWriter) Header() http.Header {
+	return bw.header
+}
+
+// WriteHeader implements http.ResponseWriter interface.
+func (bw *bufferedResponseWriter) WriteHeader(statusCode int) {
+	if bw.statusCode == 0 {
+		bw.statusCode = statusCode
+	}
+}
+
+// Write implements http.ResponseWriter interface.
+func (bw *bufferedResponseWriter) Write(p []byte) (int, error) {
+	if bw.statusCode == 0 {
+		bw.statusCode = http.StatusOK
+	}
+	return bw.buf.Write(p)
+}
+
+func (bw *bufferedResponseWriter) getStatusCode() int {
+	if bw.statusCode == 0 {
+		// The request has been canceled before the response has been received.
+		return http.StatusServiceUnavailable
+	}
+	return bw.statusCode
+}
+
+func (bw *bufferedResponseWriter) writeTo(w http.ResponseWriter) {
+	h := w.Header()
+	for k, vv := range bw.header {
+		h[k] = vv
+	}
+	w.WriteHeader(bw.getStatusCode())
+	_, _ = w.Write(bw.buf.Bytes())
+}
+
+type prometheusMatrixResponse struct {
+	Status    string   `json:"status"`
+	IsPartial *bool    `json:"isPartial,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+	Data      struct {
+		ResultType string                   `json:"resultType"`
+		Result     []prometheusMatrixSeries `json:"result"`
+	} `json:"data"`
+}
+
+type prometheusMatrixSeries struct {
+	Metric map[string]string    `json:"metric"`
+	Values [][2]json.RawMessage `json:"values"`
+}
+
+// mergePrometheusMatrixResponses merges /api/v1/query_range responses for adjacent time ranges.
+//
+// The oldData must contain the response for the older time range.
+// Only the fields from prometheusMatrixResponse are preserved in the merged response, e.g. `stats` are dropped.
+func mergePrometheusMatrixResponses(oldData, recentData []byte) ([]byte, error) {
+	var old, recent prometheusMatrixResponse
+	if err := json.Unmarshal(oldData, &old); err != nil {
+		return nil, fmt.Errorf("cannot parse response for the older time range: %w", err)
+	}
+	if err := json.Unmarshal(recentData, &recent); err != nil {
+		return nil, fmt.Errorf("cannot parse response for the recent time range: %w", err)
+	}
+	for _, resp := range []*prometheusMatrixResponse{&old, &recent} {
+		if resp.Status != "success" {
+			return nil, fmt.Errorf("unexpected response status %q; want %q", resp.Status, "success")
+		}
+		if resp.Data.ResultType != "matrix" {
+			return nil, fmt.Errorf("unexpected resultType %q; want %q", resp.Data.ResultType, "matrix")
+		}
+	}
+
+	m := make(map[string]int, len(old.Data.Result))
+	for i := range old.Data.Result {
+		m[getMetricKey(old.Data.Result[i].Metric)] = i
+	}
+	result := old.Data.Result
+	for _, s := range recent.Data.Result {
+		if idx, ok := m[getMetricKey(s.Metric)]; ok {
+			result[idx].Values = append(result[idx].Values, s.Values...)
+			continue
+		}
+		result = append(result, s)
+	}
+
+	merged := old
+	merged.Data.Result = result
+	merged.Warnings = append(merged.Warnings, recent.Warnings...)
+	if recent.IsPartial != nil && *recent.IsPartial {
+		merged.IsPartial = recent.IsPartial
+	}
+	return json.Marshal(&merged)
+}
+
+func getMetricKey(m map[string]string) string {
+	// json.Marshal sorts map keys, so the result is identical for identical label sets.
+	data, _ := json.Marshal(m)
+	return string(data)
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+func TestGetRequestTimeRangeSuccess(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	f := func(method, requestURI, body string, startExpected, endExpected, stepExpected float64) {
+		t.Helper()
+		var r *http.Request
+		if body != "" {
+			r = httptest.NewRequest(method, requestURI, strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			r = httptest.NewRequest(method, requestURI, nil)
+		}
+		args, err := getRequestArgs(r)
+		if err != nil {
+			t.Fatalf("cannot get request args: %s", err)
+		}
+		tr, err := getRequestTimeRange(r, args, now)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if tr.start != startExpected {
+			t.Fatalf("unexpected start; got %v; want %v", tr.start, startExpected)
+		}
+		if tr.end != endExpected {
+			t.Fatalf("unexpected end; got %v; want %v", tr.end, endExpected)
+		}
+		if tr.step != stepExpected {
+			t.Fatalf("unexpected step; got %v; want %v", tr.step, stepExpected)
+		}
+		if body != "" {
+			// The request body must be restored
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatalf("cannot read request body: %s", err)
+			}
+			if string(data) != body {
+				t.Fatalf("unexpected request body; got %q; want %q", data, body)
+			}
+		}
+	}
+
+	// query_range
+	f("GET", "/api/v1/query_range?query=up&start=1699990000&end=1699999000&step=60", "", 1699990000, 1699999000, 60)
+	f("GET", "/api/v1/query_range?query=up&start=1699990000&step=1m", "", 1699990000, 1700000000, 60)
+	f("GET", "/api/v1/query_range?query=up&start=2023-11-14T22:00:00Z&end=now-10m&step=60", "", 1699999200, 1699999400, 60)
+	f("POST", "/api/v1/query_range", "query=up&start=1699990000&end=1699999000&step=30s", 1699990000, 1699999000, 30)
+
+	// instant query
+	f("GET", "/api/v1/query?query=up&time=1699990000", "", 1699990000, 1699990000, 0)
+	f("GET", "/api/v1/query?query=up", "", 1700000000, 1700000000, 0)
+	f("POST", "/api/v1/query", "query=up&time=1699990000", 1699990000, 1699990000, 0)
+
+	// series without start
+	f("GET", "/api/v1/series?match[]=up&end=1699990000", "", math.Inf(-1), 1699990000, 0)
+}
+
+func TestGetRequestTimeRangeMissing(t *testing.T) {
+	f := func(requestURI string) {
+		t.Helper()
+		r := httptest.NewRequest("GET", requestURI, nil)
+		tr, err := getRequestTimeRange(r, r.URL.Query(), time.Now())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if tr != nil {
+			t.Fatalf("expecting nil time range; got %+v", tr)
+		}
+	}
+
+	f("/api/v1/write")
+	f("/api/v1/labels")
+	f("/api/v1/series?match[]=up")
+}
+
+func TestGetRequestTimeRangeFailure(t *testing.T) {
+	f := func(requestURI string) {
+		t.Helper()
+		r := httptest.NewRequest("GET", requestURI, nil)
+		if _, err := getRequestTimeRange(r, r.URL.Query(), time.Now()); err == nil {
+			t.Fatalf("expecting non-nil error for %q", requestURI)
+		}
+	}
+
+	f("/api/v1/query?query=up&time=foobar")
+	f("/api/v1/query_range?query=up&start=foobar")
+	f("/api/v1/query_range?query=up&start=1699990000&end=foobar")
+	f("/api/v1/query_range?query=up&start=1699990000&step=foobar")
+	f("/api/v1/query_range?query=up&start=1699990000&step=-1")
+}
+
+func TestURLMapMatchTimeRange(t *testing.T) {
+	hot := &URLMap{
+		SrcMaxAge: promutils.NewDuration(time.Hour),
+	}
+	hotSplit := &URLMap{
+		SrcMaxAge:      promutils.NewDuration(time.Hour),
+		SplitTimeRange: true,
+	}
+	cold := &URLMap{
+		SrcMinAge: promutils.NewDuration(time.Hour),
+	}
+	noAge := &URLMap{}
+	f := func(e *URLMap, path string, start, end, step float64, resultExpected bool) {
+		t.Helper()
+		tr := &timeRange{
+			start: start,
+			end:   end,
+			now:   10000,
+			step:  step,
+		}
+		result := e.matchTimeRange(path, tr)
+		if result != resultExpected {
+			t.Fatalf("unexpected result for start=%v, end=%v; got %v; want %v", start, end, result, resultExpected)
+		}
+	}
+
+	// the whole time range is recent
+	f(hot, "/api/v1/query_range", 7000, 10000, 60, true)
+	f(hotSplit, "/api/v1/query_range", 7000, 10000, 60, true)
+	f(cold, "/api/v1/query_range", 7000, 10000, 60, false)
+	f(noAge, "/api/v1/query_range", 7000, 10000, 60, true)
+
+	// the whole time range is old
+	f(hot, "/api/v1/query_range", 1000, 5000, 60, false)
+	f(hotSplit, "/api/v1/query_range", 1000, 5000, 60, false)
+	f(cold, "/api/v1/query_range", 1000, 5000, 60, true)
+
+	// the time range spans both recent and old data
+	f(hot, "/api/v1/query_range", 1000, 10000, 60, false)
+	f(hotSplit, "/api/v1/query_range", 1000, 10000, 60, true)
+	f(hotSplit, "/api/v1/series", 1000, 10000, 0, false)
+	f(cold, "/api/v1/query_range", 1000, 10000, 60, false)
+
+	// missing start
+	f(hot, "/api/v1/series", math.Inf(-1), 10000, 0, false)
+
+	// requests without time range do not match time range matchers
+	if hot.matchTimeRange("/api/v1/write", nil) {
+		t.Fatalf("request without time range mustn't match src_max_age")
+	}
+	if !noAge.matchTimeRange("/api/v1/write", nil) {
+		t.Fatalf("request without time range must match url_map without time range matchers")
+	}
+}
+
+func TestURLMapGetSplitTimestamp(t *testing.T) {
+	e := &URLMap{
+		SrcMaxAge:      promutils.NewDuration(time.Hour),
+		SplitTimeRange: true,
+	}
+	f := func(path string, start, end, step float64, tsExpected float64, okExpected bool) {
+		t.Helper()
+		tr := &timeRange{
+			start: start,
+			end:   end,
+			now:   10000,
+			step:  step,
+		}
+		ts, ok := e.getSplitTimestamp(path, tr)
+		if ok != okExpected {
+			t.Fatalf("unexpected ok; got %v; want %v", ok, okExpected)
+		}
+		if ts != tsExpected {
+			t.Fatalf("unexpected split timestamp; got %v; want %v", ts, tsExpected)
+		}
+	}
+
+	// The split timestamp is aligned to step
+	f("/api/v1/query_range", 1000, 10000, 60, 6400, true)
+	f("/api/v1/query_range", 1030, 10000, 60, 6430, true)
+
+	// The time range is recent
+	f("/api/v1/query_range", 7000, 10000, 60, 0, false)
+
+	// Unsupported path
+	f("/api/v1/export", 1000, 10000, 60, 0, false)
+
+	// Missing step
+	f("/api/v1/query_range", 1000, 10000, 0, 0, false)
+}
+
+func TestMergePrometheusMatrixResponsesSuccess(t *testing.T) {
+	f := func(oldData, recentData, resultExpected string) {
+		t.Helper()
+		result, err := mergePrometheusMatrixResponses([]byte(oldData), []byte(recentData))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(result) != resultExpected {
+			t.Fatalf("unexpected result;\ngot\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+
+	f(`{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+		`{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+		`{"status":"success","data":{"resultType":"matrix","result":[]}}`)
+	f(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"a"},"values":[[1000,"1"],[1060,"2"]]},{"metric":{"job":"b"},"values":[[1000,"0"]]}]}}`,
+		`{"status":"success","isPartial":false,"data":{"resultType":"matrix","result":[{"metric":{"job":"a","__name__":"up"},"values":[[1120,"3"]]},{"metric":{"job":"c"},"values":[[1120.5,"5"]]}]}}`,
+		`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"a"},"values":[[1000,"1"],[1060,"2"],[1120,"3"]]},{"metric":{"job":"b"},"values":[[1000,"0"]]},{"metric":{"job":"c"},"values":[[1120.5,"5"]]}]}}`)
+	f(`{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+		`{"status":"success","isPartial":true,"warnings":["foo"],"data":{"resultType":"matrix","result":[]}}`,
+		`{"status":"success","isPartial":true,"warnings":["foo"],"data":{"resultType":"matrix","result":[]}}`)
+}
+
+func TestMergePrometheusMatrixResponsesFailure(t *testing.T) {
+	f := func(oldData, recentData string) {
+		t.Helper()
+		if _, err := mergePrometheusMatrixResponses([]byte(oldData), []byte(recentData)); err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+
+	ok := `{"status":"success","data":{"resultType":"matrix","result":[]}}`
+	f("foobar", ok)
+	f(ok, "foobar")
+	f(ok, `{"status":"error","errorType":"bad_data","error":"foo"}`)
+	f(`{"status":"success","data":{"resultType":"vector","result":[]}}`, ok)
+}
+
+func TestProcessRequestSplitTimeRange(t *testing.T) {
+	newBackend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("cannot parse request form: %s", err)
+			}
+			fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"backend":"%s"},"values":[[%s,"1"]]},{"metric":{"job":"x"},"values":[[%s,"%s"]]}]}}`,
+				name, r.FormValue("start"), r.FormValue("end"), name)
+		}))
+	}
+	hot := newBackend("hot")
+	defer hot.Close()
+	cold := newBackend("cold")
+	defer cold.Close()
+
+	ac, err := parseAuthConfig([]byte(fmt.Sprintf(`
+users:
+- username: time-range-test
+  url_map:
+  - src_max_age: 1h
+    split_time_range: true
+    url_prefix: %q
+  - src_paths: ["/api/v1/.*"]
+    url_prefix: %q
+`, hot.URL, cold.URL)))
+	if err != nil {
+		t.Fatalf("cannot parse auth config: %s", err)
+	}
+	uis, err := parseAuthConfigUsers(ac)
+	if err != nil {
+		t.Fatalf("cannot parse users: %s", err)
+	}
+	var ui *UserInfo
+	for _, v := range uis {
+		ui = v
+	}
+
+	f := func(method, requestURI, body, responseExpected string) {
+		t.Helper()
+		var r *http.Request
+		if body != "" {
+			r = httptest.NewRequest(method, requestURI, strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			r = httptest.NewRequest(method, requestURI, nil)
+		}
+		w := httptest.NewRecorder()
+		processRequest(w, r, ui, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status code; got %d; want %d; response: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+		if w.Body.String() != responseExpected {
+			t.Fatalf("unexpected response;\ngot\n%s\nwant\n%s", w.Body.String(), responseExpected)
+		}
+	}
+
+	end := time.Now().Unix()
+	start := end - 2*3600
+
+	// The request is split between cold and hot backends
+	args := url.Values{
+		"query": {"up"},
+		"start": {fmt.Sprintf("%d", start)},
+		"end":   {fmt.Sprintf("%d", end)},
+		"step":  {"600"},
+	}
+	// The older part ends at start+1h, while the recent part starts at the next step after start+1h.
+	responseExpected := fmt.Sprintf(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"backend":"cold"},"values":[[%d,"1"]]},{"metric":{"job":"x"},"values":[[%d,"cold"],[%d,"hot"]]},{"metric":{"backend":"hot"},"values":[[%d,"1"]]}]}}`,
+		start, start+3600, end, start+4200)
+	f("GET", "/api/v1/query_range?"+args.Encode(), "", responseExpected)
+	f("POST", "/api/v1/query_range", args.Encode(), responseExpected)
+}
+
+func TestProcessRequestSplitTimeRangeURLPrefixArgs(t *testing.T) {
+	newBackend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("cannot parse request form: %s", err)
+			}
+			fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"backend":"%s","extra_label":%q},"values":[[%s,"1"]]}]}}`,
+				name, strings.Join(r.Form["extra_label"], ","), r.FormValue("start"))
+		}))
+	}
+	hot := newBackend("hot")
+	defer hot.Close()
+	cold := newBackend("cold")
+	defer cold.Close()
+
+	ac, err := parseAuthConfig([]byte(fmt.Sprintf(`
+users:
+- username: time-range-test
+  url_map:
+  - src_max_age: 1h
+    split_time_range: true
+    url_prefix: %q
+  url_prefix: %q
+`, hot.URL+"?extra_label=tenant=hot", cold.URL+"?extra_label=tenant=cold")))
+	if err != nil {
+		t.Fatalf("cannot parse auth config: %s", err)
+	}
+	uis, err := parseAuthConfigUsers(ac)
+	if err != nil {
+		t.Fatalf("cannot parse users: %s", err)
+	}
+	var ui *UserInfo
+	for _, v := range uis {
+		ui = v
+	}
+
+	end := time.Now().Unix()
+	start := end - 2*3600
+	args := url.Values{
+		"query":       {"up"},
+		"start":       {fmt.Sprintf("%d", start)},
+		"end":         {fmt.Sprintf("%d", end)},
+		"step":        {"600"},
+		"extra_label": {"tenant=foo"},
+	}
+	// extra_label from the client must be dropped in favor of extra_label from url_prefix
+	responseExpected := fmt.Sprintf(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"backend":"cold","extra_label":"tenant=cold"},"values":[[%d,"1"]]},{"metric":{"backend":"hot","extra_label":"tenant=hot"},"values":[[%d,"1"]]}]}}`,
+		start, start+4200)
+	f := func(r *http.Request) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		processRequest(w, r, ui, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status code; got %d; want %d; response: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+		if w.Body.String() != responseExpected {
+			t.Fatalf("unexpected response;\ngot\n%s\nwant\n%s", w.Body.String(), responseExpected)
+		}
+	}
+
+	f(httptest.NewRequest("GET", "/api/v1/query_range?"+args.Encode(), nil))
+	r := httptest.NewRequest("POST", "/api/v1/query_range", strings.NewReader(args.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	f(r)
+}
+
+func TestProcessSplitTimeRangeRequestRateLimitedCold(t *testing.T) {
+	var hotRequests, coldRequests atomic.Int64
+	hot := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hotRequests.Add(1)
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[]}}`)
+	}))
+	defer hot.Close()
+	cold := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		coldRequests.Add(1)
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[]}}`)
+	}))
+	defer cold.Close()
+
+	ac, err := parseAuthConfig([]byte(fmt.Sprintf(`
+users:
+- username: time-range-rate-limit-test
+  max_requests_per_second: 1
+  url_map:
+  - src_max_age: 1h
+    split_time_range: true
+    url_prefix: %q
+  - src_paths: ["/api/v1/query_range"]
+    max_requests_per_second: 1
+    url_prefix: %q
+`, hot.URL, cold.URL)))
+	if err != nil {
+		t.Fatalf("cannot parse auth config: %s", err)
+	}
+	uis, err := parseAuthConfigUsers(ac)
+	if err != nil {
+		t.Fatalf("cannot parse users: %s", err)
+	}
+	var ui *UserInfo
+	for _, v := range uis {
+		ui = v
+	}
+
+	// Exhaust the rate limit for the cold route.
+	if _, err := ui.URLMaps[1].RateLimitConf.beginRequest(0); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	end := time.Now().Unix()
+	start := end - 2*3600
+	args := url.Values{
+		"query": {"up"},
+		"start": {fmt.Sprintf("%d", start)},
+		"end":   {fmt.Sprintf("%d", end)},
+		"step":  {"600"},
+	}
+	r := httptest.NewRequest("GET", "/api/v1/query_range?"+args.Encode(), nil)
+	w := httptest.NewRecorder()
+	processUserRequest(w, r, ui, nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status code; got %d; want %d; response: %s", w.Code, http.StatusTooManyRequests, w.Body.String())
+	}
+	if n := hotRequests.Load(); n != 1 {
+		t.Fatalf("unexpected number of requests to hot backend; got %d; want 1", n)
+	}
+	if n := coldRequests.Load(); n != 0 {
+		t.Fatalf("unexpected number of requests to cold backend; got %d; want 0", n)
+	}
+
+	// The user must be charged for the request, since its recent part has been proxied to backend.
+	if _, err := ui.RateLimitConf.beginRequest(0); err == nil {
+		t.Fatalf("expecting non-nil error when exceeding user-level max_requests_per_second")
+	}
+}
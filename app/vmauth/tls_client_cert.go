@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+var (
+	mtlsEnable = flag.Bool("mtls", false, "Whether to require valid client certificate for https requests to -httpListenAddr. This flag works only if -tls flag is set. See also -mtlsCAFile")
+	mtlsCAFile = flag.String("mtlsCAFile", "", "Optional path to TLS Root CA for verifying client certificates at -httpListenAddr when -mtls is set. "+
+		"By default the host system TLS Root CA is used for client certificate verification")
+)
+
+// updateServerTLSConfig enables client certificate verification at tc if -mtls is set.
+func updateServerTLSConfig(tc *tls.Config) error {
+	if !*mtlsEnable {
+		return nil
+	}
+	if err := setClientCertVerification(tc, *mtlsCAFile); err != nil {
+		return fmt.Errorf("cannot enable client certificate verification with -mtlsCAFile=%q: %w", *mtlsCAFile, err)
+	}
+	return nil
+}
+
+// setClientCertVerification configures tc to require client certificates signed by CA from caFile.
+//
+// The host system Root CA is used if caFile is empty.
+func setClientCertVerification(tc *tls.Config, caFile string) error {
+	tc.ClientAuth = tls.RequireAndVerifyClientCert
+	if caFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return fmt.Errorf("cannot read CA file: %w", err)
+	}
+	cp := x509.NewCertPool()
+	if !cp.AppendCertsFromPEM(data) {
+		return fmt.Errorf("cannot find PEM-encoded certificates in %q", caFile)
+	}
+	tc.ClientCAs = cp
+	return nil
+}
+
+// TLSClientCertMatcher identifies user by the verified TLS client certificate.
+//
+// All the non-empty fields must match the certificate.
+type TLSClientCertMatcher struct {
+	// CommonName must match the common name (CN) of the certificate subject.
+	CommonName string `yaml:"common_name,omitempty"`
+	// Organization must match one of organizations (O) of the certificate subject.
+	Organization string `yaml:"organization,omitempty"`
+	// OrganizationalUnit must match one of organizational units (OU) of the certificate subject.
+	OrganizationalUnit string `yaml:"organizational_unit,omitempty"`
+	// DNSName must match one of DNS names in the certificate SAN.
+	DNSName string `yaml:"dns_name,omitempty"`
+	// EmailAddress must match one of email addresses in the certificate SAN.
+	EmailAddress string `yaml:"email_address,omitempty"`
+	// URI must match one of URIs in the certificate SAN.
+	URI string `yaml:"uri,omitempty"`
+}
+
+func (m *TLSClientCertMatcher) validate() error {
+	if m.CommonName == "" && m.Organization == "" && m.OrganizationalUnit == "" && m.DNSName == "" && m.EmailAddress == "" && m.URI == "" {
+		return fmt.Errorf("`tls_client_cert` must contain at least one of common_name, organization, organizational_unit, dns_name, email_address or uri")
+	}
+	return nil
+}
+
+func (m *TLSClientCertMatcher) matches(cert *x509.Certificate) bool {
+	if m.CommonName != "" && cert.Subject.CommonName != m.CommonName {
+		return false
+	}
+	if m.Organization != "" && !containsString(cert.Subject.Organization, m.Organization) {
+		return false
+	}
+	if m.OrganizationalUnit != "" && !containsString(cert.Subject.OrganizationalUnit, m.OrganizationalUnit) {
+		return false
+	}
+	if m.DNSName != "" && !containsString(cert.DNSNames, m.DNSName) {
+		return false
+	}
+	if m.EmailAddress != "" && !containsString(cert.EmailAddresses, m.EmailAddress) {
+		return false
+	}
+	if m.URI != "" {
+		found := false
+		for _, u := range cert.URIs {
+			if u.String() == m.URI {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(a []string, s string) bool {
+	for _, x := range a {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// getVerifiedClientCert returns the client certificate for r if it has been verified during TLS handshake.
+//
+// Client certificates are verified only if vmauth runs with -tls and -mtls command-line flags.
+func getVerifiedClientCert(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return r.TLS.PeerCertificates[0]
+}
+
+// getTLSClientCertUser returns the first user from uis, which matches the verified client certificate for r.
+func getTLSClientCertUser(uis []*UserInfo, r *http.Request) *UserInfo {
+	cert := getVerifiedClientCert(r)
+	if cert == nil {
+		return nil
+	}
+	for _, ui := range uis {
+		if ui.TLSClientCert.matches(cert) {
+			return ui
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestTLSClientCertMatcherMatches(t *testing.T) {
+	u, err := url.Parse("spiffe://cluster.local/ns/monitoring/sa/vmagent")
+	if err != nil {
+		t.Fatalf("cannot parse url: %s", err)
+	}
+	cert := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "vmagent",
+			Organization:       []string{"acme"},
+			OrganizationalUnit: []string{"monitoring", "ops"},
+		},
+		DNSNames:       []string{"vmagent.monitoring.svc"},
+		EmailAddresses: []string{"ops@acme.org"},
+		URIs:           []*url.URL{u},
+	}
+	f := func(m *TLSClientCertMatcher, resultExpected bool) {
+		t.Helper()
+		if result := m.matches(cert); result != resultExpected {
+			t.Fatalf("unexpected result for %+v; got %v; want %v", m, result, resultExpected)
+		}
+	}
+
+	f(&TLSClientCertMatcher{CommonName: "vmagent"}, true)
+	f(&TLSClientCertMatcher{CommonName: "vmagent", Organization: "acme", OrganizationalUnit: "ops"}, true)
+	f(&TLSClientCertMatcher{DNSName: "vmagent.monitoring.svc"}, true)
+	f(&TLSClientCertMatcher{EmailAddress: "ops@acme.org"}, true)
+	f(&TLSClientCertMatcher{URI: "spiffe://cluster.local/ns/monitoring/sa/vmagent"}, true)
+
+	f(&TLSClientCertMatcher{CommonName: "vmalert"}, false)
+	f(&TLSClientCertMatcher{CommonName: "vmagent", Organization: "other"}, false)
+	f(&TLSClientCertMatcher{OrganizationalUnit: "dev"}, false)
+	f(&TLSClientCertMatcher{DNSName: "vmagent"}, false)
+	f(&TLSClientCertMatcher{EmailAddress: "dev@acme.org"}, false)
+	f(&TLSClientCertMatcher{URI: "spiffe://cluster.local/ns/default/sa/vmagent"}, false)
+}
+
+func TestGetTLSClientCertUser(t *testing.T) {
+	uis := []*UserInfo{
+		{Name: "vmagent", TLSClientCert: &TLSClientCertMatcher{CommonName: "vmagent"}},
+		{Name: "acme", TLSClientCert: &TLSClientCertMatcher{Organization: "acme"}},
+	}
+	f := func(cert *x509.Certificate, verified bool, expectedUser string) {
+		t.Helper()
+		r := httptest.NewRequest("GET", "/", nil)
+		if cert != nil {
+			r.TLS = &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+			}
+			if verified {
+				r.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+			}
+		}
+		ui := getTLSClientCertUser(uis, r)
+		var name string
+		if ui != nil {
+			name = ui.Name
+		}
+		if name != expectedUser {
+			t.Fatalf("unexpected user; got %q; want %q", name, expectedUser)
+		}
+	}
+
+	f(&x509.Certificate{Subject: pkix.Name{CommonName: "vmagent", Organization: []string{"acme"}}}, true, "vmagent")
+	f(&x509.Certificate{Subject: pkix.Name{CommonName: "vmalert", Organization: []string{"acme"}}}, true, "acme")
+	f(&x509.Certificate{Subject: pkix.Name{CommonName: "vmalert"}}, true, "")
+	// unverified certificate
+	f(&x509.Certificate{Subject: pkix.Name{CommonName: "vmagent"}}, false, "")
+	// missing certificate
+	f(nil, false, "")
+}
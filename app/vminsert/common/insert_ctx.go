@@ -9,6 +9,7 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/bytesutil"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/httpserver"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompb"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/storage"
 )
 
@@ -19,6 +20,10 @@ type InsertCtx struct {
 	mrs            []storage.MetricRow
 	metricNamesBuf []byte
 
+	exemplars      []storage.ExemplarRow
+	exemplarLabels []prompb.Label
+	exemplarsBuf   []byte
+
 	relabelCtx    relabel.Ctx
 	streamAggrCtx streamAggrCtx
 
@@ -45,6 +50,23 @@ func (ctx *InsertCtx) Reset(rowsLen int) {
 	}
 	ctx.mrs = ctx.mrs[:0]
 	ctx.metricNamesBuf = ctx.metricNamesBuf[:0]
+
+	ers := ctx.exemplars
+	for i := range ers {
+		er := &ers[i]
+		er.MetricNameRaw = nil
+		er.Labels = nil
+	}
+	ctx.exemplars = ers[:0]
+	exemplarLabels := ctx.exemplarLabels
+	for i := range exemplarLabels {
+		label := &exemplarLabels[i]
+		label.Name = nil
+		label.Value = nil
+	}
+	ctx.exemplarLabels = exemplarLabels[:0]
+	ctx.exemplarsBuf = ctx.exemplarsBuf[:0]
+
 	ctx.relabelCtx.Reset()
 	ctx.streamAggrCtx.Reset()
 	ctx.skipStreamAggr = false
@@ -99,6 +121,57 @@ func (ctx *InsertCtx) addRow(metricNameRaw []byte, timestamp int64, value float6
 	return nil
 }
 
+// WriteExemplar writes exemplar e for the series with the given metricNameRaw into ctx buffer.
+//
+// metricNameRaw must be obtained from WriteDataPointExt for the series the exemplar belongs to.
+func (ctx *InsertCtx) WriteExemplar(metricNameRaw []byte, e *prompbmarshal.Exemplar) {
+	labelsLen := len(ctx.exemplarLabels)
+	for i := range e.Labels {
+		label := &e.Labels[i]
+		ctx.addExemplarLabel(bytesutil.ToUnsafeBytes(label.Name), bytesutil.ToUnsafeBytes(label.Value))
+	}
+	ctx.addExemplar(metricNameRaw, labelsLen, e.Timestamp, e.Value)
+}
+
+// WriteExemplarBytes writes exemplar e for the series with the given metricNameRaw into ctx buffer.
+//
+// metricNameRaw must be obtained from WriteDataPointExt for the series the exemplar belongs to.
+func (ctx *InsertCtx) WriteExemplarBytes(metricNameRaw []byte, e *prompb.Exemplar) {
+	labelsLen := len(ctx.exemplarLabels)
+	for i := range e.Labels {
+		label := &e.Labels[i]
+		ctx.addExemplarLabel(label.Name, label.Value)
+	}
+	ctx.addExemplar(metricNameRaw, labelsLen, e.Timestamp, e.Value)
+}
+
+func (ctx *InsertCtx) addExemplarLabel(name, value []byte) {
+	// Copy name and value, since exemplars may outlive the source buffers until FlushBufs call.
+	buf := ctx.exemplarsBuf
+	buf = append(buf, name...)
+	name = buf[len(buf)-len(name):]
+	buf = append(buf, value...)
+	value = buf[len(buf)-len(value):]
+	ctx.exemplarsBuf = buf
+	ctx.exemplarLabels = append(ctx.exemplarLabels, prompb.Label{
+		Name:  name,
+		Value: value,
+	})
+}
+
+func (ctx *InsertCtx) addExemplar(metricNameRaw []byte, labelsLen int, timestamp int64, value float64) {
+	buf := ctx.exemplarsBuf
+	buf = append(buf, metricNameRaw...)
+	metricNameRaw = buf[len(buf)-len(metricNameRaw):]
+	ctx.exemplarsBuf = buf
+	ctx.exemplars = append(ctx.exemplars, storage.ExemplarRow{
+		MetricNameRaw: metricNameRaw,
+		Labels:        ctx.exemplarLabels[labelsLen:],
+		Timestamp:     timestamp,
+		Value:         value,
+	})
+}
+
 // AddLabelBytes adds (name, value) label to ctx.Labels.
 //
 // name and value must exist until ctx.Labels is used.
@@ -156,6 +229,10 @@ func (ctx *InsertCtx) FlushBufs() error {
 	// since the number of concurrent FlushBufs() calls should be already limited via writeconcurrencylimiter
 	// used at every stream.Parse() call under lib/protoparser/*
 	err := vmstorage.AddRows(ctx.mrs)
+	if err == nil {
+		// Exemplars must be added after the rows, since they are attached to already existing series.
+		vmstorage.AddExemplars(ctx.exemplars)
+	}
 	ctx.Reset(0)
 	if err == nil {
 		return nil
@@ -44,7 +44,7 @@ var (
 	sasGlobal atomic.Pointer[streamaggr.Aggregators]
 )
 
-// CheckStreamAggrConfig checks config pointed by -stramaggr.config
+// CheckStreamAggrConfig checks config pointed by -streamAggr.config
 func CheckStreamAggrConfig() error {
 	if *streamAggrConfig == "" {
 		return nil
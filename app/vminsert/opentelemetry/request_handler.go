@@ -71,6 +71,13 @@ func insertRows(tss []prompbmarshal.TimeSeries, extraLabels []prompbmarshal.Labe
 				return err
 			}
 		}
+		if len(metricNameRaw) == 0 {
+			// Exemplars can be attached only to series with samples.
+			continue
+		}
+		for i := range ts.Exemplars {
+			ctx.WriteExemplar(metricNameRaw, &ts.Exemplars[i])
+		}
 	}
 	rowsInserted.Add(rowsTotal)
 	rowsPerInsert.Update(float64(rowsTotal))
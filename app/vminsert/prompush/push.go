@@ -73,6 +73,13 @@ func push(ctx *common.InsertCtx, tss []prompbmarshal.TimeSeries) {
 				return
 			}
 		}
+		if len(metricNameRaw) == 0 {
+			// Exemplars can be attached only to series with samples.
+			continue
+		}
+		for i := range ts.Exemplars {
+			ctx.WriteExemplar(metricNameRaw, &ts.Exemplars[i])
+		}
 	}
 	rowsInserted.Add(rowsTotal)
 	rowsPerInsert.Update(float64(rowsTotal))
@@ -70,6 +70,13 @@ func insertRows(timeseries []prompb.TimeSeries, extraLabels []prompbmarshal.Labe
 				return err
 			}
 		}
+		if len(metricNameRaw) == 0 {
+			// Exemplars can be attached only to series with samples.
+			continue
+		}
+		for i := range ts.Exemplars {
+			ctx.WriteExemplarBytes(metricNameRaw, &ts.Exemplars[i])
+		}
 	}
 	rowsInserted.Add(rowsTotal)
 	rowsPerInsert.Update(float64(rowsTotal))
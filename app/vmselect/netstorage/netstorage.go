@@ -1115,6 +1115,35 @@ func SearchMetricNames(qt *querytracer.Tracer, sq *storage.SearchQuery, deadline
 	return metricNames, nil
 }
 
+// SearchExemplars returns exemplars for series matching the given sq until the given deadline.
+func SearchExemplars(qt *querytracer.Tracer, sq *storage.SearchQuery, deadline searchutils.Deadline) ([]storage.SeriesExemplars, error) {
+	qt = qt.NewChild("fetch exemplars: %s", sq)
+	defer qt.Done()
+	if deadline.Exceeded() {
+		return nil, fmt.Errorf("timeout exceeded before starting to search exemplars: %s", deadline.String())
+	}
+
+	// Setup search.
+	tr := sq.GetTimeRange()
+	if err := vmstorage.CheckTimeRange(tr); err != nil {
+		return nil, err
+	}
+	tfss, err := setupTfss(qt, tr, sq.TagFilterss, sq.MaxMetrics, deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := vmstorage.SearchExemplars(qt, tfss, tr, sq.MaxMetrics, deadline.Deadline())
+	if err != nil {
+		return nil, fmt.Errorf("cannot find exemplars: %w", err)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].MetricName < result[j].MetricName
+	})
+	qt.Printf("sort exemplars for %d series", len(result))
+	return result, nil
+}
+
 // ProcessSearchQuery performs sq until the given deadline.
 //
 // Results.RunParallel or Results.Cancel must be called on the returned Results.
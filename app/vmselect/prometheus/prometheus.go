@@ -672,6 +672,70 @@ func SeriesHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseW
 
 var seriesDuration = metrics.NewSummary(`vm_request_duration_seconds{path="/api/v1/series"}`)
 
+// QueryExemplarsHandler processes /api/v1/query_exemplars request.
+//
+// See https://prometheus.io/docs/prometheus/latest/querying/api/#querying-exemplars
+func QueryExemplarsHandler(qt *querytracer.Tracer, startTime time.Time, w http.ResponseWriter, r *http.Request) error {
+	defer queryExemplarsDuration.UpdateDuration(startTime)
+
+	query := r.FormValue("query")
+	if len(query) == 0 {
+		return fmt.Errorf("missing `query` arg")
+	}
+	if len(query) > maxQueryLen.IntN() {
+		return fmt.Errorf("too long query; got %d bytes; mustn't exceed `-search.maxQueryLen=%d` bytes", len(query), maxQueryLen.N)
+	}
+	cp, err := getCommonParamsWithDefaultDuration(r, startTime, false)
+	if err != nil {
+		return err
+	}
+	tagFilterss, err := getTagFilterssFromQuery(query)
+	if err != nil {
+		return err
+	}
+	etfs, err := searchutils.GetExtraTagFilters(r)
+	if err != nil {
+		return err
+	}
+	filterss := searchutils.JoinTagFilterss(tagFilterss, etfs)
+
+	sq := storage.NewSearchQuery(cp.start, cp.end, filterss, *maxSeriesLimit)
+	result, err := netstorage.SearchExemplars(qt, sq, cp.deadline)
+	if err != nil {
+		return fmt.Errorf("cannot fetch exemplars for %q: %w", sq, err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	bw := bufferedwriter.Get(w)
+	defer bufferedwriter.Put(bw)
+	qtDone := func() {
+		qt.Donef("query=%s, start=%d, end=%d", query, cp.start, cp.end)
+	}
+	WriteQueryExemplarsResponse(bw, result, qt, qtDone)
+	return bw.Flush()
+}
+
+var queryExemplarsDuration = metrics.NewSummary(`vm_request_duration_seconds{path="/api/v1/query_exemplars"}`)
+
+// getTagFilterssFromQuery returns tag filters for all the series selectors in the given MetricsQL query.
+func getTagFilterssFromQuery(query string) ([][]storage.TagFilter, error) {
+	expr, err := metricsql.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse query=%q: %w", query, err)
+	}
+	var tfss [][]storage.TagFilter
+	metricsql.VisitAll(expr, func(e metricsql.Expr) {
+		me, ok := e.(*metricsql.MetricExpr)
+		if !ok || len(me.LabelFilterss) == 0 {
+			return
+		}
+		tfss = append(tfss, searchutils.ToTagFilterss(me.LabelFilterss)...)
+	})
+	if len(tfss) == 0 {
+		return nil, fmt.Errorf("query=%q must contain at least a single series selector", query)
+	}
+	return tfss, nil
+}
+
 // QueryHandler processes /api/v1/query request.
 //
 // See https://prometheus.io/docs/prometheus/latest/querying/api/#instant-queries
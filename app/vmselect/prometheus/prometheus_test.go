@@ -229,3 +229,33 @@ func TestGetLatencyOffsetMillisecondsFailure(t *testing.T) {
 	}
 	f("http://localhost?latency_offset=foobar")
 }
+
+func TestGetTagFilterssFromQuerySuccess(t *testing.T) {
+	f := func(query string, expectedFiltersCount int) {
+		t.Helper()
+		tfss, err := getTagFilterssFromQuery(query)
+		if err != nil {
+			t.Fatalf("unexpected error for query=%q: %s", query, err)
+		}
+		if len(tfss) != expectedFiltersCount {
+			t.Fatalf("unexpected number of tag filters for query=%q; got %d; want %d", query, len(tfss), expectedFiltersCount)
+		}
+	}
+	f(`foo`, 1)
+	f(`foo{bar="baz"}`, 1)
+	f(`histogram_quantile(0.99, sum(rate(foo_bucket[5m])) by (le))`, 1)
+	f(`rate(foo[5m]) / rate(bar[5m])`, 2)
+	f(`{a="b" or c="d"}`, 2)
+}
+
+func TestGetTagFilterssFromQueryFailure(t *testing.T) {
+	f := func(query string) {
+		t.Helper()
+		if _, err := getTagFilterssFromQuery(query); err == nil {
+			t.Fatalf("expecting non-nil error for query=%q", query)
+		}
+	}
+	f(`foo{`)
+	f(`1+2`)
+	f(`time()`)
+}
@@ -0,0 +1,148 @@
+// Code generated by qtc from "query_exemplars_response.qtpl". DO NOT EDIT.
+// See https://github.com/valyala/quicktemplate for details.
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:1
+package prometheus
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:1
+import (
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/querytracer"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/storage"
+)
+
+// QueryExemplarsResponse generates response for /api/v1/query_exemplars.See https://prometheus.io/docs/prometheus/latest/querying/api/#querying-exemplars
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:9
+import (
+	qtio422016 "io"
+
+	qt422016 "github.com/valyala/quicktemplate"
+)
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:9
+var (
+	_ = qtio422016.Copy
+	_ = qt422016.AcquireByteBuffer
+)
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:9
+func StreamQueryExemplarsResponse(qw422016 *qt422016.Writer, result []storage.SeriesExemplars, qt *querytracer.Tracer, qtDone func()) {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:9
+	qw422016.N().S(`{"status":"success","data":[`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:13
+	var mn storage.MetricName
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:14
+	for i := range result {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:15
+		se := &result[i]
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:15
+		qw422016.N().S(`{"seriesLabels":`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:18
+		err := mn.UnmarshalString(se.MetricName)
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:19
+		if err != nil {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:20
+			qw422016.N().Q(err.Error())
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:21
+		} else {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:22
+			streammetricNameObject(qw422016, &mn)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:23
+		}
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:23
+		qw422016.N().S(`,"exemplars":[`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:25
+		for j := range se.Exemplars {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:26
+			e := &se.Exemplars[j]
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:26
+			qw422016.N().S(`{"labels":{`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:29
+			for k := range e.Labels {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:30
+				label := &e.Labels[k]
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:31
+				qw422016.N().QZ(label.Name)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:31
+				qw422016.N().S(`:`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:31
+				qw422016.N().QZ(label.Value)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:31
+				if k+1 < len(e.Labels) {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:31
+					qw422016.N().S(`,`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:31
+				}
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:32
+			}
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:32
+			qw422016.N().S(`},"value":"`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:34
+			qw422016.N().F(e.Value)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:34
+			qw422016.N().S(`","timestamp":`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:35
+			qw422016.N().F(float64(e.Timestamp) / 1e3)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:35
+			qw422016.N().S(`}`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:37
+			if j+1 < len(se.Exemplars) {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:37
+				qw422016.N().S(`,`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:37
+			}
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:38
+		}
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:38
+		qw422016.N().S(`]}`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:41
+		if i+1 < len(result) {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:41
+			qw422016.N().S(`,`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:41
+		}
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:42
+	}
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:42
+	qw422016.N().S(`]`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:45
+	qt.Printf("generate response: series=%d", len(result))
+	qtDone()
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:48
+	streamdumpQueryTrace(qw422016, qt)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:48
+	qw422016.N().S(`}`)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+}
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+func WriteQueryExemplarsResponse(qq422016 qtio422016.Writer, result []storage.SeriesExemplars, qt *querytracer.Tracer, qtDone func()) {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+	StreamQueryExemplarsResponse(qw422016, result, qt, qtDone)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+	qt422016.ReleaseWriter(qw422016)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+}
+
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+func QueryExemplarsResponse(result []storage.SeriesExemplars, qt *querytracer.Tracer, qtDone func()) string {
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+	qb422016 := qt422016.AcquireByteBuffer()
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+	WriteQueryExemplarsResponse(qb422016, result, qt, qtDone)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+	qs422016 := string(qb422016.B)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+	qt422016.ReleaseByteBuffer(qb422016)
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+	return qs422016
+//line app/vmselect/prometheus/query_exemplars_response.qtpl:50
+}
@@ -58,6 +58,10 @@ var (
 		"Excess series are logged and dropped. This can be useful for limiting series churn rate. See https://docs.victoriametrics.com/#cardinality-limiter . "+
 		"See also -storage.maxHourlySeries")
 
+	maxExemplars = flag.Int("storage.maxExemplars", 100000, "The maximum number of exemplars to keep in memory. "+
+		"The oldest exemplars are dropped when the limit is reached. Set to 0 for disabling exemplars storage. "+
+		"See https://docs.victoriametrics.com/#exemplars")
+
 	minFreeDiskSpaceBytes = flagutil.NewBytes("storage.minFreeDiskSpaceBytes", 10e6, "The minimum free disk space at -storageDataPath after which the storage stops accepting new data")
 
 	cacheSizeStorageTSID = flagutil.NewBytes("storage.cacheSizeStorageTSID", 0, "Overrides max size for storage/tsid cache. "+
@@ -98,6 +102,7 @@ func Init(resetCacheIfNeeded func(mrs []storage.MetricRow)) {
 	storage.SetRetentionTimezoneOffset(*retentionTimezoneOffset)
 	storage.SetFreeDiskSpaceLimit(minFreeDiskSpaceBytes.N)
 	storage.SetTSIDCacheSize(cacheSizeStorageTSID.IntN())
+	storage.SetMaxExemplars(*maxExemplars)
 	storage.SetTagFiltersCacheSize(cacheSizeIndexDBTagFilters.IntN())
 	mergeset.SetIndexBlocksCacheSize(cacheSizeIndexDBIndexBlocks.IntN())
 	mergeset.SetDataBlocksCacheSize(cacheSizeIndexDBDataBlocks.IntN())
@@ -152,6 +157,18 @@ func AddRows(mrs []storage.MetricRow) error {
 	return err
 }
 
+// AddExemplars adds ers to the storage.
+//
+// Samples for the corresponding series must be added via AddRows before calling this function.
+func AddExemplars(ers []storage.ExemplarRow) {
+	if len(ers) == 0 {
+		return
+	}
+	WG.Add(1)
+	Storage.AddExemplars(ers)
+	WG.Done()
+}
+
 var errReadOnly = errors.New("the storage is in read-only mode; check -storage.minFreeDiskSpaceBytes command-line flag value")
 
 // RegisterMetricNames registers all the metrics from mrs in the storage.
@@ -179,6 +196,14 @@ func SearchMetricNames(qt *querytracer.Tracer, tfss []*storage.TagFilters, tr st
 	return metricNames, err
 }
 
+// SearchExemplars returns exemplars for series matching the given tfss on the given tr.
+func SearchExemplars(qt *querytracer.Tracer, tfss []*storage.TagFilters, tr storage.TimeRange, maxMetrics int, deadline uint64) ([]storage.SeriesExemplars, error) {
+	WG.Add(1)
+	result, err := Storage.SearchExemplars(qt, tfss, tr, maxMetrics, deadline)
+	WG.Done()
+	return result, err
+}
+
 // SearchLabelNamesWithFiltersOnTimeRange searches for tag keys matching the given tfss on tr.
 func SearchLabelNamesWithFiltersOnTimeRange(qt *querytracer.Tracer, tfss []*storage.TagFilters, tr storage.TimeRange, maxTagKeys, maxMetrics int, deadline uint64) ([]string, error) {
 	WG.Add(1)
@@ -917,6 +942,22 @@ func registerStorageMetrics(strg *storage.Storage) {
 		return float64(m().MetricNameCacheCollisions)
 	})
 
+	metrics.NewGauge(`vm_exemplars_in_storage`, func() float64 {
+		return float64(m().ExemplarsCount)
+	})
+	metrics.NewGauge(`vm_exemplars_max_in_storage`, func() float64 {
+		return float64(m().ExemplarsMaxCount)
+	})
+	metrics.NewGauge(`vm_exemplars_added_total`, func() float64 {
+		return float64(m().ExemplarsAdded)
+	})
+	metrics.NewGauge(`vm_exemplars_ignored_total{reason="out_of_order"}`, func() float64 {
+		return float64(m().ExemplarsOutOfOrder)
+	})
+	metrics.NewGauge(`vm_exemplars_ignored_total{reason="unknown_series"}`, func() float64 {
+		return float64(m().ExemplarsUnknownSeries)
+	})
+
 	metrics.NewGauge(`vm_next_retention_seconds`, func() float64 {
 		return float64(m().NextRetentionSeconds)
 	})
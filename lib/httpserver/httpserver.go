@@ -80,6 +80,14 @@ type RequestHandler func(w http.ResponseWriter, r *http.Request) bool
 // If useProxyProtocol is set to true, then the incoming connections are accepted via proxy protocol.
 // See https://www.haproxy.org/download/1.8/doc/proxy-protocol.txt
 func Serve(addr string, useProxyProtocol bool, rh RequestHandler) {
+	ServeWithTLSConfigUpdater(addr, useProxyProtocol, rh, nil)
+}
+
+// ServeWithTLSConfigUpdater works in the same way as Serve, but additionally calls updateTLSConfig
+// for the TLS config of the server if -tls is set.
+//
+// This allows enabling app-specific TLS options such as client certificate verification.
+func ServeWithTLSConfigUpdater(addr string, useProxyProtocol bool, rh RequestHandler, updateTLSConfig func(tc *tls.Config) error) {
 	if rh == nil {
 		rh = func(w http.ResponseWriter, r *http.Request) bool {
 			return false
@@ -101,6 +109,11 @@ func Serve(addr string, useProxyProtocol bool, rh RequestHandler) {
 		if err != nil {
 			logger.Fatalf("cannot load TLS cert from -tlsCertFile=%q, -tlsKeyFile=%q, -tlsMinVersion=%q: %s", *tlsCertFile, *tlsKeyFile, *tlsMinVersion, err)
 		}
+		if updateTLSConfig != nil {
+			if err := updateTLSConfig(tc); err != nil {
+				logger.Fatalf("cannot update TLS config: %s", err)
+			}
+		}
 		tlsConfig = tc
 	}
 	ln, err := netutil.NewTCPListener(scheme, addr, useProxyProtocol, tlsConfig)
@@ -0,0 +1,115 @@
+package hyperloglog
+
+import (
+	"math"
+	"math/bits"
+)
+
+// precision is the number of bits from hash used for selecting the register.
+//
+// It results in 1KiB per dense Sketch with the standard error of 1.04/sqrt(2^precision) = 3.25%.
+const precision = 10
+
+const registersCount = 1 << precision
+
+// maxSparseItems is the maximum number of unique hashes, which are stored in the sparse Sketch.
+//
+// Sparse sketch returns exact estimations and occupies less memory than dense Sketch,
+// since most of the tracked sets have low cardinality.
+const maxSparseItems = 64
+
+// Sketch estimates the number of unique hashes added to it with the bounded memory usage.
+//
+// Sketch isn't safe for concurrent use.
+type Sketch struct {
+	// sparse contains unique hashes until their number exceeds maxSparseItems.
+	sparse []uint64
+
+	// registers contains HyperLogLog registers after the switch to dense mode.
+	registers []uint8
+}
+
+// Add adds h to s.
+//
+// h must be a well-distributed hash such as xxhash.
+func (s *Sketch) Add(h uint64) {
+	if s.registers != nil {
+		s.addDense(h)
+		return
+	}
+	for _, x := range s.sparse {
+		if x == h {
+			return
+		}
+	}
+	if len(s.sparse) < maxSparseItems {
+		s.sparse = append(s.sparse, h)
+		return
+	}
+	s.toDense()
+	s.addDense(h)
+}
+
+func (s *Sketch) addDense(h uint64) {
+	idx := h >> (64 - precision)
+	// Set the lowest bit in order to limit the rank to 64-precision+1
+	w := (h << precision) | (1 << (precision - 1))
+	rank := uint8(bits.LeadingZeros64(w) + 1)
+	if rank > s.registers[idx] {
+		s.registers[idx] = rank
+	}
+}
+
+func (s *Sketch) toDense() {
+	s.registers = make([]uint8, registersCount)
+	for _, h := range s.sparse {
+		s.addDense(h)
+	}
+	s.sparse = nil
+}
+
+// Merge merges src into s, so s estimates the number of unique hashes in the union of s and src.
+func (s *Sketch) Merge(src *Sketch) {
+	if src.registers == nil {
+		for _, h := range src.sparse {
+			s.Add(h)
+		}
+		return
+	}
+	if s.registers == nil {
+		s.toDense()
+	}
+	for i, v := range src.registers {
+		if v > s.registers[i] {
+			s.registers[i] = v
+		}
+	}
+}
+
+// Estimate returns an estimation for the number of unique hashes added to s.
+func (s *Sketch) Estimate() uint64 {
+	if s.registers == nil {
+		return uint64(len(s.sparse))
+	}
+	m := float64(registersCount)
+	sum := 0.0
+	zeros := 0
+	for _, v := range s.registers {
+		sum += 1 / float64(uint64(1)<<v)
+		if v == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Use linear counting for small cardinalities.
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// SizeBytes returns the approximate size of s in bytes.
+func (s *Sketch) SizeBytes() int {
+	return cap(s.sparse)*8 + cap(s.registers)
+}
@@ -0,0 +1,82 @@
+package hyperloglog
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+func TestSketchEstimate(t *testing.T) {
+	f := func(n int, maxRelativeError float64) {
+		t.Helper()
+		var s Sketch
+		for i := 0; i < n; i++ {
+			h := xxhash.Sum64String(fmt.Sprintf("item_%d", i))
+			// Add every item twice in order to verify duplicates aren't counted.
+			s.Add(h)
+			s.Add(h)
+		}
+		estimate := s.Estimate()
+		relativeError := math.Abs(float64(estimate)-float64(n)) / float64(n)
+		if relativeError > maxRelativeError {
+			t.Fatalf("too big relative error for n=%d: %.3f; estimate=%d", n, relativeError, estimate)
+		}
+	}
+
+	// Sparse sketch must return exact results
+	f(1, 0)
+	f(10, 0)
+	f(maxSparseItems, 0)
+
+	// Dense sketch. The standard error for the used precision is 3.25%, so allow 3x of it.
+	f(maxSparseItems+1, 0.1)
+	f(1000, 0.1)
+	f(10000, 0.1)
+	f(100000, 0.1)
+	f(1000000, 0.1)
+}
+
+func TestSketchEmpty(t *testing.T) {
+	var s Sketch
+	if n := s.Estimate(); n != 0 {
+		t.Fatalf("unexpected estimate for empty sketch: %d", n)
+	}
+	s.toDense()
+	if n := s.Estimate(); n != 0 {
+		t.Fatalf("unexpected estimate for empty dense sketch: %d", n)
+	}
+}
+
+func TestSketchMerge(t *testing.T) {
+	f := func(n1, n2, overlap int, maxRelativeError float64) {
+		t.Helper()
+		var s1, s2 Sketch
+		for i := 0; i < n1; i++ {
+			s1.Add(xxhash.Sum64String(fmt.Sprintf("item_%d", i)))
+		}
+		for i := n1 - overlap; i < n1-overlap+n2; i++ {
+			s2.Add(xxhash.Sum64String(fmt.Sprintf("item_%d", i)))
+		}
+		s1.Merge(&s2)
+		n := n1 + n2 - overlap
+		estimate := s1.Estimate()
+		relativeError := math.Abs(float64(estimate)-float64(n)) / float64(n)
+		if relativeError > maxRelativeError {
+			t.Fatalf("too big relative error for n1=%d, n2=%d, overlap=%d: %.3f; estimate=%d; want %d", n1, n2, overlap, relativeError, estimate, n)
+		}
+	}
+
+	// sparse + sparse
+	f(10, 10, 5, 0)
+
+	// sparse + dense
+	f(10, 10000, 5, 0.1)
+
+	// dense + sparse
+	f(10000, 10, 5, 0.1)
+
+	// dense + dense
+	f(10000, 10000, 5000, 0.1)
+}
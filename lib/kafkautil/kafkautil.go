@@ -0,0 +1,158 @@
+package kafkautil
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// ParseBrokers parses Kafka brokers list delimited by `;`, e.g. `host1:9092;host2:9092`.
+func ParseBrokers(s string) ([]string, error) {
+	var brokers []string
+	for _, broker := range strings.Split(s, ";") {
+		broker = strings.TrimSpace(broker)
+		if broker == "" {
+			continue
+		}
+		brokers = append(brokers, broker)
+	}
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("missing Kafka brokers")
+	}
+	return brokers, nil
+}
+
+// ParseOptions parses Kafka client options in the form `key1=value1;key2=value2`.
+func ParseOptions(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, kv := range strings.Split(s, ";") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		n := strings.IndexByte(kv, '=')
+		if n < 0 {
+			return nil, fmt.Errorf("missing `=` in option %q; it must have the form `key=value`", kv)
+		}
+		m[kv[:n]] = kv[n+1:]
+	}
+	return m, nil
+}
+
+// Config contains common settings for Kafka consumers and producers.
+type Config struct {
+	// Brokers is the list of Kafka brokers to connect to.
+	Brokers []string
+
+	// Options contains additional client options. The following options are supported:
+	//
+	//   - client.id - the client id to send to Kafka brokers.
+	//   - security.protocol - the protocol for communicating with brokers: plaintext, ssl, sasl_plaintext or sasl_ssl.
+	//   - sasl.mechanisms - SASL mechanism to use: PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512.
+	//
+	// Options specific to consumers or producers must be removed from Options before calling NewClientOpts.
+	Options map[string]string
+
+	// Username and Password are used for SASL authentication.
+	Username string
+	Password string
+
+	// TLSConfig is used for connecting to brokers if security.protocol is ssl or sasl_ssl.
+	//
+	// Default TLS config is used if TLSConfig is nil.
+	TLSConfig *tls.Config
+}
+
+// NewClientOpts returns options for Kafka client according to cfg.
+func (cfg *Config) NewClientOpts() ([]kgo.Opt, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("missing Kafka brokers")
+	}
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.ClientID("vmagent"),
+	}
+	securityProtocol := "plaintext"
+	saslMechanism := "PLAIN"
+	keys := make([]string, 0, len(cfg.Options))
+	for k := range cfg.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := cfg.Options[k]
+		switch k {
+		case "client.id":
+			opts = append(opts, kgo.ClientID(v))
+		case "security.protocol":
+			securityProtocol = strings.ToLower(v)
+		case "sasl.mechanisms", "sasl.mechanism":
+			saslMechanism = strings.ToUpper(v)
+		default:
+			return nil, fmt.Errorf("unsupported Kafka option %q; supported options: client.id, security.protocol, sasl.mechanisms", k)
+		}
+	}
+
+	useTLS := false
+	useSASL := false
+	switch securityProtocol {
+	case "plaintext":
+	case "ssl":
+		useTLS = true
+	case "sasl_plaintext":
+		useSASL = true
+	case "sasl_ssl":
+		useTLS = true
+		useSASL = true
+	default:
+		return nil, fmt.Errorf("unsupported security.protocol=%q; supported values: plaintext, ssl, sasl_plaintext, sasl_ssl", securityProtocol)
+	}
+	if useTLS {
+		tlsCfg := cfg.TLSConfig
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{}
+		}
+		opts = append(opts, kgo.DialTLSConfig(tlsCfg))
+	}
+	if useSASL {
+		m, err := newSASLMechanism(saslMechanism, cfg.Username, cfg.Password)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kgo.SASL(m))
+	} else if cfg.Username != "" || cfg.Password != "" {
+		return nil, fmt.Errorf("username and password can be used only with security.protocol=sasl_plaintext or security.protocol=sasl_ssl")
+	}
+	return opts, nil
+}
+
+func newSASLMechanism(name, username, password string) (sasl.Mechanism, error) {
+	switch name {
+	case "PLAIN":
+		a := plain.Auth{
+			User: username,
+			Pass: password,
+		}
+		return a.AsMechanism(), nil
+	case "SCRAM-SHA-256":
+		a := scram.Auth{
+			User: username,
+			Pass: password,
+		}
+		return a.AsSha256Mechanism(), nil
+	case "SCRAM-SHA-512":
+		a := scram.Auth{
+			User: username,
+			Pass: password,
+		}
+		return a.AsSha512Mechanism(), nil
+	default:
+		return nil, fmt.Errorf("unsupported sasl.mechanisms=%q; supported values: PLAIN, SCRAM-SHA-256, SCRAM-SHA-512", name)
+	}
+}
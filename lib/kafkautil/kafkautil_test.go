@@ -0,0 +1,132 @@
+package kafkautil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBrokersSuccess(t *testing.T) {
+	f := func(s string, brokersExpected []string) {
+		t.Helper()
+		brokers, err := ParseBrokers(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(brokers, brokersExpected) {
+			t.Fatalf("unexpected brokers; got %q; want %q", brokers, brokersExpected)
+		}
+	}
+	f("localhost:9092", []string{"localhost:9092"})
+	f("host1:9092;host2:9092", []string{"host1:9092", "host2:9092"})
+	f(" host1:9092 ; ;host2:9092;", []string{"host1:9092", "host2:9092"})
+}
+
+func TestParseBrokersFailure(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		if _, err := ParseBrokers(s); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	f("")
+	f(" ; ")
+}
+
+func TestParseOptionsSuccess(t *testing.T) {
+	f := func(s string, optionsExpected map[string]string) {
+		t.Helper()
+		options, err := ParseOptions(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(options, optionsExpected) {
+			t.Fatalf("unexpected options; got %v; want %v", options, optionsExpected)
+		}
+	}
+	f("", map[string]string{})
+	f("client.id=foo", map[string]string{
+		"client.id": "foo",
+	})
+	f("security.protocol=SASL_SSL;sasl.mechanisms=PLAIN;", map[string]string{
+		"security.protocol": "SASL_SSL",
+		"sasl.mechanisms":   "PLAIN",
+	})
+	f("client.id=a=b", map[string]string{
+		"client.id": "a=b",
+	})
+}
+
+func TestParseOptionsFailure(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		if _, err := ParseOptions(s); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	f("foo")
+	f("client.id=foo;bar")
+}
+
+func TestConfigNewClientOpts(t *testing.T) {
+	f := func(cfg *Config, resultExpected bool) {
+		t.Helper()
+		_, err := cfg.NewClientOpts()
+		if resultExpected && err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !resultExpected && err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+
+	// Missing brokers
+	f(&Config{}, false)
+
+	// Plaintext
+	f(&Config{
+		Brokers: []string{"localhost:9092"},
+	}, true)
+
+	// Supported options
+	f(&Config{
+		Brokers: []string{"localhost:9092"},
+		Options: map[string]string{
+			"client.id":         "foo",
+			"security.protocol": "SASL_SSL",
+			"sasl.mechanisms":   "SCRAM-SHA-512",
+		},
+		Username: "user",
+		Password: "pass",
+	}, true)
+
+	// Unsupported option
+	f(&Config{
+		Brokers: []string{"localhost:9092"},
+		Options: map[string]string{
+			"foo": "bar",
+		},
+	}, false)
+
+	// Unsupported security protocol
+	f(&Config{
+		Brokers: []string{"localhost:9092"},
+		Options: map[string]string{
+			"security.protocol": "foo",
+		},
+	}, false)
+
+	// Unsupported sasl mechanism
+	f(&Config{
+		Brokers: []string{"localhost:9092"},
+		Options: map[string]string{
+			"security.protocol": "sasl_plaintext",
+			"sasl.mechanisms":   "GSSAPI",
+		},
+	}, false)
+
+	// Credentials without SASL
+	f(&Config{
+		Brokers:  []string{"localhost:9092"},
+		Username: "user",
+	}, false)
+}
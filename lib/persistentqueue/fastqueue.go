@@ -124,6 +124,23 @@ func (fq *FastQueue) GetPendingBytes() uint64 {
 	return n
 }
 
+// IsFull returns true if fq has no space for the next block of up to MaxBlockSize bytes.
+//
+// Writing a block to the full fq may result in dropping the oldest data from fq
+// if fq size is limited by maxPendingBytes passed to MustOpenFastQueue.
+func (fq *FastQueue) IsFull() bool {
+	fq.mu.Lock()
+	defer fq.mu.Unlock()
+
+	maxPendingBytes := fq.pq.maxPendingBytes
+	if maxPendingBytes == 0 {
+		return false
+	}
+	n := fq.pendingInmemoryBytes
+	n += fq.pq.GetPendingBytes()
+	return n+fq.pq.maxBlockSize+8 > maxPendingBytes
+}
+
 // GetInmemoryQueueLen returns the length of inmemory queue.
 func (fq *FastQueue) GetInmemoryQueueLen() int {
 	fq.mu.Lock()
@@ -278,3 +278,41 @@ func TestFastQueueReadWriteConcurrent(t *testing.T) {
 	fq.MustClose()
 	mustDeleteDir(path)
 }
+
+func TestFastQueueIsFull(t *testing.T) {
+	path := "fast-queue-is-full"
+	mustDeleteDir(path)
+
+	// The queue without size limit is never full
+	fq := MustOpenFastQueue(path, "foobar", 1, 0)
+	for i := 0; i < 100; i++ {
+		fq.MustWriteBlock([]byte("foobar"))
+		if fq.IsFull() {
+			t.Fatalf("unexpected full queue without size limit after writing %d blocks", i+1)
+		}
+	}
+	fq.MustClose()
+	mustDeleteDir(path)
+
+	maxPendingBytes := int64(MaxBlockSize + 8 + 100)
+	fq = MustOpenFastQueue(path, "foobar", 1, maxPendingBytes)
+	blocksWritten := 0
+	for !fq.IsFull() {
+		if blocksWritten > 100 {
+			t.Fatalf("the queue must be full after writing %d blocks; pending bytes: %d", blocksWritten, fq.GetPendingBytes())
+		}
+		fq.MustWriteBlock([]byte("foobar"))
+		blocksWritten++
+	}
+	if blocksWritten < 2 {
+		t.Fatalf("unexpected full queue after writing %d blocks", blocksWritten)
+	}
+	if _, ok := fq.MustReadBlock(nil); !ok {
+		t.Fatalf("unexpected ok=false")
+	}
+	if fq.IsFull() {
+		t.Fatalf("unexpected full queue after reading a block; pending bytes: %d", fq.GetPendingBytes())
+	}
+	fq.MustClose()
+	mustDeleteDir(path)
+}
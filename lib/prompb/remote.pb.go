@@ -11,8 +11,10 @@ import (
 type WriteRequest struct {
 	Timeseries []TimeSeries
 
-	labelsPool  []Label
-	samplesPool []Sample
+	labelsPool         []Label
+	samplesPool        []Sample
+	exemplarsPool      []Exemplar
+	exemplarLabelsPool []Label
 }
 
 // Unmarshal unmarshals m from dAtA.
@@ -78,7 +80,7 @@ func (m *WriteRequest) Unmarshal(dAtA []byte) error {
 			}
 			ts := &m.Timeseries[len(m.Timeseries)-1]
 			var err error
-			m.labelsPool, m.samplesPool, err = ts.Unmarshal(dAtA[iNdEx:postIndex], m.labelsPool, m.samplesPool)
+			m.labelsPool, m.samplesPool, m.exemplarsPool, m.exemplarLabelsPool, err = ts.Unmarshal(dAtA[iNdEx:postIndex], m.labelsPool, m.samplesPool, m.exemplarsPool, m.exemplarLabelsPool)
 			if err != nil {
 				return err
 			}
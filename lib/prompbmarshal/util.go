@@ -30,6 +30,7 @@ func ResetTimeSeries(tss []TimeSeries) []TimeSeries {
 		ts := tss[i]
 		ts.Labels = nil
 		ts.Samples = nil
+		ts.Exemplars = nil
 	}
 	return tss[:0]
 }
@@ -1,11 +1,13 @@
 package promscrape
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"strings"
@@ -15,6 +17,7 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/flagutil"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	parser "github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/prometheus"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/proxy"
 	"github.com/VictoriaMetrics/fasthttp"
 	"github.com/VictoriaMetrics/metrics"
@@ -56,6 +59,8 @@ type client struct {
 	denyRedirects           bool
 	disableCompression      bool
 	disableKeepAlive        bool
+	acceptHeader            string
+	nativeHistogramFormat   string
 }
 
 func addMissingPort(addr string, isTLS bool) string {
@@ -81,6 +86,53 @@ func concatTwoStrings(x, y string) string {
 
 const scrapeUserAgent = "vm_promscrape"
 
+// The following `Accept` header has been copied from Prometheus sources.
+// See https://github.com/prometheus/prometheus/blob/f9d21f10ecd2a343a381044f131ea4e46381ce09/scrape/scrape.go#L532 .
+// This is needed as a workaround for scraping stupid Java-based servers such as Spring Boot.
+// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/608 for details.
+// Do not bloat the `Accept` header with OpenMetrics shit, since it looks like dead standard now.
+const defaultAcceptHeader = "text/plain;version=0.0.4;q=1,*/*;q=0.1"
+
+// scrapeProtocolHeaders contains media types for the supported `scrape_protocols`.
+//
+// Protocol names are compatible with Prometheus.
+// See https://prometheus.io/docs/prometheus/latest/configuration/configuration/#scrape_config
+var scrapeProtocolHeaders = map[string]string{
+	"PrometheusProto":     parser.ProtobufContentType,
+	"PrometheusText0.0.4": "text/plain;version=0.0.4",
+}
+
+// checkScrapeProtocols verifies that protocols contain only supported `scrape_protocols` without duplicates.
+func checkScrapeProtocols(protocols []string) error {
+	m := make(map[string]bool, len(protocols))
+	for _, p := range protocols {
+		if _, ok := scrapeProtocolHeaders[p]; !ok {
+			return fmt.Errorf("unsupported scrape protocol %q; supported protocols: PrometheusProto, PrometheusText0.0.4", p)
+		}
+		if m[p] {
+			return fmt.Errorf("duplicate scrape protocol %q", p)
+		}
+		m[p] = true
+	}
+	return nil
+}
+
+// getAcceptHeader returns `Accept` header for the given protocols in the order of preference.
+func getAcceptHeader(protocols []string) string {
+	if len(protocols) == 0 {
+		return defaultAcceptHeader
+	}
+	// Assign decreasing weights to protocols in the same way as Prometheus does.
+	weight := len(protocols) + 1
+	a := make([]string, 0, len(protocols)+1)
+	for _, p := range protocols {
+		a = append(a, fmt.Sprintf("%s;q=0.%d", scrapeProtocolHeaders[p], weight))
+		weight--
+	}
+	a = append(a, fmt.Sprintf("*/*;q=0.%d", weight))
+	return strings.Join(a, ",")
+}
+
 func newClient(ctx context.Context, sw *ScrapeWork) *client {
 	var u fasthttp.URI
 	u.Update(sw.ScrapeURL)
@@ -175,6 +227,8 @@ func newClient(ctx context.Context, sw *ScrapeWork) *client {
 		denyRedirects:           sw.DenyRedirects,
 		disableCompression:      sw.DisableCompression,
 		disableKeepAlive:        sw.DisableKeepAlive,
+		acceptHeader:            getAcceptHeader(sw.ScrapeProtocols),
+		nativeHistogramFormat:   sw.NativeHistogramFormat,
 	}
 }
 
@@ -186,12 +240,7 @@ func (c *client) GetStreamReader() (*streamReader, error) {
 		cancel()
 		return nil, fmt.Errorf("cannot create request for %q: %w", c.scrapeURL, err)
 	}
-	// The following `Accept` header has been copied from Prometheus sources.
-	// See https://github.com/prometheus/prometheus/blob/f9d21f10ecd2a343a381044f131ea4e46381ce09/scrape/scrape.go#L532 .
-	// This is needed as a workaround for scraping stupid Java-based servers such as Spring Boot.
-	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/608 for details.
-	// Do not bloat the `Accept` header with OpenMetrics shit, since it looks like dead standard now.
-	req.Header.Set("Accept", "text/plain;version=0.0.4;q=1,*/*;q=0.1")
+	req.Header.Set("Accept", c.acceptHeader)
 	// Set X-Prometheus-Scrape-Timeout-Seconds like Prometheus does, since it is used by some exporters such as PushProx.
 	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/1179#issuecomment-813117162
 	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", c.scrapeTimeoutSecondsStr)
@@ -213,12 +262,45 @@ func (c *client) GetStreamReader() (*streamReader, error) {
 			c.scrapeURL, resp.StatusCode, http.StatusOK, respBody)
 	}
 	scrapesOK.Inc()
-	return &streamReader{
+	sr := &streamReader{
 		r:           resp.Body,
 		cancel:      cancel,
 		scrapeURL:   c.scrapeURL,
 		maxBodySize: int64(c.hc.MaxResponseBodySize),
-	}, nil
+	}
+	if parser.IsProtobufContentType(resp.Header.Get("Content-Type")) {
+		// Protobuf messages cannot be parsed in streaming manner, so read the whole response
+		// and convert it to Prometheus text exposition format.
+		data, err := io.ReadAll(sr)
+		if err != nil {
+			sr.MustClose()
+			return nil, fmt.Errorf("cannot read protobuf response from %q: %w", c.scrapeURL, err)
+		}
+		sr.MustClose()
+		text, err := c.convertProtobufResponse(nil, data)
+		if err != nil {
+			return nil, err
+		}
+		// The converted response is usually bigger than the original response, so do not limit its size.
+		return &streamReader{
+			r:           io.NopCloser(bytes.NewReader(text)),
+			cancel:      func() {},
+			scrapeURL:   c.scrapeURL,
+			maxBodySize: math.MaxInt64,
+		}, nil
+	}
+	return sr, nil
+}
+
+// convertProtobufResponse appends the response body in Prometheus protobuf format at src to dst in Prometheus text exposition format.
+func (c *client) convertProtobufResponse(dst, src []byte) ([]byte, error) {
+	dst, err := parser.AppendProtobufAsText(dst, src, c.nativeHistogramFormat)
+	if err != nil {
+		scrapesProtobufFailed.Inc()
+		return dst, fmt.Errorf("cannot parse protobuf response from %q: %w", c.scrapeURL, err)
+	}
+	scrapesProtobuf.Inc()
+	return dst, nil
 }
 
 // checks fasthttp status code for redirect as standard http/client does.
@@ -231,16 +313,12 @@ func isStatusRedirect(statusCode int) bool {
 }
 
 func (c *client) ReadData(dst []byte) ([]byte, error) {
+	dstLen := len(dst)
 	deadline := time.Now().Add(c.hc.ReadTimeout)
 	req := fasthttp.AcquireRequest()
 	req.SetRequestURI(c.requestURI)
 	req.Header.SetHost(c.hostPort)
-	// The following `Accept` header has been copied from Prometheus sources.
-	// See https://github.com/prometheus/prometheus/blob/f9d21f10ecd2a343a381044f131ea4e46381ce09/scrape/scrape.go#L532 .
-	// This is needed as a workaround for scraping stupid Java-based servers such as Spring Boot.
-	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/608 for details.
-	// Do not bloat the `Accept` header with OpenMetrics shit, since it looks like dead standard now.
-	req.Header.Set("Accept", "text/plain;version=0.0.4;q=1,*/*;q=0.1")
+	req.Header.Set("Accept", c.acceptHeader)
 	// Set X-Prometheus-Scrape-Timeout-Seconds like Prometheus does, since it is used by some exporters such as PushProx.
 	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/1179#issuecomment-813117162
 	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", c.scrapeTimeoutSecondsStr)
@@ -323,6 +401,7 @@ func (c *client) ReadData(dst []byte) ([]byte, error) {
 	} else if !swapResponseBodies {
 		dst = append(dst, resp.Body()...)
 	}
+	isProtobuf := parser.IsProtobufContentType(string(resp.Header.Peek("Content-Type")))
 	fasthttp.ReleaseResponse(resp)
 	if len(dst) > c.hc.MaxResponseBodySize {
 		maxScrapeSizeExceeded.Inc()
@@ -335,10 +414,23 @@ func (c *client) ReadData(dst []byte) ([]byte, error) {
 			c.scrapeURL, statusCode, fasthttp.StatusOK, dst)
 	}
 	scrapesOK.Inc()
+	if isProtobuf {
+		bb := protobufBufPool.Get()
+		var err error
+		bb.B, err = c.convertProtobufResponse(bb.B[:0], dst[dstLen:])
+		dst = append(dst[:dstLen], bb.B...)
+		protobufBufPool.Put(bb)
+		if err != nil {
+			return dst, err
+		}
+	}
 	return dst, nil
 }
 
-var gunzipBufPool bytesutil.ByteBufferPool
+var (
+	gunzipBufPool   bytesutil.ByteBufferPool
+	protobufBufPool bytesutil.ByteBufferPool
+)
 
 var (
 	maxScrapeSizeExceeded = metrics.NewCounter(`vm_promscrape_max_scrape_size_exceeded_errors_total`)
@@ -346,6 +438,8 @@ var (
 	scrapesOK             = metrics.NewCounter(`vm_promscrape_scrapes_total{status_code="200"}`)
 	scrapesGunzipped      = metrics.NewCounter(`vm_promscrape_scrapes_gunziped_total`)
 	scrapesGunzipFailed   = metrics.NewCounter(`vm_promscrape_scrapes_gunzip_failed_total`)
+	scrapesProtobuf       = metrics.NewCounter(`vm_promscrape_scrapes_protobuf_total`)
+	scrapesProtobufFailed = metrics.NewCounter(`vm_promscrape_scrapes_protobuf_failed_total`)
 	scrapeRequests        = metrics.NewCounter(`vm_promscrape_scrape_requests_total`)
 	scrapeRetries         = metrics.NewCounter(`vm_promscrape_scrape_retries_total`)
 )
@@ -0,0 +1,23 @@
+package promscrape
+
+import (
+	"testing"
+)
+
+func TestGetAcceptHeader(t *testing.T) {
+	f := func(protocols []string, resultExpected string) {
+		t.Helper()
+		if err := checkScrapeProtocols(protocols); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result := getAcceptHeader(protocols)
+		if result != resultExpected {
+			t.Fatalf("unexpected Accept header for %q;\ngot\n%s\nwant\n%s", protocols, result, resultExpected)
+		}
+	}
+
+	f(nil, defaultAcceptHeader)
+	f([]string{"PrometheusText0.0.4"}, "text/plain;version=0.0.4;q=0.2,*/*;q=0.1")
+	f([]string{"PrometheusProto", "PrometheusText0.0.4"}, "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.3,"+
+		"text/plain;version=0.0.4;q=0.2,*/*;q=0.1")
+}
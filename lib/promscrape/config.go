@@ -29,13 +29,20 @@ import (
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/ec2"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/eureka"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/gce"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/hetzner"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/http"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/ionos"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/kubernetes"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/kuma"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/linode"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/nomad"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/openstack"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/puppetdb"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/scaleway"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/vultr"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discovery/yandexcloud"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+	parser "github.com/VictoriaMetrics/VictoriaMetrics/lib/protoparser/prometheus"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/proxy"
 	"github.com/VictoriaMetrics/metrics"
 	"github.com/cespare/xxhash/v2"
@@ -267,6 +274,7 @@ type ScrapeConfig struct {
 	RelabelConfigs       []promrelabel.RelabelConfig `yaml:"relabel_configs,omitempty"`
 	MetricRelabelConfigs []promrelabel.RelabelConfig `yaml:"metric_relabel_configs,omitempty"`
 	SampleLimit          int                         `yaml:"sample_limit,omitempty"`
+	ScrapeProtocols      []string                    `yaml:"scrape_protocols,omitempty"`
 
 	AzureSDConfigs        []azure.SDConfig        `yaml:"azure_sd_configs,omitempty"`
 	ConsulSDConfigs       []consul.SDConfig       `yaml:"consul_sd_configs,omitempty"`
@@ -279,12 +287,18 @@ type ScrapeConfig struct {
 	EurekaSDConfigs       []eureka.SDConfig       `yaml:"eureka_sd_configs,omitempty"`
 	FileSDConfigs         []FileSDConfig          `yaml:"file_sd_configs,omitempty"`
 	GCESDConfigs          []gce.SDConfig          `yaml:"gce_sd_configs,omitempty"`
+	HetznerSDConfigs      []hetzner.SDConfig      `yaml:"hetzner_sd_configs,omitempty"`
 	HTTPSDConfigs         []http.SDConfig         `yaml:"http_sd_configs,omitempty"`
+	IONOSSDConfigs        []ionos.SDConfig        `yaml:"ionos_sd_configs,omitempty"`
 	KubernetesSDConfigs   []kubernetes.SDConfig   `yaml:"kubernetes_sd_configs,omitempty"`
 	KumaSDConfigs         []kuma.SDConfig         `yaml:"kuma_sd_configs,omitempty"`
+	LinodeSDConfigs       []linode.SDConfig       `yaml:"linode_sd_configs,omitempty"`
 	NomadSDConfigs        []nomad.SDConfig        `yaml:"nomad_sd_configs,omitempty"`
 	OpenStackSDConfigs    []openstack.SDConfig    `yaml:"openstack_sd_configs,omitempty"`
+	PuppetDBSDConfigs     []puppetdb.SDConfig     `yaml:"puppetdb_sd_configs,omitempty"`
+	ScalewaySDConfigs     []scaleway.SDConfig     `yaml:"scaleway_sd_configs,omitempty"`
 	StaticConfigs         []StaticConfig          `yaml:"static_configs,omitempty"`
+	VultrSDConfigs        []vultr.SDConfig        `yaml:"vultr_sd_configs,omitempty"`
 	YandexCloudSDConfigs  []yandexcloud.SDConfig  `yaml:"yandexcloud_sd_configs,omitempty"`
 
 	// These options are supported only by lib/promscrape.
@@ -294,9 +308,13 @@ type ScrapeConfig struct {
 	ScrapeAlignInterval *promutils.Duration        `yaml:"scrape_align_interval,omitempty"`
 	ScrapeOffset        *promutils.Duration        `yaml:"scrape_offset,omitempty"`
 	SeriesLimit         int                        `yaml:"series_limit,omitempty"`
+	SeriesBudget        int                        `yaml:"series_budget,omitempty"`
 	NoStaleMarkers      *bool                      `yaml:"no_stale_markers,omitempty"`
 	ProxyClientConfig   promauth.ProxyClientConfig `yaml:",inline"`
 
+	// NativeHistogramFormat is the format for native histograms scraped via protobuf protocol.
+	NativeHistogramFormat string `yaml:"native_histogram_format,omitempty"`
+
 	// This is set in loadConfig
 	swc *scrapeWorkConfig
 }
@@ -347,21 +365,39 @@ func (sc *ScrapeConfig) mustStop() {
 	for i := range sc.GCESDConfigs {
 		sc.GCESDConfigs[i].MustStop()
 	}
+	for i := range sc.HetznerSDConfigs {
+		sc.HetznerSDConfigs[i].MustStop()
+	}
 	for i := range sc.HTTPSDConfigs {
 		sc.HTTPSDConfigs[i].MustStop()
 	}
+	for i := range sc.IONOSSDConfigs {
+		sc.IONOSSDConfigs[i].MustStop()
+	}
 	for i := range sc.KubernetesSDConfigs {
 		sc.KubernetesSDConfigs[i].MustStop()
 	}
 	for i := range sc.KumaSDConfigs {
 		sc.KumaSDConfigs[i].MustStop()
 	}
+	for i := range sc.LinodeSDConfigs {
+		sc.LinodeSDConfigs[i].MustStop()
+	}
 	for i := range sc.NomadSDConfigs {
 		sc.NomadSDConfigs[i].MustStop()
 	}
 	for i := range sc.OpenStackSDConfigs {
 		sc.OpenStackSDConfigs[i].MustStop()
 	}
+	for i := range sc.PuppetDBSDConfigs {
+		sc.PuppetDBSDConfigs[i].MustStop()
+	}
+	for i := range sc.ScalewaySDConfigs {
+		sc.ScalewaySDConfigs[i].MustStop()
+	}
+	for i := range sc.VultrSDConfigs {
+		sc.VultrSDConfigs[i].MustStop()
+	}
 }
 
 // FileSDConfig represents file-based service discovery config.
@@ -794,6 +830,33 @@ func (cfg *Config) getGCESDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
 	return dst
 }
 
+// getHetznerSDScrapeWork returns `hetzner_sd_configs` ScrapeWork from cfg.
+func (cfg *Config) getHetznerSDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
+	swsPrevByJob := getSWSByJob(prev)
+	dst := make([]*ScrapeWork, 0, len(prev))
+	for _, sc := range cfg.ScrapeConfigs {
+		dstLen := len(dst)
+		ok := true
+		for j := range sc.HetznerSDConfigs {
+			sdc := &sc.HetznerSDConfigs[j]
+			var okLocal bool
+			dst, okLocal = appendSDScrapeWork(dst, sdc, cfg.baseDir, sc.swc, "hetzner_sd_config")
+			if ok {
+				ok = okLocal
+			}
+		}
+		if ok {
+			continue
+		}
+		swsPrev := swsPrevByJob[sc.swc.jobName]
+		if len(swsPrev) > 0 {
+			logger.Errorf("there were errors when discovering hetzner targets for job %q, so preserving the previous targets", sc.swc.jobName)
+			dst = append(dst[:dstLen], swsPrev...)
+		}
+	}
+	return dst
+}
+
 // getHTTPDScrapeWork returns `http_sd_configs` ScrapeWork from cfg.
 func (cfg *Config) getHTTPDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
 	swsPrevByJob := getSWSByJob(prev)
@@ -821,6 +884,33 @@ func (cfg *Config) getHTTPDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
 	return dst
 }
 
+// getIONOSSDScrapeWork returns `ionos_sd_configs` ScrapeWork from cfg.
+func (cfg *Config) getIONOSSDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
+	swsPrevByJob := getSWSByJob(prev)
+	dst := make([]*ScrapeWork, 0, len(prev))
+	for _, sc := range cfg.ScrapeConfigs {
+		dstLen := len(dst)
+		ok := true
+		for j := range sc.IONOSSDConfigs {
+			sdc := &sc.IONOSSDConfigs[j]
+			var okLocal bool
+			dst, okLocal = appendSDScrapeWork(dst, sdc, cfg.baseDir, sc.swc, "ionos_sd_config")
+			if ok {
+				ok = okLocal
+			}
+		}
+		if ok {
+			continue
+		}
+		swsPrev := swsPrevByJob[sc.swc.jobName]
+		if len(swsPrev) > 0 {
+			logger.Errorf("there were errors when discovering ionos targets for job %q, so preserving the previous targets", sc.swc.jobName)
+			dst = append(dst[:dstLen], swsPrev...)
+		}
+	}
+	return dst
+}
+
 // getKubernetesSDScrapeWork returns `kubernetes_sd_configs` ScrapeWork from cfg.
 func (cfg *Config) getKubernetesSDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
 	swsPrevByJob := getSWSByJob(prev)
@@ -880,6 +970,33 @@ func (cfg *Config) getKumaSDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
 	return dst
 }
 
+// getLinodeSDScrapeWork returns `linode_sd_configs` ScrapeWork from cfg.
+func (cfg *Config) getLinodeSDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
+	swsPrevByJob := getSWSByJob(prev)
+	dst := make([]*ScrapeWork, 0, len(prev))
+	for _, sc := range cfg.ScrapeConfigs {
+		dstLen := len(dst)
+		ok := true
+		for j := range sc.LinodeSDConfigs {
+			sdc := &sc.LinodeSDConfigs[j]
+			var okLocal bool
+			dst, okLocal = appendSDScrapeWork(dst, sdc, cfg.baseDir, sc.swc, "linode_sd_config")
+			if ok {
+				ok = okLocal
+			}
+		}
+		if ok {
+			continue
+		}
+		swsPrev := swsPrevByJob[sc.swc.jobName]
+		if len(swsPrev) > 0 {
+			logger.Errorf("there were errors when discovering linode targets for job %q, so preserving the previous targets", sc.swc.jobName)
+			dst = append(dst[:dstLen], swsPrev...)
+		}
+	}
+	return dst
+}
+
 // getNomadSDScrapeWork returns `nomad_sd_configs` ScrapeWork from cfg.
 func (cfg *Config) getNomadSDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
 	swsPrevByJob := getSWSByJob(prev)
@@ -934,6 +1051,87 @@ func (cfg *Config) getOpenStackSDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
 	return dst
 }
 
+// getPuppetDBSDScrapeWork returns `puppetdb_sd_configs` ScrapeWork from cfg.
+func (cfg *Config) getPuppetDBSDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
+	swsPrevByJob := getSWSByJob(prev)
+	dst := make([]*ScrapeWork, 0, len(prev))
+	for _, sc := range cfg.ScrapeConfigs {
+		dstLen := len(dst)
+		ok := true
+		for j := range sc.PuppetDBSDConfigs {
+			sdc := &sc.PuppetDBSDConfigs[j]
+			var okLocal bool
+			dst, okLocal = appendSDScrapeWork(dst, sdc, cfg.baseDir, sc.swc, "puppetdb_sd_config")
+			if ok {
+				ok = okLocal
+			}
+		}
+		if ok {
+			continue
+		}
+		swsPrev := swsPrevByJob[sc.swc.jobName]
+		if len(swsPrev) > 0 {
+			logger.Errorf("there were errors when discovering puppetdb targets for job %q, so preserving the previous targets", sc.swc.jobName)
+			dst = append(dst[:dstLen], swsPrev...)
+		}
+	}
+	return dst
+}
+
+// getScalewaySDScrapeWork returns `scaleway_sd_configs` ScrapeWork from cfg.
+func (cfg *Config) getScalewaySDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
+	swsPrevByJob := getSWSByJob(prev)
+	dst := make([]*ScrapeWork, 0, len(prev))
+	for _, sc := range cfg.ScrapeConfigs {
+		dstLen := len(dst)
+		ok := true
+		for j := range sc.ScalewaySDConfigs {
+			sdc := &sc.ScalewaySDConfigs[j]
+			var okLocal bool
+			dst, okLocal = appendSDScrapeWork(dst, sdc, cfg.baseDir, sc.swc, "scaleway_sd_config")
+			if ok {
+				ok = okLocal
+			}
+		}
+		if ok {
+			continue
+		}
+		swsPrev := swsPrevByJob[sc.swc.jobName]
+		if len(swsPrev) > 0 {
+			logger.Errorf("there were errors when discovering scaleway targets for job %q, so preserving the previous targets", sc.swc.jobName)
+			dst = append(dst[:dstLen], swsPrev...)
+		}
+	}
+	return dst
+}
+
+// getVultrSDScrapeWork returns `vultr_sd_configs` ScrapeWork from cfg.
+func (cfg *Config) getVultrSDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
+	swsPrevByJob := getSWSByJob(prev)
+	dst := make([]*ScrapeWork, 0, len(prev))
+	for _, sc := range cfg.ScrapeConfigs {
+		dstLen := len(dst)
+		ok := true
+		for j := range sc.VultrSDConfigs {
+			sdc := &sc.VultrSDConfigs[j]
+			var okLocal bool
+			dst, okLocal = appendSDScrapeWork(dst, sdc, cfg.baseDir, sc.swc, "vultr_sd_config")
+			if ok {
+				ok = okLocal
+			}
+		}
+		if ok {
+			continue
+		}
+		swsPrev := swsPrevByJob[sc.swc.jobName]
+		if len(swsPrev) > 0 {
+			logger.Errorf("there were errors when discovering vultr targets for job %q, so preserving the previous targets", sc.swc.jobName)
+			dst = append(dst[:dstLen], swsPrev...)
+		}
+	}
+	return dst
+}
+
 // getYandexCloudSDScrapeWork returns `yandexcloud_sd_configs` ScrapeWork from cfg.
 func (cfg *Config) getYandexCloudSDScrapeWork(prev []*ScrapeWork) []*ScrapeWork {
 	swsPrevByJob := getSWSByJob(prev)
@@ -1041,62 +1239,77 @@ func getScrapeWorkConfig(sc *ScrapeConfig, baseDir string, globalCfg *GlobalConf
 	if sc.SeriesLimit > 0 {
 		seriesLimit = sc.SeriesLimit
 	}
+	if err := checkScrapeProtocols(sc.ScrapeProtocols); err != nil {
+		return nil, fmt.Errorf("invalid `scrape_protocols` for `job_name` %q: %w", jobName, err)
+	}
+	switch sc.NativeHistogramFormat {
+	case "", parser.NativeHistogramFormatVMRange, parser.NativeHistogramFormatLE:
+	default:
+		return nil, fmt.Errorf("unexpected `native_histogram_format` for `job_name` %q: %q; supported values: %s or %s",
+			jobName, sc.NativeHistogramFormat, parser.NativeHistogramFormatVMRange, parser.NativeHistogramFormatLE)
+	}
 	swc := &scrapeWorkConfig{
-		scrapeInterval:       scrapeInterval,
-		scrapeIntervalString: scrapeInterval.String(),
-		scrapeTimeout:        scrapeTimeout,
-		scrapeTimeoutString:  scrapeTimeout.String(),
-		jobName:              jobName,
-		metricsPath:          metricsPath,
-		scheme:               scheme,
-		params:               params,
-		proxyURL:             sc.ProxyURL,
-		proxyAuthConfig:      proxyAC,
-		authConfig:           ac,
-		honorLabels:          honorLabels,
-		honorTimestamps:      honorTimestamps,
-		denyRedirects:        denyRedirects,
-		externalLabels:       externalLabels,
-		relabelConfigs:       relabelConfigs,
-		metricRelabelConfigs: metricRelabelConfigs,
-		sampleLimit:          sc.SampleLimit,
-		disableCompression:   sc.DisableCompression,
-		disableKeepAlive:     sc.DisableKeepAlive,
-		streamParse:          sc.StreamParse,
-		scrapeAlignInterval:  sc.ScrapeAlignInterval.Duration(),
-		scrapeOffset:         sc.ScrapeOffset.Duration(),
-		seriesLimit:          seriesLimit,
-		noStaleMarkers:       noStaleTracking,
+		scrapeInterval:        scrapeInterval,
+		scrapeIntervalString:  scrapeInterval.String(),
+		scrapeTimeout:         scrapeTimeout,
+		scrapeTimeoutString:   scrapeTimeout.String(),
+		jobName:               jobName,
+		metricsPath:           metricsPath,
+		scheme:                scheme,
+		params:                params,
+		proxyURL:              sc.ProxyURL,
+		proxyAuthConfig:       proxyAC,
+		authConfig:            ac,
+		honorLabels:           honorLabels,
+		honorTimestamps:       honorTimestamps,
+		denyRedirects:         denyRedirects,
+		externalLabels:        externalLabels,
+		relabelConfigs:        relabelConfigs,
+		metricRelabelConfigs:  metricRelabelConfigs,
+		sampleLimit:           sc.SampleLimit,
+		disableCompression:    sc.DisableCompression,
+		disableKeepAlive:      sc.DisableKeepAlive,
+		streamParse:           sc.StreamParse,
+		scrapeAlignInterval:   sc.ScrapeAlignInterval.Duration(),
+		scrapeOffset:          sc.ScrapeOffset.Duration(),
+		seriesLimit:           seriesLimit,
+		seriesBudget:          sc.SeriesBudget,
+		noStaleMarkers:        noStaleTracking,
+		scrapeProtocols:       sc.ScrapeProtocols,
+		nativeHistogramFormat: sc.NativeHistogramFormat,
 	}
 	return swc, nil
 }
 
 type scrapeWorkConfig struct {
-	scrapeInterval       time.Duration
-	scrapeIntervalString string
-	scrapeTimeout        time.Duration
-	scrapeTimeoutString  string
-	jobName              string
-	metricsPath          string
-	scheme               string
-	params               map[string][]string
-	proxyURL             *proxy.URL
-	proxyAuthConfig      *promauth.Config
-	authConfig           *promauth.Config
-	honorLabels          bool
-	honorTimestamps      bool
-	denyRedirects        bool
-	externalLabels       *promutils.Labels
-	relabelConfigs       *promrelabel.ParsedConfigs
-	metricRelabelConfigs *promrelabel.ParsedConfigs
-	sampleLimit          int
-	disableCompression   bool
-	disableKeepAlive     bool
-	streamParse          bool
-	scrapeAlignInterval  time.Duration
-	scrapeOffset         time.Duration
-	seriesLimit          int
-	noStaleMarkers       bool
+	scrapeInterval        time.Duration
+	scrapeIntervalString  string
+	scrapeTimeout         time.Duration
+	scrapeTimeoutString   string
+	jobName               string
+	metricsPath           string
+	scheme                string
+	params                map[string][]string
+	proxyURL              *proxy.URL
+	proxyAuthConfig       *promauth.Config
+	authConfig            *promauth.Config
+	honorLabels           bool
+	honorTimestamps       bool
+	denyRedirects         bool
+	externalLabels        *promutils.Labels
+	relabelConfigs        *promrelabel.ParsedConfigs
+	metricRelabelConfigs  *promrelabel.ParsedConfigs
+	sampleLimit           int
+	disableCompression    bool
+	disableKeepAlive      bool
+	streamParse           bool
+	scrapeAlignInterval   time.Duration
+	scrapeOffset          time.Duration
+	seriesLimit           int
+	seriesBudget          int
+	noStaleMarkers        bool
+	scrapeProtocols       []string
+	nativeHistogramFormat string
 }
 
 type targetLabelsGetter interface {
@@ -1331,6 +1544,16 @@ func (swc *scrapeWorkConfig) getScrapeWork(target string, extraLabels, metaLabel
 		}
 		seriesLimit = n
 	}
+	// Read series_budget option from __series_budget__ label.
+	// See https://docs.victoriametrics.com/vmagent.html#series-budget
+	seriesBudget := swc.seriesBudget
+	if s := labels.Get("__series_budget__"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse __series_budget__=%q: %w", s, err)
+		}
+		seriesBudget = n
+	}
 	// Read stream_parse option from __stream_parse__ label.
 	// See https://docs.victoriametrics.com/vmagent.html#stream-parsing-mode
 	streamParse := swc.streamParse
@@ -1360,29 +1583,32 @@ func (swc *scrapeWorkConfig) getScrapeWork(target string, extraLabels, metaLabel
 	labelsCopy.InternStrings()
 
 	sw := &ScrapeWork{
-		ScrapeURL:            scrapeURL,
-		ScrapeInterval:       scrapeInterval,
-		ScrapeTimeout:        scrapeTimeout,
-		HonorLabels:          swc.honorLabels,
-		HonorTimestamps:      swc.honorTimestamps,
-		DenyRedirects:        swc.denyRedirects,
-		OriginalLabels:       originalLabels,
-		Labels:               labelsCopy,
-		ExternalLabels:       swc.externalLabels,
-		ProxyURL:             swc.proxyURL,
-		ProxyAuthConfig:      swc.proxyAuthConfig,
-		AuthConfig:           swc.authConfig,
-		RelabelConfigs:       swc.relabelConfigs,
-		MetricRelabelConfigs: swc.metricRelabelConfigs,
-		SampleLimit:          swc.sampleLimit,
-		DisableCompression:   swc.disableCompression,
-		DisableKeepAlive:     swc.disableKeepAlive,
-		StreamParse:          streamParse,
-		ScrapeAlignInterval:  swc.scrapeAlignInterval,
-		ScrapeOffset:         swc.scrapeOffset,
-		SeriesLimit:          seriesLimit,
-		NoStaleMarkers:       swc.noStaleMarkers,
-		AuthToken:            at,
+		ScrapeURL:             scrapeURL,
+		ScrapeInterval:        scrapeInterval,
+		ScrapeTimeout:         scrapeTimeout,
+		HonorLabels:           swc.honorLabels,
+		HonorTimestamps:       swc.honorTimestamps,
+		DenyRedirects:         swc.denyRedirects,
+		OriginalLabels:        originalLabels,
+		Labels:                labelsCopy,
+		ExternalLabels:        swc.externalLabels,
+		ProxyURL:              swc.proxyURL,
+		ProxyAuthConfig:       swc.proxyAuthConfig,
+		AuthConfig:            swc.authConfig,
+		RelabelConfigs:        swc.relabelConfigs,
+		MetricRelabelConfigs:  swc.metricRelabelConfigs,
+		SampleLimit:           swc.sampleLimit,
+		DisableCompression:    swc.disableCompression,
+		DisableKeepAlive:      swc.disableKeepAlive,
+		StreamParse:           streamParse,
+		ScrapeAlignInterval:   swc.scrapeAlignInterval,
+		ScrapeOffset:          swc.scrapeOffset,
+		SeriesLimit:           seriesLimit,
+		SeriesBudget:          seriesBudget,
+		NoStaleMarkers:        swc.noStaleMarkers,
+		AuthToken:             at,
+		ScrapeProtocols:       swc.scrapeProtocols,
+		NativeHistogramFormat: swc.nativeHistogramFormat,
 
 		jobNameOriginal: swc.jobName,
 	}
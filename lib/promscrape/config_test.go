@@ -711,6 +711,33 @@ scrape_config_files:
   static_configs:
   - targets: ["s"]
 `)
+
+	// Unsupported scrape_protocols
+	f(`
+scrape_configs:
+- job_name: aa
+  scrape_protocols: [OpenMetricsText1.0.0]
+  static_configs:
+  - targets: ["s"]
+`)
+
+	// Duplicate scrape_protocols
+	f(`
+scrape_configs:
+- job_name: aa
+  scrape_protocols: [PrometheusProto, PrometheusProto]
+  static_configs:
+  - targets: ["s"]
+`)
+
+	// Invalid native_histogram_format
+	f(`
+scrape_configs:
+- job_name: aa
+  native_histogram_format: foobar
+  static_configs:
+  - targets: ["s"]
+`)
 }
 
 func resetNonEssentialFields(sws []*ScrapeWork) {
@@ -1217,6 +1244,8 @@ scrape_configs:
         replacement: 127.0.0.1:9116  # The SNMP exporter's real hostname:port.
       - target_label: __series_limit__
         replacement: 1234
+      - target_label: __series_budget__
+        replacement: 100
       - target_label: __stream_parse__
         replacement: true
 `, []*ScrapeWork{
@@ -1237,6 +1266,7 @@ scrape_configs:
 			ScrapeAlignInterval: time.Second,
 			ScrapeOffset:        500 * time.Millisecond,
 			SeriesLimit:         1234,
+			SeriesBudget:        100,
 			jobNameOriginal:     "snmp",
 		},
 	})
@@ -0,0 +1,196 @@
+package hetzner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+// hcloudServer represents Hetzner Cloud server.
+//
+// See https://docs.hetzner.cloud/#servers-get-all-servers
+type hcloudServer struct {
+	ID         int                `json:"id"`
+	Name       string             `json:"name"`
+	Status     string             `json:"status"`
+	PublicNet  hcloudPublicNet    `json:"public_net"`
+	PrivateNet []hcloudPrivateNet `json:"private_net"`
+	ServerType hcloudServerType   `json:"server_type"`
+	Datacenter hcloudDatacenter   `json:"datacenter"`
+	Image      *hcloudImage       `json:"image"`
+	Labels     map[string]string  `json:"labels"`
+}
+
+type hcloudPublicNet struct {
+	IPv4 struct {
+		IP string `json:"ip"`
+	} `json:"ipv4"`
+	IPv6 struct {
+		IP string `json:"ip"`
+	} `json:"ipv6"`
+}
+
+type hcloudPrivateNet struct {
+	Network int    `json:"network"`
+	IP      string `json:"ip"`
+}
+
+type hcloudServerType struct {
+	Name    string  `json:"name"`
+	Cores   int     `json:"cores"`
+	CPUType string  `json:"cpu_type"`
+	Memory  float64 `json:"memory"`
+	Disk    int     `json:"disk"`
+}
+
+type hcloudDatacenter struct {
+	Name     string `json:"name"`
+	Location struct {
+		Name        string `json:"name"`
+		NetworkZone string `json:"network_zone"`
+	} `json:"location"`
+}
+
+type hcloudImage struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	OSVersion   string `json:"os_version"`
+	OSFlavor    string `json:"os_flavor"`
+}
+
+// hcloudNetwork represents Hetzner Cloud private network.
+//
+// See https://docs.hetzner.cloud/#networks-get-all-networks
+type hcloudNetwork struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// hcloudMeta represents pagination info in Hetzner Cloud API responses.
+//
+// See https://docs.hetzner.cloud/#pagination
+type hcloudMeta struct {
+	Pagination struct {
+		NextPage int `json:"next_page"`
+	} `json:"pagination"`
+}
+
+func getHCloudServerLabels(cfg *apiConfig) ([]*promutils.Labels, error) {
+	servers, err := getHCloudServers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	networks, err := getHCloudNetworks(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return getHCloudLabels(servers, networks, cfg.port), nil
+}
+
+func getHCloudServers(cfg *apiConfig) ([]hcloudServer, error) {
+	var servers []hcloudServer
+	err := getHCloudPages(cfg, "/servers", func(data []byte) (*hcloudMeta, error) {
+		var resp struct {
+			Servers []hcloudServer `json:"servers"`
+			Meta    hcloudMeta     `json:"meta"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, err
+		}
+		servers = append(servers, resp.Servers...)
+		return &resp.Meta, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+func getHCloudNetworks(cfg *apiConfig) ([]hcloudNetwork, error) {
+	var networks []hcloudNetwork
+	err := getHCloudPages(cfg, "/networks", func(data []byte) (*hcloudMeta, error) {
+		var resp struct {
+			Networks []hcloudNetwork `json:"networks"`
+			Meta     hcloudMeta      `json:"meta"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, err
+		}
+		networks = append(networks, resp.Networks...)
+		return &resp.Meta, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return networks, nil
+}
+
+// getHCloudPages calls parse for every page returned by Hetzner Cloud API at the given path.
+func getHCloudPages(cfg *apiConfig, path string, parse func(data []byte) (*hcloudMeta, error)) error {
+	page := 1
+	for {
+		pagePath := fmt.Sprintf("%s?page=%d&per_page=50", path, page)
+		data, err := cfg.client.GetAPIResponse(pagePath)
+		if err != nil {
+			return fmt.Errorf("cannot fetch data from Hetzner Cloud API at %q: %w", pagePath, err)
+		}
+		meta, err := parse(data)
+		if err != nil {
+			return fmt.Errorf("cannot parse Hetzner Cloud API response from %q: %w", pagePath, err)
+		}
+		nextPage := meta.Pagination.NextPage
+		if nextPage <= page {
+			return nil
+		}
+		page = nextPage
+	}
+}
+
+func getHCloudLabels(servers []hcloudServer, networks []hcloudNetwork, port int) []*promutils.Labels {
+	networkNames := make(map[int]string, len(networks))
+	for _, network := range networks {
+		networkNames[network.ID] = network.Name
+	}
+	ms := make([]*promutils.Labels, 0, len(servers))
+	for i := range servers {
+		server := &servers[i]
+		m := promutils.NewLabels(24)
+		m.Add("__address__", discoveryutils.JoinHostPort(server.PublicNet.IPv4.IP, port))
+		m.Add("__meta_hetzner_role", "hcloud")
+		m.Add("__meta_hetzner_server_id", strconv.Itoa(server.ID))
+		m.Add("__meta_hetzner_server_name", server.Name)
+		m.Add("__meta_hetzner_datacenter", server.Datacenter.Name)
+		m.Add("__meta_hetzner_public_ipv4", server.PublicNet.IPv4.IP)
+		m.Add("__meta_hetzner_public_ipv6_network", server.PublicNet.IPv6.IP)
+		m.Add("__meta_hetzner_server_status", server.Status)
+		m.Add("__meta_hetzner_hcloud_datacenter_location", server.Datacenter.Location.Name)
+		m.Add("__meta_hetzner_hcloud_datacenter_location_network_zone", server.Datacenter.Location.NetworkZone)
+		m.Add("__meta_hetzner_hcloud_server_type", server.ServerType.Name)
+		m.Add("__meta_hetzner_hcloud_cpu_cores", strconv.Itoa(server.ServerType.Cores))
+		m.Add("__meta_hetzner_hcloud_cpu_type", server.ServerType.CPUType)
+		m.Add("__meta_hetzner_hcloud_memory_size_gb", strconv.Itoa(int(server.ServerType.Memory)))
+		m.Add("__meta_hetzner_hcloud_disk_size_gb", strconv.Itoa(server.ServerType.Disk))
+		if image := server.Image; image != nil {
+			m.Add("__meta_hetzner_hcloud_image_name", image.Name)
+			m.Add("__meta_hetzner_hcloud_image_description", image.Description)
+			m.Add("__meta_hetzner_hcloud_image_os_version", image.OSVersion)
+			m.Add("__meta_hetzner_hcloud_image_os_flavor", image.OSFlavor)
+		}
+		for _, privateNet := range server.PrivateNet {
+			networkName, ok := networkNames[privateNet.Network]
+			if !ok {
+				continue
+			}
+			m.Add(discoveryutils.SanitizeLabelName("__meta_hetzner_hcloud_private_ipv4_"+networkName), privateNet.IP)
+		}
+		for k, v := range server.Labels {
+			m.Add(discoveryutils.SanitizeLabelName("__meta_hetzner_hcloud_label_"+k), v)
+			m.Add(discoveryutils.SanitizeLabelName("__meta_hetzner_hcloud_labelpresent_"+k), "true")
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
@@ -0,0 +1,136 @@
+package hetzner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+func TestGetHCloudServerLabels(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RequestURI() {
+		case "/servers?page=1&per_page=50":
+			fmt.Fprint(w, `{
+  "servers": [
+    {
+      "id": 42,
+      "name": "my-server",
+      "status": "running",
+      "public_net": {
+        "ipv4": {"ip": "1.2.3.4"},
+        "ipv6": {"ip": "2001:db8::/64"}
+      },
+      "private_net": [
+        {"network": 4711, "ip": "10.0.0.2"}
+      ],
+      "server_type": {"name": "cx11", "cores": 1, "cpu_type": "shared", "memory": 1.0, "disk": 25},
+      "datacenter": {
+        "name": "fsn1-dc8",
+        "location": {"name": "fsn1", "network_zone": "eu-central"}
+      },
+      "image": {"name": "ubuntu-20.04", "description": "Ubuntu 20.04 Standard 64 bit", "os_version": "20.04", "os_flavor": "ubuntu"},
+      "labels": {"my-key": "my-value"}
+    }
+  ],
+  "meta": {"pagination": {"page": 1, "per_page": 50, "next_page": 2}}
+}`)
+		case "/servers?page=2&per_page=50":
+			fmt.Fprint(w, `{
+  "servers": [
+    {
+      "id": 44,
+      "name": "another-server",
+      "status": "off",
+      "public_net": {
+        "ipv4": {"ip": "1.2.3.5"},
+        "ipv6": {"ip": "2001:db9::/64"}
+      },
+      "private_net": [],
+      "server_type": {"name": "cpx11", "cores": 2, "cpu_type": "dedicated", "memory": 2.5, "disk": 40},
+      "datacenter": {
+        "name": "hel1-dc2",
+        "location": {"name": "hel1", "network_zone": "eu-central"}
+      },
+      "image": null,
+      "labels": {}
+    }
+  ],
+  "meta": {"pagination": {"page": 2, "per_page": 50, "next_page": null}}
+}`)
+		case "/networks?page=1&per_page=50":
+			fmt.Fprint(w, `{
+  "networks": [
+    {"id": 4711, "name": "mynet"}
+  ],
+  "meta": {"pagination": {"page": 1, "per_page": 50, "next_page": null}}
+}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "API path not found: %s", r.URL.RequestURI())
+		}
+	}))
+	defer testServer.Close()
+
+	c, err := discoveryutils.NewClient(testServer.URL, nil, nil, nil, &promauth.HTTPClientConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error at client create: %s", err)
+	}
+	defer c.Stop()
+	cfg := &apiConfig{
+		client: c,
+		port:   9100,
+	}
+	labelss, err := getHCloudServerLabels(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedLabels := []*promutils.Labels{
+		promutils.NewLabelsFromMap(map[string]string{
+			"__address__":                                            "1.2.3.4:9100",
+			"__meta_hetzner_role":                                    "hcloud",
+			"__meta_hetzner_server_id":                               "42",
+			"__meta_hetzner_server_name":                             "my-server",
+			"__meta_hetzner_datacenter":                              "fsn1-dc8",
+			"__meta_hetzner_public_ipv4":                             "1.2.3.4",
+			"__meta_hetzner_public_ipv6_network":                     "2001:db8::/64",
+			"__meta_hetzner_server_status":                           "running",
+			"__meta_hetzner_hcloud_datacenter_location":              "fsn1",
+			"__meta_hetzner_hcloud_datacenter_location_network_zone": "eu-central",
+			"__meta_hetzner_hcloud_server_type":                      "cx11",
+			"__meta_hetzner_hcloud_cpu_cores":                        "1",
+			"__meta_hetzner_hcloud_cpu_type":                         "shared",
+			"__meta_hetzner_hcloud_memory_size_gb":                   "1",
+			"__meta_hetzner_hcloud_disk_size_gb":                     "25",
+			"__meta_hetzner_hcloud_image_name":                       "ubuntu-20.04",
+			"__meta_hetzner_hcloud_image_description":                "Ubuntu 20.04 Standard 64 bit",
+			"__meta_hetzner_hcloud_image_os_version":                 "20.04",
+			"__meta_hetzner_hcloud_image_os_flavor":                  "ubuntu",
+			"__meta_hetzner_hcloud_private_ipv4_mynet":               "10.0.0.2",
+			"__meta_hetzner_hcloud_label_my_key":                     "my-value",
+			"__meta_hetzner_hcloud_labelpresent_my_key":              "true",
+		}),
+		promutils.NewLabelsFromMap(map[string]string{
+			"__address__":                                            "1.2.3.5:9100",
+			"__meta_hetzner_role":                                    "hcloud",
+			"__meta_hetzner_server_id":                               "44",
+			"__meta_hetzner_server_name":                             "another-server",
+			"__meta_hetzner_datacenter":                              "hel1-dc2",
+			"__meta_hetzner_public_ipv4":                             "1.2.3.5",
+			"__meta_hetzner_public_ipv6_network":                     "2001:db9::/64",
+			"__meta_hetzner_server_status":                           "off",
+			"__meta_hetzner_hcloud_datacenter_location":              "hel1",
+			"__meta_hetzner_hcloud_datacenter_location_network_zone": "eu-central",
+			"__meta_hetzner_hcloud_server_type":                      "cpx11",
+			"__meta_hetzner_hcloud_cpu_cores":                        "2",
+			"__meta_hetzner_hcloud_cpu_type":                         "dedicated",
+			"__meta_hetzner_hcloud_memory_size_gb":                   "2",
+			"__meta_hetzner_hcloud_disk_size_gb":                     "40",
+		}),
+	}
+	discoveryutils.TestEqualLabelss(t, labelss, expectedLabels)
+}
@@ -0,0 +1,77 @@
+package hetzner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+// robotServer represents Hetzner Robot dedicated server.
+//
+// See https://robot.your-server.de/doc/webservice/en.html#get-server
+type robotServer struct {
+	Server struct {
+		ServerIP     string `json:"server_ip"`
+		ServerNumber int    `json:"server_number"`
+		ServerName   string `json:"server_name"`
+		DC           string `json:"dc"`
+		Status       string `json:"status"`
+		Product      string `json:"product"`
+		Cancelled    bool   `json:"cancelled"`
+		Subnet       []struct {
+			IP   string `json:"ip"`
+			Mask string `json:"mask"`
+		} `json:"subnet"`
+	} `json:"server"`
+}
+
+func getRobotServerLabels(cfg *apiConfig) ([]*promutils.Labels, error) {
+	servers, err := getRobotServers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return getRobotLabels(servers, cfg.port), nil
+}
+
+func getRobotServers(cfg *apiConfig) ([]robotServer, error) {
+	data, err := cfg.client.GetAPIResponse("/server")
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch servers from Hetzner Robot API: %w", err)
+	}
+	var servers []robotServer
+	if err := json.Unmarshal(data, &servers); err != nil {
+		return nil, fmt.Errorf("cannot parse Hetzner Robot API response %q: %w", data, err)
+	}
+	return servers, nil
+}
+
+func getRobotLabels(servers []robotServer, port int) []*promutils.Labels {
+	ms := make([]*promutils.Labels, 0, len(servers))
+	for i := range servers {
+		server := &servers[i].Server
+		m := promutils.NewLabels(10)
+		m.Add("__address__", discoveryutils.JoinHostPort(server.ServerIP, port))
+		m.Add("__meta_hetzner_role", "robot")
+		m.Add("__meta_hetzner_server_id", strconv.Itoa(server.ServerNumber))
+		m.Add("__meta_hetzner_server_name", server.ServerName)
+		m.Add("__meta_hetzner_datacenter", strings.ToLower(server.DC))
+		m.Add("__meta_hetzner_public_ipv4", server.ServerIP)
+		m.Add("__meta_hetzner_server_status", server.Status)
+		m.Add("__meta_hetzner_robot_product", server.Product)
+		m.Add("__meta_hetzner_robot_cancelled", strconv.FormatBool(server.Cancelled))
+		for _, subnet := range server.Subnet {
+			ip := net.ParseIP(subnet.IP)
+			if ip.To4() == nil {
+				m.Add("__meta_hetzner_public_ipv6_network", subnet.IP+"/"+subnet.Mask)
+				break
+			}
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
@@ -0,0 +1,91 @@
+package hetzner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+func TestGetRobotServerLabels(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/server" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "API path not found: %s", r.URL.Path)
+			return
+		}
+		fmt.Fprint(w, `[
+  {
+    "server": {
+      "server_ip": "123.123.123.123",
+      "server_number": 321,
+      "server_name": "server1",
+      "product": "DS 3000",
+      "dc": "NBG1-DC1",
+      "status": "ready",
+      "cancelled": false,
+      "subnet": [
+        {"ip": "2a01:4f8:111:4221::", "mask": "64"}
+      ]
+    }
+  },
+  {
+    "server": {
+      "server_ip": "123.123.123.124",
+      "server_number": 421,
+      "server_name": "server2",
+      "product": "X5",
+      "dc": "FSN1-DC10",
+      "status": "ready",
+      "cancelled": true,
+      "subnet": null
+    }
+  }
+]`)
+	}))
+	defer testServer.Close()
+
+	c, err := discoveryutils.NewClient(testServer.URL, nil, nil, nil, &promauth.HTTPClientConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error at client create: %s", err)
+	}
+	defer c.Stop()
+	cfg := &apiConfig{
+		client: c,
+		port:   9100,
+	}
+	labelss, err := getRobotServerLabels(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedLabels := []*promutils.Labels{
+		promutils.NewLabelsFromMap(map[string]string{
+			"__address__":                        "123.123.123.123:9100",
+			"__meta_hetzner_role":                "robot",
+			"__meta_hetzner_server_id":           "321",
+			"__meta_hetzner_server_name":         "server1",
+			"__meta_hetzner_datacenter":          "nbg1-dc1",
+			"__meta_hetzner_public_ipv4":         "123.123.123.123",
+			"__meta_hetzner_public_ipv6_network": "2a01:4f8:111:4221::/64",
+			"__meta_hetzner_server_status":       "ready",
+			"__meta_hetzner_robot_product":       "DS 3000",
+			"__meta_hetzner_robot_cancelled":     "false",
+		}),
+		promutils.NewLabelsFromMap(map[string]string{
+			"__address__":                    "123.123.123.124:9100",
+			"__meta_hetzner_role":            "robot",
+			"__meta_hetzner_server_id":       "421",
+			"__meta_hetzner_server_name":     "server2",
+			"__meta_hetzner_datacenter":      "fsn1-dc10",
+			"__meta_hetzner_public_ipv4":     "123.123.123.124",
+			"__meta_hetzner_server_status":   "ready",
+			"__meta_hetzner_robot_product":   "X5",
+			"__meta_hetzner_robot_cancelled": "true",
+		}),
+	}
+	discoveryutils.TestEqualLabelss(t, labelss, expectedLabels)
+}
@@ -0,0 +1,116 @@
+package ionos
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+)
+
+var configMap = discoveryutils.NewConfigMap()
+
+type apiConfig struct {
+	client       *discoveryutils.Client
+	datacenterID string
+	port         int
+}
+
+func newAPIConfig(sdc *SDConfig, baseDir string) (*apiConfig, error) {
+	if sdc.DatacenterID == "" {
+		return nil, fmt.Errorf("missing `datacenter_id` option")
+	}
+	ac, err := sdc.HTTPClientConfig.NewConfig(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse auth config: %w", err)
+	}
+	proxyAC, err := sdc.ProxyClientConfig.NewConfig(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse proxy auth config: %w", err)
+	}
+	apiServer := "https://api.ionos.com"
+	client, err := discoveryutils.NewClient(apiServer, ac, sdc.ProxyURL, proxyAC, &sdc.HTTPClientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create HTTP client for %q: %w", apiServer, err)
+	}
+	cfg := &apiConfig{
+		client:       client,
+		datacenterID: sdc.DatacenterID,
+		port:         sdc.Port,
+	}
+	if cfg.port == 0 {
+		cfg.port = 80
+	}
+	return cfg, nil
+}
+
+func getAPIConfig(sdc *SDConfig, baseDir string) (*apiConfig, error) {
+	v, err := configMap.Get(sdc, func() (interface{}, error) { return newAPIConfig(sdc, baseDir) })
+	if err != nil {
+		return nil, err
+	}
+	return v.(*apiConfig), nil
+}
+
+// serverList represents the list of servers returned by IONOS Cloud API.
+//
+// See https://api.ionos.com/docs/cloud/v6/#tag/Servers/operation/datacentersServersGet
+type serverList struct {
+	ID    string   `json:"id"`
+	Items []server `json:"items"`
+}
+
+type server struct {
+	ID       string `json:"id"`
+	Metadata struct {
+		State string `json:"state"`
+	} `json:"metadata"`
+	Properties struct {
+		Name             string       `json:"name"`
+		AvailabilityZone string       `json:"availabilityZone"`
+		VMState          string       `json:"vmState"`
+		CPUFamily        string       `json:"cpuFamily"`
+		Type             string       `json:"type"`
+		BootCdrom        *resourceRef `json:"bootCdrom"`
+		BootVolume       *resourceRef `json:"bootVolume"`
+	} `json:"properties"`
+	Entities struct {
+		Nics struct {
+			Items []nic `json:"items"`
+		} `json:"nics"`
+		Volumes struct {
+			Items []volume `json:"items"`
+		} `json:"volumes"`
+	} `json:"entities"`
+}
+
+type resourceRef struct {
+	ID string `json:"id"`
+}
+
+type nic struct {
+	Properties struct {
+		Name *string  `json:"name"`
+		IPs  []string `json:"ips"`
+	} `json:"properties"`
+}
+
+type volume struct {
+	Properties struct {
+		Image *string `json:"image"`
+	} `json:"properties"`
+}
+
+func getServers(cfg *apiConfig) (*serverList, error) {
+	// depth=3 is needed in order to obtain nics with their ips and volumes in a single request.
+	path := fmt.Sprintf("/cloudapi/v6/datacenters/%s/servers?depth=3", url.PathEscape(cfg.datacenterID))
+	data, err := cfg.client.GetAPIResponse(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch servers from IONOS Cloud API: %w", err)
+	}
+	var sl serverList
+	if err := json.Unmarshal(data, &sl); err != nil {
+		return nil, fmt.Errorf("cannot parse IONOS Cloud API response %q: %w", data, err)
+	}
+	return &sl, nil
+}
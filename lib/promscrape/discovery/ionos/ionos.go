@@ -0,0 +1,106 @@
+package ionos
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/proxy"
+)
+
+// SDCheckInterval defines interval for targets refresh.
+var SDCheckInterval = flag.Duration("promscrape.ionosSDCheckInterval", time.Minute, "Interval for checking for changes in IONOS Cloud. "+
+	"This works only if ionos_sd_configs is configured in '-promscrape.config' file. "+
+	"See https://docs.victoriametrics.com/sd_configs.html#ionos_sd_configs for details")
+
+// SDConfig represents service discovery config for IONOS Cloud.
+//
+// See https://prometheus.io/docs/prometheus/latest/configuration/configuration/#ionos_sd_config
+type SDConfig struct {
+	DatacenterID      string                     `yaml:"datacenter_id"`
+	HTTPClientConfig  promauth.HTTPClientConfig  `yaml:",inline"`
+	ProxyURL          *proxy.URL                 `yaml:"proxy_url,omitempty"`
+	ProxyClientConfig promauth.ProxyClientConfig `yaml:",inline"`
+	Port              int                        `yaml:"port,omitempty"`
+}
+
+// GetLabels returns IONOS Cloud server labels according to sdc.
+func (sdc *SDConfig) GetLabels(baseDir string) ([]*promutils.Labels, error) {
+	cfg, err := getAPIConfig(sdc, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get API config: %w", err)
+	}
+	sl, err := getServers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return getServerLabels(sl, cfg.port), nil
+}
+
+func getServerLabels(sl *serverList, port int) []*promutils.Labels {
+	var ms []*promutils.Labels
+	for i := range sl.Items {
+		s := &sl.Items[i]
+		var ips []string
+		ipsByNICName := make(map[string][]string)
+		for _, nic := range s.Entities.Nics.Items {
+			nicName := "unnamed"
+			if nic.Properties.Name != nil {
+				nicName = *nic.Properties.Name
+			}
+			// Put ips of the last nic first in order to be consistent with Prometheus.
+			nicIPs := nic.Properties.IPs
+			ips = append(append([]string{}, nicIPs...), ips...)
+			ipsByNICName[nicName] = append(append([]string{}, nicIPs...), ipsByNICName[nicName]...)
+		}
+		// Servers without ip addresses cannot be scraped.
+		if len(ips) == 0 {
+			continue
+		}
+
+		m := promutils.NewLabels(16)
+		m.Add("__address__", discoveryutils.JoinHostPort(ips[0], port))
+		m.Add("__meta_ionos_server_availability_zone", s.Properties.AvailabilityZone)
+		m.Add("__meta_ionos_server_cpu_family", s.Properties.CPUFamily)
+		m.Add("__meta_ionos_server_servers_id", sl.ID)
+		m.Add("__meta_ionos_server_id", s.ID)
+		m.Add("__meta_ionos_server_ip", joinWithSeparator(ips))
+		m.Add("__meta_ionos_server_lifecycle", s.Metadata.State)
+		m.Add("__meta_ionos_server_name", s.Properties.Name)
+		m.Add("__meta_ionos_server_state", s.Properties.VMState)
+		m.Add("__meta_ionos_server_type", s.Properties.Type)
+		for nicName, nicIPs := range ipsByNICName {
+			m.Add(discoveryutils.SanitizeLabelName("__meta_ionos_server_nic_ip_"+nicName), joinWithSeparator(nicIPs))
+		}
+		if s.Properties.BootCdrom != nil {
+			m.Add("__meta_ionos_server_boot_cdrom_id", s.Properties.BootCdrom.ID)
+		}
+		if s.Properties.BootVolume != nil {
+			m.Add("__meta_ionos_server_boot_volume_id", s.Properties.BootVolume.ID)
+		}
+		if volumes := s.Entities.Volumes.Items; len(volumes) > 0 {
+			if image := volumes[0].Properties.Image; image != nil {
+				m.Add("__meta_ionos_server_boot_image_id", *image)
+			}
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+func joinWithSeparator(a []string) string {
+	return "," + strings.Join(a, ",") + ","
+}
+
+// MustStop stops further usage for sdc.
+func (sdc *SDConfig) MustStop() {
+	v := configMap.Delete(sdc)
+	if v != nil {
+		cfg := v.(*apiConfig)
+		cfg.client.Stop()
+	}
+}
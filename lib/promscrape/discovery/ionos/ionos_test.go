@@ -0,0 +1,143 @@
+package ionos
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+func TestGetServerLabels(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RequestURI() != "/cloudapi/v6/datacenters/8feda53f-15f0-447f-badf-ebe32dad2fc0/servers?depth=3" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "API path not found: %s", r.URL.RequestURI())
+			return
+		}
+		fmt.Fprint(w, `{
+  "id": "8feda53f-15f0-447f-badf-ebe32dad2fc0/servers",
+  "type": "collection",
+  "items": [
+    {
+      "id": "b501942c-4e08-43e6-8ec1-00e59c64e0e4",
+      "type": "server",
+      "metadata": {"state": "AVAILABLE"},
+      "properties": {
+        "name": "prometheus-2",
+        "availabilityZone": "ZONE_2",
+        "vmState": "RUNNING",
+        "bootCdrom": {"id": "0e4d57f9-cd78-11e9-b88c-525400f64d8d", "type": "image"},
+        "bootVolume": null,
+        "cpuFamily": "INTEL_SKYLAKE",
+        "type": "ENTERPRISE"
+      },
+      "entities": {
+        "nics": {
+          "items": [
+            {"id": "nic-1", "properties": {"name": null, "ips": ["185.56.150.9", "85.215.238.118"]}},
+            {"id": "nic-2", "properties": {"name": "metrics", "ips": ["85.215.243.177"]}}
+          ]
+        },
+        "volumes": {"items": []}
+      }
+    },
+    {
+      "id": "523415e6-ff8c-4dc0-86d3-09c256039b30",
+      "type": "server",
+      "metadata": {"state": "AVAILABLE"},
+      "properties": {
+        "name": "prometheus-1",
+        "availabilityZone": "ZONE_1",
+        "vmState": "RUNNING",
+        "bootCdrom": null,
+        "bootVolume": {"id": "ab0a4d8c-a7e5-4c13-9c1d-69ff0c9ff3e1", "type": "volume"},
+        "cpuFamily": "AMD_OPTERON",
+        "type": "ENTERPRISE"
+      },
+      "entities": {
+        "nics": {
+          "items": [
+            {"id": "nic-3", "properties": {"name": "eth0", "ips": ["85.215.248.84"]}}
+          ]
+        },
+        "volumes": {
+          "items": [
+            {"id": "ab0a4d8c-a7e5-4c13-9c1d-69ff0c9ff3e1", "properties": {"image": "2ba6d6c2-e3cf-11ec-92a2-36b1b4f6e89f"}}
+          ]
+        }
+      }
+    },
+    {
+      "id": "8fc8f1a1-6d9b-4bc2-a5ea-4e4f7e6fe5d7",
+      "type": "server",
+      "metadata": {"state": "BUSY"},
+      "properties": {
+        "name": "without-nics",
+        "availabilityZone": "AUTO",
+        "vmState": "SHUTOFF",
+        "cpuFamily": "INTEL_SKYLAKE",
+        "type": "ENTERPRISE"
+      },
+      "entities": {
+        "nics": {"items": []},
+        "volumes": {"items": []}
+      }
+    }
+  ]
+}`)
+	}))
+	defer testServer.Close()
+
+	c, err := discoveryutils.NewClient(testServer.URL, nil, nil, nil, &promauth.HTTPClientConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error at client create: %s", err)
+	}
+	defer c.Stop()
+	cfg := &apiConfig{
+		client:       c,
+		datacenterID: "8feda53f-15f0-447f-badf-ebe32dad2fc0",
+		port:         80,
+	}
+	sl, err := getServers(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	labelss := getServerLabels(sl, cfg.port)
+	expectedLabels := []*promutils.Labels{
+		promutils.NewLabelsFromMap(map[string]string{
+			"__address__":                           "85.215.243.177:80",
+			"__meta_ionos_server_availability_zone": "ZONE_2",
+			"__meta_ionos_server_boot_cdrom_id":     "0e4d57f9-cd78-11e9-b88c-525400f64d8d",
+			"__meta_ionos_server_cpu_family":        "INTEL_SKYLAKE",
+			"__meta_ionos_server_id":                "b501942c-4e08-43e6-8ec1-00e59c64e0e4",
+			"__meta_ionos_server_ip":                ",85.215.243.177,185.56.150.9,85.215.238.118,",
+			"__meta_ionos_server_nic_ip_metrics":    ",85.215.243.177,",
+			"__meta_ionos_server_nic_ip_unnamed":    ",185.56.150.9,85.215.238.118,",
+			"__meta_ionos_server_lifecycle":         "AVAILABLE",
+			"__meta_ionos_server_name":              "prometheus-2",
+			"__meta_ionos_server_servers_id":        "8feda53f-15f0-447f-badf-ebe32dad2fc0/servers",
+			"__meta_ionos_server_state":             "RUNNING",
+			"__meta_ionos_server_type":              "ENTERPRISE",
+		}),
+		promutils.NewLabelsFromMap(map[string]string{
+			"__address__":                           "85.215.248.84:80",
+			"__meta_ionos_server_availability_zone": "ZONE_1",
+			"__meta_ionos_server_boot_volume_id":    "ab0a4d8c-a7e5-4c13-9c1d-69ff0c9ff3e1",
+			"__meta_ionos_server_boot_image_id":     "2ba6d6c2-e3cf-11ec-92a2-36b1b4f6e89f",
+			"__meta_ionos_server_cpu_family":        "AMD_OPTERON",
+			"__meta_ionos_server_id":                "523415e6-ff8c-4dc0-86d3-09c256039b30",
+			"__meta_ionos_server_ip":                ",85.215.248.84,",
+			"__meta_ionos_server_nic_ip_eth0":       ",85.215.248.84,",
+			"__meta_ionos_server_lifecycle":         "AVAILABLE",
+			"__meta_ionos_server_name":              "prometheus-1",
+			"__meta_ionos_server_servers_id":        "8feda53f-15f0-447f-badf-ebe32dad2fc0/servers",
+			"__meta_ionos_server_state":             "RUNNING",
+			"__meta_ionos_server_type":              "ENTERPRISE",
+		}),
+	}
+	discoveryutils.TestEqualLabelss(t, labelss, expectedLabels)
+}
@@ -0,0 +1,149 @@
+package linode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+)
+
+var configMap = discoveryutils.NewConfigMap()
+
+type apiConfig struct {
+	client       *discoveryutils.Client
+	port         int
+	tagSeparator string
+}
+
+func newAPIConfig(sdc *SDConfig, baseDir string) (*apiConfig, error) {
+	ac, err := sdc.HTTPClientConfig.NewConfig(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse auth config: %w", err)
+	}
+	proxyAC, err := sdc.ProxyClientConfig.NewConfig(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse proxy auth config: %w", err)
+	}
+	apiServer := "https://api.linode.com"
+	client, err := discoveryutils.NewClient(apiServer, ac, sdc.ProxyURL, proxyAC, &sdc.HTTPClientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create HTTP client for %q: %w", apiServer, err)
+	}
+	cfg := &apiConfig{
+		client:       client,
+		port:         sdc.Port,
+		tagSeparator: ",",
+	}
+	if cfg.port == 0 {
+		cfg.port = 80
+	}
+	if sdc.TagSeparator != nil {
+		cfg.tagSeparator = *sdc.TagSeparator
+	}
+	return cfg, nil
+}
+
+func getAPIConfig(sdc *SDConfig, baseDir string) (*apiConfig, error) {
+	v, err := configMap.Get(sdc, func() (interface{}, error) { return newAPIConfig(sdc, baseDir) })
+	if err != nil {
+		return nil, err
+	}
+	return v.(*apiConfig), nil
+}
+
+// instance represents Linode instance.
+//
+// See https://www.linode.com/docs/api/linode-instances/#linodes-list
+type instance struct {
+	ID         int      `json:"id"`
+	Label      string   `json:"label"`
+	Image      string   `json:"image"`
+	IPv4       []string `json:"ipv4"`
+	IPv6       string   `json:"ipv6"`
+	Region     string   `json:"region"`
+	Type       string   `json:"type"`
+	Status     string   `json:"status"`
+	Group      string   `json:"group"`
+	Hypervisor string   `json:"hypervisor"`
+	Backups    struct {
+		Enabled bool `json:"enabled"`
+	} `json:"backups"`
+	Specs struct {
+		Disk     int64 `json:"disk"`
+		Memory   int64 `json:"memory"`
+		VCPUs    int   `json:"vcpus"`
+		Transfer int64 `json:"transfer"`
+	} `json:"specs"`
+	Tags []string `json:"tags"`
+}
+
+// ipAddress represents Linode IP address.
+//
+// See https://www.linode.com/docs/api/networking/#ip-addresses-list
+type ipAddress struct {
+	Address string `json:"address"`
+	Public  bool   `json:"public"`
+	RDNS    string `json:"rdns"`
+}
+
+func getInstances(cfg *apiConfig) ([]instance, error) {
+	var instances []instance
+	err := getAllPages(cfg, "/v4/linode/instances", func(data []byte) error {
+		var a []instance
+		if err := json.Unmarshal(data, &a); err != nil {
+			return fmt.Errorf("cannot parse instances: %w", err)
+		}
+		instances = append(instances, a...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+func getIPAddresses(cfg *apiConfig) ([]ipAddress, error) {
+	var ips []ipAddress
+	err := getAllPages(cfg, "/v4/networking/ips", func(data []byte) error {
+		var a []ipAddress
+		if err := json.Unmarshal(data, &a); err != nil {
+			return fmt.Errorf("cannot parse ip addresses: %w", err)
+		}
+		ips = append(ips, a...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ips, nil
+}
+
+// pageResponse represents a single page of Linode list API response.
+//
+// See https://www.linode.com/docs/api/#pagination
+type pageResponse struct {
+	Data  json.RawMessage `json:"data"`
+	Page  int             `json:"page"`
+	Pages int             `json:"pages"`
+}
+
+// getAllPages calls f for the data of every page returned by Linode API at the given path.
+func getAllPages(cfg *apiConfig, path string, f func(data []byte) error) error {
+	for page := 1; ; page++ {
+		pagePath := fmt.Sprintf("%s?page=%d&page_size=500", path, page)
+		data, err := cfg.client.GetAPIResponse(pagePath)
+		if err != nil {
+			return fmt.Errorf("cannot fetch data from Linode API at %q: %w", pagePath, err)
+		}
+		var pr pageResponse
+		if err := json.Unmarshal(data, &pr); err != nil {
+			return fmt.Errorf("cannot parse Linode API response from %q: %w", pagePath, err)
+		}
+		if err := f(pr.Data); err != nil {
+			return fmt.Errorf("unexpected Linode API response from %q: %w", pagePath, err)
+		}
+		if page >= pr.Pages {
+			return nil
+		}
+	}
+}
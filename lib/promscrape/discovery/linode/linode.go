@@ -0,0 +1,140 @@
+package linode
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/proxy"
+)
+
+// SDCheckInterval defines interval for targets refresh.
+var SDCheckInterval = flag.Duration("promscrape.linodeSDCheckInterval", time.Minute, "Interval for checking for changes in Linode. "+
+	"This works only if linode_sd_configs is configured in '-promscrape.config' file. "+
+	"See https://docs.victoriametrics.com/sd_configs.html#linode_sd_configs for details")
+
+// SDConfig represents service discovery config for Linode.
+//
+// See https://prometheus.io/docs/prometheus/latest/configuration/configuration/#linode_sd_config
+type SDConfig struct {
+	HTTPClientConfig  promauth.HTTPClientConfig  `yaml:",inline"`
+	ProxyURL          *proxy.URL                 `yaml:"proxy_url,omitempty"`
+	ProxyClientConfig promauth.ProxyClientConfig `yaml:",inline"`
+	Port              int                        `yaml:"port,omitempty"`
+	TagSeparator      *string                    `yaml:"tag_separator,omitempty"`
+}
+
+// GetLabels returns Linode instance labels according to sdc.
+func (sdc *SDConfig) GetLabels(baseDir string) ([]*promutils.Labels, error) {
+	cfg, err := getAPIConfig(sdc, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get API config: %w", err)
+	}
+	instances, err := getInstances(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := getIPAddresses(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return getInstanceLabels(instances, ips, cfg.port, cfg.tagSeparator), nil
+}
+
+func getInstanceLabels(instances []instance, ips []ipAddress, port int, tagSeparator string) []*promutils.Labels {
+	ipsByAddress := make(map[string]*ipAddress, len(ips))
+	for i := range ips {
+		ip := &ips[i]
+		ipsByAddress[ip.Address] = ip
+	}
+	var ms []*promutils.Labels
+	for _, inst := range instances {
+		if len(inst.IPv4) == 0 {
+			continue
+		}
+		var privateIPv4, publicIPv4, publicIPv6 string
+		var privateIPv4RDNS, publicIPv4RDNS, publicIPv6RDNS string
+		var extraIPs []string
+		for _, addr := range inst.IPv4 {
+			ip := ipsByAddress[addr]
+			if ip == nil {
+				continue
+			}
+			switch {
+			case ip.Public && publicIPv4 == "":
+				publicIPv4 = ip.Address
+				publicIPv4RDNS = getRDNS(ip)
+			case !ip.Public && privateIPv4 == "":
+				privateIPv4 = ip.Address
+				privateIPv4RDNS = getRDNS(ip)
+			default:
+				extraIPs = append(extraIPs, ip.Address)
+			}
+		}
+		if inst.IPv6 != "" {
+			addr, _, _ := strings.Cut(inst.IPv6, "/")
+			if ip := ipsByAddress[addr]; ip != nil {
+				publicIPv6 = ip.Address
+				publicIPv6RDNS = getRDNS(ip)
+			}
+		}
+		backupsStatus := "disabled"
+		if inst.Backups.Enabled {
+			backupsStatus = "enabled"
+		}
+
+		m := promutils.NewLabels(22)
+		m.Add("__address__", discoveryutils.JoinHostPort(publicIPv4, port))
+		m.Add("__meta_linode_instance_id", strconv.Itoa(inst.ID))
+		m.Add("__meta_linode_instance_label", inst.Label)
+		m.Add("__meta_linode_image", inst.Image)
+		m.Add("__meta_linode_private_ipv4", privateIPv4)
+		m.Add("__meta_linode_public_ipv4", publicIPv4)
+		m.Add("__meta_linode_public_ipv6", publicIPv6)
+		m.Add("__meta_linode_private_ipv4_rdns", privateIPv4RDNS)
+		m.Add("__meta_linode_public_ipv4_rdns", publicIPv4RDNS)
+		m.Add("__meta_linode_public_ipv6_rdns", publicIPv6RDNS)
+		m.Add("__meta_linode_region", inst.Region)
+		m.Add("__meta_linode_type", inst.Type)
+		m.Add("__meta_linode_status", inst.Status)
+		m.Add("__meta_linode_group", inst.Group)
+		m.Add("__meta_linode_hypervisor", inst.Hypervisor)
+		m.Add("__meta_linode_backups", backupsStatus)
+		m.Add("__meta_linode_specs_disk_bytes", strconv.FormatInt(inst.Specs.Disk<<20, 10))
+		m.Add("__meta_linode_specs_memory_bytes", strconv.FormatInt(inst.Specs.Memory<<20, 10))
+		m.Add("__meta_linode_specs_vcpus", strconv.Itoa(inst.Specs.VCPUs))
+		m.Add("__meta_linode_specs_transfer_bytes", strconv.FormatInt(inst.Specs.Transfer<<20, 10))
+		if len(inst.Tags) > 0 {
+			// We surround the separated list with the separator as well. This way regular expressions
+			// in relabeling rules don't have to consider tag positions.
+			m.Add("__meta_linode_tags", tagSeparator+strings.Join(inst.Tags, tagSeparator)+tagSeparator)
+		}
+		if len(extraIPs) > 0 {
+			m.Add("__meta_linode_extra_ips", tagSeparator+strings.Join(extraIPs, tagSeparator)+tagSeparator)
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+func getRDNS(ip *ipAddress) string {
+	// Linode API returns "null" string for missing reverse DNS records in some cases.
+	if ip.RDNS == "null" {
+		return ""
+	}
+	return ip.RDNS
+}
+
+// MustStop stops further usage for sdc.
+func (sdc *SDConfig) MustStop() {
+	v := configMap.Delete(sdc)
+	if v != nil {
+		cfg := v.(*apiConfig)
+		cfg.client.Stop()
+	}
+}
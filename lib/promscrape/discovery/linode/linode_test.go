@@ -0,0 +1,130 @@
+package linode
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+func TestGetInstanceLabels(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RequestURI() {
+		case "/v4/linode/instances?page=1&page_size=500":
+			fmt.Fprint(w, `{
+  "data": [
+    {
+      "id": 26838044,
+      "label": "prometheus-linode-sd-exporter-1",
+      "group": "",
+      "status": "running",
+      "type": "g6-standard-2",
+      "ipv4": ["96.126.108.16", "192.168.170.51", "192.168.170.52"],
+      "ipv6": "2600:3c03::f03c:92ff:fe1a:1382/128",
+      "image": "linode/arch",
+      "region": "us-east",
+      "specs": {"disk": 81920, "memory": 4096, "vcpus": 2, "gpus": 0, "transfer": 4000},
+      "backups": {"enabled": false},
+      "hypervisor": "kvm",
+      "tags": ["monitoring"]
+    }
+  ],
+  "page": 1,
+  "pages": 2,
+  "results": 2
+}`)
+		case "/v4/linode/instances?page=2&page_size=500":
+			fmt.Fprint(w, `{
+  "data": [
+    {
+      "id": 26848419,
+      "label": "no-ipv4",
+      "status": "provisioning",
+      "type": "g6-nanode-1",
+      "ipv4": [],
+      "ipv6": "",
+      "image": "linode/debian11",
+      "region": "us-east",
+      "specs": {"disk": 25600, "memory": 1024, "vcpus": 1, "transfer": 1000},
+      "backups": {"enabled": true},
+      "hypervisor": "kvm",
+      "tags": []
+    }
+  ],
+  "page": 2,
+  "pages": 2,
+  "results": 2
+}`)
+		case "/v4/networking/ips?page=1&page_size=500":
+			fmt.Fprint(w, `{
+  "data": [
+    {"address": "96.126.108.16", "public": true, "rdns": "li1028-16.members.linode.com", "type": "ipv4"},
+    {"address": "192.168.170.51", "public": false, "rdns": null, "type": "ipv4"},
+    {"address": "192.168.170.52", "public": false, "rdns": "null", "type": "ipv4"},
+    {"address": "2600:3c03::f03c:92ff:fe1a:1382", "public": true, "rdns": "", "type": "ipv6"}
+  ],
+  "page": 1,
+  "pages": 1,
+  "results": 4
+}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "API path not found: %s", r.URL.RequestURI())
+		}
+	}))
+	defer testServer.Close()
+
+	c, err := discoveryutils.NewClient(testServer.URL, nil, nil, nil, &promauth.HTTPClientConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error at client create: %s", err)
+	}
+	defer c.Stop()
+	cfg := &apiConfig{
+		client:       c,
+		port:         9100,
+		tagSeparator: ",",
+	}
+	instances, err := getInstances(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error when obtaining instances: %s", err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("unexpected number of instances; got %d; want 2", len(instances))
+	}
+	ips, err := getIPAddresses(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error when obtaining ip addresses: %s", err)
+	}
+	labelss := getInstanceLabels(instances, ips, cfg.port, cfg.tagSeparator)
+	expectedLabels := []*promutils.Labels{
+		promutils.NewLabelsFromMap(map[string]string{
+			"__address__":                        "96.126.108.16:9100",
+			"__meta_linode_instance_id":          "26838044",
+			"__meta_linode_instance_label":       "prometheus-linode-sd-exporter-1",
+			"__meta_linode_image":                "linode/arch",
+			"__meta_linode_private_ipv4":         "192.168.170.51",
+			"__meta_linode_public_ipv4":          "96.126.108.16",
+			"__meta_linode_public_ipv6":          "2600:3c03::f03c:92ff:fe1a:1382",
+			"__meta_linode_private_ipv4_rdns":    "",
+			"__meta_linode_public_ipv4_rdns":     "li1028-16.members.linode.com",
+			"__meta_linode_public_ipv6_rdns":     "",
+			"__meta_linode_region":               "us-east",
+			"__meta_linode_type":                 "g6-standard-2",
+			"__meta_linode_status":               "running",
+			"__meta_linode_group":                "",
+			"__meta_linode_hypervisor":           "kvm",
+			"__meta_linode_backups":              "disabled",
+			"__meta_linode_specs_disk_bytes":     "85899345920",
+			"__meta_linode_specs_memory_bytes":   "4294967296",
+			"__meta_linode_specs_vcpus":          "2",
+			"__meta_linode_specs_transfer_bytes": "4194304000",
+			"__meta_linode_tags":                 ",monitoring,",
+			"__meta_linode_extra_ips":            ",192.168.170.52,",
+		}),
+	}
+	discoveryutils.TestEqualLabelss(t, labelss, expectedLabels)
+}
@@ -0,0 +1,97 @@
+package puppetdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+)
+
+var configMap = discoveryutils.NewConfigMap()
+
+type apiConfig struct {
+	client            *discoveryutils.Client
+	query             string
+	includeParameters bool
+	port              int
+}
+
+func newAPIConfig(sdc *SDConfig, baseDir string) (*apiConfig, error) {
+	if sdc.URL == "" {
+		return nil, fmt.Errorf("missing `url` option")
+	}
+	u, err := url.Parse(sdc.URL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse `url` %q: %w", sdc.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme in `url` %q; must be http or https", sdc.URL)
+	}
+	if sdc.Query == "" {
+		return nil, fmt.Errorf("missing `query` option")
+	}
+	ac, err := sdc.HTTPClientConfig.NewConfig(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse auth config: %w", err)
+	}
+	proxyAC, err := sdc.ProxyClientConfig.NewConfig(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse proxy auth config: %w", err)
+	}
+	apiServer := strings.TrimSuffix(sdc.URL, "/")
+	client, err := discoveryutils.NewClient(apiServer, ac, sdc.ProxyURL, proxyAC, &sdc.HTTPClientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create HTTP client for %q: %w", apiServer, err)
+	}
+	cfg := &apiConfig{
+		client:            client,
+		query:             sdc.Query,
+		includeParameters: sdc.IncludeParameters,
+		port:              sdc.Port,
+	}
+	if cfg.port == 0 {
+		cfg.port = 80
+	}
+	return cfg, nil
+}
+
+func getAPIConfig(sdc *SDConfig, baseDir string) (*apiConfig, error) {
+	v, err := configMap.Get(sdc, func() (interface{}, error) { return newAPIConfig(sdc, baseDir) })
+	if err != nil {
+		return nil, err
+	}
+	return v.(*apiConfig), nil
+}
+
+// resource represents PuppetDB resource.
+//
+// See https://www.puppet.com/docs/puppetdb/7/api/query/v4/resources.html#response-format
+type resource struct {
+	Certname    string                 `json:"certname"`
+	Resource    string                 `json:"resource"`
+	Type        string                 `json:"type"`
+	Title       string                 `json:"title"`
+	Exported    bool                   `json:"exported"`
+	Tags        []string               `json:"tags"`
+	File        string                 `json:"file"`
+	Environment string                 `json:"environment"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// getResources returns resources matching cfg.query.
+//
+// See https://www.puppet.com/docs/puppetdb/7/api/query/v4/overview.html
+func getResources(cfg *apiConfig) ([]resource, error) {
+	path := "/pdb/query/v4?query=" + url.QueryEscape(cfg.query)
+	data, err := cfg.client.GetAPIResponse(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query PuppetDB: %w", err)
+	}
+	var resources []resource
+	if err := json.Unmarshal(data, &resources); err != nil {
+		return nil, fmt.Errorf("cannot parse PuppetDB response %q: %w", data, err)
+	}
+	return resources, nil
+}
@@ -0,0 +1,118 @@
+package puppetdb
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/proxy"
+)
+
+// SDCheckInterval defines interval for targets refresh.
+var SDCheckInterval = flag.Duration("promscrape.puppetdbSDCheckInterval", time.Minute, "Interval for checking for changes in PuppetDB. "+
+	"This works only if puppetdb_sd_configs is configured in '-promscrape.config' file. "+
+	"See https://docs.victoriametrics.com/sd_configs.html#puppetdb_sd_configs for details")
+
+// SDConfig represents service discovery config for PuppetDB.
+//
+// See https://prometheus.io/docs/prometheus/latest/configuration/configuration/#puppetdb_sd_config
+type SDConfig struct {
+	URL               string                     `yaml:"url"`
+	Query             string                     `yaml:"query"`
+	IncludeParameters bool                       `yaml:"include_parameters,omitempty"`
+	HTTPClientConfig  promauth.HTTPClientConfig  `yaml:",inline"`
+	ProxyURL          *proxy.URL                 `yaml:"proxy_url,omitempty"`
+	ProxyClientConfig promauth.ProxyClientConfig `yaml:",inline"`
+	Port              int                        `yaml:"port,omitempty"`
+}
+
+// GetLabels returns PuppetDB resource labels according to sdc.
+func (sdc *SDConfig) GetLabels(baseDir string) ([]*promutils.Labels, error) {
+	cfg, err := getAPIConfig(sdc, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get API config: %w", err)
+	}
+	resources, err := getResources(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return getResourceLabels(resources, cfg), nil
+}
+
+func getResourceLabels(resources []resource, cfg *apiConfig) []*promutils.Labels {
+	ms := make([]*promutils.Labels, 0, len(resources))
+	for i := range resources {
+		r := &resources[i]
+		m := promutils.NewLabels(10)
+		m.Add("__address__", discoveryutils.JoinHostPort(r.Certname, cfg.port))
+		m.Add("__meta_puppetdb_query", cfg.query)
+		m.Add("__meta_puppetdb_certname", r.Certname)
+		m.Add("__meta_puppetdb_resource", r.Resource)
+		m.Add("__meta_puppetdb_type", r.Type)
+		m.Add("__meta_puppetdb_title", r.Title)
+		m.Add("__meta_puppetdb_exported", strconv.FormatBool(r.Exported))
+		m.Add("__meta_puppetdb_file", r.File)
+		m.Add("__meta_puppetdb_environment", r.Environment)
+		if len(r.Tags) > 0 {
+			// We surround the separated list with the separator as well. This way regular expressions
+			// in relabeling rules don't have to consider tag positions.
+			m.Add("__meta_puppetdb_tags", ","+strings.Join(r.Tags, ",")+",")
+		}
+		// Parameters aren't exposed by default, since they may contain secrets.
+		if cfg.includeParameters {
+			addParameterLabels(m, "__meta_puppetdb_parameter_", r.Parameters)
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+func addParameterLabels(m *promutils.Labels, prefix string, params map[string]interface{}) {
+	for k, v := range params {
+		var value string
+		switch t := v.(type) {
+		case map[string]interface{}:
+			addParameterLabels(m, prefix+k+"_", t)
+			continue
+		case []interface{}:
+			values := make([]string, len(t))
+			for i, item := range t {
+				values[i] = formatParameterValue(item)
+			}
+			value = strings.Join(values, ",")
+		default:
+			value = formatParameterValue(v)
+		}
+		if value == "" {
+			continue
+		}
+		m.Add(discoveryutils.SanitizeLabelName(prefix+k), value)
+	}
+}
+
+func formatParameterValue(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// MustStop stops further usage for sdc.
+func (sdc *SDConfig) MustStop() {
+	v := configMap.Delete(sdc)
+	if v != nil {
+		cfg := v.(*apiConfig)
+		cfg.client.Stop()
+	}
+}
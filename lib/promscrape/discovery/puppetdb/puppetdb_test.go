@@ -0,0 +1,117 @@
+package puppetdb
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+func TestGetResourceLabels(t *testing.T) {
+	const query = `resources { type = "Class" and title = "Prometheus::Node_exporter" }`
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pdb/query/v4" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "API path not found: %s", r.URL.Path)
+			return
+		}
+		if q := r.URL.Query().Get("query"); q != query {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "unexpected query: %q", q)
+			return
+		}
+		fmt.Fprint(w, `[
+  {
+    "certname": "edinburgh.example.com",
+    "environment": "prod",
+    "exported": false,
+    "file": "/etc/puppetlabs/code/environments/prod/modules/upstream/apache/manifests/init.pp",
+    "line": 384,
+    "parameters": {
+      "access_log": true,
+      "access_log_file": "ssl_access_log",
+      "additional_includes": [],
+      "directoryindex": "",
+      "docroot": "/var/www/html",
+      "ensure": "absent",
+      "options": ["Indexes", "FollowSymLinks", "MultiViews"],
+      "php_flags": {},
+      "labels": {"alias": "edinburgh"},
+      "scriptaliases": [{"alias": "/cgi-bin", "path": "/var/www/cgi-bin"}],
+      "port": 22,
+      "pi": 3.141592653589793,
+      "buckets": [0, 2, 5],
+      "coordinates": [60.13464726551357, -2.0513768021728893]
+    },
+    "resource": "49af83866dc5a1518968b68e58a25319107afe11",
+    "tags": ["roles::hypervisor", "apache", "apache::vhost", "class", "default-ssl"],
+    "title": "default-ssl",
+    "type": "Apache::Vhost"
+  }
+]`)
+	}))
+	defer testServer.Close()
+
+	c, err := discoveryutils.NewClient(testServer.URL, nil, nil, nil, &promauth.HTTPClientConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error at client create: %s", err)
+	}
+	defer c.Stop()
+
+	f := func(includeParameters bool, expectedLabels []*promutils.Labels) {
+		t.Helper()
+		cfg := &apiConfig{
+			client:            c,
+			query:             query,
+			includeParameters: includeParameters,
+			port:              9100,
+		}
+		resources, err := getResources(cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		labelss := getResourceLabels(resources, cfg)
+		discoveryutils.TestEqualLabelss(t, labelss, expectedLabels)
+	}
+
+	baseLabels := map[string]string{
+		"__address__":                 "edinburgh.example.com:9100",
+		"__meta_puppetdb_query":       query,
+		"__meta_puppetdb_certname":    "edinburgh.example.com",
+		"__meta_puppetdb_environment": "prod",
+		"__meta_puppetdb_exported":    "false",
+		"__meta_puppetdb_file":        "/etc/puppetlabs/code/environments/prod/modules/upstream/apache/manifests/init.pp",
+		"__meta_puppetdb_resource":    "49af83866dc5a1518968b68e58a25319107afe11",
+		"__meta_puppetdb_tags":        ",roles::hypervisor,apache,apache::vhost,class,default-ssl,",
+		"__meta_puppetdb_title":       "default-ssl",
+		"__meta_puppetdb_type":        "Apache::Vhost",
+	}
+
+	// Parameters are excluded by default
+	f(false, []*promutils.Labels{
+		promutils.NewLabelsFromMap(baseLabels),
+	})
+
+	// Parameters are included
+	m := make(map[string]string)
+	for k, v := range baseLabels {
+		m[k] = v
+	}
+	m["__meta_puppetdb_parameter_access_log"] = "true"
+	m["__meta_puppetdb_parameter_access_log_file"] = "ssl_access_log"
+	m["__meta_puppetdb_parameter_buckets"] = "0,2,5"
+	m["__meta_puppetdb_parameter_coordinates"] = "60.13464726551357,-2.0513768021728893"
+	m["__meta_puppetdb_parameter_docroot"] = "/var/www/html"
+	m["__meta_puppetdb_parameter_ensure"] = "absent"
+	m["__meta_puppetdb_parameter_labels_alias"] = "edinburgh"
+	m["__meta_puppetdb_parameter_options"] = "Indexes,FollowSymLinks,MultiViews"
+	m["__meta_puppetdb_parameter_pi"] = "3.141592653589793"
+	m["__meta_puppetdb_parameter_port"] = "22"
+	f(true, []*promutils.Labels{
+		promutils.NewLabelsFromMap(m),
+	})
+}
@@ -0,0 +1,143 @@
+package scaleway
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+)
+
+var configMap = discoveryutils.NewConfigMap()
+
+type apiConfig struct {
+	client        *discoveryutils.Client
+	secretKey     *promauth.Secret
+	secretKeyFile string
+	zone          string
+	projectID     string
+	nameFilter    string
+	tagsFilter    []string
+	port          int
+}
+
+func newAPIConfig(sdc *SDConfig, baseDir string) (*apiConfig, error) {
+	switch sdc.Role {
+	case "instance", "baremetal":
+	default:
+		return nil, fmt.Errorf("unexpected `role`: %q; must be one of `instance` or `baremetal`", sdc.Role)
+	}
+	if sdc.ProjectID == "" {
+		return nil, fmt.Errorf("missing `project_id` option")
+	}
+	if sdc.AccessKey == "" {
+		return nil, fmt.Errorf("missing `access_key` option")
+	}
+	if sdc.SecretKey == nil && sdc.SecretKeyFile == "" {
+		return nil, fmt.Errorf("missing `secret_key` or `secret_key_file` option")
+	}
+	if sdc.SecretKey != nil && sdc.SecretKeyFile != "" {
+		return nil, fmt.Errorf("only one of `secret_key` or `secret_key_file` option must be set")
+	}
+	ac, err := sdc.HTTPClientConfig.NewConfig(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse auth config: %w", err)
+	}
+	proxyAC, err := sdc.ProxyClientConfig.NewConfig(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse proxy auth config: %w", err)
+	}
+	apiServer := sdc.APIURL
+	if apiServer == "" {
+		apiServer = "https://api.scaleway.com"
+	}
+	apiServer = strings.TrimSuffix(apiServer, "/")
+	client, err := discoveryutils.NewClient(apiServer, ac, sdc.ProxyURL, proxyAC, &sdc.HTTPClientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create HTTP client for %q: %w", apiServer, err)
+	}
+	cfg := &apiConfig{
+		client:     client,
+		secretKey:  sdc.SecretKey,
+		zone:       sdc.Zone,
+		projectID:  sdc.ProjectID,
+		nameFilter: sdc.NameFilter,
+		tagsFilter: sdc.TagsFilter,
+		port:       sdc.Port,
+	}
+	if sdc.SecretKeyFile != "" {
+		cfg.secretKeyFile = fs.GetFilepath(baseDir, sdc.SecretKeyFile)
+	}
+	if cfg.zone == "" {
+		cfg.zone = "fr-par-1"
+	}
+	if cfg.port == 0 {
+		cfg.port = 80
+	}
+	return cfg, nil
+}
+
+func getAPIConfig(sdc *SDConfig, baseDir string) (*apiConfig, error) {
+	v, err := configMap.Get(sdc, func() (interface{}, error) { return newAPIConfig(sdc, baseDir) })
+	if err != nil {
+		return nil, err
+	}
+	return v.(*apiConfig), nil
+}
+
+// getSecretKey returns the secret key for Scaleway API.
+//
+// The secret key is re-read from secretKeyFile on every call, so it could be rotated without restart.
+func (cfg *apiConfig) getSecretKey() (string, error) {
+	if cfg.secretKeyFile == "" {
+		return cfg.secretKey.String(), nil
+	}
+	data, err := fs.ReadFileOrHTTP(cfg.secretKeyFile)
+	if err != nil {
+		return "", fmt.Errorf("cannot read `secret_key_file`: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// getAPIResponse returns response from Scaleway API at the given path.
+//
+// See https://www.scaleway.com/en/developers/api/#authentication
+func (cfg *apiConfig) getAPIResponse(path string) ([]byte, error) {
+	secretKey, err := cfg.getSecretKey()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.client.GetAPIResponseWithReqParams(path, func(req *http.Request) {
+		req.Header.Set("X-Auth-Token", secretKey)
+	})
+}
+
+// pageSize is the number of items to request per page from Scaleway list APIs.
+const pageSize = 50
+
+// getAllPages calls parse for the response of every page from Scaleway list API at the given path.
+//
+// parse must return the number of items in the response.
+// The iteration stops when the page contains less than pageSize items.
+func (cfg *apiConfig) getAllPages(path, pageSizeArg string, parse func(data []byte) (int, error)) error {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	for page := 1; ; page++ {
+		pagePath := fmt.Sprintf("%s%spage=%d&%s=%d", path, sep, page, pageSizeArg, pageSize)
+		data, err := cfg.getAPIResponse(pagePath)
+		if err != nil {
+			return fmt.Errorf("cannot fetch data from Scaleway API at %q: %w", pagePath, err)
+		}
+		n, err := parse(data)
+		if err != nil {
+			return fmt.Errorf("cannot parse Scaleway API response from %q: %w", pagePath, err)
+		}
+		if n < pageSize {
+			return nil
+		}
+	}
+}
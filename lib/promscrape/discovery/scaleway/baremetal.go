@@ -0,0 +1,188 @@
+package scaleway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+// baremetalServer represents Scaleway Elastic Metal server.
+//
+// See https://www.scaleway.com/en/developers/api/elastic-metal/#path-elastic-metal-servers-list-elastic-metal-servers-for-an-organization
+type baremetalServer struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Zone      string   `json:"zone"`
+	Status    string   `json:"status"`
+	ProjectID string   `json:"project_id"`
+	OfferID   string   `json:"offer_id"`
+	Tags      []string `json:"tags"`
+	Install   *struct {
+		OSID string `json:"os_id"`
+	} `json:"install"`
+	IPs []struct {
+		Address string `json:"address"`
+		Version string `json:"version"`
+	} `json:"ips"`
+}
+
+// baremetalOffer represents Scaleway Elastic Metal offer.
+type baremetalOffer struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// baremetalOS represents Scaleway Elastic Metal operating system.
+type baremetalOS struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func getBaremetalServerLabels(cfg *apiConfig) ([]*promutils.Labels, error) {
+	servers, err := getBaremetalServers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	offers, err := getBaremetalOffers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	oss, err := getBaremetalOSs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return getBaremetalLabels(servers, offers, oss, cfg.port), nil
+}
+
+func getBaremetalServers(cfg *apiConfig) ([]baremetalServer, error) {
+	args := url.Values{}
+	args.Set("project_id", cfg.projectID)
+	if cfg.nameFilter != "" {
+		args.Set("name", cfg.nameFilter)
+	}
+	for _, tag := range cfg.tagsFilter {
+		args.Add("tags", tag)
+	}
+	var servers []baremetalServer
+	err := cfg.getAllPages(getBaremetalPath(cfg, "servers")+"?"+args.Encode(), "page_size", func(data []byte) (int, error) {
+		var resp struct {
+			Servers []baremetalServer `json:"servers"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return 0, err
+		}
+		servers = append(servers, resp.Servers...)
+		return len(resp.Servers), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+func getBaremetalOffers(cfg *apiConfig) ([]baremetalOffer, error) {
+	var offers []baremetalOffer
+	err := cfg.getAllPages(getBaremetalPath(cfg, "offers"), "page_size", func(data []byte) (int, error) {
+		var resp struct {
+			Offers []baremetalOffer `json:"offers"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return 0, err
+		}
+		offers = append(offers, resp.Offers...)
+		return len(resp.Offers), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return offers, nil
+}
+
+func getBaremetalOSs(cfg *apiConfig) ([]baremetalOS, error) {
+	var oss []baremetalOS
+	err := cfg.getAllPages(getBaremetalPath(cfg, "os"), "page_size", func(data []byte) (int, error) {
+		var resp struct {
+			OS []baremetalOS `json:"os"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return 0, err
+		}
+		oss = append(oss, resp.OS...)
+		return len(resp.OS), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return oss, nil
+}
+
+func getBaremetalPath(cfg *apiConfig, resource string) string {
+	return fmt.Sprintf("/baremetal/v1/zones/%s/%s", url.PathEscape(cfg.zone), resource)
+}
+
+func getBaremetalLabels(servers []baremetalServer, offers []baremetalOffer, oss []baremetalOS, port int) []*promutils.Labels {
+	offerNames := make(map[string]string, len(offers))
+	for _, offer := range offers {
+		offerNames[offer.ID] = offer.Name
+	}
+	ossByID := make(map[string]*baremetalOS, len(oss))
+	for i := range oss {
+		ossByID[oss[i].ID] = &oss[i]
+	}
+	ms := make([]*promutils.Labels, 0, len(servers))
+	for i := range servers {
+		server := &servers[i]
+		m := promutils.NewLabels(12)
+		m.Add("__meta_scaleway_baremetal_id", server.ID)
+		m.Add("__meta_scaleway_baremetal_name", server.Name)
+		m.Add("__meta_scaleway_baremetal_zone", server.Zone)
+		m.Add("__meta_scaleway_baremetal_status", server.Status)
+		m.Add("__meta_scaleway_baremetal_project_id", server.ProjectID)
+		if offerName, ok := offerNames[server.OfferID]; ok {
+			m.Add("__meta_scaleway_baremetal_type", offerName)
+		}
+		if server.Install != nil {
+			if os := ossByID[server.Install.OSID]; os != nil {
+				m.Add("__meta_scaleway_baremetal_os_name", os.Name)
+				m.Add("__meta_scaleway_baremetal_os_version", os.Version)
+			}
+		}
+		if len(server.Tags) > 0 {
+			m.Add("__meta_scaleway_baremetal_tags", ","+strings.Join(server.Tags, ",")+",")
+		}
+		var publicIPv4, publicIPv6 string
+		for _, ip := range server.IPs {
+			switch ip.Version {
+			case "IPv4":
+				if publicIPv4 == "" {
+					publicIPv4 = ip.Address
+				}
+			case "IPv6":
+				if publicIPv6 == "" {
+					publicIPv6 = ip.Address
+				}
+			}
+		}
+		// Prefer IPv4 address for scraping.
+		addr := publicIPv4
+		if publicIPv4 != "" {
+			m.Add("__meta_scaleway_baremetal_public_ipv4", publicIPv4)
+		}
+		if publicIPv6 != "" {
+			m.Add("__meta_scaleway_baremetal_public_ipv6", publicIPv6)
+			if addr == "" {
+				addr = publicIPv6
+			}
+		}
+		if addr != "" {
+			m.Add("__address__", discoveryutils.JoinHostPort(addr, port))
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
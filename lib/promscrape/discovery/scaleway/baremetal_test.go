@@ -0,0 +1,91 @@
+package scaleway
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+func TestGetBaremetalServerLabels(t *testing.T) {
+	testServer := newTestServer(t, map[string]string{
+		"/baremetal/v1/zones/fr-par-1/servers?project_id=11111111-1111-1111-1111-111111111111&tags=prometheus&page=1&page_size=50": `{
+  "total_count": 2,
+  "servers": [
+    {
+      "id": "5a4e6f5a-e38d-4b5b-8b8a-c7e3f5d0d0a1",
+      "name": "scw-beautiful-hellman",
+      "zone": "fr-par-1",
+      "status": "ready",
+      "project_id": "11111111-1111-1111-1111-111111111111",
+      "offer_id": "3ab0dc29-2fd4-486e-88bf-d08fbf49214b",
+      "tags": ["prometheus", "baremetal"],
+      "install": {"os_id": "7e865c16-1a63-4dc7-8181-eb8c3c4d3ab5", "hostname": "scw-beautiful-hellman"},
+      "ips": [
+        {"id": "ip-1", "address": "2001:bc8:1234::1", "version": "IPv6"},
+        {"id": "ip-2", "address": "51.159.1.2", "version": "IPv4"}
+      ]
+    },
+    {
+      "id": "98fe5eb2-5abf-4a0c-9f86-57c1c7a0a8e1",
+      "name": "ipv6-only",
+      "zone": "fr-par-1",
+      "status": "delivering",
+      "project_id": "11111111-1111-1111-1111-111111111111",
+      "offer_id": "unknown-offer",
+      "tags": [],
+      "install": null,
+      "ips": [
+        {"id": "ip-3", "address": "2001:bc8:1234::2", "version": "IPv6"}
+      ]
+    }
+  ]
+}`,
+		"/baremetal/v1/zones/fr-par-1/offers?page=1&page_size=50": `{
+  "total_count": 1,
+  "offers": [
+    {"id": "3ab0dc29-2fd4-486e-88bf-d08fbf49214b", "name": "EM-B112X-SSD"}
+  ]
+}`,
+		"/baremetal/v1/zones/fr-par-1/os?page=1&page_size=50": `{
+  "total_count": 1,
+  "os": [
+    {"id": "7e865c16-1a63-4dc7-8181-eb8c3c4d3ab5", "name": "Ubuntu", "version": "22.04 LTS (Jammy Jellyfish)"}
+  ]
+}`,
+	})
+	defer testServer.Close()
+	cfg := newTestAPIConfig(t, testServer.URL)
+	defer cfg.client.Stop()
+
+	labelss, err := getBaremetalServerLabels(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedLabels := []*promutils.Labels{
+		promutils.NewLabelsFromMap(map[string]string{
+			"__address__":                           "51.159.1.2:80",
+			"__meta_scaleway_baremetal_id":          "5a4e6f5a-e38d-4b5b-8b8a-c7e3f5d0d0a1",
+			"__meta_scaleway_baremetal_name":        "scw-beautiful-hellman",
+			"__meta_scaleway_baremetal_zone":        "fr-par-1",
+			"__meta_scaleway_baremetal_status":      "ready",
+			"__meta_scaleway_baremetal_project_id":  "11111111-1111-1111-1111-111111111111",
+			"__meta_scaleway_baremetal_type":        "EM-B112X-SSD",
+			"__meta_scaleway_baremetal_os_name":     "Ubuntu",
+			"__meta_scaleway_baremetal_os_version":  "22.04 LTS (Jammy Jellyfish)",
+			"__meta_scaleway_baremetal_tags":        ",prometheus,baremetal,",
+			"__meta_scaleway_baremetal_public_ipv4": "51.159.1.2",
+			"__meta_scaleway_baremetal_public_ipv6": "2001:bc8:1234::1",
+		}),
+		promutils.NewLabelsFromMap(map[string]string{
+			"__address__":                           "[2001:bc8:1234::2]:80",
+			"__meta_scaleway_baremetal_id":          "98fe5eb2-5abf-4a0c-9f86-57c1c7a0a8e1",
+			"__meta_scaleway_baremetal_name":        "ipv6-only",
+			"__meta_scaleway_baremetal_zone":        "fr-par-1",
+			"__meta_scaleway_baremetal_status":      "delivering",
+			"__meta_scaleway_baremetal_project_id":  "11111111-1111-1111-1111-111111111111",
+			"__meta_scaleway_baremetal_public_ipv6": "2001:bc8:1234::2",
+		}),
+	}
+	discoveryutils.TestEqualLabelss(t, labelss, expectedLabels)
+}
@@ -0,0 +1,144 @@
+package scaleway
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+// instanceServer represents Scaleway Instance server.
+//
+// See https://www.scaleway.com/en/developers/api/instance/#path-instances-list-all-instances
+type instanceServer struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Organization   string   `json:"organization"`
+	Project        string   `json:"project"`
+	CommercialType string   `json:"commercial_type"`
+	Hostname       string   `json:"hostname"`
+	State          string   `json:"state"`
+	BootType       string   `json:"boot_type"`
+	Zone           string   `json:"zone"`
+	Tags           []string `json:"tags"`
+	PrivateIP      *string  `json:"private_ip"`
+	PublicIP       *struct {
+		Address string `json:"address"`
+	} `json:"public_ip"`
+	IPv6 *struct {
+		Address string `json:"address"`
+	} `json:"ipv6"`
+	Image *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Arch string `json:"arch"`
+	} `json:"image"`
+	Location *struct {
+		ClusterID    string `json:"cluster_id"`
+		HypervisorID string `json:"hypervisor_id"`
+		NodeID       string `json:"node_id"`
+	} `json:"location"`
+	SecurityGroup *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"security_group"`
+}
+
+func getInstanceServerLabels(cfg *apiConfig) ([]*promutils.Labels, error) {
+	servers, err := getInstanceServers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return getInstanceLabels(servers, cfg.port), nil
+}
+
+func getInstanceServers(cfg *apiConfig) ([]instanceServer, error) {
+	args := url.Values{}
+	args.Set("project", cfg.projectID)
+	if cfg.nameFilter != "" {
+		args.Set("name", cfg.nameFilter)
+	}
+	if len(cfg.tagsFilter) > 0 {
+		args.Set("tags", strings.Join(cfg.tagsFilter, ","))
+	}
+	path := "/instance/v1/zones/" + url.PathEscape(cfg.zone) + "/servers?" + args.Encode()
+	var servers []instanceServer
+	err := cfg.getAllPages(path, "per_page", func(data []byte) (int, error) {
+		var resp struct {
+			Servers []instanceServer `json:"servers"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return 0, err
+		}
+		servers = append(servers, resp.Servers...)
+		return len(resp.Servers), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+func getInstanceLabels(servers []instanceServer, port int) []*promutils.Labels {
+	var ms []*promutils.Labels
+	for i := range servers {
+		server := &servers[i]
+		// Only servers with private ip are returned in order to be consistent with Prometheus.
+		if server.PrivateIP == nil {
+			continue
+		}
+		m := promutils.NewLabels(24)
+		m.Add("__address__", discoveryutils.JoinHostPort(*server.PrivateIP, port))
+		m.Add("__meta_scaleway_instance_boot_type", server.BootType)
+		m.Add("__meta_scaleway_instance_hostname", server.Hostname)
+		m.Add("__meta_scaleway_instance_id", server.ID)
+		m.Add("__meta_scaleway_instance_name", server.Name)
+		m.Add("__meta_scaleway_instance_organization_id", server.Organization)
+		m.Add("__meta_scaleway_instance_private_ipv4", *server.PrivateIP)
+		m.Add("__meta_scaleway_instance_project_id", server.Project)
+		m.Add("__meta_scaleway_instance_status", server.State)
+		m.Add("__meta_scaleway_instance_type", server.CommercialType)
+		m.Add("__meta_scaleway_instance_zone", server.Zone)
+		if region := getRegion(server.Zone); region != "" {
+			m.Add("__meta_scaleway_instance_region", region)
+		}
+		if image := server.Image; image != nil {
+			m.Add("__meta_scaleway_instance_image_arch", image.Arch)
+			m.Add("__meta_scaleway_instance_image_id", image.ID)
+			m.Add("__meta_scaleway_instance_image_name", image.Name)
+		}
+		if location := server.Location; location != nil {
+			m.Add("__meta_scaleway_instance_location_cluster_id", location.ClusterID)
+			m.Add("__meta_scaleway_instance_location_hypervisor_id", location.HypervisorID)
+			m.Add("__meta_scaleway_instance_location_node_id", location.NodeID)
+		}
+		if sg := server.SecurityGroup; sg != nil {
+			m.Add("__meta_scaleway_instance_security_group_id", sg.ID)
+			m.Add("__meta_scaleway_instance_security_group_name", sg.Name)
+		}
+		if server.PublicIP != nil {
+			m.Add("__meta_scaleway_instance_public_ipv4", server.PublicIP.Address)
+		}
+		if server.IPv6 != nil {
+			m.Add("__meta_scaleway_instance_public_ipv6", server.IPv6.Address)
+		}
+		if len(server.Tags) > 0 {
+			m.Add("__meta_scaleway_instance_tags", ","+strings.Join(server.Tags, ",")+",")
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+// getRegion returns Scaleway region for the given zone, e.g. `fr-par` for `fr-par-1` zone.
+//
+// An empty string is returned if the zone has unexpected format.
+func getRegion(zone string) string {
+	n := strings.LastIndexByte(zone, '-')
+	if n <= 0 {
+		return ""
+	}
+	return zone[:n]
+}
@@ -0,0 +1,161 @@
+package scaleway
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+)
+
+const testSecretKey = "6d6579e5-a5b9-49fc-a35f-b4feb9b87301"
+
+func newTestServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") != testSecretKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		resp, ok := responses[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "API path not found: %s", r.URL.RequestURI())
+			return
+		}
+		fmt.Fprint(w, resp)
+	}))
+}
+
+func newTestAPIConfig(t *testing.T, serverURL string) *apiConfig {
+	t.Helper()
+	c, err := discoveryutils.NewClient(serverURL, nil, nil, nil, &promauth.HTTPClientConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error at client create: %s", err)
+	}
+	return &apiConfig{
+		client:     c,
+		secretKey:  promauth.NewSecret(testSecretKey),
+		zone:       "fr-par-1",
+		projectID:  "11111111-1111-1111-1111-111111111111",
+		tagsFilter: []string{"prometheus"},
+		port:       80,
+	}
+}
+
+func TestGetInstanceServerLabels(t *testing.T) {
+	testServer := newTestServer(t, map[string]string{
+		"/instance/v1/zones/fr-par-1/servers?project=11111111-1111-1111-1111-111111111111&tags=prometheus&page=1&per_page=50": `{
+  "servers": [
+    {
+      "id": "93c18a61-b681-49d0-a1cc-62b43883ae89",
+      "name": "scw-nervous-shirley",
+      "organization": "cb334986-b054-4725-9d3a-40850fdc6015",
+      "project": "cb334986-b054-4725-9d3a-40850fdc6015",
+      "hostname": "scw-nervous-shirley",
+      "commercial_type": "DEV1-S",
+      "boot_type": "local",
+      "state": "running",
+      "zone": "fr-par-1",
+      "tags": ["prometheus", "node"],
+      "private_ip": "10.70.60.57",
+      "public_ip": {"id": "ip-1", "address": "51.158.183.115"},
+      "ipv6": {"address": "2001:bc8:630:1e1c::1", "gateway": "2001:bc8:630:1e1c::", "netmask": "64"},
+      "image": {"id": "45a86b35-eca6-4055-9b34-ca69845da146", "name": "Ubuntu 20.04 Focal Fossa", "arch": "x86_64"},
+      "location": {"cluster_id": "40", "hypervisor_id": "1601", "node_id": "29", "platform_id": "14", "zone_id": "par1"},
+      "security_group": {"id": "984414da-9fc2-49c0-a925-fed6266fe092", "name": "Default security group"}
+    },
+    {
+      "id": "5b6198b4-c677-41b5-9c05-04557264ae1f",
+      "name": "scw-quizzical-feistel",
+      "organization": "cb334986-b054-4725-9d3a-40850fdc6015",
+      "project": "cb334986-b054-4725-9d3a-40850fdc6015",
+      "hostname": "scw-quizzical-feistel",
+      "commercial_type": "DEV1-S",
+      "boot_type": "local",
+      "state": "stopped",
+      "zone": "fr-par-1",
+      "tags": [],
+      "private_ip": null,
+      "public_ip": null,
+      "ipv6": null,
+      "image": null,
+      "location": null,
+      "security_group": null
+    }
+  ]
+}`,
+	})
+	defer testServer.Close()
+	cfg := newTestAPIConfig(t, testServer.URL)
+	defer cfg.client.Stop()
+
+	labelss, err := getInstanceServerLabels(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedLabels := []*promutils.Labels{
+		promutils.NewLabelsFromMap(map[string]string{
+			"__address__":                                     "10.70.60.57:80",
+			"__meta_scaleway_instance_boot_type":              "local",
+			"__meta_scaleway_instance_hostname":               "scw-nervous-shirley",
+			"__meta_scaleway_instance_id":                     "93c18a61-b681-49d0-a1cc-62b43883ae89",
+			"__meta_scaleway_instance_image_arch":             "x86_64",
+			"__meta_scaleway_instance_image_id":               "45a86b35-eca6-4055-9b34-ca69845da146",
+			"__meta_scaleway_instance_image_name":             "Ubuntu 20.04 Focal Fossa",
+			"__meta_scaleway_instance_location_cluster_id":    "40",
+			"__meta_scaleway_instance_location_hypervisor_id": "1601",
+			"__meta_scaleway_instance_location_node_id":       "29",
+			"__meta_scaleway_instance_name":                   "scw-nervous-shirley",
+			"__meta_scaleway_instance_organization_id":        "cb334986-b054-4725-9d3a-40850fdc6015",
+			"__meta_scaleway_instance_private_ipv4":           "10.70.60.57",
+			"__meta_scaleway_instance_project_id":             "cb334986-b054-4725-9d3a-40850fdc6015",
+			"__meta_scaleway_instance_public_ipv4":            "51.158.183.115",
+			"__meta_scaleway_instance_public_ipv6":            "2001:bc8:630:1e1c::1",
+			"__meta_scaleway_instance_region":                 "fr-par",
+			"__meta_scaleway_instance_security_group_id":      "984414da-9fc2-49c0-a925-fed6266fe092",
+			"__meta_scaleway_instance_security_group_name":    "Default security group",
+			"__meta_scaleway_instance_status":                 "running",
+			"__meta_scaleway_instance_tags":                   ",prometheus,node,",
+			"__meta_scaleway_instance_type":                   "DEV1-S",
+			"__meta_scaleway_instance_zone":                   "fr-par-1",
+		}),
+	}
+	discoveryutils.TestEqualLabelss(t, labelss, expectedLabels)
+}
+
+func TestGetSecretKeyFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret_key")
+	if err := os.WriteFile(path, []byte(testSecretKey+"\n"), 0600); err != nil {
+		t.Fatalf("cannot write secret key file: %s", err)
+	}
+	cfg := &apiConfig{
+		secretKeyFile: path,
+	}
+	secretKey, err := cfg.getSecretKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if secretKey != testSecretKey {
+		t.Fatalf("unexpected secret key; got %q; want %q", secretKey, testSecretKey)
+	}
+}
+
+func TestGetRegion(t *testing.T) {
+	f := func(zone, regionExpected string) {
+		t.Helper()
+		region := getRegion(zone)
+		if region != regionExpected {
+			t.Fatalf("unexpected region for zone %q; got %q; want %q", zone, region, regionExpected)
+		}
+	}
+	f("fr-par-1", "fr-par")
+	f("nl-ams-3", "nl-ams")
+	f("", "")
+	f("foo", "")
+}
@@ -0,0 +1,115 @@
+package vultr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+)
+
+var configMap = discoveryutils.NewConfigMap()
+
+type apiConfig struct {
+	client *discoveryutils.Client
+	port   int
+}
+
+func newAPIConfig(sdc *SDConfig, baseDir string) (*apiConfig, error) {
+	ac, err := sdc.HTTPClientConfig.NewConfig(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse auth config: %w", err)
+	}
+	proxyAC, err := sdc.ProxyClientConfig.NewConfig(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse proxy auth config: %w", err)
+	}
+	apiServer := "https://api.vultr.com"
+	client, err := discoveryutils.NewClient(apiServer, ac, sdc.ProxyURL, proxyAC, &sdc.HTTPClientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create HTTP client for %q: %w", apiServer, err)
+	}
+	cfg := &apiConfig{
+		client: client,
+		port:   sdc.Port,
+	}
+	if cfg.port == 0 {
+		cfg.port = 80
+	}
+	return cfg, nil
+}
+
+func getAPIConfig(sdc *SDConfig, baseDir string) (*apiConfig, error) {
+	v, err := configMap.Get(sdc, func() (interface{}, error) { return newAPIConfig(sdc, baseDir) })
+	if err != nil {
+		return nil, err
+	}
+	return v.(*apiConfig), nil
+}
+
+// getInstances returns all the instances from Vultr API.
+//
+// See https://www.vultr.com/api/#tag/instances/operation/list-instances
+func getInstances(cfg *apiConfig) ([]instance, error) {
+	var instances []instance
+	cursor := ""
+	for {
+		path := "/v2/instances?per_page=100"
+		if cursor != "" {
+			path += "&cursor=" + url.QueryEscape(cursor)
+		}
+		data, err := cfg.client.GetAPIResponse(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot fetch instances from Vultr API: %w", err)
+		}
+		resp, err := parseInstancesResponse(data)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, resp.Instances...)
+		cursor = resp.Meta.Links.Next
+		if cursor == "" {
+			return instances, nil
+		}
+	}
+}
+
+// listInstancesResponse represents the response from Vultr list instances API.
+//
+// See https://www.vultr.com/api/#tag/instances/operation/list-instances
+type listInstancesResponse struct {
+	Instances []instance `json:"instances"`
+	Meta      struct {
+		Links struct {
+			Next string `json:"next"`
+		} `json:"links"`
+	} `json:"meta"`
+}
+
+type instance struct {
+	ID               string   `json:"id"`
+	Label            string   `json:"label"`
+	OS               string   `json:"os"`
+	OSID             int      `json:"os_id"`
+	Region           string   `json:"region"`
+	Plan             string   `json:"plan"`
+	MainIP           string   `json:"main_ip"`
+	V6MainIP         string   `json:"v6_main_ip"`
+	InternalIP       string   `json:"internal_ip"`
+	Features         []string `json:"features"`
+	Tags             []string `json:"tags"`
+	Hostname         string   `json:"hostname"`
+	ServerStatus     string   `json:"server_status"`
+	VCPUCount        int      `json:"vcpu_count"`
+	RAM              int      `json:"ram"`
+	AllowedBandwidth int      `json:"allowed_bandwidth"`
+	Disk             int      `json:"disk"`
+}
+
+func parseInstancesResponse(data []byte) (*listInstancesResponse, error) {
+	var resp listInstancesResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("cannot parse Vultr API response %q: %w", data, err)
+	}
+	return &resp, nil
+}
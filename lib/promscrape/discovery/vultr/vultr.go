@@ -0,0 +1,82 @@
+package vultr
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promauth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promscrape/discoveryutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/proxy"
+)
+
+// SDCheckInterval defines interval for targets refresh.
+var SDCheckInterval = flag.Duration("promscrape.vultrSDCheckInterval", time.Minute, "Interval for checking for changes in Vultr. "+
+	"This works only if vultr_sd_configs is configured in '-promscrape.config' file. "+
+	"See https://docs.victoriametrics.com/sd_configs.html#vultr_sd_configs for details")
+
+// SDConfig represents service discovery config for Vultr.
+//
+// See https://prometheus.io/docs/prometheus/latest/configuration/configuration/#vultr_sd_config
+type SDConfig struct {
+	HTTPClientConfig  promauth.HTTPClientConfig  `yaml:",inline"`
+	ProxyURL          *proxy.URL                 `yaml:"proxy_url,omitempty"`
+	ProxyClientConfig promauth.ProxyClientConfig `yaml:",inline"`
+	Port              int                        `yaml:"port,omitempty"`
+}
+
+// GetLabels returns Vultr instance labels according to sdc.
+func (sdc *SDConfig) GetLabels(baseDir string) ([]*promutils.Labels, error) {
+	cfg, err := getAPIConfig(sdc, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get API config: %w", err)
+	}
+	instances, err := getInstances(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return getInstanceLabels(instances, cfg.port), nil
+}
+
+func getInstanceLabels(instances []instance, port int) []*promutils.Labels {
+	ms := make([]*promutils.Labels, 0, len(instances))
+	for _, inst := range instances {
+		m := promutils.NewLabels(18)
+		m.Add("__address__", discoveryutils.JoinHostPort(inst.MainIP, port))
+		m.Add("__meta_vultr_instance_id", inst.ID)
+		m.Add("__meta_vultr_instance_label", inst.Label)
+		m.Add("__meta_vultr_instance_os", inst.OS)
+		m.Add("__meta_vultr_instance_os_id", strconv.Itoa(inst.OSID))
+		m.Add("__meta_vultr_instance_region", inst.Region)
+		m.Add("__meta_vultr_instance_plan", inst.Plan)
+		m.Add("__meta_vultr_instance_main_ip", inst.MainIP)
+		m.Add("__meta_vultr_instance_main_ipv6", inst.V6MainIP)
+		m.Add("__meta_vultr_instance_internal_ip", inst.InternalIP)
+		m.Add("__meta_vultr_instance_hostname", inst.Hostname)
+		m.Add("__meta_vultr_instance_server_status", inst.ServerStatus)
+		m.Add("__meta_vultr_instance_vcpu_count", strconv.Itoa(inst.VCPUCount))
+		m.Add("__meta_vultr_instance_ram_mb", strconv.Itoa(inst.RAM))
+		m.Add("__meta_vultr_instance_allowed_bandwidth_gb", strconv.Itoa(inst.AllowedBandwidth))
+		m.Add("__meta_vultr_instance_disk_gb", strconv.Itoa(inst.Disk))
+		if len(inst.Features) > 0 {
+			m.Add("__meta_vultr_instance_features", ","+strings.Join(inst.Features, ",")+",")
+		}
+		if len(inst.Tags) > 0 {
+			m.Add("__meta_vultr_instance_tags", ","+strings.Join(inst.Tags, ",")+",")
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+// MustStop stops further usage for sdc.
+func (sdc *SDConfig) MustStop() {
+	v := configMap.Delete(sdc)
+	if v != nil {
+		cfg := v.(*apiConfig)
+		cfg.client.Stop()
+	}
+}
@@ -140,6 +140,10 @@ type ScrapeWork struct {
 	// Optional limit on the number of unique series the scrape target can expose.
 	SeriesLimit int
 
+	// Optional budget on the number of unique series the scrape target can expose.
+	// Series, which were already accepted, are kept, while newly appearing series are dropped when the budget is exhausted.
+	SeriesBudget int
+
 	// Whether to process stale markers for the given target.
 	// See https://docs.victoriametrics.com/vmagent.html#prometheus-staleness-markers
 	NoStaleMarkers bool
@@ -147,6 +151,14 @@ type ScrapeWork struct {
 	// The Tenant Info
 	AuthToken *auth.Token
 
+	// ScrapeProtocols contains the list of protocols to negotiate with the scrape target in the order of preference.
+	// The default Accept header for Prometheus text exposition format is used if the list is empty.
+	ScrapeProtocols []string
+
+	// NativeHistogramFormat is the format for converting native histograms scraped via protobuf protocol.
+	// See lib/protoparser/prometheus.NativeHistogramFormat*. NativeHistogramFormatVMRange is used if it is empty.
+	NativeHistogramFormat string
+
 	// The original 'job_name'
 	jobNameOriginal string
 }
@@ -168,12 +180,12 @@ func (sw *ScrapeWork) key() string {
 		"ExternalLabels=%s, "+
 		"ProxyURL=%s, ProxyAuthConfig=%s, AuthConfig=%s, MetricRelabelConfigs=%q, "+
 		"SampleLimit=%d, DisableCompression=%v, DisableKeepAlive=%v, StreamParse=%v, "+
-		"ScrapeAlignInterval=%s, ScrapeOffset=%s, SeriesLimit=%d, NoStaleMarkers=%v",
+		"ScrapeAlignInterval=%s, ScrapeOffset=%s, SeriesLimit=%d, SeriesBudget=%d, NoStaleMarkers=%v, ScrapeProtocols=%q, NativeHistogramFormat=%s",
 		sw.jobNameOriginal, sw.ScrapeURL, sw.ScrapeInterval, sw.ScrapeTimeout, sw.HonorLabels, sw.HonorTimestamps, sw.DenyRedirects, sw.Labels.String(),
 		sw.ExternalLabels.String(),
 		sw.ProxyURL.String(), sw.ProxyAuthConfig.String(), sw.AuthConfig.String(), sw.MetricRelabelConfigs.String(),
 		sw.SampleLimit, sw.DisableCompression, sw.DisableKeepAlive, sw.StreamParse,
-		sw.ScrapeAlignInterval, sw.ScrapeOffset, sw.SeriesLimit, sw.NoStaleMarkers)
+		sw.ScrapeAlignInterval, sw.ScrapeOffset, sw.SeriesLimit, sw.SeriesBudget, sw.NoStaleMarkers, sw.ScrapeProtocols, sw.NativeHistogramFormat)
 	return key
 }
 
@@ -210,6 +222,9 @@ type scrapeWork struct {
 	// Optional limiter on the number of unique series per scrape target.
 	seriesLimiter *bloomfilter.Limiter
 
+	// Optional budget on the number of unique series per scrape target.
+	seriesBudget *seriesBudget
+
 	// prevBodyLen contains the previous response body length for the given scrape work.
 	// It is used as a hint in order to reduce memory usage for body buffers.
 	prevBodyLen int
@@ -347,6 +362,7 @@ func (sw *scrapeWork) run(stopCh <-chan struct{}, globalStopCh <-chan struct{})
 				sw.seriesLimiter.MustStop()
 				sw.seriesLimiter = nil
 			}
+			sw.seriesBudget = nil
 			return
 		case tt := <-ticker.C:
 			t := tt.UnixNano() / 1e6
@@ -498,6 +514,11 @@ func (sw *scrapeWork) processScrapedData(scrapeTimestamp, realTimestamp int64, b
 	if sw.seriesLimitExceeded || !areIdenticalSeries {
 		samplesDropped = sw.applySeriesLimit(wc)
 	}
+	seriesDropped := 0
+	mustApplySeriesBudget := sw.mustApplySeriesBudget(areIdenticalSeries)
+	if mustApplySeriesBudget {
+		seriesDropped = sw.applySeriesBudget(wc)
+	}
 	am := &autoMetrics{
 		up:                        up,
 		scrapeDurationSeconds:     duration,
@@ -505,6 +526,7 @@ func (sw *scrapeWork) processScrapedData(scrapeTimestamp, realTimestamp int64, b
 		samplesPostRelabeling:     samplesPostRelabeling,
 		seriesAdded:               seriesAdded,
 		seriesLimitSamplesDropped: samplesDropped,
+		seriesBudgetDropped:       seriesDropped,
 	}
 	sw.addAutoMetrics(am, wc, scrapeTimestamp)
 	sw.pushData(sw.Config.AuthToken, &wc.writeRequest)
@@ -525,7 +547,11 @@ func (sw *scrapeWork) processScrapedData(scrapeTimestamp, realTimestamp int64, b
 		sw.storeLastScrape(body.B)
 	}
 	sw.finalizeLastScrape()
-	tsmGlobal.Update(sw, up == 1, realTimestamp, int64(duration*1000), samplesScraped, err)
+	if mustApplySeriesBudget && up == 1 {
+		// Stale markers for the series missing in the scrape are already sent, so they can be removed from the budget.
+		sw.seriesBudget.Finalize()
+	}
+	tsmGlobal.Update(sw, up == 1, realTimestamp, int64(duration*1000), samplesScraped, seriesDropped, err)
 	return !mustSwitchToStreamParse, err
 }
 
@@ -577,7 +603,9 @@ func (sw *scrapeWork) scrapeStream(scrapeTimestamp, realTimestamp int64) error {
 	lastScrape := sw.loadLastScrape()
 	bodyString := ""
 	areIdenticalSeries := true
+	mustApplySeriesBudget := false
 	samplesDropped := 0
+	seriesDropped := 0
 	sr, err := sw.GetStreamReader()
 	if err != nil {
 		err = fmt.Errorf("cannot read data: %s", err)
@@ -587,6 +615,7 @@ func (sw *scrapeWork) scrapeStream(scrapeTimestamp, realTimestamp int64) error {
 		if err == nil {
 			bodyString = bytesutil.ToUnsafeString(sbr.body)
 			areIdenticalSeries = sw.areIdenticalSeries(lastScrape, bodyString)
+			mustApplySeriesBudget = sw.mustApplySeriesBudget(areIdenticalSeries)
 			err = stream.Parse(&sbr, scrapeTimestamp, false, false, func(rows []parser.Row) error {
 				mu.Lock()
 				defer mu.Unlock()
@@ -604,6 +633,9 @@ func (sw *scrapeWork) scrapeStream(scrapeTimestamp, realTimestamp int64) error {
 				if sw.seriesLimitExceeded || !areIdenticalSeries {
 					samplesDropped += sw.applySeriesLimit(wc)
 				}
+				if mustApplySeriesBudget {
+					seriesDropped += sw.applySeriesBudget(wc)
+				}
 				// Push the collected rows to sw before returning from the callback, since they cannot be held
 				// after returning from the callback - this will result in data race.
 				// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/825#issuecomment-723198247
@@ -641,6 +673,7 @@ func (sw *scrapeWork) scrapeStream(scrapeTimestamp, realTimestamp int64) error {
 		samplesPostRelabeling:     samplesPostRelabeling,
 		seriesAdded:               seriesAdded,
 		seriesLimitSamplesDropped: samplesDropped,
+		seriesBudgetDropped:       seriesDropped,
 	}
 	sw.addAutoMetrics(am, wc, scrapeTimestamp)
 	sw.pushData(sw.Config.AuthToken, &wc.writeRequest)
@@ -655,16 +688,21 @@ func (sw *scrapeWork) scrapeStream(scrapeTimestamp, realTimestamp int64) error {
 		sw.storeLastScrape(sbr.body)
 	}
 	sw.finalizeLastScrape()
-	tsmGlobal.Update(sw, up == 1, realTimestamp, int64(duration*1000), samplesScraped, err)
+	if mustApplySeriesBudget && up == 1 {
+		// Stale markers for the series missing in the scrape are already sent, so they can be removed from the budget.
+		sw.seriesBudget.Finalize()
+	}
+	tsmGlobal.Update(sw, up == 1, realTimestamp, int64(duration*1000), samplesScraped, seriesDropped, err)
 	// Do not track active series in streaming mode, since this may need too big amounts of memory
 	// when the target exports too big number of metrics.
 	return err
 }
 
 func (sw *scrapeWork) areIdenticalSeries(prevData, currData string) bool {
-	if sw.Config.NoStaleMarkers && sw.Config.SeriesLimit <= 0 {
+	if sw.Config.NoStaleMarkers && sw.Config.SeriesLimit <= 0 && sw.Config.SeriesBudget <= 0 {
 		// Do not spend CPU time on tracking the changes in series if stale markers are disabled.
 		// The check for series_limit is needed for https://github.com/VictoriaMetrics/VictoriaMetrics/issues/3660
+		// The check for series_budget is needed for detecting series, which must be removed from the budget.
 		return true
 	}
 	return parser.AreIdenticalSeriesFast(prevData, currData)
@@ -721,6 +759,9 @@ type writeRequestCtx struct {
 	writeRequest prompbmarshal.WriteRequest
 	labels       []prompbmarshal.Label
 	samples      []prompbmarshal.Sample
+
+	exemplars      []prompbmarshal.Exemplar
+	exemplarLabels []prompbmarshal.Label
 }
 
 func (wc *writeRequestCtx) reset() {
@@ -740,6 +781,14 @@ func (wc *writeRequestCtx) resetNoRows() {
 	wc.labels = wc.labels[:0]
 
 	wc.samples = wc.samples[:0]
+
+	for i := range wc.exemplars {
+		wc.exemplars[i].Labels = nil
+	}
+	wc.exemplars = wc.exemplars[:0]
+
+	promrelabel.CleanLabels(wc.exemplarLabels)
+	wc.exemplarLabels = wc.exemplarLabels[:0]
 }
 
 var writeRequestCtxPool leveledWriteRequestCtxPool
@@ -778,6 +827,56 @@ func (sw *scrapeWork) applySeriesLimit(wc *writeRequestCtx) int {
 	return samplesDropped
 }
 
+// mustApplySeriesBudget returns true if series_budget must be applied to the current scrape.
+//
+// There is no need in applying the budget if the scrape contains the same series as the previous scrape,
+// which fit the budget.
+func (sw *scrapeWork) mustApplySeriesBudget(areIdenticalSeries bool) bool {
+	if sw.Config.SeriesBudget <= 0 {
+		return false
+	}
+	if sw.seriesBudget == nil {
+		sw.seriesBudget = newSeriesBudget(sw.Config.SeriesBudget)
+		return true
+	}
+	return sw.seriesBudget.Exceeded() || !areIdenticalSeries
+}
+
+// applySeriesBudget drops series from wc, which do not fit series_budget.
+//
+// It returns the number of dropped series.
+func (sw *scrapeWork) applySeriesBudget(wc *writeRequestCtx) int {
+	sb := sw.seriesBudget
+	dstSeries := wc.writeRequest.Timeseries[:0]
+	seriesDropped := 0
+	for _, ts := range wc.writeRequest.Timeseries {
+		h := sw.getLabelsHash(ts.Labels)
+		if !sb.Add(h) {
+			seriesDropped++
+			continue
+		}
+		dstSeries = append(dstSeries, ts)
+	}
+	prompbmarshal.ResetTimeSeries(wc.writeRequest.Timeseries[len(dstSeries):])
+	wc.writeRequest.Timeseries = dstSeries
+	return seriesDropped
+}
+
+// dropSeriesOutsideBudget drops series from wc, which weren't accepted by series_budget.
+func (sw *scrapeWork) dropSeriesOutsideBudget(wc *writeRequestCtx) {
+	sb := sw.seriesBudget
+	dstSeries := wc.writeRequest.Timeseries[:0]
+	for _, ts := range wc.writeRequest.Timeseries {
+		h := sw.getLabelsHash(ts.Labels)
+		if !sb.Has(h) {
+			continue
+		}
+		dstSeries = append(dstSeries, ts)
+	}
+	prompbmarshal.ResetTimeSeries(wc.writeRequest.Timeseries[len(dstSeries):])
+	wc.writeRequest.Timeseries = dstSeries
+}
+
 var sendStaleSeriesConcurrencyLimitCh = make(chan struct{}, cgroup.AvailableCPUs())
 
 func (sw *scrapeWork) sendStaleSeries(lastScrape, currScrape string, timestamp int64, addAutoSeries bool) {
@@ -819,6 +918,10 @@ func (sw *scrapeWork) sendStaleSeries(lastScrape, currScrape string, timestamp i
 			if sw.seriesLimitExceeded {
 				sw.applySeriesLimit(wc)
 			}
+			// Do not send stale markers for series dropped by series_budget, since they weren't sent to remote storage.
+			if sw.seriesBudget != nil {
+				sw.dropSeriesOutsideBudget(wc)
+			}
 			// Push the collected rows to sw before returning from the callback, since they cannot be held
 			// after returning from the callback - this will result in data race.
 			// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/825#issuecomment-723198247
@@ -870,6 +973,7 @@ type autoMetrics struct {
 	samplesPostRelabeling     int
 	seriesAdded               int
 	seriesLimitSamplesDropped int
+	seriesBudgetDropped       int
 }
 
 func isAutoMetric(s string) bool {
@@ -878,7 +982,8 @@ func isAutoMetric(s string) bool {
 		"scrape_samples_post_metric_relabeling", "scrape_series_added",
 		"scrape_timeout_seconds", "scrape_samples_limit",
 		"scrape_series_limit_samples_dropped", "scrape_series_limit",
-		"scrape_series_current":
+		"scrape_series_current", "scrape_series_budget",
+		"scrape_series_dropped":
 		return true
 	}
 	return false
@@ -901,6 +1006,10 @@ func (sw *scrapeWork) addAutoMetrics(am *autoMetrics, wc *writeRequestCtx, times
 		sw.addAutoTimeseries(wc, "scrape_series_limit", float64(sl.MaxItems()), timestamp)
 		sw.addAutoTimeseries(wc, "scrape_series_current", float64(sl.CurrentItems()), timestamp)
 	}
+	if sb := sw.seriesBudget; sb != nil {
+		sw.addAutoTimeseries(wc, "scrape_series_budget", float64(sb.MaxSeries()), timestamp)
+		sw.addAutoTimeseries(wc, "scrape_series_dropped", float64(am.seriesBudgetDropped), timestamp)
+	}
 }
 
 // addAutoTimeseries adds automatically generated time series with the given name, value and timestamp.
@@ -958,10 +1067,32 @@ func (sw *scrapeWork) addRowToTimeseries(wc *writeRequestCtx, r *parser.Row, tim
 		Value:     r.Value,
 		Timestamp: sampleTimestamp,
 	})
+	var exemplars []prompbmarshal.Exemplar
+	if e := &r.Exemplar; len(e.Tags) > 0 {
+		exemplarLabelsLen := len(wc.exemplarLabels)
+		for i := range e.Tags {
+			tag := &e.Tags[i]
+			wc.exemplarLabels = append(wc.exemplarLabels, prompbmarshal.Label{
+				Name:  tag.Key,
+				Value: tag.Value,
+			})
+		}
+		exemplarTimestamp := e.Timestamp
+		if exemplarTimestamp == 0 {
+			exemplarTimestamp = sampleTimestamp
+		}
+		wc.exemplars = append(wc.exemplars, prompbmarshal.Exemplar{
+			Labels:    wc.exemplarLabels[exemplarLabelsLen:],
+			Value:     e.Value,
+			Timestamp: exemplarTimestamp,
+		})
+		exemplars = wc.exemplars[len(wc.exemplars)-1:]
+	}
 	wr := &wc.writeRequest
 	wr.Timeseries = append(wr.Timeseries, prompbmarshal.TimeSeries{
-		Labels:  wc.labels[labelsLen:],
-		Samples: wc.samples[len(wc.samples)-1:],
+		Labels:    wc.labels[labelsLen:],
+		Samples:   wc.samples[len(wc.samples)-1:],
+		Exemplars: exemplars,
 	})
 }
 
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/auth"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/decimal"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promrelabel"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/promutils"
@@ -31,6 +32,8 @@ func TestIsAutoMetric(t *testing.T) {
 	f("scrape_samples_limit", true)
 	f("scrape_series_limit_samples_dropped", true)
 	f("scrape_series_limit", true)
+	f("scrape_series_budget", true)
+	f("scrape_series_dropped", true)
 	f("scrape_series_current", true)
 
 	f("foobar", false)
@@ -502,6 +505,13 @@ func TestAddRowToTimeseriesNoRelabeling(t *testing.T) {
 			HonorLabels: true,
 		},
 		`metric 0 123`)
+	// Exemplars
+	f(`metric{a="f"} 0 123 # {trace_id="abc"} 1.5 2`,
+		&ScrapeWork{},
+		`metric{a="f"} 0 123 # {trace_id="abc"} 1.5 2`)
+	f(`metric{a="f"} 0 123 # {trace_id="abc",span_id="def"} 1.5`,
+		&ScrapeWork{},
+		`metric{a="f"} 0 123 # {trace_id="abc",span_id="def"} 1.5`)
 	f(`metric{a="f"} 0 123`,
 		&ScrapeWork{
 			HonorLabels: true,
@@ -705,6 +715,66 @@ func TestAddRowToTimeseriesNoRelabeling(t *testing.T) {
 		`metric{a="e",foo="bar"} 0 123`)
 }
 
+func TestScrapeWorkSeriesBudget(t *testing.T) {
+	var sw scrapeWork
+	sw.Config = &ScrapeWork{
+		ScrapeTimeout: time.Second * 42,
+		SeriesBudget:  2,
+	}
+	common.StartUnmarshalWorkers()
+	defer common.StopUnmarshalWorkers()
+
+	var pushed []string
+	sw.PushData = func(at *auth.Token, wr *prompbmarshal.WriteRequest) {
+		for _, ts := range wr.Timeseries {
+			name := ts.Labels[0].Value
+			switch name {
+			case "foo", "bar", "baz", "scrape_series_dropped":
+			default:
+				continue
+			}
+			v := ts.Samples[0].Value
+			if decimal.IsStaleNaN(v) {
+				pushed = append(pushed, name+" stale")
+			} else {
+				pushed = append(pushed, fmt.Sprintf("%s %g", name, v))
+			}
+		}
+	}
+	f := func(data, resultExpected string) {
+		t.Helper()
+		sw.ReadData = func(dst []byte) ([]byte, error) {
+			return append(dst, data...), nil
+		}
+		pushed = pushed[:0]
+		timestamp := int64(123000)
+		if err := sw.scrapeInternal(timestamp, timestamp); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result := strings.Join(pushed, ",")
+		if result != resultExpected {
+			t.Fatalf("unexpected result;\ngot\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+
+	// Series fit the budget
+	f("foo 1\nbar 2\n", "foo 1,bar 2,scrape_series_dropped 0")
+
+	// New series is dropped when the budget is exhausted, while the existing series are kept
+	f("foo 1\nbar 2\nbaz 3\n", "foo 1,bar 2,scrape_series_dropped 1")
+
+	// The disappeared series frees up the budget only after the scrape, so the new series is still dropped.
+	// Stale marker must be sent for the disappeared series.
+	f("foo 1\nbaz 3\n", "foo 1,scrape_series_dropped 1,bar stale")
+
+	// The new series fits the freed budget
+	f("foo 1\nbaz 3\n", "foo 1,baz 3,scrape_series_dropped 0")
+
+	// Stale marker mustn't be sent for the series, which were dropped by the budget
+	f("foo 1\nbar 2\nbaz 3\n", "foo 1,baz 3,scrape_series_dropped 1")
+	f("foo 1\nbaz 3\n", "foo 1,baz 3,scrape_series_dropped 0")
+}
+
 func TestSendStaleSeries(t *testing.T) {
 	f := func(lastScrape, currScrape string, staleMarksExpected int) {
 		t.Helper()
@@ -781,6 +851,26 @@ func parseData(data string) []prompbmarshal.TimeSeries {
 				Timestamp: r.Timestamp,
 			},
 		}
+		if e := r.Exemplar; len(e.Tags) > 0 {
+			var exemplarLabels []prompbmarshal.Label
+			for _, tag := range e.Tags {
+				exemplarLabels = append(exemplarLabels, prompbmarshal.Label{
+					Name:  tag.Key,
+					Value: tag.Value,
+				})
+			}
+			exemplarTimestamp := e.Timestamp
+			if exemplarTimestamp == 0 {
+				exemplarTimestamp = r.Timestamp
+			}
+			ts.Exemplars = []prompbmarshal.Exemplar{
+				{
+					Labels:    exemplarLabels,
+					Value:     e.Value,
+					Timestamp: exemplarTimestamp,
+				},
+			}
+		}
 		tss = append(tss, ts)
 	}
 	return tss
@@ -845,6 +935,16 @@ func timeseriesToString(ts *prompbmarshal.TimeSeries) string {
 	}
 	s := ts.Samples[0]
 	fmt.Fprintf(&sb, "%g %d", s.Value, s.Timestamp)
+	for _, e := range ts.Exemplars {
+		fmt.Fprintf(&sb, " # {")
+		for i, label := range e.Labels {
+			fmt.Fprintf(&sb, "%s=%q", label.Name, label.Value)
+			if i+1 < len(e.Labels) {
+				fmt.Fprintf(&sb, ",")
+			}
+		}
+		fmt.Fprintf(&sb, "} %g %d", e.Value, e.Timestamp)
+	}
 	return sb.String()
 }
 
@@ -0,0 +1,82 @@
+package promscrape
+
+// seriesBudget limits the number of unique series accepted from a single scrape target.
+//
+// Unlike bloomfilter.Limiter used for series_limit, seriesBudget always accepts series,
+// which were already accepted during the previous scrapes, and drops only newly appearing series
+// when the budget is exhausted. Series, which disappear from the target response, free up the budget.
+//
+// See https://docs.victoriametrics.com/vmagent.html#series-budget
+//
+// seriesBudget isn't safe for concurrent use.
+type seriesBudget struct {
+	maxSeries int
+
+	// generation is incremented on every Finalize call.
+	generation uint64
+
+	// series contains hashes of the accepted series with the last generation when they were seen.
+	series map[uint64]uint64
+
+	// seriesDropped is the number of series dropped during the current generation.
+	seriesDropped int
+
+	// exceeded is set to true if some series were dropped during the last finalized generation.
+	exceeded bool
+}
+
+func newSeriesBudget(maxSeries int) *seriesBudget {
+	return &seriesBudget{
+		maxSeries: maxSeries,
+		series:    make(map[uint64]uint64),
+	}
+}
+
+// Add returns true if the series with the given hash h fits the budget.
+func (sb *seriesBudget) Add(h uint64) bool {
+	if _, ok := sb.series[h]; ok {
+		sb.series[h] = sb.generation
+		return true
+	}
+	if len(sb.series) >= sb.maxSeries {
+		sb.seriesDropped++
+		return false
+	}
+	sb.series[h] = sb.generation
+	return true
+}
+
+// Has returns true if the series with the given hash h has been accepted by sb.
+func (sb *seriesBudget) Has(h uint64) bool {
+	_, ok := sb.series[h]
+	return ok
+}
+
+// Finalize must be called after all the series from a successful scrape are passed to Add.
+//
+// It removes series missing in the scrape, so they no longer occupy the budget.
+func (sb *seriesBudget) Finalize() {
+	for h, generation := range sb.series {
+		if generation != sb.generation {
+			delete(sb.series, h)
+		}
+	}
+	sb.exceeded = sb.seriesDropped > 0
+	sb.seriesDropped = 0
+	sb.generation++
+}
+
+// Exceeded returns true if some series were dropped during the last finalized scrape.
+func (sb *seriesBudget) Exceeded() bool {
+	return sb.exceeded
+}
+
+// MaxSeries returns the maximum number of series, which can be accepted by sb.
+func (sb *seriesBudget) MaxSeries() int {
+	return sb.maxSeries
+}
+
+// CurrentSeries returns the number of series accepted by sb.
+func (sb *seriesBudget) CurrentSeries() int {
+	return len(sb.series)
+}
@@ -0,0 +1,46 @@
+package promscrape
+
+import (
+	"testing"
+)
+
+func TestSeriesBudget(t *testing.T) {
+	sb := newSeriesBudget(2)
+	f := func(h uint64, resultExpected bool) {
+		t.Helper()
+		if result := sb.Add(h); result != resultExpected {
+			t.Fatalf("unexpected result for Add(%d); got %v; want %v", h, result, resultExpected)
+		}
+	}
+
+	f(1, true)
+	f(2, true)
+	f(1, true)
+	f(3, false)
+	sb.Finalize()
+	if !sb.Exceeded() {
+		t.Fatalf("the budget must be exceeded")
+	}
+	if n := sb.CurrentSeries(); n != 2 {
+		t.Fatalf("unexpected number of series; got %d; want 2", n)
+	}
+
+	// The series 2 disappears, so it must be removed from the budget after Finalize.
+	f(1, true)
+	f(3, false)
+	if !sb.Has(2) {
+		t.Fatalf("the series 2 must remain in the budget until Finalize call")
+	}
+	sb.Finalize()
+	if sb.Has(2) {
+		t.Fatalf("the series 2 must be removed from the budget after Finalize call")
+	}
+
+	f(1, true)
+	f(3, true)
+	f(4, false)
+	sb.Finalize()
+	if n := sb.CurrentSeries(); n != 2 {
+		t.Fatalf("unexpected number of series; got %d; want 2", n)
+	}
+}
@@ -122,7 +122,7 @@ func (tsm *targetStatusMap) Unregister(sw *scrapeWork) {
 	tsm.mu.Unlock()
 }
 
-func (tsm *targetStatusMap) Update(sw *scrapeWork, up bool, scrapeTime, scrapeDuration int64, samplesScraped int, err error) {
+func (tsm *targetStatusMap) Update(sw *scrapeWork, up bool, scrapeTime, scrapeDuration int64, samplesScraped, seriesDropped int, err error) {
 	tsm.mu.Lock()
 	ts := tsm.m[sw]
 	if ts == nil {
@@ -135,6 +135,7 @@ func (tsm *targetStatusMap) Update(sw *scrapeWork, up bool, scrapeTime, scrapeDu
 	ts.scrapeTime = scrapeTime
 	ts.scrapeDuration = scrapeDuration
 	ts.samplesScraped = samplesScraped
+	ts.seriesDropped = seriesDropped
 	ts.scrapesTotal++
 	if !up {
 		ts.scrapesFailed++
@@ -208,6 +209,10 @@ func (tsm *targetStatusMap) WriteActiveTargetsJSON(w io.Writer) {
 		fmt.Fprintf(w, `,"lastScrape":%q`, time.Unix(ts.scrapeTime/1000, (ts.scrapeTime%1000)*1e6).Format(time.RFC3339Nano))
 		fmt.Fprintf(w, `,"lastScrapeDuration":%g`, (time.Millisecond * time.Duration(ts.scrapeDuration)).Seconds())
 		fmt.Fprintf(w, `,"lastSamplesScraped":%d`, ts.samplesScraped)
+		if seriesBudget := ts.sw.Config.SeriesBudget; seriesBudget > 0 {
+			fmt.Fprintf(w, `,"seriesBudget":%d`, seriesBudget)
+			fmt.Fprintf(w, `,"lastSeriesDropped":%d`, ts.seriesDropped)
+		}
 		state := "up"
 		if !ts.up {
 			state = "down"
@@ -238,11 +243,17 @@ type targetStatus struct {
 	scrapeTime     int64
 	scrapeDuration int64
 	samplesScraped int
+	seriesDropped  int
 	scrapesTotal   int
 	scrapesFailed  int
 	err            error
 }
 
+// isOverBudget returns true if some series were dropped during the last scrape because of series_budget.
+func (ts *targetStatus) isOverBudget() bool {
+	return ts.seriesDropped > 0
+}
+
 func (ts *targetStatus) getDurationFromLastScrape() time.Duration {
 	return time.Since(time.Unix(ts.scrapeTime/1000, (ts.scrapeTime%1000)*1e6))
 }
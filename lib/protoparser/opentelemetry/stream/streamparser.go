@@ -1,8 +1,10 @@
 package stream
 
 import (
+	"encoding/hex"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
 	"sync"
 
@@ -102,6 +104,9 @@ func (wr *writeContext) appendSampleFromNumericPoint(metricName string, p *pb.Nu
 	wr.pointLabels = appendAttributesToPromLabels(wr.pointLabels[:0], p.Attributes)
 
 	wr.appendSample(metricName, t, v, isStale)
+	if !isStale {
+		wr.appendExemplarsToLastSeries(p.Exemplars, math.Inf(-1), math.Inf(1))
+	}
 }
 
 // appendSamplesFromSummary appends summary p to wr.tss
@@ -145,13 +150,80 @@ func (wr *writeContext) appendSamplesFromHistogram(metricName string, p *pb.Hist
 	wr.appendSample(metricName+"_sum", t, *p.Sum, isStale)
 
 	var cumulative uint64
+	lowerBound := math.Inf(-1)
 	for index, bound := range p.ExplicitBounds {
 		cumulative += p.BucketCounts[index]
 		boundLabelValue := strconv.FormatFloat(bound, 'f', -1, 64)
 		wr.appendSampleWithExtraLabel(metricName+"_bucket", "le", boundLabelValue, t, float64(cumulative), isStale)
+		if !isStale {
+			// Attach exemplars to the bucket they belong to in the same way as Prometheus does.
+			wr.appendExemplarsToLastSeries(p.Exemplars, lowerBound, bound)
+		}
+		lowerBound = bound
 	}
 	cumulative += p.BucketCounts[len(p.BucketCounts)-1]
 	wr.appendSampleWithExtraLabel(metricName+"_bucket", "le", "+Inf", t, float64(cumulative), isStale)
+	if !isStale {
+		wr.appendExemplarsToLastSeries(p.Exemplars, lowerBound, math.Inf(1))
+	}
+}
+
+// appendExemplarsToLastSeries attaches exemplars with values in the range (lowerBound ... upperBound] to the last series at wr.tss
+//
+// trace_id and span_id are converted to hex-encoded labels, which are added to exemplar filtered attributes.
+// Exemplars without labels are skipped, since they cannot be used for referring traces.
+func (wr *writeContext) appendExemplarsToLastSeries(exemplars []*pb.Exemplar, lowerBound, upperBound float64) {
+	if len(exemplars) == 0 {
+		return
+	}
+	ts := &wr.tss[len(wr.tss)-1]
+	exemplarsPool := wr.exemplarsPool
+	exemplarsLen := len(exemplarsPool)
+	for _, e := range exemplars {
+		var v float64
+		switch t := e.Value.(type) {
+		case *pb.Exemplar_AsInt:
+			v = float64(t.AsInt)
+		case *pb.Exemplar_AsDouble:
+			v = t.AsDouble
+		}
+		if v <= lowerBound || v > upperBound {
+			continue
+		}
+		labelsPool := wr.exemplarLabelsPool
+		labelsLen := len(labelsPool)
+		if len(e.TraceId) > 0 {
+			labelsPool = append(labelsPool, prompbmarshal.Label{
+				Name:  "trace_id",
+				Value: hex.EncodeToString(e.TraceId),
+			})
+		}
+		if len(e.SpanId) > 0 {
+			labelsPool = append(labelsPool, prompbmarshal.Label{
+				Name:  "span_id",
+				Value: hex.EncodeToString(e.SpanId),
+			})
+		}
+		labelsPool = appendAttributesToPromLabels(labelsPool, e.FilteredAttributes)
+		wr.exemplarLabelsPool = labelsPool
+		if len(labelsPool) == labelsLen {
+			continue
+		}
+		t := int64(e.TimeUnixNano / 1e6)
+		if t <= 0 {
+			// Use the sample timestamp if the exemplar timestamp isn't set.
+			t = ts.Samples[0].Timestamp
+		}
+		exemplarsPool = append(exemplarsPool, prompbmarshal.Exemplar{
+			Labels:    labelsPool[labelsLen:],
+			Value:     v,
+			Timestamp: t,
+		})
+	}
+	if len(exemplarsPool) > exemplarsLen {
+		ts.Exemplars = exemplarsPool[exemplarsLen:]
+	}
+	wr.exemplarsPool = exemplarsPool
 }
 
 // appendSample appends sample with the given metricName to wr.tss
@@ -227,8 +299,10 @@ type writeContext struct {
 	pointLabels []prompbmarshal.Label
 
 	// pools are used for reducing memory allocations when parsing time series
-	labelsPool  []prompbmarshal.Label
-	samplesPool []prompbmarshal.Sample
+	labelsPool         []prompbmarshal.Label
+	samplesPool        []prompbmarshal.Sample
+	exemplarsPool      []prompbmarshal.Exemplar
+	exemplarLabelsPool []prompbmarshal.Label
 }
 
 func (wr *writeContext) reset() {
@@ -239,6 +313,7 @@ func (wr *writeContext) reset() {
 		ts := &tss[i]
 		ts.Labels = nil
 		ts.Samples = nil
+		ts.Exemplars = nil
 	}
 	wr.tss = tss[:0]
 
@@ -247,6 +322,12 @@ func (wr *writeContext) reset() {
 
 	wr.labelsPool = resetLabels(wr.labelsPool)
 	wr.samplesPool = wr.samplesPool[:0]
+
+	for i := range wr.exemplarsPool {
+		wr.exemplarsPool[i].Labels = nil
+	}
+	wr.exemplarsPool = wr.exemplarsPool[:0]
+	wr.exemplarLabelsPool = resetLabels(wr.exemplarLabelsPool)
 }
 
 func resetLabels(labels []prompbmarshal.Label) []prompbmarshal.Label {
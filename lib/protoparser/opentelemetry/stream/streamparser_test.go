@@ -49,6 +49,9 @@ func TestParseStream(t *testing.T) {
 							i, j, prettifySample(sample), prettifySample(sampleExpected))
 					}
 				}
+				if !reflect.DeepEqual(ts.Exemplars, tsExpected.Exemplars) {
+					return fmt.Errorf("idx: %d, not equal exemplars, \ngot: \n%v,\nwant: \n%v", i, ts.Exemplars, tsExpected.Exemplars)
+				}
 			}
 			return nil
 		}
@@ -111,6 +114,42 @@ func TestParseStream(t *testing.T) {
 			newPromPBTs("my-summary", 35000, 15.0, jobLabelValue, kvLabel("label6", "value6"), kvLabel("quantile", "1")),
 		})
 
+	// Test exemplars
+	withExemplars := func(ts prompbmarshal.TimeSeries, exemplars ...prompbmarshal.Exemplar) prompbmarshal.TimeSeries {
+		ts.Exemplars = exemplars
+		return ts
+	}
+	f(
+		[]*pb.Metric{
+			generateSumWithExemplars("my-sum"),
+			generateHistogramWithExemplars("my-histogram"),
+		},
+		[]prompbmarshal.TimeSeries{
+			withExemplars(newPromPBTs("my-sum", 150000, 15.5, jobLabelValue),
+				prompbmarshal.Exemplar{
+					Labels:    []prompbmarshal.Label{kvLabel("trace_id", "0102"), kvLabel("span_id", "03")},
+					Value:     2,
+					Timestamp: 140000,
+				},
+			),
+			newPromPBTs("my-histogram_count", 30000, 3, jobLabelValue),
+			newPromPBTs("my-histogram_sum", 30000, 5, jobLabelValue),
+			withExemplars(newPromPBTs("my-histogram_bucket", 30000, 2, jobLabelValue, leLabel("1")),
+				prompbmarshal.Exemplar{
+					Labels:    []prompbmarshal.Label{kvLabel("trace_id", "0a")},
+					Value:     0.5,
+					Timestamp: 30000,
+				},
+			),
+			withExemplars(newPromPBTs("my-histogram_bucket", 30000, 3, jobLabelValue, leLabel("+Inf")),
+				prompbmarshal.Exemplar{
+					Labels:    []prompbmarshal.Label{kvLabel("user", "foo")},
+					Value:     4,
+					Timestamp: 30000,
+				},
+			),
+		})
+
 	// Test gauge
 	f(
 		[]*pb.Metric{
@@ -217,6 +256,67 @@ func generateSum(name string) *pb.Metric {
 	}
 }
 
+func generateSumWithExemplars(name string) *pb.Metric {
+	points := []*pb.NumberDataPoint{
+		{
+			Value:        &pb.NumberDataPoint_AsDouble{AsDouble: 15.5},
+			TimeUnixNano: uint64(150 * time.Second),
+			Exemplars: []*pb.Exemplar{
+				{
+					TraceId:      []byte{1, 2},
+					SpanId:       []byte{3},
+					Value:        &pb.Exemplar_AsInt{AsInt: 2},
+					TimeUnixNano: uint64(140 * time.Second),
+				},
+				// Exemplar without labels must be skipped
+				{
+					Value: &pb.Exemplar_AsDouble{AsDouble: 3},
+				},
+			},
+		},
+	}
+	return &pb.Metric{
+		Name: name,
+		Data: &pb.Metric_Sum{
+			Sum: &pb.Sum{
+				AggregationTemporality: pb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				DataPoints:             points,
+			},
+		},
+	}
+}
+
+func generateHistogramWithExemplars(name string) *pb.Metric {
+	points := []*pb.HistogramDataPoint{
+		{
+			Count:          3,
+			Sum:            func() *float64 { v := 5.0; return &v }(),
+			ExplicitBounds: []float64{1.0},
+			BucketCounts:   []uint64{2, 1},
+			TimeUnixNano:   uint64(30 * time.Second),
+			Exemplars: []*pb.Exemplar{
+				{
+					TraceId: []byte{10},
+					Value:   &pb.Exemplar_AsDouble{AsDouble: 0.5},
+				},
+				{
+					FilteredAttributes: attributesFromKV("user", "foo"),
+					Value:              &pb.Exemplar_AsDouble{AsDouble: 4},
+				},
+			},
+		},
+	}
+	return &pb.Metric{
+		Name: name,
+		Data: &pb.Metric_Histogram{
+			Histogram: &pb.Histogram{
+				AggregationTemporality: pb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				DataPoints:             points,
+			},
+		},
+	}
+}
+
 func generateSummary(name string) *pb.Metric {
 	points := []*pb.SummaryDataPoint{
 		{
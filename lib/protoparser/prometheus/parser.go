@@ -63,6 +63,9 @@ type Row struct {
 	Tags      []Tag
 	Value     float64
 	Timestamp int64
+
+	// Exemplar is an optional OpenMetrics exemplar for the row.
+	Exemplar Exemplar
 }
 
 func (r *Row) reset() {
@@ -70,14 +73,73 @@ func (r *Row) reset() {
 	r.Tags = nil
 	r.Value = 0
 	r.Timestamp = 0
+	r.Exemplar.reset()
 }
 
-func skipTrailingComment(s string) string {
-	n := strings.IndexByte(s, '#')
-	if n < 0 {
-		return s
+// Exemplar is an OpenMetrics exemplar.
+//
+// See https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md#exemplars
+type Exemplar struct {
+	// Tags contains exemplar labels. Exemplar is missing if Tags is empty.
+	Tags []Tag
+
+	Value float64
+
+	// Timestamp is exemplar timestamp in milliseconds. It is zero if the exemplar has no timestamp.
+	Timestamp int64
+}
+
+func (e *Exemplar) reset() {
+	e.Tags = nil
+	e.Value = 0
+	e.Timestamp = 0
+}
+
+// unmarshal unmarshals exemplar from s, which contains the line tail after '#' char.
+//
+// e remains empty if s contains an ordinary comment instead of exemplar.
+// Exemplars without labels are ignored, since they cannot be used for referring traces.
+func (e *Exemplar) unmarshal(s string, tagsPool []Tag, noEscapes bool) ([]Tag, error) {
+	e.reset()
+	s = skipLeadingWhitespace(s)
+	if len(s) == 0 || s[0] != '{' {
+		// Ordinary comment
+		return tagsPool, nil
+	}
+	tagsStart := len(tagsPool)
+	s, tagsPool, err := unmarshalTags(tagsPool, s[1:], noEscapes)
+	if err != nil {
+		return tagsPool, fmt.Errorf("cannot unmarshal exemplar tags: %w", err)
 	}
-	return s[:n]
+	s = skipTrailingWhitespace(skipLeadingWhitespace(s))
+	if len(s) == 0 {
+		return tagsPool, fmt.Errorf("exemplar value cannot be empty")
+	}
+	var tsString string
+	if n := nextWhitespace(s); n >= 0 {
+		tsString = skipLeadingWhitespace(s[n+1:])
+		s = s[:n]
+	}
+	v, err := fastfloat.Parse(s)
+	if err != nil {
+		return tagsPool, fmt.Errorf("cannot parse exemplar value %q: %w", s, err)
+	}
+	var ts float64
+	if len(tsString) > 0 {
+		ts, err = fastfloat.Parse(tsString)
+		if err != nil {
+			return tagsPool, fmt.Errorf("cannot parse exemplar timestamp %q: %w", tsString, err)
+		}
+	}
+	tags := tagsPool[tagsStart:]
+	if len(tags) == 0 {
+		return tagsPool, nil
+	}
+	e.Tags = tags[:len(tags):len(tags)]
+	e.Value = v
+	// OpenMetrics exemplar timestamps are always in seconds.
+	e.Timestamp = int64(ts * 1000)
+	return tagsPool, nil
 }
 
 func skipLeadingWhitespace(s string) string {
@@ -143,7 +205,14 @@ func (r *Row) unmarshal(s string, tagsPool []Tag, noEscapes bool) ([]Tag, error)
 		return tagsPool, fmt.Errorf("metric cannot be empty")
 	}
 	s = skipLeadingWhitespace(s)
-	s = skipTrailingComment(s)
+	if n := strings.IndexByte(s, '#'); n >= 0 {
+		var err error
+		tagsPool, err = r.Exemplar.unmarshal(s[n+1:], tagsPool, noEscapes)
+		if err != nil {
+			return tagsPool, err
+		}
+		s = s[:n]
+	}
 	if len(s) == 0 {
 		return tagsPool, fmt.Errorf("value cannot be empty")
 	}
@@ -241,6 +241,12 @@ func TestRowsUnmarshalFailure(t *testing.T) {
 
 	// Invalid timestamp
 	f("foo 123 bar")
+
+	// Invalid exemplar
+	f(`foo 123 # {trace_id="abc"`)
+	f(`foo 123 # {trace_id="abc"}`)
+	f(`foo 123 # {trace_id="abc"} bar`)
+	f(`foo 123 # {trace_id="abc"} 1 bar`)
 }
 
 func TestRowsUnmarshalSuccess(t *testing.T) {
@@ -381,6 +387,16 @@ cassandra_token_ownership_ratio 78.9`, &Rows{
 					},
 				},
 				Value: 17,
+				Exemplar: Exemplar{
+					Tags: []Tag{
+						{
+							Key:   "trace_id",
+							Value: "oHg5SJ#YRHA0",
+						},
+					},
+					Value:     9.8,
+					Timestamp: 1520879607789,
+				},
 			},
 			{
 				Metric:    "abc",
@@ -0,0 +1,665 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ProtobufContentType is the content type for Prometheus protobuf exposition format.
+//
+// The format consists of length-delimited io.prometheus.client.MetricFamily messages.
+// See https://github.com/prometheus/client_model/blob/master/io/prometheus/client/metrics.proto
+const ProtobufContentType = "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited"
+
+// IsProtobufContentType returns true if contentType corresponds to Prometheus protobuf exposition format.
+func IsProtobufContentType(contentType string) bool {
+	mediaType, params, _ := strings.Cut(contentType, ";")
+	if strings.TrimSpace(mediaType) != "application/vnd.google.protobuf" {
+		return false
+	}
+	return strings.Contains(params, "proto=io.prometheus.client.MetricFamily")
+}
+
+// Supported formats for native histograms conversion.
+const (
+	// NativeHistogramFormatVMRange converts native histogram buckets into VictoriaMetrics histogram buckets with `vmrange` label.
+	//
+	// See https://valyala.medium.com/improving-histogram-usability-for-prometheus-and-grafana-bc7e5df0e350
+	NativeHistogramFormatVMRange = "vmrange"
+
+	// NativeHistogramFormatLE converts native histogram buckets into Prometheus histogram buckets with `le` label.
+	NativeHistogramFormatLE = "le"
+)
+
+// Metric types from io.prometheus.client.MetricType
+const (
+	metricTypeCounter        = 0
+	metricTypeGauge          = 1
+	metricTypeSummary        = 2
+	metricTypeUntyped        = 3
+	metricTypeHistogram      = 4
+	metricTypeGaugeHistogram = 5
+)
+
+// AppendProtobufAsText appends metrics from length-delimited io.prometheus.client.MetricFamily messages at src
+// to dst in Prometheus text exposition format and returns the result.
+//
+// Native histograms are converted into buckets according to nativeHistogramFormat.
+// NativeHistogramFormatVMRange is used if nativeHistogramFormat isn't NativeHistogramFormatLE.
+func AppendProtobufAsText(dst, src []byte, nativeHistogramFormat string) ([]byte, error) {
+	for len(src) > 0 {
+		size, n := protowire.ConsumeVarint(src)
+		if n < 0 {
+			return dst, fmt.Errorf("cannot read MetricFamily message size: %w", protowire.ParseError(n))
+		}
+		src = src[n:]
+		if uint64(len(src)) < size {
+			return dst, fmt.Errorf("unexpected end of MetricFamily message; want %d bytes; got %d bytes", size, len(src))
+		}
+		var err error
+		dst, err = appendMetricFamilyAsText(dst, src[:size], nativeHistogramFormat)
+		if err != nil {
+			return dst, err
+		}
+		src = src[size:]
+	}
+	return dst, nil
+}
+
+func appendMetricFamilyAsText(dst, src []byte, nativeHistogramFormat string) ([]byte, error) {
+	// Read the name and the type at first, since they may be located after metrics.
+	var name string
+	metricType := uint64(metricTypeUntyped)
+	err := forEachField(src, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+		switch num {
+		case 1:
+			name = string(b)
+		case 3:
+			metricType = v
+		}
+		return nil
+	})
+	if err != nil {
+		return dst, fmt.Errorf("cannot parse MetricFamily: %w", err)
+	}
+	if name == "" {
+		return dst, fmt.Errorf("missing MetricFamily name")
+	}
+	err = forEachField(src, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+		if num != 4 {
+			return nil
+		}
+		var m metric
+		if err := m.unmarshal(b); err != nil {
+			return fmt.Errorf("cannot parse metric: %w", err)
+		}
+		dst = m.appendText(dst, name, metricType, nativeHistogramFormat)
+		return nil
+	})
+	if err != nil {
+		return dst, fmt.Errorf("cannot parse MetricFamily %q: %w", name, err)
+	}
+	return dst, nil
+}
+
+// metric represents io.prometheus.client.Metric
+type metric struct {
+	labels      []Tag
+	value       float64
+	summary     *summary
+	histogram   *histogram
+	timestampMs int64
+
+	// exemplar is an optional exemplar for counter value
+	exemplar *exemplar
+}
+
+// exemplar represents io.prometheus.client.Exemplar
+type exemplar struct {
+	labels      []Tag
+	value       float64
+	timestampMs int64
+}
+
+type summary struct {
+	sampleCount uint64
+	sampleSum   float64
+	quantiles   []quantile
+}
+
+type quantile struct {
+	quantile float64
+	value    float64
+}
+
+type histogram struct {
+	sampleCount      uint64
+	sampleCountFloat float64
+	sampleSum        float64
+	buckets          []bucket
+
+	schema         int32
+	zeroThreshold  float64
+	zeroCount      uint64
+	zeroCountFloat float64
+	negativeSpans  []bucketSpan
+	negativeDeltas []int64
+	negativeCounts []float64
+	positiveSpans  []bucketSpan
+	positiveDeltas []int64
+	positiveCounts []float64
+}
+
+type bucket struct {
+	cumulativeCount      uint64
+	cumulativeCountFloat float64
+	upperBound           float64
+	exemplar             *exemplar
+}
+
+type bucketSpan struct {
+	offset int32
+	length uint32
+}
+
+func (m *metric) unmarshal(src []byte) error {
+	return forEachField(src, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+		switch num {
+		case 1:
+			var err error
+			m.labels, err = appendLabelPair(m.labels, b)
+			return err
+		case 2, 3, 5:
+			// Gauge, Counter and Untyped messages contain the value at the first field.
+			// Counter message contains an optional exemplar at the second field.
+			return forEachField(b, func(fieldNum protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+				switch fieldNum {
+				case 1:
+					m.value = math.Float64frombits(v)
+				case 2:
+					if num == 3 {
+						m.exemplar = &exemplar{}
+						return m.exemplar.unmarshal(b)
+					}
+				}
+				return nil
+			})
+		case 4:
+			m.summary = &summary{}
+			return m.summary.unmarshal(b)
+		case 6:
+			m.timestampMs = int64(v)
+		case 7:
+			m.histogram = &histogram{}
+			return m.histogram.unmarshal(b)
+		}
+		return nil
+	})
+}
+
+func (s *summary) unmarshal(src []byte) error {
+	return forEachField(src, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+		switch num {
+		case 1:
+			s.sampleCount = v
+		case 2:
+			s.sampleSum = math.Float64frombits(v)
+		case 3:
+			var q quantile
+			err := forEachField(b, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+				switch num {
+				case 1:
+					q.quantile = math.Float64frombits(v)
+				case 2:
+					q.value = math.Float64frombits(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("cannot parse quantile: %w", err)
+			}
+			s.quantiles = append(s.quantiles, q)
+		}
+		return nil
+	})
+}
+
+func (h *histogram) unmarshal(src []byte) error {
+	return forEachField(src, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+		var err error
+		switch num {
+		case 1:
+			h.sampleCount = v
+		case 2:
+			h.sampleSum = math.Float64frombits(v)
+		case 3:
+			var bk bucket
+			err = forEachField(b, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+				switch num {
+				case 1:
+					bk.cumulativeCount = v
+				case 2:
+					bk.upperBound = math.Float64frombits(v)
+				case 3:
+					bk.exemplar = &exemplar{}
+					return bk.exemplar.unmarshal(b)
+				case 4:
+					bk.cumulativeCountFloat = math.Float64frombits(v)
+				}
+				return nil
+			})
+			h.buckets = append(h.buckets, bk)
+		case 4:
+			h.sampleCountFloat = math.Float64frombits(v)
+		case 5:
+			h.schema = int32(protowire.DecodeZigZag(v))
+		case 6:
+			h.zeroThreshold = math.Float64frombits(v)
+		case 7:
+			h.zeroCount = v
+		case 8:
+			h.zeroCountFloat = math.Float64frombits(v)
+		case 9:
+			h.negativeSpans, err = appendBucketSpan(h.negativeSpans, b)
+		case 10:
+			h.negativeDeltas, err = appendSint64s(h.negativeDeltas, typ, b, v)
+		case 11:
+			h.negativeCounts, err = appendDoubles(h.negativeCounts, typ, b, v)
+		case 12:
+			h.positiveSpans, err = appendBucketSpan(h.positiveSpans, b)
+		case 13:
+			h.positiveDeltas, err = appendSint64s(h.positiveDeltas, typ, b, v)
+		case 14:
+			h.positiveCounts, err = appendDoubles(h.positiveCounts, typ, b, v)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot parse histogram field #%d: %w", num, err)
+		}
+		return nil
+	})
+}
+
+func (e *exemplar) unmarshal(src []byte) error {
+	return forEachField(src, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+		switch num {
+		case 1:
+			var err error
+			e.labels, err = appendLabelPair(e.labels, b)
+			return err
+		case 2:
+			e.value = math.Float64frombits(v)
+		case 3:
+			// google.protobuf.Timestamp
+			var secs, nsecs int64
+			err := forEachField(b, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+				switch num {
+				case 1:
+					secs = int64(v)
+				case 2:
+					nsecs = int64(int32(v))
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("cannot parse exemplar timestamp: %w", err)
+			}
+			e.timestampMs = secs*1000 + nsecs/1e6
+		}
+		return nil
+	})
+}
+
+func appendLabelPair(dst []Tag, src []byte) ([]Tag, error) {
+	var tag Tag
+	err := forEachField(src, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+		switch num {
+		case 1:
+			tag.Key = string(b)
+		case 2:
+			tag.Value = string(b)
+		}
+		return nil
+	})
+	if err != nil {
+		return dst, fmt.Errorf("cannot parse label: %w", err)
+	}
+	return append(dst, tag), nil
+}
+
+func appendBucketSpan(dst []bucketSpan, src []byte) ([]bucketSpan, error) {
+	var bs bucketSpan
+	err := forEachField(src, func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error {
+		switch num {
+		case 1:
+			bs.offset = int32(protowire.DecodeZigZag(v))
+		case 2:
+			bs.length = uint32(v)
+		}
+		return nil
+	})
+	return append(dst, bs), err
+}
+
+func appendSint64s(dst []int64, typ protowire.Type, b []byte, v uint64) ([]int64, error) {
+	if typ != protowire.BytesType {
+		return append(dst, protowire.DecodeZigZag(v)), nil
+	}
+	// Packed repeated field
+	for len(b) > 0 {
+		x, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return dst, protowire.ParseError(n)
+		}
+		dst = append(dst, protowire.DecodeZigZag(x))
+		b = b[n:]
+	}
+	return dst, nil
+}
+
+func appendDoubles(dst []float64, typ protowire.Type, b []byte, v uint64) ([]float64, error) {
+	if typ != protowire.BytesType {
+		return append(dst, math.Float64frombits(v)), nil
+	}
+	// Packed repeated field
+	for len(b) > 0 {
+		x, n := protowire.ConsumeFixed64(b)
+		if n < 0 {
+			return dst, protowire.ParseError(n)
+		}
+		dst = append(dst, math.Float64frombits(x))
+		b = b[n:]
+	}
+	return dst, nil
+}
+
+// forEachField calls f for each field in protobuf message src.
+//
+// b contains the field contents for length-delimited fields, while v contains the field value for varint and fixed-size fields.
+func forEachField(src []byte, f func(num protowire.Number, typ protowire.Type, b []byte, v uint64) error) error {
+	for len(src) > 0 {
+		num, typ, n := protowire.ConsumeTag(src)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		src = src[n:]
+		var b []byte
+		var v uint64
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(src)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(src)
+		case protowire.Fixed32Type:
+			var v32 uint32
+			v32, n = protowire.ConsumeFixed32(src)
+			v = uint64(v32)
+		case protowire.BytesType:
+			b, n = protowire.ConsumeBytes(src)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, src)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		src = src[n:]
+		if err := f(num, typ, b, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *metric) appendText(dst []byte, name string, metricType uint64, nativeHistogramFormat string) []byte {
+	switch metricType {
+	case metricTypeSummary:
+		s := m.summary
+		if s == nil {
+			return dst
+		}
+		for _, q := range s.quantiles {
+			dst = m.appendSample(dst, name, "", "quantile", formatFloat(q.quantile), q.value)
+		}
+		dst = m.appendSample(dst, name, "_sum", "", "", s.sampleSum)
+		dst = m.appendSample(dst, name, "_count", "", "", float64(s.sampleCount))
+	case metricTypeHistogram, metricTypeGaugeHistogram:
+		h := m.histogram
+		if h == nil {
+			return dst
+		}
+		if h.isNative() {
+			if nativeHistogramFormat == NativeHistogramFormatLE {
+				dst = m.appendNativeHistogramLEBuckets(dst, name)
+			} else {
+				dst = m.appendNativeHistogramVMRangeBuckets(dst, name)
+			}
+		} else {
+			dst = m.appendClassicHistogramBuckets(dst, name)
+		}
+		dst = m.appendSample(dst, name, "_sum", "", "", h.sampleSum)
+		dst = m.appendSample(dst, name, "_count", "", "", h.getSampleCount())
+	default:
+		dst = m.appendSampleWithExemplar(dst, name, "", "", "", m.value, m.exemplar)
+	}
+	return dst
+}
+
+func (m *metric) appendClassicHistogramBuckets(dst []byte, name string) []byte {
+	h := m.histogram
+	hasInf := false
+	for _, b := range h.buckets {
+		count := float64(b.cumulativeCount)
+		if b.cumulativeCountFloat > 0 {
+			count = b.cumulativeCountFloat
+		}
+		if math.IsInf(b.upperBound, 1) {
+			hasInf = true
+		}
+		dst = m.appendSampleWithExemplar(dst, name, "_bucket", "le", formatFloat(b.upperBound), count, b.exemplar)
+	}
+	if !hasInf {
+		dst = m.appendSample(dst, name, "_bucket", "le", "+Inf", h.getSampleCount())
+	}
+	return dst
+}
+
+// nativeBucket is a bucket of native histogram with (lower, upper] bounds.
+type nativeBucket struct {
+	lower float64
+	upper float64
+	count float64
+}
+
+func (m *metric) appendNativeHistogramVMRangeBuckets(dst []byte, name string) []byte {
+	h := m.histogram
+	negative := h.getNativeBuckets(h.negativeSpans, h.negativeDeltas, h.negativeCounts)
+	positive := h.getNativeBuckets(h.positiveSpans, h.positiveDeltas, h.positiveCounts)
+	for i := len(negative) - 1; i >= 0; i-- {
+		b := negative[i]
+		dst = m.appendSample(dst, name, "_bucket", "vmrange", formatVMRange(-b.upper, -b.lower), b.count)
+	}
+	if zeroCount := h.getZeroCount(); zeroCount > 0 {
+		zeroLower := float64(0)
+		if len(negative) > 0 {
+			zeroLower = -h.zeroThreshold
+		}
+		dst = m.appendSample(dst, name, "_bucket", "vmrange", formatVMRange(zeroLower, h.zeroThreshold), zeroCount)
+	}
+	for _, b := range positive {
+		dst = m.appendSample(dst, name, "_bucket", "vmrange", formatVMRange(b.lower, b.upper), b.count)
+	}
+	return dst
+}
+
+func (m *metric) appendNativeHistogramLEBuckets(dst []byte, name string) []byte {
+	h := m.histogram
+	negative := h.getNativeBuckets(h.negativeSpans, h.negativeDeltas, h.negativeCounts)
+	positive := h.getNativeBuckets(h.positiveSpans, h.positiveDeltas, h.positiveCounts)
+
+	// Negative buckets cover [-upper, -lower) ranges.
+	// Sort all the buckets by their upper bounds in order to calculate cumulative counts.
+	bs := make([]nativeBucket, 0, len(negative)+len(positive)+1)
+	for _, b := range negative {
+		bs = append(bs, nativeBucket{
+			upper: -b.lower,
+			count: b.count,
+		})
+	}
+	if zeroCount := h.getZeroCount(); zeroCount > 0 {
+		bs = append(bs, nativeBucket{
+			upper: h.zeroThreshold,
+			count: zeroCount,
+		})
+	}
+	bs = append(bs, positive...)
+	sort.SliceStable(bs, func(i, j int) bool {
+		return bs[i].upper < bs[j].upper
+	})
+	cumulativeCount := float64(0)
+	for _, b := range bs {
+		cumulativeCount += b.count
+		dst = m.appendSample(dst, name, "_bucket", "le", formatFloat(b.upper), cumulativeCount)
+	}
+	return m.appendSample(dst, name, "_bucket", "le", "+Inf", h.getSampleCount())
+}
+
+// isNative returns true if h contains native histogram.
+//
+// See https://github.com/prometheus/prometheus/blob/main/model/textparse/protobufparse.go
+func (h *histogram) isNative() bool {
+	return len(h.positiveSpans) > 0 || len(h.negativeSpans) > 0 || h.zeroThreshold > 0 || h.zeroCount > 0 || h.zeroCountFloat > 0
+}
+
+func (h *histogram) getSampleCount() float64 {
+	if h.sampleCountFloat > 0 {
+		return h.sampleCountFloat
+	}
+	return float64(h.sampleCount)
+}
+
+func (h *histogram) getZeroCount() float64 {
+	if h.zeroCountFloat > 0 {
+		return h.zeroCountFloat
+	}
+	return float64(h.zeroCount)
+}
+
+// getNativeBuckets returns non-empty buckets for the given spans.
+//
+// Bucket counts are taken either from deltas for integer histograms or from counts for float histograms.
+func (h *histogram) getNativeBuckets(spans []bucketSpan, deltas []int64, counts []float64) []nativeBucket {
+	var bs []nativeBucket
+	idx := int32(0)
+	n := 0
+	count := int64(0)
+	for _, span := range spans {
+		idx += span.offset
+		for j := uint32(0); j < span.length; j++ {
+			var c float64
+			if len(counts) > 0 {
+				if n >= len(counts) {
+					return bs
+				}
+				c = counts[n]
+			} else {
+				if n >= len(deltas) {
+					return bs
+				}
+				count += deltas[n]
+				c = float64(count)
+			}
+			n++
+			if c > 0 {
+				bs = append(bs, nativeBucket{
+					lower: getNativeBucketBound(h.schema, idx-1),
+					upper: getNativeBucketBound(h.schema, idx),
+					count: c,
+				})
+			}
+			idx++
+		}
+	}
+	return bs
+}
+
+// getNativeBucketBound returns the upper bound for the bucket with the given idx for native histogram with the given schema.
+//
+// The upper bound equals to base^idx, where base = 2^(2^-schema).
+func getNativeBucketBound(schema, idx int32) float64 {
+	if schema < 0 {
+		return math.Ldexp(1, int(idx)<<(-schema))
+	}
+	return math.Exp2(float64(idx) / float64(int64(1)<<schema))
+}
+
+func formatVMRange(start, end float64) string {
+	return fmt.Sprintf("%.3e...%.3e", start, end)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// appendSample appends a sample with the given name+suffix, m labels, optional extra label and value to dst in Prometheus text exposition format.
+func (m *metric) appendSample(dst []byte, name, suffix, extraLabelName, extraLabelValue string, value float64) []byte {
+	return m.appendSampleWithExemplar(dst, name, suffix, extraLabelName, extraLabelValue, value, nil)
+}
+
+// appendSampleWithExemplar appends a sample with optional exemplar e to dst.
+//
+// The exemplar is appended in OpenMetrics format. See https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md#exemplars
+func (m *metric) appendSampleWithExemplar(dst []byte, name, suffix, extraLabelName, extraLabelValue string, value float64, e *exemplar) []byte {
+	dst = append(dst, name...)
+	dst = append(dst, suffix...)
+	if len(m.labels) > 0 || extraLabelName != "" {
+		dst = append(dst, '{')
+		for i, tag := range m.labels {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = append(dst, tag.Key...)
+			dst = append(dst, `="`...)
+			dst = appendEscapedValue(dst, tag.Value)
+			dst = append(dst, '"')
+		}
+		if extraLabelName != "" {
+			if len(m.labels) > 0 {
+				dst = append(dst, ',')
+			}
+			dst = append(dst, extraLabelName...)
+			dst = append(dst, `="`...)
+			dst = appendEscapedValue(dst, extraLabelValue)
+			dst = append(dst, '"')
+		}
+		dst = append(dst, '}')
+	}
+	dst = append(dst, ' ')
+	dst = strconv.AppendFloat(dst, value, 'g', -1, 64)
+	if m.timestampMs != 0 {
+		dst = append(dst, ' ')
+		dst = strconv.AppendInt(dst, m.timestampMs, 10)
+	}
+	if e != nil && len(e.labels) > 0 {
+		dst = append(dst, " # {"...)
+		for i, tag := range e.labels {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = append(dst, tag.Key...)
+			dst = append(dst, `="`...)
+			dst = appendEscapedValue(dst, tag.Value)
+			dst = append(dst, '"')
+		}
+		dst = append(dst, "} "...)
+		dst = strconv.AppendFloat(dst, e.value, 'g', -1, 64)
+		if e.timestampMs != 0 {
+			dst = append(dst, ' ')
+			dst = strconv.AppendFloat(dst, float64(e.timestampMs)/1e3, 'f', 3, 64)
+		}
+	}
+	return append(dst, '\n')
+}
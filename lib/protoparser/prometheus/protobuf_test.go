@@ -0,0 +1,323 @@
+package prometheus
+
+import (
+	"math"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestIsProtobufContentType(t *testing.T) {
+	f := func(contentType string, resultExpected bool) {
+		t.Helper()
+		result := IsProtobufContentType(contentType)
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %q; got %v; want %v", contentType, result, resultExpected)
+		}
+	}
+
+	f(ProtobufContentType, true)
+	f("application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited", true)
+	f("application/vnd.google.protobuf; proto=foo.Bar; encoding=delimited", false)
+	f("text/plain; version=0.0.4", false)
+	f("", false)
+}
+
+func marshalMetricFamilies(t *testing.T, mfs ...*dto.MetricFamily) []byte {
+	t.Helper()
+	var dst []byte
+	for _, mf := range mfs {
+		data, err := proto.Marshal(mf)
+		if err != nil {
+			t.Fatalf("cannot marshal MetricFamily: %s", err)
+		}
+		dst = protowire.AppendVarint(dst, uint64(len(data)))
+		dst = append(dst, data...)
+	}
+	return dst
+}
+
+func TestAppendProtobufAsTextSuccess(t *testing.T) {
+	f := func(nativeHistogramFormat string, mfs []*dto.MetricFamily, resultExpected string) {
+		t.Helper()
+		data := marshalMetricFamilies(t, mfs...)
+		result, err := AppendProtobufAsText(nil, data, nativeHistogramFormat)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(result) != resultExpected {
+			t.Fatalf("unexpected result;\ngot\n%s\nwant\n%s", result, resultExpected)
+		}
+
+		// Verify that the result can be parsed
+		var rows Rows
+		rows.UnmarshalWithErrLogger(string(result), func(s string) {
+			t.Fatalf("unexpected error when parsing %q: %s", result, s)
+		})
+	}
+
+	// empty response
+	f(NativeHistogramFormatVMRange, nil, "")
+
+	// counter, gauge and untyped
+	f(NativeHistogramFormatVMRange, []*dto.MetricFamily{
+		{
+			Name: proto.String("http_requests_total"),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Label: []*dto.LabelPair{
+						{Name: proto.String("path"), Value: proto.String("/foo")},
+						{Name: proto.String("code"), Value: proto.String(`2"0\0`)},
+					},
+					Counter: &dto.Counter{Value: proto.Float64(123)},
+				},
+				{
+					Counter:     &dto.Counter{Value: proto.Float64(1.5)},
+					TimestampMs: proto.Int64(1700000000123),
+				},
+			},
+		},
+		{
+			Name: proto.String("temperature"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{
+				{Gauge: &dto.Gauge{Value: proto.Float64(-12.25)}},
+			},
+		},
+		{
+			Name: proto.String("foo"),
+			Type: dto.MetricType_UNTYPED.Enum(),
+			Metric: []*dto.Metric{
+				{Untyped: &dto.Untyped{Value: proto.Float64(math.Inf(1))}},
+			},
+		},
+	}, `http_requests_total{path="/foo",code="2\"0\\0"} 123
+http_requests_total 1.5 1700000000123
+temperature -12.25
+foo +Inf
+`)
+
+	// exemplars
+	f(NativeHistogramFormatVMRange, []*dto.MetricFamily{
+		{
+			Name: proto.String("requests_total"),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Counter: &dto.Counter{
+						Value: proto.Float64(5),
+						Exemplar: &dto.Exemplar{
+							Label: []*dto.LabelPair{
+								{Name: proto.String("trace_id"), Value: proto.String("abc")},
+							},
+							Value:     proto.Float64(1),
+							Timestamp: &timestamppb.Timestamp{Seconds: 1700000000, Nanos: 123000000},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: proto.String("duration_seconds"),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Histogram: &dto.Histogram{
+						SampleCount: proto.Uint64(2),
+						SampleSum:   proto.Float64(0.3),
+						Bucket: []*dto.Bucket{
+							{
+								UpperBound:      proto.Float64(0.5),
+								CumulativeCount: proto.Uint64(2),
+								Exemplar: &dto.Exemplar{
+									Label: []*dto.LabelPair{
+										{Name: proto.String("trace_id"), Value: proto.String("def")},
+									},
+									Value: proto.Float64(0.2),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, `requests_total 5 # {trace_id="abc"} 1 1700000000.123
+duration_seconds_bucket{le="0.5"} 2 # {trace_id="def"} 0.2
+duration_seconds_bucket{le="+Inf"} 2
+duration_seconds_sum 0.3
+duration_seconds_count 2
+`)
+
+	// summary
+	f(NativeHistogramFormatVMRange, []*dto.MetricFamily{
+		{
+			Name: proto.String("rpc_duration_seconds"),
+			Type: dto.MetricType_SUMMARY.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Label: []*dto.LabelPair{
+						{Name: proto.String("service"), Value: proto.String("a")},
+					},
+					Summary: &dto.Summary{
+						SampleCount: proto.Uint64(10),
+						SampleSum:   proto.Float64(2.5),
+						Quantile: []*dto.Quantile{
+							{Quantile: proto.Float64(0.5), Value: proto.Float64(0.2)},
+							{Quantile: proto.Float64(0.99), Value: proto.Float64(0.9)},
+						},
+					},
+				},
+			},
+		},
+	}, `rpc_duration_seconds{service="a",quantile="0.5"} 0.2
+rpc_duration_seconds{service="a",quantile="0.99"} 0.9
+rpc_duration_seconds_sum{service="a"} 2.5
+rpc_duration_seconds_count{service="a"} 10
+`)
+
+	// classic histogram
+	f(NativeHistogramFormatVMRange, []*dto.MetricFamily{
+		{
+			Name: proto.String("request_duration_seconds"),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Histogram: &dto.Histogram{
+						SampleCount: proto.Uint64(7),
+						SampleSum:   proto.Float64(3.5),
+						Bucket: []*dto.Bucket{
+							{UpperBound: proto.Float64(0.1), CumulativeCount: proto.Uint64(2)},
+							{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(5)},
+						},
+					},
+				},
+			},
+		},
+	}, `request_duration_seconds_bucket{le="0.1"} 2
+request_duration_seconds_bucket{le="1"} 5
+request_duration_seconds_bucket{le="+Inf"} 7
+request_duration_seconds_sum 3.5
+request_duration_seconds_count 7
+`)
+
+	// native histogram with schema=0: buckets (0.5, 1], (1, 2], (2, 4], (4, 8]
+	nativeHistogram := []*dto.MetricFamily{
+		{
+			Name: proto.String("latency"),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Label: []*dto.LabelPair{
+						{Name: proto.String("job"), Value: proto.String("x")},
+					},
+					Histogram: &dto.Histogram{
+						SampleCount:   proto.Uint64(12),
+						SampleSum:     proto.Float64(20),
+						Schema:        proto.Int32(0),
+						ZeroThreshold: proto.Float64(0.001),
+						ZeroCount:     proto.Uint64(1),
+						NegativeSpan: []*dto.BucketSpan{
+							{Offset: proto.Int32(1), Length: proto.Uint32(1)},
+						},
+						NegativeDelta: []int64{2},
+						PositiveSpan: []*dto.BucketSpan{
+							{Offset: proto.Int32(0), Length: proto.Uint32(2)},
+							{Offset: proto.Int32(1), Length: proto.Uint32(1)},
+						},
+						// Bucket counts: 3, 0, 6
+						PositiveDelta: []int64{3, -3, 6},
+					},
+				},
+			},
+		},
+	}
+	f(NativeHistogramFormatVMRange, nativeHistogram, `latency_bucket{job="x",vmrange="-2.000e+00...-1.000e+00"} 2
+latency_bucket{job="x",vmrange="-1.000e-03...1.000e-03"} 1
+latency_bucket{job="x",vmrange="5.000e-01...1.000e+00"} 3
+latency_bucket{job="x",vmrange="4.000e+00...8.000e+00"} 6
+latency_sum{job="x"} 20
+latency_count{job="x"} 12
+`)
+	f(NativeHistogramFormatLE, nativeHistogram, `latency_bucket{job="x",le="-1"} 2
+latency_bucket{job="x",le="0.001"} 3
+latency_bucket{job="x",le="1"} 6
+latency_bucket{job="x",le="8"} 12
+latency_bucket{job="x",le="+Inf"} 12
+latency_sum{job="x"} 20
+latency_count{job="x"} 12
+`)
+
+	// float native histogram with schema=1 and schema=-1
+	f(NativeHistogramFormatVMRange, []*dto.MetricFamily{
+		{
+			Name: proto.String("float_hist"),
+			Type: dto.MetricType_GAUGE_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{
+				{
+					Histogram: &dto.Histogram{
+						SampleCountFloat: proto.Float64(2.5),
+						SampleSum:        proto.Float64(4),
+						Schema:           proto.Int32(1),
+						PositiveSpan: []*dto.BucketSpan{
+							{Offset: proto.Int32(2), Length: proto.Uint32(1)},
+						},
+						PositiveCount: []float64{2.5},
+					},
+				},
+				{
+					Histogram: &dto.Histogram{
+						SampleCount: proto.Uint64(1),
+						SampleSum:   proto.Float64(10),
+						Schema:      proto.Int32(-1),
+						PositiveSpan: []*dto.BucketSpan{
+							{Offset: proto.Int32(2), Length: proto.Uint32(1)},
+						},
+						PositiveDelta: []int64{1},
+					},
+				},
+			},
+		},
+	}, `float_hist_bucket{vmrange="1.414e+00...2.000e+00"} 2.5
+float_hist_sum 4
+float_hist_count 2.5
+float_hist_bucket{vmrange="4.000e+00...1.600e+01"} 1
+float_hist_sum 10
+float_hist_count 1
+`)
+}
+
+func TestAppendProtobufAsTextFailure(t *testing.T) {
+	f := func(data []byte) {
+		t.Helper()
+		_, err := AppendProtobufAsText(nil, data, NativeHistogramFormatVMRange)
+		if err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+
+	// invalid message size
+	f([]byte{0xff})
+
+	// truncated message
+	valid := marshalMetricFamilies(t, &dto.MetricFamily{
+		Name: proto.String("foo"),
+		Metric: []*dto.Metric{
+			{Untyped: &dto.Untyped{Value: proto.Float64(1)}},
+		},
+	})
+	f(valid[:len(valid)-1])
+
+	// missing name
+	f(marshalMetricFamilies(t, &dto.MetricFamily{
+		Metric: []*dto.Metric{
+			{Untyped: &dto.Untyped{Value: proto.Float64(1)}},
+		},
+	}))
+
+	// invalid message contents
+	f([]byte{3, 0x0a, 0x05, 'f'})
+}
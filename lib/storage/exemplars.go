@@ -0,0 +1,364 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompb"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/querytracer"
+)
+
+// ExemplarRow is an exemplar for the series identified by MetricNameRaw.
+type ExemplarRow struct {
+	// MetricNameRaw contains raw metric name, which must be decoded
+	// with MetricName.UnmarshalRaw.
+	MetricNameRaw []byte
+
+	// Labels contains exemplar labels such as trace_id.
+	Labels []prompb.Label
+
+	Timestamp int64
+	Value     float64
+}
+
+// Exemplar is an exemplar returned by Storage.SearchExemplars.
+type Exemplar struct {
+	Labels    []prompb.Label
+	Timestamp int64
+	Value     float64
+}
+
+// SeriesExemplars contains exemplars for a single series.
+type SeriesExemplars struct {
+	// MetricName is marshaled metric name, which must be unmarshaled via MetricName.UnmarshalString().
+	MetricName string
+
+	// Exemplars contains exemplars for the series sorted by timestamp.
+	Exemplars []Exemplar
+}
+
+// SetMaxExemplars sets the maximum number of exemplars, which can be stored in memory.
+//
+// Exemplars are stored in a circular buffer, so the oldest exemplars are dropped when the buffer is full.
+// Zero value disables exemplars storage.
+//
+// This function must be called before MustOpenStorage.
+func SetMaxExemplars(n int) {
+	maxExemplars = n
+}
+
+var maxExemplars = 100000
+
+// exemplarStorage holds the last exemplars for all the series in a fixed-size circular buffer.
+//
+// It is modelled after the exemplar storage in Prometheus, so it has bounded memory usage
+// regardless of the number of series with exemplars.
+type exemplarStorage struct {
+	exemplarsAdded         uint64
+	exemplarsOutOfOrder    uint64
+	exemplarsUnknownSeries uint64
+
+	mu sync.Mutex
+
+	// entries is a circular buffer with exemplars.
+	entries []exemplarEntry
+
+	// nextIdx is the index of the next entry to overwrite in entries.
+	nextIdx int
+
+	// size is the number of occupied entries.
+	size int
+
+	// index maps metricID to the oldest and the newest exemplar for the given series.
+	index map[uint64]*exemplarIndexEntry
+}
+
+type exemplarEntry struct {
+	ref *exemplarIndexEntry
+
+	// labels contains marshaled exemplar labels.
+	labels []byte
+
+	timestamp int64
+	value     float64
+
+	// next is the index of the next exemplar for the same series in entries or -1.
+	next int
+}
+
+type exemplarIndexEntry struct {
+	metricID uint64
+	oldest   int
+	newest   int
+}
+
+func newExemplarStorage(maxSize int) *exemplarStorage {
+	if maxSize < 0 {
+		maxSize = 0
+	}
+	return &exemplarStorage{
+		entries: make([]exemplarEntry, maxSize),
+		index:   make(map[uint64]*exemplarIndexEntry),
+	}
+}
+
+func (es *exemplarStorage) add(metricID uint64, labels []byte, timestamp int64, value float64) {
+	if len(es.entries) == 0 {
+		return
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if ie := es.index[metricID]; ie != nil {
+		newest := &es.entries[ie.newest]
+		if timestamp < newest.timestamp {
+			atomic.AddUint64(&es.exemplarsOutOfOrder, 1)
+			return
+		}
+		if timestamp == newest.timestamp && math.Float64bits(value) == math.Float64bits(newest.value) && bytes.Equal(labels, newest.labels) {
+			// Skip duplicate exemplar. This is a common case when scraping targets,
+			// which expose the same exemplar until it is replaced with a newer one.
+			return
+		}
+	}
+
+	// Evict the oldest entry. It is always the oldest entry for its series.
+	e := &es.entries[es.nextIdx]
+	if prev := e.ref; prev != nil {
+		if e.next < 0 {
+			delete(es.index, prev.metricID)
+		} else {
+			prev.oldest = e.next
+		}
+	} else {
+		es.size++
+	}
+
+	e.labels = append(e.labels[:0], labels...)
+	e.timestamp = timestamp
+	e.value = value
+	e.next = -1
+	ie := es.index[metricID]
+	if ie == nil {
+		ie = &exemplarIndexEntry{
+			metricID: metricID,
+			oldest:   es.nextIdx,
+		}
+		es.index[metricID] = ie
+	} else {
+		es.entries[ie.newest].next = es.nextIdx
+	}
+	ie.newest = es.nextIdx
+	e.ref = ie
+
+	es.nextIdx++
+	if es.nextIdx >= len(es.entries) {
+		es.nextIdx = 0
+	}
+	atomic.AddUint64(&es.exemplarsAdded, 1)
+}
+
+// appendExemplars appends exemplars for the given metricID on the given tr to dst and returns the result.
+func (es *exemplarStorage) appendExemplars(dst []Exemplar, metricID uint64, tr TimeRange) ([]Exemplar, error) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	ie := es.index[metricID]
+	if ie == nil {
+		return dst, nil
+	}
+	for idx := ie.oldest; idx >= 0; idx = es.entries[idx].next {
+		e := &es.entries[idx]
+		if e.timestamp < tr.MinTimestamp || e.timestamp > tr.MaxTimestamp {
+			continue
+		}
+		labels, err := unmarshalExemplarLabels(nil, e.labels)
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, Exemplar{
+			Labels:    labels,
+			Timestamp: e.timestamp,
+			Value:     e.value,
+		})
+	}
+	return dst, nil
+}
+
+func (es *exemplarStorage) len() int {
+	es.mu.Lock()
+	n := es.size
+	es.mu.Unlock()
+	return n
+}
+
+// marshal appends all the stored exemplars from the oldest to the newest to dst and returns the result.
+func (es *exemplarStorage) marshal(dst []byte) []byte {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	n := len(es.entries)
+	for i := 0; i < n; i++ {
+		e := &es.entries[(es.nextIdx+i)%n]
+		if e.ref == nil {
+			continue
+		}
+		dst = encoding.MarshalUint64(dst, e.ref.metricID)
+		dst = encoding.MarshalInt64(dst, e.timestamp)
+		dst = encoding.MarshalUint64(dst, math.Float64bits(e.value))
+		dst = encoding.MarshalBytes(dst, e.labels)
+	}
+	return dst
+}
+
+// unmarshal adds exemplars from src, which must be obtained via marshal.
+func (es *exemplarStorage) unmarshal(src []byte) error {
+	for len(src) > 0 {
+		if len(src) < 24 {
+			return fmt.Errorf("cannot unmarshal exemplar header; got %d bytes; want at least 24 bytes", len(src))
+		}
+		metricID := encoding.UnmarshalUint64(src)
+		timestamp := encoding.UnmarshalInt64(src[8:])
+		value := math.Float64frombits(encoding.UnmarshalUint64(src[16:]))
+		tail, labels, err := encoding.UnmarshalBytes(src[24:])
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal exemplar labels: %w", err)
+		}
+		src = tail
+		es.add(metricID, labels, timestamp, value)
+	}
+	return nil
+}
+
+func marshalExemplarLabels(dst []byte, labels []prompb.Label) []byte {
+	for i := range labels {
+		label := &labels[i]
+		dst = encoding.MarshalBytes(dst, label.Name)
+		dst = encoding.MarshalBytes(dst, label.Value)
+	}
+	return dst
+}
+
+func unmarshalExemplarLabels(dst []prompb.Label, src []byte) ([]prompb.Label, error) {
+	for len(src) > 0 {
+		tail, name, err := encoding.UnmarshalBytes(src)
+		if err != nil {
+			return dst, fmt.Errorf("cannot unmarshal exemplar label name: %w", err)
+		}
+		tail, value, err := encoding.UnmarshalBytes(tail)
+		if err != nil {
+			return dst, fmt.Errorf("cannot unmarshal exemplar label value: %w", err)
+		}
+		src = tail
+		dst = append(dst, prompb.Label{
+			Name:  append([]byte{}, name...),
+			Value: append([]byte{}, value...),
+		})
+	}
+	return dst, nil
+}
+
+// AddExemplars adds the given exemplars to s.
+//
+// Exemplars are attached to already existing series only, so the corresponding samples
+// must be added via AddRows before calling this function.
+// Exemplars for unknown series are silently dropped.
+func (s *Storage) AddExemplars(ers []ExemplarRow) {
+	if len(s.exemplars.entries) == 0 {
+		return
+	}
+	var genTSID generationTSID
+	var labels []byte
+	for i := range ers {
+		er := &ers[i]
+		if !s.getTSIDFromCache(&genTSID, er.MetricNameRaw) {
+			atomic.AddUint64(&s.exemplars.exemplarsUnknownSeries, 1)
+			continue
+		}
+		labels = marshalExemplarLabels(labels[:0], er.Labels)
+		s.exemplars.add(genTSID.TSID.MetricID, labels, er.Timestamp, er.Value)
+	}
+}
+
+// SearchExemplars returns exemplars for series matching the given tfss on the given tr.
+func (s *Storage) SearchExemplars(qt *querytracer.Tracer, tfss []*TagFilters, tr TimeRange, maxMetrics int, deadline uint64) ([]SeriesExemplars, error) {
+	qt = qt.NewChild("search for exemplars: filters=%s, timeRange=%s", tfss, &tr)
+	defer qt.Done()
+	if len(s.exemplars.entries) == 0 {
+		return nil, nil
+	}
+	idb := s.idb()
+	metricIDs, err := idb.searchMetricIDs(qt, tfss, tr, maxMetrics, deadline)
+	if err != nil {
+		return nil, err
+	}
+	var result []SeriesExemplars
+	var metricName []byte
+	for i, metricID := range metricIDs {
+		if i&paceLimiterSlowIterationsMask == 0 {
+			if err := checkSearchDeadlineAndPace(deadline); err != nil {
+				return nil, err
+			}
+		}
+		exemplars, err := s.exemplars.appendExemplars(nil, metricID, tr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load exemplars for metricID=%d: %w", metricID, err)
+		}
+		if len(exemplars) == 0 {
+			continue
+		}
+		var ok bool
+		metricName, ok = idb.searchMetricNameWithCache(metricName[:0], metricID)
+		if !ok {
+			// Skip missing metricName for metricID.
+			// It should be automatically fixed. See indexDB.searchMetricName for details.
+			continue
+		}
+		result = append(result, SeriesExemplars{
+			MetricName: string(metricName),
+			Exemplars:  exemplars,
+		})
+	}
+	qt.Printf("found exemplars for %d series out of %d matching series", len(result), len(metricIDs))
+	return result, nil
+}
+
+const exemplarsFilename = "exemplars"
+
+func (s *Storage) mustLoadExemplars() *exemplarStorage {
+	es := newExemplarStorage(maxExemplars)
+	path := filepath.Join(s.cachePath, exemplarsFilename)
+	if !fs.IsPathExist(path) {
+		return es
+	}
+	if len(es.entries) == 0 {
+		// Exemplars storage is disabled
+		return es
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		logger.Panicf("FATAL: cannot read %s: %s", path, err)
+	}
+	if err := es.unmarshal(src); err != nil {
+		logger.Errorf("discarding %s: %s", path, err)
+		return newExemplarStorage(maxExemplars)
+	}
+	return es
+}
+
+func (s *Storage) mustSaveExemplars() {
+	path := filepath.Join(s.cachePath, exemplarsFilename)
+	dst := s.exemplars.marshal(nil)
+	if err := os.WriteFile(path, dst, 0644); err != nil {
+		logger.Panicf("FATAL: cannot write %d bytes to %q: %s", len(dst), path, err)
+	}
+}
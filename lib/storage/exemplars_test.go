@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompb"
+)
+
+func TestExemplarStorageAdd(t *testing.T) {
+	f := func(maxSize int, rows []ExemplarRow, metricIDs []uint64, expected map[uint64][]int64) {
+		t.Helper()
+		es := newExemplarStorage(maxSize)
+		for i := range rows {
+			r := &rows[i]
+			labels := marshalExemplarLabels(nil, r.Labels)
+			es.add(metricIDs[i], labels, r.Timestamp, r.Value)
+		}
+		tr := TimeRange{
+			MinTimestamp: 0,
+			MaxTimestamp: 1e18,
+		}
+		for metricID, timestampsExpected := range expected {
+			exemplars, err := es.appendExemplars(nil, metricID, tr)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var timestamps []int64
+			for _, e := range exemplars {
+				timestamps = append(timestamps, e.Timestamp)
+			}
+			if !reflect.DeepEqual(timestamps, timestampsExpected) {
+				t.Fatalf("unexpected timestamps for metricID=%d; got %v; want %v", metricID, timestamps, timestampsExpected)
+			}
+		}
+
+		// Verify marshal/unmarshal roundtrip
+		data := es.marshal(nil)
+		es2 := newExemplarStorage(maxSize)
+		if err := es2.unmarshal(data); err != nil {
+			t.Fatalf("cannot unmarshal exemplars: %s", err)
+		}
+		if es2.len() != es.len() {
+			t.Fatalf("unexpected number of exemplars after unmarshal; got %d; want %d", es2.len(), es.len())
+		}
+	}
+
+	newRow := func(traceID string, timestamp int64) ExemplarRow {
+		return ExemplarRow{
+			Labels: []prompb.Label{
+				{
+					Name:  []byte("trace_id"),
+					Value: []byte(traceID),
+				},
+			},
+			Timestamp: timestamp,
+			Value:     1,
+		}
+	}
+
+	// disabled storage
+	f(0, []ExemplarRow{newRow("a", 1)}, []uint64{1}, map[uint64][]int64{
+		1: nil,
+	})
+
+	// multiple series
+	f(10, []ExemplarRow{newRow("a", 1), newRow("b", 2), newRow("c", 3)}, []uint64{1, 2, 1}, map[uint64][]int64{
+		1: {1, 3},
+		2: {2},
+		3: nil,
+	})
+
+	// duplicate and out of order exemplars are skipped
+	f(10, []ExemplarRow{newRow("a", 2), newRow("a", 2), newRow("b", 1), newRow("c", 3)}, []uint64{1, 1, 1, 1}, map[uint64][]int64{
+		1: {2, 3},
+	})
+
+	// the oldest exemplars are evicted
+	f(3, []ExemplarRow{newRow("a", 1), newRow("b", 2), newRow("c", 3), newRow("d", 4), newRow("e", 5)}, []uint64{1, 2, 1, 1, 2}, map[uint64][]int64{
+		1: {3, 4},
+		2: {5},
+	})
+
+	// all the exemplars for a series are evicted
+	f(2, []ExemplarRow{newRow("a", 1), newRow("b", 2), newRow("c", 3)}, []uint64{1, 2, 2}, map[uint64][]int64{
+		1: nil,
+		2: {2, 3},
+	})
+}
+
+func TestStorageAddSearchExemplars(t *testing.T) {
+	path := "TestStorageAddSearchExemplars"
+	s := MustOpenStorage(path, 0, 0, 0)
+
+	now := timestampFromTime(time.Now())
+	var mrs []MetricRow
+	var ers []ExemplarRow
+	for i := 0; i < 3; i++ {
+		mn := MetricName{
+			MetricGroup: []byte("http_request_duration_seconds_bucket"),
+			Tags: []Tag{
+				{[]byte("le"), []byte(fmt.Sprintf("%d", i))},
+			},
+		}
+		metricNameRaw := mn.marshalRaw(nil)
+		mrs = append(mrs, MetricRow{
+			MetricNameRaw: metricNameRaw,
+			Timestamp:     now,
+			Value:         float64(i),
+		})
+		if i == 1 {
+			ers = append(ers, ExemplarRow{
+				MetricNameRaw: metricNameRaw,
+				Labels: []prompb.Label{
+					{
+						Name:  []byte("trace_id"),
+						Value: []byte("abc"),
+					},
+				},
+				Timestamp: now,
+				Value:     0.5,
+			})
+		}
+	}
+	// An exemplar for unknown series must be dropped
+	unknownMN := MetricName{
+		MetricGroup: []byte("unknown"),
+	}
+	ers = append(ers, ExemplarRow{
+		MetricNameRaw: unknownMN.marshalRaw(nil),
+		Timestamp:     now,
+		Value:         1,
+	})
+
+	if err := s.AddRows(mrs, defaultPrecisionBits); err != nil {
+		t.Fatalf("unexpected error when adding rows: %s", err)
+	}
+	s.AddExemplars(ers)
+	s.DebugFlush()
+
+	checkExemplars := func(s *Storage) {
+		t.Helper()
+		tfs := NewTagFilters()
+		if err := tfs.Add(nil, []byte("http_request_duration_seconds_bucket"), false, false); err != nil {
+			t.Fatalf("cannot add tag filter: %s", err)
+		}
+		tr := TimeRange{
+			MinTimestamp: now - 3600*1000,
+			MaxTimestamp: now + 3600*1000,
+		}
+		result, err := s.SearchExemplars(nil, []*TagFilters{tfs}, tr, 1e5, noDeadline)
+		if err != nil {
+			t.Fatalf("unexpected error in SearchExemplars: %s", err)
+		}
+		if len(result) != 1 {
+			t.Fatalf("unexpected number of series with exemplars; got %d; want 1", len(result))
+		}
+		var mn MetricName
+		if err := mn.UnmarshalString(result[0].MetricName); err != nil {
+			t.Fatalf("cannot unmarshal metric name: %s", err)
+		}
+		if string(mn.GetTagValue("le")) != "1" {
+			t.Fatalf("unexpected series returned: %s", &mn)
+		}
+		exemplarsExpected := []Exemplar{
+			{
+				Labels: []prompb.Label{
+					{
+						Name:  []byte("trace_id"),
+						Value: []byte("abc"),
+					},
+				},
+				Timestamp: now,
+				Value:     0.5,
+			},
+		}
+		if !reflect.DeepEqual(result[0].Exemplars, exemplarsExpected) {
+			t.Fatalf("unexpected exemplars\ngot\n%v\nwant\n%v", result[0].Exemplars, exemplarsExpected)
+		}
+	}
+	checkExemplars(s)
+
+	var m Metrics
+	s.UpdateMetrics(&m)
+	if m.ExemplarsUnknownSeries != 1 {
+		t.Fatalf("unexpected number of exemplars for unknown series; got %d; want 1", m.ExemplarsUnknownSeries)
+	}
+
+	// Verify exemplars are persisted across restarts
+	s.MustClose()
+	s = MustOpenStorage(path, 0, 0, 0)
+	checkExemplars(s)
+
+	s.MustClose()
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatalf("cannot remove %q: %s", path, err)
+	}
+}
@@ -94,6 +94,9 @@ type Storage struct {
 	// metricNameCache is MetricID -> MetricName cache.
 	metricNameCache *workingsetcache.Cache
 
+	// exemplars contains the last exemplars for the stored series.
+	exemplars *exemplarStorage
+
 	// dateMetricIDCache is (generation, Date, MetricID) cache, where generation is the indexdb generation.
 	// See generationTSID for details.
 	dateMetricIDCache *dateMetricIDCache
@@ -210,6 +213,7 @@ func MustOpenStorage(path string, retention time.Duration, maxHourlySeries, maxD
 	s.metricIDCache = s.mustLoadCache("metricID_tsid", mem/16)
 	s.metricNameCache = s.mustLoadCache("metricID_metricName", mem/10)
 	s.dateMetricIDCache = newDateMetricIDCache()
+	s.exemplars = s.mustLoadExemplars()
 
 	hour := fasttime.UnixHour()
 	hmCurr := s.mustLoadHourMetricIDs(hour, "curr_hour_metric_ids")
@@ -525,6 +529,12 @@ type Metrics struct {
 	PrefetchedMetricIDsSize      uint64
 	PrefetchedMetricIDsSizeBytes uint64
 
+	ExemplarsCount         uint64
+	ExemplarsMaxCount      uint64
+	ExemplarsAdded         uint64
+	ExemplarsOutOfOrder    uint64
+	ExemplarsUnknownSeries uint64
+
 	NextRetentionSeconds uint64
 
 	IndexDBMetrics IndexDBMetrics
@@ -616,6 +626,12 @@ func (s *Storage) UpdateMetrics(m *Metrics) {
 	m.PrefetchedMetricIDsSize += uint64(prefetchedMetricIDs.Len())
 	m.PrefetchedMetricIDsSizeBytes += uint64(prefetchedMetricIDs.SizeBytes())
 
+	m.ExemplarsCount += uint64(s.exemplars.len())
+	m.ExemplarsMaxCount += uint64(len(s.exemplars.entries))
+	m.ExemplarsAdded += atomic.LoadUint64(&s.exemplars.exemplarsAdded)
+	m.ExemplarsOutOfOrder += atomic.LoadUint64(&s.exemplars.exemplarsOutOfOrder)
+	m.ExemplarsUnknownSeries += atomic.LoadUint64(&s.exemplars.exemplarsUnknownSeries)
+
 	d := s.nextRetentionSeconds()
 	if d < 0 {
 		d = 0
@@ -839,6 +855,7 @@ func (s *Storage) MustClose() {
 	s.metricIDCache.Stop()
 	s.mustSaveCache(s.metricNameCache, "metricID_metricName")
 	s.metricNameCache.Stop()
+	s.mustSaveExemplars()
 
 	hmCurr := s.currHourMetricIDs.Load()
 	s.mustSaveHourMetricIDs(hmCurr, "curr_hour_metric_ids")
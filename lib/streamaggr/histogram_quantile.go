@@ -0,0 +1,340 @@
+package streamaggr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/bytesutil"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
+)
+
+// histogramQuantileAggrState calculates output=histogram_quantile(phi1, ..., phiN),
+// e.g. quantiles over the increase of input Prometheus histogram buckets.
+type histogramQuantileAggrState struct {
+	m sync.Map
+
+	phis []float64
+
+	ignoreInputDeadline uint64
+	stalenessSecs       uint64
+}
+
+type histogramQuantileStateValue struct {
+	mu             sync.Mutex
+	buckets        map[string]*histogramQuantileBucketState
+	deleteDeadline uint64
+	deleted        bool
+}
+
+type histogramQuantileBucketState struct {
+	// le is the value of `le` label for the bucket. It is set to NaN if the bucket has no valid `le` label.
+	le float64
+
+	value          float64
+	increase       float64
+	deleteDeadline uint64
+}
+
+func newHistogramQuantileAggrState(interval time.Duration, stalenessInterval time.Duration, phis []float64) *histogramQuantileAggrState {
+	currentTime := fasttime.UnixTimestamp()
+	intervalSecs := roundDurationToSecs(interval)
+	stalenessSecs := roundDurationToSecs(stalenessInterval)
+	return &histogramQuantileAggrState{
+		phis:                phis,
+		ignoreInputDeadline: currentTime + intervalSecs,
+		stalenessSecs:       stalenessSecs,
+	}
+}
+
+func (as *histogramQuantileAggrState) pushSample(inputKey, outputKey string, value float64, _ int64) {
+	currentTime := fasttime.UnixTimestamp()
+	deleteDeadline := currentTime + as.stalenessSecs
+
+again:
+	v, ok := as.m.Load(outputKey)
+	if !ok {
+		// The entry is missing in the map. Try creating it.
+		v = &histogramQuantileStateValue{
+			buckets: make(map[string]*histogramQuantileBucketState),
+		}
+		vNew, loaded := as.m.LoadOrStore(outputKey, v)
+		if loaded {
+			// Use the entry created by a concurrent goroutine.
+			v = vNew
+		}
+	}
+	sv := v.(*histogramQuantileStateValue)
+	sv.mu.Lock()
+	deleted := sv.deleted
+	if !deleted {
+		bs, ok := sv.buckets[inputKey]
+		if !ok {
+			bs = &histogramQuantileBucketState{
+				le: getBucketLe(inputKey),
+			}
+			sv.buckets[inputKey] = bs
+		}
+		d := value
+		if ok && bs.value <= value {
+			d = value - bs.value
+		}
+		if ok || currentTime > as.ignoreInputDeadline {
+			bs.increase += d
+		}
+		bs.value = value
+		bs.deleteDeadline = deleteDeadline
+		sv.deleteDeadline = deleteDeadline
+	}
+	sv.mu.Unlock()
+	if deleted {
+		// The entry has been deleted by the concurrent call to appendSeriesForFlush
+		// Try obtaining and updating the entry again.
+		goto again
+	}
+}
+
+// getBucketLe returns the value of `le` label from inputKey.
+//
+// NaN is returned if inputKey has no valid `le` label.
+func getBucketLe(inputKey string) float64 {
+	labels, err := unmarshalLabelsFast(nil, bytesutil.ToUnsafeBytes(inputKey))
+	if err != nil {
+		return math.NaN()
+	}
+	for _, label := range labels {
+		if label.Name != "le" {
+			continue
+		}
+		le, err := strconv.ParseFloat(label.Value, 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return le
+	}
+	return math.NaN()
+}
+
+func (as *histogramQuantileAggrState) removeOldEntries(currentTime uint64) {
+	m := &as.m
+	m.Range(func(k, v interface{}) bool {
+		sv := v.(*histogramQuantileStateValue)
+
+		sv.mu.Lock()
+		deleted := currentTime > sv.deleteDeadline
+		if deleted {
+			// Mark the current entry as deleted
+			sv.deleted = deleted
+		} else {
+			// Delete outdated entries in sv.buckets
+			m := sv.buckets
+			for k1, v1 := range m {
+				if currentTime > v1.deleteDeadline {
+					delete(m, k1)
+				}
+			}
+		}
+		sv.mu.Unlock()
+
+		if deleted {
+			m.Delete(k)
+		}
+		return true
+	})
+}
+
+func (as *histogramQuantileAggrState) appendSeriesForFlush(ctx *flushCtx) {
+	currentTime := fasttime.UnixTimestamp()
+	currentTimeMsec := int64(currentTime) * 1000
+
+	as.removeOldEntries(currentTime)
+
+	m := &as.m
+	phis := as.phis
+	var buckets []leBucket
+	var b []byte
+	m.Range(func(k, v interface{}) bool {
+		sv := v.(*histogramQuantileStateValue)
+		sv.mu.Lock()
+		buckets = buckets[:0]
+		for _, bs := range sv.buckets {
+			if !math.IsNaN(bs.le) {
+				buckets = append(buckets, leBucket{
+					le:    bs.le,
+					count: bs.increase,
+				})
+			}
+			bs.increase = 0
+		}
+		deleted := sv.deleted
+		sv.mu.Unlock()
+		if deleted {
+			return true
+		}
+
+		buckets = mergeLeBuckets(buckets)
+		key := k.(string)
+		for _, phi := range phis {
+			q := bucketQuantile(phi, buckets)
+			if math.IsNaN(q) {
+				continue
+			}
+			b = strconv.AppendFloat(b[:0], phi, 'g', -1, 64)
+			phiStr := bytesutil.InternBytes(b)
+			ctx.appendSeriesWithExtraLabel(key, "histogram_quantile", currentTimeMsec, q, "quantile", phiStr)
+		}
+		return true
+	})
+}
+
+type leBucket struct {
+	le    float64
+	count float64
+}
+
+// mergeLeBuckets sorts buckets by le, merges buckets with identical le
+// and makes bucket counts monotonically increasing.
+func mergeLeBuckets(buckets []leBucket) []leBucket {
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].le < buckets[j].le
+	})
+	dst := buckets[:0]
+	for _, b := range buckets {
+		if len(dst) > 0 && dst[len(dst)-1].le == b.le {
+			dst[len(dst)-1].count += b.count
+			continue
+		}
+		dst = append(dst, b)
+	}
+	for i := 1; i < len(dst); i++ {
+		if dst[i].count < dst[i-1].count {
+			dst[i].count = dst[i-1].count
+		}
+	}
+	return dst
+}
+
+// bucketQuantile calculates phi-quantile over the given sorted buckets in the same way as Prometheus does.
+//
+// NaN is returned if the quantile cannot be calculated.
+func bucketQuantile(phi float64, buckets []leBucket) float64 {
+	if len(buckets) < 2 {
+		return math.NaN()
+	}
+	last := buckets[len(buckets)-1]
+	if !math.IsInf(last.le, 1) {
+		return math.NaN()
+	}
+	if phi < 0 {
+		return math.Inf(-1)
+	}
+	if phi > 1 {
+		return math.Inf(1)
+	}
+	total := last.count
+	if total == 0 {
+		return math.NaN()
+	}
+	rank := phi * total
+	i := sort.Search(len(buckets)-1, func(i int) bool {
+		return buckets[i].count >= rank
+	})
+	if i == len(buckets)-1 {
+		// The quantile falls into +Inf bucket. Return the upper bound of the previous bucket.
+		return buckets[len(buckets)-2].le
+	}
+	if i == 0 && buckets[0].le <= 0 {
+		return buckets[0].le
+	}
+	bucketStart := float64(0)
+	bucketEnd := buckets[i].le
+	count := buckets[i].count
+	if i > 0 {
+		bucketStart = buckets[i-1].le
+		count -= buckets[i-1].count
+		rank -= buckets[i-1].count
+	}
+	if count == 0 {
+		return bucketEnd
+	}
+	return bucketStart + (bucketEnd-bucketStart)*(rank/count)
+}
+
+func (as *histogramQuantileAggrState) marshalState(dst []byte) []byte {
+	var tmp []byte
+	n := uint64(0)
+	as.m.Range(func(k, v interface{}) bool {
+		sv := v.(*histogramQuantileStateValue)
+		sv.mu.Lock()
+		if !sv.deleted {
+			tmp = encoding.MarshalBytes(tmp, []byte(k.(string)))
+			tmp = encoding.MarshalVarUint64(tmp, sv.deleteDeadline)
+			tmp = encoding.MarshalVarUint64(tmp, uint64(len(sv.buckets)))
+			for k1, bs := range sv.buckets {
+				tmp = encoding.MarshalBytes(tmp, []byte(k1))
+				tmp = marshalFloat64(tmp, bs.value)
+				tmp = marshalFloat64(tmp, bs.increase)
+				tmp = encoding.MarshalVarUint64(tmp, bs.deleteDeadline)
+			}
+			n++
+		}
+		sv.mu.Unlock()
+		return true
+	})
+	dst = encoding.MarshalVarUint64(dst, n)
+	return append(dst, tmp...)
+}
+
+func (as *histogramQuantileAggrState) unmarshalState(src []byte) error {
+	tail, n, err := encoding.UnmarshalVarUint64(src)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal the number of entries: %w", err)
+	}
+	src = tail
+	for i := uint64(0); i < n; i++ {
+		tail, k, err := unmarshalString(src)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal output key: %w", err)
+		}
+		tail, deleteDeadline, err := encoding.UnmarshalVarUint64(tail)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal deleteDeadline: %w", err)
+		}
+		tail, bucketsLen, err := encoding.UnmarshalVarUint64(tail)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal the number of buckets: %w", err)
+		}
+		buckets := make(map[string]*histogramQuantileBucketState, bucketsLen)
+		for j := uint64(0); j < bucketsLen; j++ {
+			var k1 string
+			bs := &histogramQuantileBucketState{}
+			if tail, k1, err = unmarshalString(tail); err != nil {
+				return fmt.Errorf("cannot unmarshal input key: %w", err)
+			}
+			if tail, bs.value, err = unmarshalFloat64(tail); err != nil {
+				return fmt.Errorf("cannot unmarshal bucket value: %w", err)
+			}
+			if tail, bs.increase, err = unmarshalFloat64(tail); err != nil {
+				return fmt.Errorf("cannot unmarshal bucket increase: %w", err)
+			}
+			if tail, bs.deleteDeadline, err = encoding.UnmarshalVarUint64(tail); err != nil {
+				return fmt.Errorf("cannot unmarshal deleteDeadline for bucket: %w", err)
+			}
+			bs.le = getBucketLe(k1)
+			buckets[k1] = bs
+		}
+		src = tail
+		as.m.Store(k, &histogramQuantileStateValue{
+			buckets:        buckets,
+			deleteDeadline: deleteDeadline,
+		})
+	}
+	if len(src) > 0 {
+		return fmt.Errorf("unexpected non-empty tail left; len(tail)=%d", len(src))
+	}
+	return nil
+}
@@ -1,18 +1,28 @@
 package streamaggr
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
 )
 
-// increaseAggrState calculates output=increase, e.g. the increase over input counters.
+// increaseAggrState calculates output=increase and output=increase_prometheus, e.g. the increase over input counters.
 type increaseAggrState struct {
 	m sync.Map
 
 	ignoreInputDeadline uint64
 	stalenessSecs       uint64
+
+	// ignoreFirstSample is set to true for output=increase_prometheus.
+	// In this case the first sample for new time series isn't counted as increase
+	// in the same way as Prometheus does.
+	ignoreFirstSample bool
+
+	// suffix is the output suffix: either increase or increase_prometheus.
+	suffix string
 }
 
 type increaseStateValue struct {
@@ -23,17 +33,23 @@ type increaseStateValue struct {
 	deleted        bool
 }
 
-func newIncreaseAggrState(interval time.Duration, stalenessInterval time.Duration) *increaseAggrState {
+func newIncreaseAggrState(interval time.Duration, stalenessInterval time.Duration, ignoreFirstSample bool) *increaseAggrState {
 	currentTime := fasttime.UnixTimestamp()
 	intervalSecs := roundDurationToSecs(interval)
 	stalenessSecs := roundDurationToSecs(stalenessInterval)
+	suffix := "increase"
+	if ignoreFirstSample {
+		suffix = "increase_prometheus"
+	}
 	return &increaseAggrState{
 		ignoreInputDeadline: currentTime + intervalSecs,
 		stalenessSecs:       stalenessSecs,
+		ignoreFirstSample:   ignoreFirstSample,
+		suffix:              suffix,
 	}
 }
 
-func (as *increaseAggrState) pushSample(inputKey, outputKey string, value float64) {
+func (as *increaseAggrState) pushSample(inputKey, outputKey string, value float64, _ int64) {
 	currentTime := fasttime.UnixTimestamp()
 	deleteDeadline := currentTime + as.stalenessSecs
 
@@ -63,7 +79,7 @@ again:
 		if ok && lv.value <= value {
 			d = value - lv.value
 		}
-		if ok || currentTime > as.ignoreInputDeadline {
+		if ok || (!as.ignoreFirstSample && currentTime > as.ignoreInputDeadline) {
 			sv.total += d
 		}
 		lv.value = value
@@ -122,8 +138,64 @@ func (as *increaseAggrState) appendSeriesForFlush(ctx *flushCtx) {
 		sv.mu.Unlock()
 		if !deleted {
 			key := k.(string)
-			ctx.appendSeries(key, "increase", currentTimeMsec, increase)
+			ctx.appendSeries(key, as.suffix, currentTimeMsec, increase)
+		}
+		return true
+	})
+}
+
+func (as *increaseAggrState) marshalState(dst []byte) []byte {
+	var tmp []byte
+	n := uint64(0)
+	as.m.Range(func(k, v interface{}) bool {
+		sv := v.(*increaseStateValue)
+		sv.mu.Lock()
+		if !sv.deleted {
+			tmp = encoding.MarshalBytes(tmp, []byte(k.(string)))
+			tmp = marshalFloat64(tmp, sv.total)
+			tmp = encoding.MarshalVarUint64(tmp, sv.deleteDeadline)
+			tmp = marshalLastValues(tmp, sv.lastValues)
+			n++
 		}
+		sv.mu.Unlock()
 		return true
 	})
+	dst = encoding.MarshalVarUint64(dst, n)
+	return append(dst, tmp...)
+}
+
+func (as *increaseAggrState) unmarshalState(src []byte) error {
+	tail, n, err := encoding.UnmarshalVarUint64(src)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal the number of entries: %w", err)
+	}
+	src = tail
+	for i := uint64(0); i < n; i++ {
+		tail, k, err := unmarshalString(src)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal output key: %w", err)
+		}
+		tail, total, err := unmarshalFloat64(tail)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal total: %w", err)
+		}
+		tail, deleteDeadline, err := encoding.UnmarshalVarUint64(tail)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal deleteDeadline: %w", err)
+		}
+		tail, lastValues, err := unmarshalLastValues(tail)
+		if err != nil {
+			return err
+		}
+		src = tail
+		as.m.Store(k, &increaseStateValue{
+			lastValues:     lastValues,
+			total:          total,
+			deleteDeadline: deleteDeadline,
+		})
+	}
+	if len(src) > 0 {
+		return fmt.Errorf("unexpected non-empty tail left; len(tail)=%d", len(src))
+	}
+	return nil
 }
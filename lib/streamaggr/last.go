@@ -21,7 +21,7 @@ func newLastAggrState() *lastAggrState {
 	return &lastAggrState{}
 }
 
-func (as *lastAggrState) pushSample(_, outputKey string, value float64) {
+func (as *lastAggrState) pushSample(_, outputKey string, value float64, _ int64) {
 again:
 	v, ok := as.m.Load(outputKey)
 	if !ok {
@@ -21,7 +21,7 @@ func newMaxAggrState() *maxAggrState {
 	return &maxAggrState{}
 }
 
-func (as *maxAggrState) pushSample(_, outputKey string, value float64) {
+func (as *maxAggrState) pushSample(_, outputKey string, value float64, _ int64) {
 again:
 	v, ok := as.m.Load(outputKey)
 	if !ok {
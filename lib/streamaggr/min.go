@@ -21,7 +21,7 @@ func newMinAggrState() *minAggrState {
 	return &minAggrState{}
 }
 
-func (as *minAggrState) pushSample(_, outputKey string, value float64) {
+func (as *minAggrState) pushSample(_, outputKey string, value float64, _ int64) {
 again:
 	v, ok := as.m.Load(outputKey)
 	if !ok {
@@ -28,7 +28,7 @@ func newQuantilesAggrState(phis []float64) *quantilesAggrState {
 	}
 }
 
-func (as *quantilesAggrState) pushSample(_, outputKey string, value float64) {
+func (as *quantilesAggrState) pushSample(_, outputKey string, value float64, _ int64) {
 again:
 	v, ok := as.m.Load(outputKey)
 	if !ok {
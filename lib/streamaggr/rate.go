@@ -0,0 +1,252 @@
+package streamaggr
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
+)
+
+// rateAggrState calculates output=rate_sum and output=rate_avg, e.g. the sum or the average of per-second increase rates over input counters.
+type rateAggrState struct {
+	m sync.Map
+
+	stalenessSecs uint64
+
+	// suffix is the output suffix: either rate_sum or rate_avg.
+	suffix string
+}
+
+type rateStateValue struct {
+	mu             sync.Mutex
+	lastValues     map[string]*rateLastValueState
+	deleteDeadline uint64
+	deleted        bool
+}
+
+type rateLastValueState struct {
+	value          float64
+	timestamp      int64
+	deleteDeadline uint64
+
+	// prevTimestamp is the timestamp of the last sample seen at the previous flush.
+	prevTimestamp int64
+
+	// total is the increase of the counter since prevTimestamp.
+	total float64
+}
+
+func newRateAggrState(stalenessInterval time.Duration, suffix string) *rateAggrState {
+	stalenessSecs := roundDurationToSecs(stalenessInterval)
+	return &rateAggrState{
+		stalenessSecs: stalenessSecs,
+		suffix:        suffix,
+	}
+}
+
+func (as *rateAggrState) pushSample(inputKey, outputKey string, value float64, timestamp int64) {
+	currentTime := fasttime.UnixTimestamp()
+	deleteDeadline := currentTime + as.stalenessSecs
+	if timestamp <= 0 {
+		// Samples without timestamps are treated as received at the current time.
+		timestamp = int64(currentTime) * 1000
+	}
+
+again:
+	v, ok := as.m.Load(outputKey)
+	if !ok {
+		// The entry is missing in the map. Try creating it.
+		v = &rateStateValue{
+			lastValues: make(map[string]*rateLastValueState),
+		}
+		vNew, loaded := as.m.LoadOrStore(outputKey, v)
+		if loaded {
+			// Use the entry created by a concurrent goroutine.
+			v = vNew
+		}
+	}
+	sv := v.(*rateStateValue)
+	sv.mu.Lock()
+	deleted := sv.deleted
+	if !deleted {
+		lv, ok := sv.lastValues[inputKey]
+		if ok {
+			if timestamp < lv.timestamp {
+				// Skip out of order sample
+				sv.mu.Unlock()
+				return
+			}
+			if lv.prevTimestamp == 0 {
+				lv.prevTimestamp = lv.timestamp
+			}
+			d := value
+			if lv.value <= value {
+				d = value - lv.value
+			}
+			lv.total += d
+		} else {
+			lv = &rateLastValueState{}
+			sv.lastValues[inputKey] = lv
+		}
+		lv.value = value
+		lv.timestamp = timestamp
+		lv.deleteDeadline = deleteDeadline
+		sv.deleteDeadline = deleteDeadline
+	}
+	sv.mu.Unlock()
+	if deleted {
+		// The entry has been deleted by the concurrent call to appendSeriesForFlush
+		// Try obtaining and updating the entry again.
+		goto again
+	}
+}
+
+func (as *rateAggrState) removeOldEntries(currentTime uint64) {
+	m := &as.m
+	m.Range(func(k, v interface{}) bool {
+		sv := v.(*rateStateValue)
+
+		sv.mu.Lock()
+		deleted := currentTime > sv.deleteDeadline
+		if deleted {
+			// Mark the current entry as deleted
+			sv.deleted = deleted
+		} else {
+			// Delete outdated entries in sv.lastValues
+			m := sv.lastValues
+			for k1, v1 := range m {
+				if currentTime > v1.deleteDeadline {
+					delete(m, k1)
+				}
+			}
+		}
+		sv.mu.Unlock()
+
+		if deleted {
+			m.Delete(k)
+		}
+		return true
+	})
+}
+
+func (as *rateAggrState) appendSeriesForFlush(ctx *flushCtx) {
+	currentTime := fasttime.UnixTimestamp()
+	currentTimeMsec := int64(currentTime) * 1000
+
+	as.removeOldEntries(currentTime)
+
+	m := &as.m
+	m.Range(func(k, v interface{}) bool {
+		sv := v.(*rateStateValue)
+		sv.mu.Lock()
+		rate := 0.0
+		count := 0
+		for _, lv := range sv.lastValues {
+			if lv.prevTimestamp == 0 {
+				continue
+			}
+			d := float64(lv.timestamp-lv.prevTimestamp) / 1000
+			if d > 0 {
+				rate += lv.total / d
+				count++
+			}
+			lv.prevTimestamp = lv.timestamp
+			lv.total = 0
+		}
+		deleted := sv.deleted
+		sv.mu.Unlock()
+		if deleted || count == 0 {
+			return true
+		}
+		if as.suffix == "rate_avg" {
+			rate /= float64(count)
+		}
+		key := k.(string)
+		ctx.appendSeries(key, as.suffix, currentTimeMsec, rate)
+		return true
+	})
+}
+
+func (as *rateAggrState) marshalState(dst []byte) []byte {
+	var tmp []byte
+	n := uint64(0)
+	as.m.Range(func(k, v interface{}) bool {
+		sv := v.(*rateStateValue)
+		sv.mu.Lock()
+		if !sv.deleted {
+			tmp = encoding.MarshalBytes(tmp, []byte(k.(string)))
+			tmp = encoding.MarshalVarUint64(tmp, sv.deleteDeadline)
+			tmp = encoding.MarshalVarUint64(tmp, uint64(len(sv.lastValues)))
+			for k1, lv := range sv.lastValues {
+				tmp = encoding.MarshalBytes(tmp, []byte(k1))
+				tmp = marshalFloat64(tmp, lv.value)
+				tmp = encoding.MarshalVarInt64(tmp, lv.timestamp)
+				tmp = encoding.MarshalVarUint64(tmp, lv.deleteDeadline)
+				tmp = encoding.MarshalVarInt64(tmp, lv.prevTimestamp)
+				tmp = marshalFloat64(tmp, lv.total)
+			}
+			n++
+		}
+		sv.mu.Unlock()
+		return true
+	})
+	dst = encoding.MarshalVarUint64(dst, n)
+	return append(dst, tmp...)
+}
+
+func (as *rateAggrState) unmarshalState(src []byte) error {
+	tail, n, err := encoding.UnmarshalVarUint64(src)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal the number of entries: %w", err)
+	}
+	src = tail
+	for i := uint64(0); i < n; i++ {
+		tail, k, err := unmarshalString(src)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal output key: %w", err)
+		}
+		tail, deleteDeadline, err := encoding.UnmarshalVarUint64(tail)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal deleteDeadline: %w", err)
+		}
+		tail, valuesLen, err := encoding.UnmarshalVarUint64(tail)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal the number of last values: %w", err)
+		}
+		lastValues := make(map[string]*rateLastValueState, valuesLen)
+		for j := uint64(0); j < valuesLen; j++ {
+			var k1 string
+			lv := &rateLastValueState{}
+			if tail, k1, err = unmarshalString(tail); err != nil {
+				return fmt.Errorf("cannot unmarshal input key: %w", err)
+			}
+			if tail, lv.value, err = unmarshalFloat64(tail); err != nil {
+				return fmt.Errorf("cannot unmarshal last value: %w", err)
+			}
+			if tail, lv.timestamp, err = encoding.UnmarshalVarInt64(tail); err != nil {
+				return fmt.Errorf("cannot unmarshal last timestamp: %w", err)
+			}
+			if tail, lv.deleteDeadline, err = encoding.UnmarshalVarUint64(tail); err != nil {
+				return fmt.Errorf("cannot unmarshal deleteDeadline for last value: %w", err)
+			}
+			if tail, lv.prevTimestamp, err = encoding.UnmarshalVarInt64(tail); err != nil {
+				return fmt.Errorf("cannot unmarshal previous timestamp: %w", err)
+			}
+			if tail, lv.total, err = unmarshalFloat64(tail); err != nil {
+				return fmt.Errorf("cannot unmarshal total: %w", err)
+			}
+			lastValues[k1] = lv
+		}
+		src = tail
+		as.m.Store(k, &rateStateValue{
+			lastValues:     lastValues,
+			deleteDeadline: deleteDeadline,
+		})
+	}
+	if len(src) > 0 {
+		return fmt.Errorf("unexpected non-empty tail left; len(tail)=%d", len(src))
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package streamaggr
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/logger"
+	"github.com/cespare/xxhash/v2"
+)
+
+// aggrStatePersister must be implemented by aggrState, which keeps its state across flushes,
+// so the state can be persisted across restarts.
+type aggrStatePersister interface {
+	// marshalState appends the marshaled state to dst and returns the result.
+	marshalState(dst []byte) []byte
+
+	// unmarshalState restores the state from src obtained via marshalState.
+	unmarshalState(src []byte) error
+}
+
+// stateFormatVersion must be incremented on every incompatible change in the persisted state format.
+const stateFormatVersion = 1
+
+// MustSaveState saves the state of a to the file at path, so it can be restored with LoadState after the restart.
+//
+// Only the state for outputs, which keep it across aggregation intervals, is saved.
+// For example, total, increase, increase_prometheus, rate_sum, rate_avg and histogram_quantile.
+//
+// MustSaveState must be called after MustStop, so the state isn't modified concurrently.
+func (a *Aggregators) MustSaveState(path string) {
+	if a == nil {
+		return
+	}
+	dst := encoding.MarshalUint64(nil, stateFormatVersion)
+	dst = encoding.MarshalUint64(dst, xxhash.Sum64(a.configData))
+	dst = encoding.MarshalVarUint64(dst, uint64(len(a.as)))
+	var buf []byte
+	for _, aggr := range a.as {
+		dst = encoding.MarshalVarUint64(dst, uint64(len(aggr.aggrStates)))
+		for _, as := range aggr.aggrStates {
+			buf = buf[:0]
+			if asp, ok := as.(aggrStatePersister); ok {
+				buf = asp.marshalState(buf)
+			}
+			dst = encoding.MarshalBytes(dst, buf)
+		}
+	}
+	fs.MustMkdirIfNotExist(filepath.Dir(path))
+	fs.MustWriteAtomic(path, dst, true)
+	logger.Infof("saved stream aggregation state to %q; size: %d bytes", path, len(dst))
+}
+
+// LoadState restores the state of a from the file at path created with MustSaveState.
+//
+// The file is removed after the state is loaded, so the same state isn't restored twice
+// after an unclean shutdown.
+//
+// An error is returned if the state cannot be restored, e.g. if it was saved for a different aggregation config.
+// It is safe to continue using a with empty state in this case.
+func (a *Aggregators) LoadState(path string) error {
+	if a == nil || !fs.IsPathExist(path) {
+		return nil
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read stream aggregation state: %w", err)
+	}
+	fs.MustRemoveAll(path)
+
+	if len(src) < 16 {
+		return fmt.Errorf("cannot unmarshal state header from %d bytes; need at least 16 bytes", len(src))
+	}
+	version := encoding.UnmarshalUint64(src)
+	if version != stateFormatVersion {
+		return fmt.Errorf("unsupported state format version; got %d; want %d", version, stateFormatVersion)
+	}
+	configHash := encoding.UnmarshalUint64(src[8:])
+	if configHash != xxhash.Sum64(a.configData) {
+		return fmt.Errorf("the state has been saved for different stream aggregation config")
+	}
+	src = src[16:]
+
+	tail, n, err := encoding.UnmarshalVarUint64(src)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal the number of aggregators: %w", err)
+	}
+	if n != uint64(len(a.as)) {
+		return fmt.Errorf("unexpected number of aggregators; got %d; want %d", n, len(a.as))
+	}
+	src = tail
+	for i, aggr := range a.as {
+		tail, n, err := encoding.UnmarshalVarUint64(src)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal the number of outputs for aggregator #%d: %w", i, err)
+		}
+		if n != uint64(len(aggr.aggrStates)) {
+			return fmt.Errorf("unexpected number of outputs for aggregator #%d; got %d; want %d", i, n, len(aggr.aggrStates))
+		}
+		src = tail
+		for j, as := range aggr.aggrStates {
+			tail, data, err := encoding.UnmarshalBytes(src)
+			if err != nil {
+				return fmt.Errorf("cannot read the state for output #%d at aggregator #%d: %w", j, i, err)
+			}
+			src = tail
+			asp, ok := as.(aggrStatePersister)
+			if !ok || len(data) == 0 {
+				continue
+			}
+			if err := asp.unmarshalState(data); err != nil {
+				return fmt.Errorf("cannot unmarshal the state for output #%d at aggregator #%d: %w", j, i, err)
+			}
+		}
+	}
+	if len(src) > 0 {
+		return fmt.Errorf("unexpected non-empty tail left after unmarshaling the state; len(tail)=%d", len(src))
+	}
+	return nil
+}
+
+func marshalFloat64(dst []byte, v float64) []byte {
+	return encoding.MarshalUint64(dst, math.Float64bits(v))
+}
+
+func unmarshalFloat64(src []byte) ([]byte, float64, error) {
+	if len(src) < 8 {
+		return src, 0, fmt.Errorf("cannot unmarshal float64 from %d bytes; need at least 8 bytes", len(src))
+	}
+	v := math.Float64frombits(encoding.UnmarshalUint64(src))
+	return src[8:], v, nil
+}
+
+func unmarshalString(src []byte) ([]byte, string, error) {
+	tail, b, err := encoding.UnmarshalBytes(src)
+	if err != nil {
+		return src, "", err
+	}
+	return tail, string(b), nil
+}
+
+// marshalLastValues appends the marshaled m to dst and returns the result.
+func marshalLastValues(dst []byte, m map[string]*lastValueState) []byte {
+	dst = encoding.MarshalVarUint64(dst, uint64(len(m)))
+	for k, lv := range m {
+		dst = encoding.MarshalBytes(dst, []byte(k))
+		dst = marshalFloat64(dst, lv.value)
+		dst = encoding.MarshalVarUint64(dst, lv.deleteDeadline)
+	}
+	return dst
+}
+
+// unmarshalLastValues unmarshals lastValues from src obtained via marshalLastValues.
+func unmarshalLastValues(src []byte) ([]byte, map[string]*lastValueState, error) {
+	tail, n, err := encoding.UnmarshalVarUint64(src)
+	if err != nil {
+		return src, nil, fmt.Errorf("cannot unmarshal the number of last values: %w", err)
+	}
+	src = tail
+	m := make(map[string]*lastValueState, n)
+	for i := uint64(0); i < n; i++ {
+		tail, k, err := unmarshalString(src)
+		if err != nil {
+			return src, nil, fmt.Errorf("cannot unmarshal input key: %w", err)
+		}
+		tail, value, err := unmarshalFloat64(tail)
+		if err != nil {
+			return src, nil, fmt.Errorf("cannot unmarshal last value: %w", err)
+		}
+		tail, deleteDeadline, err := encoding.UnmarshalVarUint64(tail)
+		if err != nil {
+			return src, nil, fmt.Errorf("cannot unmarshal deleteDeadline: %w", err)
+		}
+		src = tail
+		m[k] = &lastValueState{
+			value:          value,
+			deleteDeadline: deleteDeadline,
+		}
+	}
+	return src, m, nil
+}
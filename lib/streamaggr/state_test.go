@@ -0,0 +1,125 @@
+package streamaggr
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fs"
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/prompbmarshal"
+)
+
+func TestAggregatorsSaveLoadState(t *testing.T) {
+	const config = `
+- match: foo
+  interval: 1m
+  outputs: [total, increase, rate_sum]
+- match: req_bucket
+  interval: 1m
+  without: [le]
+  outputs: ["histogram_quantile(0.5)", count_samples]
+`
+	path := filepath.Join(t.TempDir(), "state")
+
+	newAggregators := func(config string) (*Aggregators, func() string) {
+		t.Helper()
+		var tssOutput []prompbmarshal.TimeSeries
+		var tssOutputLock sync.Mutex
+		pushFunc := func(tss []prompbmarshal.TimeSeries) {
+			tssOutputLock.Lock()
+			for _, ts := range tss {
+				tssOutput = append(tssOutput, prompbmarshal.TimeSeries{
+					Labels:  append([]prompbmarshal.Label{}, ts.Labels...),
+					Samples: append([]prompbmarshal.Sample{}, ts.Samples...),
+				})
+			}
+			tssOutputLock.Unlock()
+		}
+		a, err := NewAggregatorsFromData([]byte(config), pushFunc, 0)
+		if err != nil {
+			t.Fatalf("cannot initialize aggregators: %s", err)
+		}
+		getOutput := func() string {
+			tssOutputLock.Lock()
+			defer tssOutputLock.Unlock()
+			tsStrings := make([]string, len(tssOutput))
+			for i, ts := range tssOutput {
+				tsStrings[i] = timeSeriesToString(ts)
+			}
+			sort.Strings(tsStrings)
+			return strings.Join(tsStrings, "")
+		}
+		return a, getOutput
+	}
+
+	// Aggregate samples and save the state
+	a, getOutput := newAggregators(config)
+	a.Push(mustParsePromMetrics(`
+foo 1 1
+foo 3 11
+req_bucket{le="1"} 0
+req_bucket{le="+Inf"} 0
+req_bucket{le="1"} 10
+req_bucket{le="+Inf"} 10
+`), nil)
+	a.MustStop()
+	a.MustSaveState(path)
+	outputExpected := `foo:1m_increase 2
+foo:1m_rate_sum 0.2
+foo:1m_total 2
+req_bucket:1m_without_le_count_samples 4
+req_bucket:1m_without_le_histogram_quantile{quantile="0.5"} 0.5
+`
+	if output := getOutput(); output != outputExpected {
+		t.Fatalf("unexpected output before saving the state;\ngot\n%s\nwant\n%s", output, outputExpected)
+	}
+
+	// Restore the state and continue the aggregation
+	a, getOutput = newAggregators(config)
+	if err := a.LoadState(path); err != nil {
+		t.Fatalf("cannot load state: %s", err)
+	}
+	if fs.IsPathExist(path) {
+		t.Fatalf("the state file at %q must be removed after loading", path)
+	}
+	a.Push(mustParsePromMetrics(`
+foo 5 21
+req_bucket{le="1"} 20
+req_bucket{le="+Inf"} 30
+`), nil)
+	a.MustStop()
+	a.MustSaveState(path)
+	outputExpected = `foo:1m_increase 2
+foo:1m_rate_sum 0.2
+foo:1m_total 4
+req_bucket:1m_without_le_count_samples 2
+req_bucket:1m_without_le_histogram_quantile{quantile="0.5"} 1
+`
+	if output := getOutput(); output != outputExpected {
+		t.Fatalf("unexpected output after restoring the state;\ngot\n%s\nwant\n%s", output, outputExpected)
+	}
+
+	// The state must be discarded for a different config
+	a, getOutput = newAggregators(`
+- interval: 1m
+  outputs: [total]
+`)
+	if err := a.LoadState(path); err == nil {
+		t.Fatalf("expecting non-nil error when loading the state for different config")
+	}
+	a.Push(mustParsePromMetrics(`foo 7 31`), nil)
+	a.MustStop()
+	outputExpected = "foo:1m_total 0\n"
+	if output := getOutput(); output != outputExpected {
+		t.Fatalf("unexpected output for discarded state;\ngot\n%s\nwant\n%s", output, outputExpected)
+	}
+
+	// Missing state file isn't an error
+	a, _ = newAggregators(config)
+	if err := a.LoadState(path); err != nil {
+		t.Fatalf("unexpected error when loading missing state: %s", err)
+	}
+	a.MustStop()
+}
@@ -24,8 +24,12 @@ import (
 var supportedOutputs = []string{
 	"total",
 	"increase",
+	"increase_prometheus",
+	"rate_sum",
+	"rate_avg",
 	"count_series",
 	"count_samples",
+	"unique_samples",
 	"sum_samples",
 	"last",
 	"min",
@@ -35,6 +39,7 @@ var supportedOutputs = []string{
 	"stdvar",
 	"histogram_bucket",
 	"quantiles(phi1, ..., phiN)",
+	"histogram_quantile(phi1, ..., phiN)",
 }
 
 // LoadFromFile loads Aggregators from the given path and uses the given pushFunc for pushing the aggregated data.
@@ -80,7 +85,8 @@ type Config struct {
 	Interval string `yaml:"interval"`
 
 	// Staleness interval is interval after which the series state will be reset if no samples have been sent during it.
-	// The parameter is only relevant for outputs: total, increase and histogram_bucket.
+	// The parameter is only relevant for outputs: total, increase, increase_prometheus, rate_sum, rate_avg,
+	// histogram_bucket and histogram_quantile.
 	StalenessInterval string `yaml:"staleness_interval,omitempty"`
 
 	// Outputs is a list of output aggregate functions to produce.
@@ -89,8 +95,12 @@ type Config struct {
 	//
 	// - total - aggregates input counters
 	// - increase - counts the increase over input counters
+	// - increase_prometheus - counts the increase over input counters, ignoring the first sample in new time series
+	// - rate_sum - sums per-series rates over input counters
+	// - rate_avg - averages per-series rates over input counters
 	// - count_series - counts the input series
 	// - count_samples - counts the input samples
+	// - unique_samples - counts the number of unique sample values
 	// - sum_samples - sums the input samples
 	// - last - the last biggest sample value
 	// - min - the minimum sample value
@@ -100,6 +110,7 @@ type Config struct {
 	// - stdvar - standard variance across all the samples
 	// - histogram_bucket - creates VictoriaMetrics histogram for input samples
 	// - quantiles(phi1, ..., phiN) - quantiles' estimation for phi in the range [0..1]
+	// - histogram_quantile(phi1, ..., phiN) - quantiles' estimation over input histogram buckets with `le` label
 	//
 	// The output time series will have the following names:
 	//
@@ -247,7 +258,7 @@ type aggregator struct {
 }
 
 type aggrState interface {
-	pushSample(inputKey, outputKey string, value float64)
+	pushSample(inputKey, outputKey string, value float64, timestamp int64)
 	appendSeriesForFlush(ctx *flushCtx)
 }
 
@@ -311,38 +322,41 @@ func newAggregator(cfg *Config, pushFunc PushFunc, dedupInterval time.Duration)
 	aggrStates := make([]aggrState, len(cfg.Outputs))
 	for i, output := range cfg.Outputs {
 		if strings.HasPrefix(output, "quantiles(") {
-			if !strings.HasSuffix(output, ")") {
-				return nil, fmt.Errorf("missing closing brace for `quantiles()` output")
+			phis, err := parsePhis("quantiles", output)
+			if err != nil {
+				return nil, err
 			}
-			argsStr := output[len("quantiles(") : len(output)-1]
-			if len(argsStr) == 0 {
-				return nil, fmt.Errorf("`quantiles()` must contain at least one phi")
+			aggrStates[i] = newQuantilesAggrState(phis)
+			continue
+		}
+		if strings.HasPrefix(output, "histogram_quantile(") {
+			phis, err := parsePhis("histogram_quantile", output)
+			if err != nil {
+				return nil, err
 			}
-			args := strings.Split(argsStr, ",")
-			phis := make([]float64, len(args))
-			for j, arg := range args {
-				arg = strings.TrimSpace(arg)
-				phi, err := strconv.ParseFloat(arg, 64)
-				if err != nil {
-					return nil, fmt.Errorf("cannot parse phi=%q for quantiles(%s): %w", arg, argsStr, err)
-				}
-				if phi < 0 || phi > 1 {
-					return nil, fmt.Errorf("phi inside quantiles(%s) must be in the range [0..1]; got %v", argsStr, phi)
-				}
-				phis[j] = phi
+			if aggregateOnlyByTime || hasInArray("le", by) || (len(without) > 0 && !hasInArray("le", without)) {
+				return nil, fmt.Errorf("`le` label must be removed from the output via `without` or `by` list for `%s` output", output)
 			}
-			aggrStates[i] = newQuantilesAggrState(phis)
+			aggrStates[i] = newHistogramQuantileAggrState(interval, stalenessInterval, phis)
 			continue
 		}
 		switch output {
 		case "total":
 			aggrStates[i] = newTotalAggrState(interval, stalenessInterval)
 		case "increase":
-			aggrStates[i] = newIncreaseAggrState(interval, stalenessInterval)
+			aggrStates[i] = newIncreaseAggrState(interval, stalenessInterval, false)
+		case "increase_prometheus":
+			aggrStates[i] = newIncreaseAggrState(interval, stalenessInterval, true)
+		case "rate_sum":
+			aggrStates[i] = newRateAggrState(stalenessInterval, "rate_sum")
+		case "rate_avg":
+			aggrStates[i] = newRateAggrState(stalenessInterval, "rate_avg")
 		case "count_series":
 			aggrStates[i] = newCountSeriesAggrState()
 		case "count_samples":
 			aggrStates[i] = newCountSamplesAggrState()
+		case "unique_samples":
+			aggrStates[i] = newUniqueSamplesAggrState()
 		case "sum_samples":
 			aggrStates[i] = newSumSamplesAggrState()
 		case "last":
@@ -416,6 +430,31 @@ func newAggregator(cfg *Config, pushFunc PushFunc, dedupInterval time.Duration)
 	return a, nil
 }
 
+// parsePhis parses phis from `name(phi1, ..., phiN)` output.
+func parsePhis(name, output string) ([]float64, error) {
+	if !strings.HasSuffix(output, ")") {
+		return nil, fmt.Errorf("missing closing brace for `%s()` output", name)
+	}
+	argsStr := output[len(name)+1 : len(output)-1]
+	if len(argsStr) == 0 {
+		return nil, fmt.Errorf("`%s()` must contain at least one phi", name)
+	}
+	args := strings.Split(argsStr, ",")
+	phis := make([]float64, len(args))
+	for j, arg := range args {
+		arg = strings.TrimSpace(arg)
+		phi, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse phi=%q for %s(%s): %w", arg, name, argsStr, err)
+		}
+		if phi < 0 || phi > 1 {
+			return nil, fmt.Errorf("phi inside %s(%s) must be in the range [0..1]; got %v", name, argsStr, phi)
+		}
+		phis[j] = phi
+	}
+	return phis, nil
+}
+
 func (a *aggregator) runDedupFlusher(interval time.Duration) {
 	t := time.NewTicker(interval)
 	defer t.Stop()
@@ -540,7 +579,7 @@ func (a *aggregator) Push(tss []prompbmarshal.TimeSeries, matchIdxs []byte) {
 		bb.B = marshalLabelsFast(bb.B[:0], labels.Labels)
 		outputKey := bytesutil.InternBytes(bb.B)
 		for _, sample := range ts.Samples {
-			pushSample(inputKey, outputKey, sample.Value)
+			pushSample(inputKey, outputKey, sample.Value, sample.Timestamp)
 		}
 	}
 	promutils.PutLabels(labels)
@@ -585,7 +624,7 @@ func (a *aggregator) push(tss []prompbmarshal.TimeSeries, matchIdxs []byte) {
 		}
 
 		for _, sample := range ts.Samples {
-			a.pushSample(inputKey, outputKey, sample.Value)
+			a.pushSample(inputKey, outputKey, sample.Value, sample.Timestamp)
 		}
 	}
 	bbPool.Put(bb)
@@ -595,13 +634,13 @@ func (a *aggregator) push(tss []prompbmarshal.TimeSeries, matchIdxs []byte) {
 
 var bbPool bytesutil.ByteBufferPool
 
-func (a *aggregator) pushSample(inputKey, outputKey string, value float64) {
+func (a *aggregator) pushSample(inputKey, outputKey string, value float64, timestamp int64) {
 	if math.IsNaN(value) {
 		// Skip nan samples
 		return
 	}
 	for _, as := range a.aggrStates {
-		as.pushSample(inputKey, outputKey, value)
+		as.pushSample(inputKey, outputKey, value, timestamp)
 	}
 }
 
@@ -117,6 +117,39 @@ func TestAggregatorsFailure(t *testing.T) {
 - interval: 1m
   outputs: ["quantiles(1.5)"]
 `)
+
+	// Invalid histogram_quantile()
+	f(`
+- interval: 1m
+  without: [le]
+  outputs: ["histogram_quantile("]
+`)
+	f(`
+- interval: 1m
+  without: [le]
+  outputs: ["histogram_quantile()"]
+`)
+	f(`
+- interval: 1m
+  without: [le]
+  outputs: ["histogram_quantile(1.5)"]
+`)
+
+	// histogram_quantile() must drop le label
+	f(`
+- interval: 1m
+  outputs: ["histogram_quantile(0.5)"]
+`)
+	f(`
+- interval: 1m
+  by: [le]
+  outputs: ["histogram_quantile(0.5)"]
+`)
+	f(`
+- interval: 1m
+  without: [instance]
+  outputs: ["histogram_quantile(0.5)"]
+`)
 }
 
 func TestAggregatorsEqual(t *testing.T) {
@@ -534,6 +567,69 @@ foo:1m_increase 0
 foo:1m_increase{baz="qwe"} 15
 `, "11111111")
 
+	// increase_prometheus output for repeated series
+	f(`
+- interval: 1m
+  outputs: [increase_prometheus]
+`, `
+foo 123
+bar{baz="qwe"} 1.32
+bar{baz="qwe"} 4.34
+bar{baz="qwe"} 2
+foo{baz="qwe"} -5
+bar{baz="qwer"} 343
+bar{baz="qwer"} 344
+foo{baz="qwe"} 10
+`, `bar:1m_increase_prometheus{baz="qwe"} 5.02
+bar:1m_increase_prometheus{baz="qwer"} 1
+foo:1m_increase_prometheus 0
+foo:1m_increase_prometheus{baz="qwe"} 15
+`, "11111111")
+
+	// rate_sum and rate_avg outputs
+	f(`
+- interval: 1m
+  without: [instance]
+  outputs: [rate_sum, rate_avg]
+`, `
+foo{instance="a"} 10 1
+foo{instance="b"} 5 1
+foo{instance="a"} 30 11
+foo{instance="b"} 25 6
+bar{instance="a"} 10 1
+`, `foo:1m_without_instance_rate_avg 3
+foo:1m_without_instance_rate_sum 6
+`, "11111")
+
+	// unique_samples output
+	f(`
+- interval: 1m
+  outputs: [unique_samples]
+`, `
+foo 1
+foo 2
+foo 1
+bar{baz="qwe"} 3
+`, `bar:1m_unique_samples{baz="qwe"} 1
+foo:1m_unique_samples 2
+`, "1111")
+
+	// histogram_quantile output
+	f(`
+- interval: 1m
+  without: [le]
+  outputs: ["histogram_quantile(0.5, 0.75)"]
+`, `
+req_bucket{le="1"} 0
+req_bucket{le="2"} 0
+req_bucket{le="+Inf"} 0
+req_bucket{le="1"} 10
+req_bucket{le="2"} 20
+req_bucket{le="+Inf"} 20
+`, `req_bucket:1m_without_le_histogram_quantile{quantile="0.5"} 1
+req_bucket:1m_without_le_histogram_quantile{quantile="0.75"} 1.5
+`, "111111")
+
 	// multiple aggregate configs
 	f(`
 - interval: 1m
@@ -1,10 +1,12 @@
 package streamaggr
 
 import (
+	"fmt"
 	"math"
 	"sync"
 	"time"
 
+	"github.com/VictoriaMetrics/VictoriaMetrics/lib/encoding"
 	"github.com/VictoriaMetrics/VictoriaMetrics/lib/fasttime"
 )
 
@@ -39,7 +41,7 @@ func newTotalAggrState(interval time.Duration, stalenessInterval time.Duration)
 	}
 }
 
-func (as *totalAggrState) pushSample(inputKey, outputKey string, value float64) {
+func (as *totalAggrState) pushSample(inputKey, outputKey string, value float64, _ int64) {
 	currentTime := fasttime.UnixTimestamp()
 	deleteDeadline := currentTime + as.stalenessSecs
 
@@ -136,3 +138,59 @@ func (as *totalAggrState) appendSeriesForFlush(ctx *flushCtx) {
 		return true
 	})
 }
+
+func (as *totalAggrState) marshalState(dst []byte) []byte {
+	var tmp []byte
+	n := uint64(0)
+	as.m.Range(func(k, v interface{}) bool {
+		sv := v.(*totalStateValue)
+		sv.mu.Lock()
+		if !sv.deleted {
+			tmp = encoding.MarshalBytes(tmp, []byte(k.(string)))
+			tmp = marshalFloat64(tmp, sv.total)
+			tmp = encoding.MarshalVarUint64(tmp, sv.deleteDeadline)
+			tmp = marshalLastValues(tmp, sv.lastValues)
+			n++
+		}
+		sv.mu.Unlock()
+		return true
+	})
+	dst = encoding.MarshalVarUint64(dst, n)
+	return append(dst, tmp...)
+}
+
+func (as *totalAggrState) unmarshalState(src []byte) error {
+	tail, n, err := encoding.UnmarshalVarUint64(src)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal the number of entries: %w", err)
+	}
+	src = tail
+	for i := uint64(0); i < n; i++ {
+		tail, k, err := unmarshalString(src)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal output key: %w", err)
+		}
+		tail, total, err := unmarshalFloat64(tail)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal total: %w", err)
+		}
+		tail, deleteDeadline, err := encoding.UnmarshalVarUint64(tail)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal deleteDeadline: %w", err)
+		}
+		tail, lastValues, err := unmarshalLastValues(tail)
+		if err != nil {
+			return err
+		}
+		src = tail
+		as.m.Store(k, &totalStateValue{
+			lastValues:     lastValues,
+			total:          total,
+			deleteDeadline: deleteDeadline,
+		})
+	}
+	if len(src) > 0 {
+		return fmt.Errorf("unexpected non-empty tail left; len(tail)=%d", len(src))
+	}
+	return nil
+}